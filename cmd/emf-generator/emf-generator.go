@@ -6,12 +6,15 @@ package main
 import (
 	"flag"
 	"fmt"
+	"log"
 	"math/rand"
 	"os"
 	"path"
 	"runtime"
-	"strconv"
+	"strings"
 	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common/emf"
 )
 
 const (
@@ -25,8 +28,23 @@ var (
 	structuredLogDir = flag.String("path", "", "Identify the directory where the structured log files will be generated.")
 	filePrefix       = flag.String("filePrefix", "structuredLogFile", "Identify the structured log file prefix")
 	runTime          = flag.Duration("runTime", 48*time.Hour, "Run time duration.")
+
+	mode              = flag.String("mode", "", fmt.Sprintf("Shape of the events, one of %v. The fixed cluster event is written when empty.", emf.Modes))
+	namespace         = flag.String("namespace", "IntegrationTest", "Namespace of the metrics of the mode.")
+	dimensionSets     = flag.Int("dimensionSets", 0, "Overrides the number of dimension sets per event of the mode.")
+	dimensionsPerSet  = flag.Int("dimensionsPerSet", -1, "Overrides the number of dimensions per dimension set of the mode.")
+	dimensionValues   = flag.Int("dimensionValues", 0, "Overrides the number of values of every dimension of the mode.")
+	metricsPerEvent   = flag.Int("metricsPerEvent", 0, "Overrides the number of metrics per event of the mode.")
+	valuesPerMetric   = flag.Int("valuesPerMetric", 0, "Overrides the number of values per metric of the mode, above 1 sends arrays.")
+	storageResolution = flag.Int("storageResolution", 0, "Overrides the StorageResolution of the metrics of the mode (1 or 60).")
 )
 
+// sample commands:
+//
+//	emfGen -fileNum 1 -eventsPerSecond 65
+//	emfGen -mode dimension-explosion -dimensionSets 50
+//	emfGen -mode oversized
+
 func main() {
 	flag.Parse()
 	if *structuredLogDir == "" {
@@ -36,16 +54,62 @@ func main() {
 			*structuredLogDir = "/tmp/soakTest"
 		}
 	}
+	newEvent := clusterEvent
+	if *mode != "" {
+		generator, err := modeGenerator()
+		if err != nil {
+			log.Fatal(err)
+		}
+		newEvent = func() string {
+			event, err := generator.Event(time.Now())
+			if err != nil {
+				log.Fatal(err)
+			}
+			return string(event)
+		}
+	}
 	// Start generating structured log
 	for i := 0; i < *fileNum; i++ {
-		go writeStructuredLog(i)
+		go writeStructuredLog(i, newEvent)
 	}
 	time.Sleep(*runTime)
 	// No cleanup needed, just exit.
 }
 
-func writeStructuredLog(fileIndex int) {
-	eventSize := len(structuredLogEvent) + len(strconv.FormatInt(makeTimestamp(), 10)) - 2
+// modeGenerator creates the generator of the mode with the flags overriding it.
+func modeGenerator() (*emf.Generator, error) {
+	cfg, err := emf.ModeConfig(emf.Mode(*mode))
+	if err != nil {
+		return nil, err
+	}
+	cfg.Namespace = *namespace
+	for _, override := range []struct {
+		value *int
+		unset int
+		field *int
+	}{
+		{dimensionSets, 0, &cfg.DimensionSets},
+		{dimensionsPerSet, -1, &cfg.DimensionsPerSet},
+		{dimensionValues, 0, &cfg.DimensionValues},
+		{metricsPerEvent, 0, &cfg.MetricsPerEvent},
+		{valuesPerMetric, 0, &cfg.ValuesPerMetric},
+		{storageResolution, 0, &cfg.StorageResolution},
+	} {
+		if *override.value != override.unset {
+			*override.field = *override.value
+		}
+	}
+	if exceeded := cfg.ExceededLimits(); len(exceeded) > 0 {
+		log.Printf("The events go above the limits of EMF: %s", strings.Join(exceeded, ", "))
+	}
+	return emf.NewGenerator(cfg, time.Now().UnixNano())
+}
+
+func clusterEvent() string {
+	return fmt.Sprintf(structuredLogEvent, makeTimestamp())
+}
+
+func writeStructuredLog(fileIndex int, newEvent func() string) {
 	curFilePath := path.Join(*structuredLogDir, fmt.Sprintf("%s%d.json", *filePrefix, fileIndex))
 	fmt.Printf("Creating file %s\n", curFilePath)
 
@@ -58,10 +122,11 @@ func writeStructuredLog(fileIndex int) {
 	ticker := time.NewTicker(time.Second)
 	for range ticker.C {
 		for i := 0; i < *eventsPerSecond; i++ {
-			sf.WriteString(fmt.Sprintf(structuredLogEvent+"\n", makeTimestamp()))
+			event := newEvent() + "\n"
+			sf.WriteString(event)
+			fileSize += len(event)
 		}
 		sf.Sync()
-		fileSize += (*eventsPerSecond) * (eventSize)
 		if fileSize >= logTruncateSize {
 			os.Truncate(curFilePath, 0)
 			sf.Seek(0, 0)
@@ -0,0 +1,82 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+// Package otelcmd holds the flags and the setup the otel-* commands share: the PromQL API of a cluster and
+// the metric catalog of a suite.
+package otelcmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/otelmetrics"
+)
+
+// Config is the cluster, PromQL API and catalog suite a command reads.
+type Config struct {
+	Region      string
+	Endpoint    string
+	ClusterName string
+	Suite       string
+	CatalogPath string
+}
+
+// RegisterFlags defines the -region, -endpoint, -cluster, -suite and -catalog flags on the command line,
+// suite being the default of -suite.
+func RegisterFlags(suite string) *Config {
+	c := &Config{}
+	flag.StringVar(&c.Region, "region", os.Getenv("AWS_REGION"), "Region of the PromQL API.")
+	flag.StringVar(&c.Endpoint, "endpoint", "", "PromQL API endpoint, https://monitoring.<region>.amazonaws.com by default.")
+	flag.StringVar(&c.ClusterName, "cluster", os.Getenv("CLUSTER_NAME"), "Name of the EKS cluster.")
+	flag.StringVar(&c.Suite, "suite", suite, "Catalog suite whose sources are read.")
+	flag.StringVar(&c.CatalogPath, "catalog", "", "Path of a metric catalog, the embedded catalog by default.")
+	return c
+}
+
+// Catalog returns the catalog at CatalogPath, or the embedded one without a path.
+func (c *Config) Catalog() (*otelmetrics.Catalog, error) {
+	if c.CatalogPath == "" {
+		return otelmetrics.DefaultCatalog(), nil
+	}
+	data, err := os.ReadFile(c.CatalogPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading the catalog: %w", err)
+	}
+	catalog, err := otelmetrics.LoadCatalog(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid catalog %s: %w", c.CatalogPath, err)
+	}
+	return catalog, nil
+}
+
+// NewQueryCache connects to the PromQL API of the cluster and returns a QueryCache of the sources of the suite
+// in the catalog. The client must be closed once done.
+func (c *Config) NewQueryCache(ctx context.Context, catalog *otelmetrics.Catalog) (*otelmetrics.QueryCache, *otelmetrics.OtelMetricsClient, error) {
+	if c.Region == "" || c.ClusterName == "" {
+		return nil, nil, errors.New("-region and -cluster are required")
+	}
+	registry, err := catalog.SourceRegistry(c.Suite)
+	if err != nil {
+		return nil, nil, err
+	}
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://monitoring.%s.amazonaws.com", c.Region)
+	}
+	client, err := otelmetrics.NewClient(ctx, otelmetrics.TestConfig{
+		Region:         c.Region,
+		Endpoint:       endpoint,
+		Timeout:        30 * time.Second,
+		MaxRetries:     3,
+		ClusterName:    c.ClusterName,
+		SigningService: "monitoring",
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("client error: %w", err)
+	}
+	return otelmetrics.NewQueryCache(client, c.ClusterName, otelmetrics.WithSourceRegistry(registry)), client, nil
+}
@@ -4,14 +4,19 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"log"
 	"math/rand"
 	"os"
 	"path"
 	"runtime"
 	"strconv"
+	"sync"
 	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common/logs"
 )
 
 const (
@@ -27,10 +32,30 @@ var (
 	outPutPath      = flag.String("path", "", "Identify the path where log files will generate.")
 	filePrefix      = flag.String("filePrefix", "tmp", "Identify the log file prefix")
 	runTime         = flag.Duration("runTime", 48*time.Hour, "Run time duration.")
+
+	sequenced      = flag.Bool("sequenced", false, "Write entries with a seq= number, so lost records can be found, using the flags below.")
+	rotation       = flag.String("rotation", string(logs.RotationRenameCreate), fmt.Sprintf("Rotation of the sequenced files, one of %v.", logs.RotationStrategies))
+	rotateSize     = flag.Int64("rotateSize", logTruncateSize, "Size in Byte a sequenced file is rotated at.")
+	maxBackups     = flag.Int("maxBackups", 5, "Number of rotated sequenced files kept.")
+	encoding       = flag.String("encoding", "utf-8", "Encoding of the sequenced files: utf-8, utf-16le, utf-16be, shift_jis or gbk.")
+	charset        = flag.String("charset", logs.CharsetASCII, "Characters of the sequenced entries: ascii, or cjk to exercise the encodings.")
+	crlf           = flag.Bool("crlf", false, "End the lines of the sequenced entries with CRLF.")
+	multilineEvery = flag.Int("multilineEvery", 0, "Make every n-th sequenced entry a multiline stack trace, 0 disables it.")
+	multilineLines = flag.Int("multilineLines", 5, "Number of lines of the stack traces of the sequenced entries.")
 )
 
+// sample commands:
+//
+//	logGen -fileNum 1 -eventsPerSecond 200 -eventSize 120
+//	logGen -sequenced -rotation gzip -rotateSize 1048576 -encoding shift_jis -charset cjk -multilineEvery 10
 func main() {
 	flag.Parse()
+	if *sequenced {
+		if err := writeSequencedLogs(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 	fmt.Printf("Start writing %d files, and each file has throughput %d Bytes/sec...\n",
 		*fileNum, (*eventsPerSecond)*(*eventSize))
 
@@ -87,3 +112,68 @@ func writeLog(logEntry string, instanceId int) {
 		fmt.Printf("%s Ended.\n", time.Now().Format(layoutFormat))
 	}
 }
+
+// writeSequencedLogs writes the files with the shared log generator until runTime, then prints the last
+// sequence number of every file.
+func writeSequencedLogs() error {
+	if *outPutPath == "" {
+		if runtime.GOOS == "windows" {
+			*outPutPath = "C:\\tmp\\soakTest"
+		} else {
+			*outPutPath = "/tmp/soakTest"
+		}
+	}
+	lineEnding := logs.LineEndingLF
+	if *crlf {
+		lineEnding = logs.LineEndingCRLF
+	}
+	multilineLinesPerEntry := 0
+	if *multilineEvery > 0 {
+		multilineLinesPerEntry = *multilineLines
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *runTime)
+	defer cancel()
+	var wg sync.WaitGroup
+	var generators []*logs.Generator
+	var writers []*logs.FileWriter
+	for i := 0; i < *fileNum; i++ {
+		filePath := path.Join(*outPutPath, fmt.Sprintf("%s%d.log", *filePrefix, i))
+		fileWriter, err := logs.NewFileWriter(logs.FileWriterConfig{
+			Path:       filePath,
+			Rotation:   logs.RotationStrategy(*rotation),
+			MaxBytes:   *rotateSize,
+			MaxBackups: *maxBackups,
+		})
+		if err != nil {
+			return err
+		}
+		writer, err := logs.NewEncodingWriter(fileWriter, *encoding)
+		if err != nil {
+			return err
+		}
+		generator, err := logs.NewGenerator(&logs.GeneratorConfig{
+			LinesPerSecond:  *eventsPerSecond,
+			LineLength:      *eventSize,
+			TimestampFormat: layoutFormat,
+			Charset:         *charset,
+			LineEnding:      lineEnding,
+			MultilineEvery:  *multilineEvery,
+			MultilineLines:  multilineLinesPerEntry,
+		}, writer)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Writing sequenced file %s\n", filePath)
+		generators = append(generators, generator)
+		writers = append(writers, fileWriter)
+		wg.Add(1)
+		go generator.Generate(ctx, &wg)
+	}
+	wg.Wait()
+	for i, generator := range generators {
+		writers[i].Close()
+		fmt.Printf("File %d: last seq=%d after %d rotations\n", i, generator.SequenceNumber(), writers[i].Rotations())
+	}
+	return nil
+}
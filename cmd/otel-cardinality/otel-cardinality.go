@@ -0,0 +1,107 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/cmd/internal/otelcmd"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/otelmetrics"
+)
+
+var (
+	config        = otelcmd.RegisterFlags("attr_limit")
+	recorded      = flag.String("recorded", "", "Directory of recorded responses to analyze instead of querying the endpoint.")
+	record        = flag.String("record", "", "Directory the responses of the endpoint are recorded to.")
+	maxAttributes = flag.Int("maxAttributes", 170, "Most attributes of a series, 0 to not check it.")
+	maxResource   = flag.Int("maxResourceAttributes", 0, "Most resource attributes of a series, 0 to not check it.")
+	maxSeries     = flag.Int("maxSeries", 0, "Most series of a metric, 0 to not check it.")
+	warnRatio     = flag.Float64("warnRatio", otelmetrics.DefaultWarnRatio, "Fraction of a limit from which a metric is flagged.")
+	format        = flag.String("format", "text", "Report format, json or text.")
+	failOnExceed  = flag.Bool("failOnExceeded", false, "Exit with status 2 when a metric exceeds a limit.")
+)
+
+// recordingSource records the results of the source as it returns them.
+type recordingSource struct {
+	source otelmetrics.MetricResultSource
+	dir    string
+}
+
+func (r recordingSource) Get(ctx context.Context, metricName string) ([]otelmetrics.MetricResult, error) {
+	results, err := r.source.Get(ctx, metricName)
+	if err != nil {
+		return nil, err
+	}
+	if err = otelmetrics.RecordResults(r.dir, metricName, results); err != nil {
+		return nil, fmt.Errorf("recording %s: %w", metricName, err)
+	}
+	return results, nil
+}
+
+// otel-cardinality reports the series cardinality and attribute counts of the metrics of each source of a
+// catalog suite, and flags the metrics approaching the limits. sample commands:
+//
+//	otel-cardinality -region us-west-2 -cluster my-cluster -record /tmp/attr_limit
+//	otel-cardinality -recorded /tmp/attr_limit -maxResourceAttributes 150 -format json
+func main() {
+	flag.Parse()
+	if *format != "json" && *format != "text" {
+		log.Fatalf("unknown format %q, expected json or text", *format)
+	}
+	catalog, err := config.Catalog()
+	if err != nil {
+		log.Fatal(err)
+	}
+	sources, err := catalog.SourceMappings(config.Suite)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+	var source otelmetrics.MetricResultSource
+	if *recorded != "" {
+		source = otelmetrics.NewRecordedResults(*recorded)
+	} else {
+		queryCache, client, err := config.NewQueryCache(ctx, catalog)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer client.Close()
+		source = queryCache
+		if *record != "" {
+			source = recordingSource{source: source, dir: *record}
+		}
+	}
+
+	limits := otelmetrics.CardinalityLimits{Attributes: *maxAttributes, Series: *maxSeries, WarnRatio: *warnRatio}
+	if *maxResource > 0 {
+		limits.ScopeAttributes = map[string]int{otelmetrics.LabelScopeResource: *maxResource}
+	}
+	report, err := otelmetrics.AnalyzeCardinality(ctx, source, sources, limits)
+	if err != nil {
+		log.Fatalf("Cardinality analysis failed: %v", err)
+	}
+	if *format == "text" {
+		err = report.WriteText(os.Stdout)
+	} else {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	}
+	if err != nil {
+		log.Fatalf("Failed to write the report: %v", err)
+	}
+	if *failOnExceed {
+		for _, m := range report.Flagged() {
+			if m.Exceeded() {
+				os.Exit(2)
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/cmd/internal/otelcmd"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/otelmetrics"
+)
+
+var (
+	config        = otelcmd.RegisterFlags("standard")
+	window        = flag.Duration("window", 15*time.Minute, "How far back the emitted metrics are read.")
+	ignoreMetrics = flag.String("ignoreMetrics", "", "Comma separated expressions of emitted metrics never reported as unexpected.")
+	ignoreLabels  = flag.String("ignoreLabels", "", "Comma separated data point labels never reported as new.")
+	format        = flag.String("format", "json", "Report format, json or text.")
+	failOnDrift   = flag.Bool("failOnDrift", false, "Exit with status 2 when the report lists any drift.")
+)
+
+// otel-drift reports how the metrics a cluster emits differ from the metric catalog: unexpected and missing
+// metrics, new and missing labels, and unit changes. sample commands:
+//
+//	otel-drift -region us-west-2 -cluster my-cluster -suite gpu
+//	otel-drift -cluster my-cluster -ignoreMetrics 'node_.*,go_.*' -ignoreLabels instance,job -format text -failOnDrift
+func main() {
+	flag.Parse()
+	if *format != "json" && *format != "text" {
+		log.Fatalf("unknown format %q, expected json or text", *format)
+	}
+
+	catalog, err := config.Catalog()
+	if err != nil {
+		log.Fatal(err)
+	}
+	sources, err := catalog.SourceMappings(config.Suite)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts := otelmetrics.DriftOptions{IgnoreLabels: splitList(*ignoreLabels)}
+	for _, expression := range splitList(*ignoreMetrics) {
+		re, err := regexp.Compile("^(?:" + expression + ")$")
+		if err != nil {
+			log.Fatalf("Invalid -ignoreMetrics expression %q: %v", expression, err)
+		}
+		opts.IgnoreMetrics = append(opts.IgnoreMetrics, re)
+	}
+
+	ctx := context.Background()
+	queryCache, client, err := config.NewQueryCache(ctx, catalog)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	opts.End = time.Now()
+	opts.Start = opts.End.Add(-*window)
+	report, err := queryCache.DetectDrift(ctx, sources, opts)
+	if err != nil {
+		log.Fatalf("Drift detection failed: %v", err)
+	}
+	if *format == "text" {
+		err = report.WriteText(os.Stdout)
+	} else {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	}
+	if err != nil {
+		log.Fatalf("Failed to write the report: %v", err)
+	}
+	if *failOnDrift && report.HasDrift() {
+		os.Exit(2)
+	}
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,183 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// zipfExponent skews the tag values so a few of them are sent most of the time, like the customers of
+// a service.
+const zipfExponent = 1.1
+
+var metricTypeSuffixes = map[string]string{
+	"gauge":        "g",
+	"count":        "c",
+	"timing":       "ms",
+	"histogram":    "h",
+	"distribution": "d",
+	"set":          "s",
+}
+
+// generator builds the statsd packets of a scenario, tick by tick.
+type generator struct {
+	scenario    *Scenario
+	rng         *rand.Rand
+	scheduler   *scheduler
+	totalWeight float64
+	tags        [][]*tagValues
+	stats       generatorStats
+}
+
+type generatorStats struct {
+	Lines      int
+	SampledOut int
+	Packets    int
+	Bytes      int
+}
+
+// tagValues draws the values of a tag, keeping the zipf distribution of the current cardinality.
+type tagValues struct {
+	tag         Tag
+	cardinality int
+	zipf        *rand.Zipf
+}
+
+func newGenerator(scenario *Scenario) *generator {
+	rng := rand.New(rand.NewSource(scenario.Seed))
+	g := &generator{
+		scenario:  scenario,
+		rng:       rng,
+		scheduler: &scheduler{shape: scenario.Traffic.Shape, rng: rng},
+		tags:      make([][]*tagValues, len(scenario.Families)),
+	}
+	for i, family := range scenario.Families {
+		g.totalWeight += family.Weight
+		for _, tag := range family.Tags {
+			g.tags[i] = append(g.tags[i], &tagValues{tag: tag})
+		}
+	}
+	return g
+}
+
+// tick returns the packets of the tick of length tick ending at elapsed since the start of the run.
+func (g *generator) tick(elapsed, tick time.Duration) [][]byte {
+	var packets [][]byte
+	var packet []byte
+	maxPacketSize := g.scenario.Batching.MaxPacketSize
+	for i := g.scheduler.lines(elapsed, tick); i > 0; i-- {
+		line, ok := g.line(elapsed)
+		if !ok {
+			g.stats.SampledOut++
+			continue
+		}
+		g.stats.Lines++
+		if len(packet) > 0 && len(packet)+1+len(line) > maxPacketSize {
+			packets = append(packets, packet)
+			packet = nil
+		}
+		if len(packet) > 0 {
+			packet = append(packet, '\n')
+		}
+		packet = append(packet, line...)
+	}
+	if len(packet) > 0 {
+		packets = append(packets, packet)
+	}
+	g.stats.Packets += len(packets)
+	for _, p := range packets {
+		g.stats.Bytes += len(p)
+	}
+	return packets
+}
+
+// line returns a metric line of a family picked by weight, or false when the line is sampled out.
+func (g *generator) line(elapsed time.Duration) (string, bool) {
+	index := g.pickFamily()
+	family := g.scenario.Families[index]
+	if family.SampleRate < 1 && g.rng.Float64() >= family.SampleRate {
+		return "", false
+	}
+
+	var line strings.Builder
+	line.WriteString(g.scenario.Namespace)
+	line.WriteString(family.Name)
+	if family.Metrics > 1 {
+		line.WriteString(".")
+		line.WriteString(strconv.Itoa(g.rng.Intn(family.Metrics)))
+	}
+	line.WriteString(":")
+	line.WriteString(g.value(family))
+	line.WriteString("|")
+	line.WriteString(metricTypeSuffixes[family.Type])
+	if family.SampleRate < 1 {
+		line.WriteString("|@")
+		line.WriteString(strconv.FormatFloat(family.SampleRate, 'f', -1, 64))
+	}
+
+	tags := append([]string(nil), g.scenario.Tags...)
+	for _, values := range g.tags[index] {
+		tags = append(tags, values.tag.Key+":"+values.draw(g.rng, elapsed))
+	}
+	if len(tags) > 0 {
+		line.WriteString("|#")
+		line.WriteString(strings.Join(tags, ","))
+	}
+	return line.String(), true
+}
+
+func (g *generator) pickFamily() int {
+	target := g.rng.Float64() * g.totalWeight
+	for i, family := range g.scenario.Families {
+		target -= family.Weight
+		if target < 0 {
+			return i
+		}
+	}
+	return len(g.scenario.Families) - 1
+}
+
+func (g *generator) value(family Family) string {
+	value := family.Value.Min + g.rng.Float64()*(family.Value.Max-family.Value.Min)
+	switch family.Type {
+	case "count", "set":
+		return strconv.FormatInt(int64(math.Round(value)), 10)
+	default:
+		return strconv.FormatFloat(math.Round(value*1000)/1000, 'f', -1, 64)
+	}
+}
+
+// CardinalityAt returns the number of values of the tag at the elapsed time.
+func (t Tag) CardinalityAt(elapsed time.Duration) int {
+	if len(t.Values) > 0 {
+		return len(t.Values)
+	}
+	if t.GrowTo == 0 {
+		return t.Cardinality
+	}
+	progress := math.Min(1, float64(elapsed)/float64(t.GrowOver))
+	return t.Cardinality + int(float64(t.GrowTo-t.Cardinality)*progress)
+}
+
+func (v *tagValues) draw(rng *rand.Rand, elapsed time.Duration) string {
+	cardinality := v.tag.CardinalityAt(elapsed)
+	var index int
+	if v.tag.Distribution == "zipf" && cardinality > 1 {
+		if v.zipf == nil || v.cardinality != cardinality {
+			v.zipf = rand.NewZipf(rng, zipfExponent, 1, uint64(cardinality-1))
+			v.cardinality = cardinality
+		}
+		index = int(v.zipf.Uint64())
+	} else {
+		index = rng.Intn(cardinality)
+	}
+	if len(v.tag.Values) > 0 {
+		return v.tag.Values[index]
+	}
+	return "v" + strconv.Itoa(index)
+}
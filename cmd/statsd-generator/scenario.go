@@ -0,0 +1,238 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultAddress       = "127.0.0.1:8125"
+	defaultProtocol      = "udp"
+	defaultTick          = 100 * time.Millisecond
+	defaultConnections   = 1
+	defaultFamilyWeight  = 1
+	defaultFamilyMetrics = 1
+	// defaultUDPPacketSize keeps a packet within the MTU of most networks, like the datadog client.
+	defaultUDPPacketSize = 1432
+	// defaultStreamPacketSize is used for unix datagrams and TCP writes, which are not limited by the MTU.
+	defaultStreamPacketSize = 8192
+)
+
+var (
+	supportedProtocols    = []string{"udp", "tcp", "unixgram"}
+	supportedMetricTypes  = []string{"gauge", "count", "timing", "histogram", "distribution", "set"}
+	supportedShapes       = []string{"constant", "ramp", "step", "sine", "burst", "poisson"}
+	supportedDistribution = []string{"uniform", "zipf"}
+)
+
+// Scenario describes the statsd traffic sent by the generator: what is sent (families), how much of it
+// over time (traffic) and where (target).
+type Scenario struct {
+	Target    Target        `yaml:"target"`
+	Duration  time.Duration `yaml:"duration"`
+	Seed      int64         `yaml:"seed"`
+	Namespace string        `yaml:"namespace"`
+	// Tags are added to every metric
+	Tags     []string `yaml:"tags"`
+	Batching Batching `yaml:"batching"`
+	Traffic  Traffic  `yaml:"traffic"`
+	Families []Family `yaml:"families"`
+}
+
+// Target is where the packets are sent. Address is host:port for udp and tcp, and a socket path for
+// unixgram.
+type Target struct {
+	Address  string `yaml:"address"`
+	Protocol string `yaml:"protocol"`
+	// Connections spreads the packets over several sockets, which shows up as several clients to the agent
+	Connections int `yaml:"connections"`
+}
+
+// Batching is how the lines are packed into packets. A packet is sent when the next line would not fit
+// in MaxPacketSize bytes, and at the end of every tick.
+type Batching struct {
+	MaxPacketSize int `yaml:"max_packet_size"`
+}
+
+// Traffic is the number of metric lines per second over the run, before sampling.
+type Traffic struct {
+	Shape Shape `yaml:"shape"`
+	// Tick is how often lines are generated and flushed
+	Tick time.Duration `yaml:"tick"`
+}
+
+// Family is a group of metrics of the same type sharing their tags, named <name>.<index> when there is
+// more than one metric.
+type Family struct {
+	Name    string  `yaml:"name"`
+	Type    string  `yaml:"type"`
+	Metrics int     `yaml:"metrics"`
+	Weight  float64 `yaml:"weight"`
+	// SampleRate sends a line with this probability and marks it with |@<rate> so the agent scales it back
+	SampleRate float64 `yaml:"sample_rate"`
+	Value      Range   `yaml:"value"`
+	Tags       []Tag   `yaml:"tags"`
+}
+
+// Range is the range values are uniformly drawn from. Set values are drawn as integers.
+type Range struct {
+	Min float64 `yaml:"min"`
+	Max float64 `yaml:"max"`
+}
+
+// Tag is a tag key with either fixed values or a number of generated values (<key>:v<index>). The
+// cardinality can grow linearly to GrowTo over GrowOver to reproduce a cardinality explosion.
+type Tag struct {
+	Key          string        `yaml:"key"`
+	Values       []string      `yaml:"values"`
+	Cardinality  int           `yaml:"cardinality"`
+	GrowTo       int           `yaml:"grow_to"`
+	GrowOver     time.Duration `yaml:"grow_over"`
+	Distribution string        `yaml:"distribution"`
+}
+
+// LoadScenario reads a scenario file, rejecting unknown fields, and applies the defaults.
+func LoadScenario(path string) (*Scenario, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	scenario, err := decodeScenario(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+	return scenario, nil
+}
+
+func decodeScenario(content []byte) (*Scenario, error) {
+	var scenario Scenario
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&scenario); err != nil {
+		if err == io.EOF {
+			return nil, errors.New("the file is empty")
+		}
+		return nil, err
+	}
+	scenario.applyDefaults()
+	if err := scenario.Validate(); err != nil {
+		return nil, err
+	}
+	return &scenario, nil
+}
+
+func (s *Scenario) applyDefaults() {
+	if s.Target.Address == "" {
+		s.Target.Address = defaultAddress
+	}
+	if s.Target.Protocol == "" {
+		s.Target.Protocol = defaultProtocol
+	}
+	if s.Target.Connections == 0 {
+		s.Target.Connections = defaultConnections
+	}
+	if s.Batching.MaxPacketSize == 0 {
+		s.Batching.MaxPacketSize = defaultStreamPacketSize
+		if s.Target.Protocol == "udp" {
+			s.Batching.MaxPacketSize = defaultUDPPacketSize
+		}
+	}
+	if s.Traffic.Tick == 0 {
+		s.Traffic.Tick = defaultTick
+	}
+	if s.Traffic.Shape.Type == "" {
+		s.Traffic.Shape.Type = "constant"
+	}
+	for i := range s.Families {
+		family := &s.Families[i]
+		if family.Metrics == 0 {
+			family.Metrics = defaultFamilyMetrics
+		}
+		if family.Weight == 0 {
+			family.Weight = defaultFamilyWeight
+		}
+		if family.SampleRate == 0 {
+			family.SampleRate = 1
+		}
+		if family.Value == (Range{}) {
+			family.Value = Range{Max: 100}
+		}
+		for j := range family.Tags {
+			if family.Tags[j].Distribution == "" {
+				family.Tags[j].Distribution = "uniform"
+			}
+		}
+	}
+}
+
+// Validate returns every problem of the scenario.
+func (s *Scenario) Validate() error {
+	var errs []error
+	addError := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if !slices.Contains(supportedProtocols, s.Target.Protocol) {
+		addError("target.protocol must be one of %v, got %q", supportedProtocols, s.Target.Protocol)
+	}
+	if s.Target.Connections < 0 {
+		addError("target.connections must not be negative")
+	}
+	if s.Duration < 0 {
+		addError("duration must not be negative")
+	}
+	if s.Batching.MaxPacketSize < 0 {
+		addError("batching.max_packet_size must not be negative")
+	}
+	if s.Traffic.Tick < 0 {
+		addError("traffic.tick must not be negative")
+	}
+	if err := s.Traffic.Shape.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(s.Families) == 0 {
+		addError("at least one family is required")
+	}
+	for _, family := range s.Families {
+		if family.Name == "" {
+			addError("every family needs a name")
+		}
+		if !slices.Contains(supportedMetricTypes, family.Type) {
+			addError("family %s: type must be one of %v, got %q", family.Name, supportedMetricTypes, family.Type)
+		}
+		if family.Metrics < 0 || family.Weight < 0 {
+			addError("family %s: metrics and weight must not be negative", family.Name)
+		}
+		if family.SampleRate < 0 || family.SampleRate > 1 {
+			addError("family %s: sample_rate must be within (0, 1], got %v", family.Name, family.SampleRate)
+		}
+		if family.Value.Min > family.Value.Max {
+			addError("family %s: value.min %v is above value.max %v", family.Name, family.Value.Min, family.Value.Max)
+		}
+		for _, tag := range family.Tags {
+			if tag.Key == "" {
+				addError("family %s: every tag needs a key", family.Name)
+			}
+			if (len(tag.Values) == 0) == (tag.Cardinality <= 0) {
+				addError("family %s: tag %s needs either values or a positive cardinality", family.Name, tag.Key)
+			}
+			if tag.GrowTo != 0 && (tag.GrowTo < tag.Cardinality || tag.GrowOver <= 0 || len(tag.Values) > 0) {
+				addError("family %s: tag %s grow_to needs a cardinality below it and a positive grow_over", family.Name, tag.Key)
+			}
+			if !slices.Contains(supportedDistribution, tag.Distribution) {
+				addError("family %s: tag %s distribution must be one of %v, got %q", family.Name, tag.Key, supportedDistribution, tag.Distribution)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,285 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"bufio"
+	"math/rand"
+	"net"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
+)
+
+var statsdLine = regexp.MustCompile(`^[\w.]+:-?[\d.]+\|(g|c|ms|h|d|s)(\|@[\d.]+)?(\|#[^|]+)?$`)
+
+func TestLoadScenarioExamples(t *testing.T) {
+	paths, err := filepath.Glob("scenarios/*.yaml")
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+	for _, path := range paths {
+		_, err := LoadScenario(path)
+		assert.NoError(t, err, path)
+	}
+}
+
+func TestDecodeScenario(t *testing.T) {
+	scenario, err := decodeScenario([]byte(`
+families:
+  - name: request.latency
+    type: timing
+`))
+	require.NoError(t, err)
+	assert.Equal(t, Target{Address: defaultAddress, Protocol: "udp", Connections: 1}, scenario.Target)
+	assert.Equal(t, defaultUDPPacketSize, scenario.Batching.MaxPacketSize)
+	assert.Equal(t, defaultTick, scenario.Traffic.Tick)
+	assert.Equal(t, "constant", scenario.Traffic.Shape.Type)
+	assert.Equal(t, Family{Name: "request.latency", Type: "timing", Metrics: 1, Weight: 1, SampleRate: 1, Value: Range{Max: 100}}, scenario.Families[0])
+
+	scenario, err = decodeScenario([]byte(`
+target: {protocol: tcp}
+families: [{name: a, type: gauge}]
+`))
+	require.NoError(t, err)
+	assert.Equal(t, defaultStreamPacketSize, scenario.Batching.MaxPacketSize)
+}
+
+func TestDecodeScenarioErrors(t *testing.T) {
+	testCases := map[string]struct {
+		content string
+		errors  []string
+	}{
+		"Empty": {
+			content: "",
+			errors:  []string{"the file is empty"},
+		},
+		"UnknownField": {
+			content: "families: [{name: a, type: gauge, rate: 1}]",
+			errors:  []string{"field rate not found"},
+		},
+		"NoFamilies": {
+			content: "target: {protocol: sctp}",
+			errors:  []string{`target.protocol must be one of [udp tcp unixgram], got "sctp"`, "at least one family is required"},
+		},
+		"InvalidFamily": {
+			content: `
+families:
+  - name: a
+    type: meter
+    sample_rate: 2
+    value: {min: 5, max: 1}
+    tags:
+      - key: customer
+      - key: region
+        values: [a]
+        distribution: normal
+`,
+			errors: []string{
+				`family a: type must be one of [gauge count timing histogram distribution set], got "meter"`,
+				"family a: sample_rate must be within (0, 1], got 2",
+				"family a: value.min 5 is above value.max 1",
+				"family a: tag customer needs either values or a positive cardinality",
+				`family a: tag region distribution must be one of [uniform zipf], got "normal"`,
+			},
+		},
+		"InvalidGrowth": {
+			content: "families: [{name: a, type: gauge, tags: [{key: customer, cardinality: 10, grow_to: 5}]}]",
+			errors:  []string{"family a: tag customer grow_to needs a cardinality below it and a positive grow_over"},
+		},
+		"InvalidShape": {
+			content: `
+traffic:
+  shape: {type: burst, rate: -1, every: 1s, burst_duration: 2s}
+families: [{name: a, type: gauge}]
+`,
+			errors: []string{"traffic.shape: rates must not be negative", "traffic.shape: burst needs a positive every and a burst_duration within it"},
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := decodeScenario([]byte(testCase.content))
+			require.Error(t, err)
+			for _, expected := range testCase.errors {
+				assert.Contains(t, err.Error(), expected)
+			}
+		})
+	}
+}
+
+func TestShapeRateAt(t *testing.T) {
+	testCases := map[string]struct {
+		shape Shape
+		rates map[time.Duration]float64
+	}{
+		"Constant": {
+			shape: Shape{Type: "constant", Rate: 100},
+			rates: map[time.Duration]float64{0: 100, time.Hour: 100},
+		},
+		"Ramp": {
+			shape: Shape{Type: "ramp", Rate: 100, To: 1100, Over: 10 * time.Second},
+			rates: map[time.Duration]float64{0: 100, 5 * time.Second: 600, 10 * time.Second: 1100, time.Minute: 1100},
+		},
+		"Step": {
+			shape: Shape{Type: "step", Rate: 10, Steps: []Step{{At: time.Minute, Rate: 500}, {At: 30 * time.Second, Rate: 100}}},
+			rates: map[time.Duration]float64{0: 10, 30 * time.Second: 100, 59 * time.Second: 100, 2 * time.Minute: 500},
+		},
+		"Sine": {
+			shape: Shape{Type: "sine", Rate: 100, Amplitude: 150, Period: 4 * time.Second},
+			rates: map[time.Duration]float64{0: 100, time.Second: 250, 2 * time.Second: 100, 3 * time.Second: 0},
+		},
+		"Burst": {
+			shape: Shape{Type: "burst", Rate: 10, BurstRate: 1000, BurstDuration: 5 * time.Second, Every: time.Minute},
+			rates: map[time.Duration]float64{0: 1000, 4 * time.Second: 1000, 5 * time.Second: 10, 61 * time.Second: 1000},
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, testCase.shape.Validate())
+			for elapsed, rate := range testCase.rates {
+				assert.InDelta(t, rate, testCase.shape.RateAt(elapsed), 1e-9, "at %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestSchedulerLines(t *testing.T) {
+	s := &scheduler{shape: Shape{Type: "constant", Rate: 3}}
+	total := 0
+	for i := 0; i < 100; i++ {
+		total += s.lines(time.Duration(i)*100*time.Millisecond, 100*time.Millisecond)
+	}
+	// The fraction of a line is carried over, 0.3 line per tick sends 30 lines in 10 seconds
+	assert.Equal(t, 30, total)
+
+	s = &scheduler{shape: Shape{Type: "poisson", Rate: 50}, rng: rand.New(rand.NewSource(1))}
+	total = 0
+	for i := 0; i < 1000; i++ {
+		total += s.lines(time.Duration(i)*time.Second, time.Second)
+	}
+	assert.InDelta(t, 50, float64(total)/1000, 1)
+	assert.InDelta(t, 5, float64(poissonTotal(rand.New(rand.NewSource(1)), 5, 1000))/1000, 0.3)
+}
+
+func poissonTotal(rng *rand.Rand, lambda float64, draws int) int {
+	total := 0
+	for i := 0; i < draws; i++ {
+		total += poisson(rng, lambda)
+	}
+	return total
+}
+
+func TestGeneratorTick(t *testing.T) {
+	scenario, err := decodeScenario([]byte(`
+namespace: "Test."
+tags: [env:test]
+batching: {max_packet_size: 512}
+traffic:
+  shape: {type: constant, rate: 1000}
+families:
+  - name: latency
+    type: timing
+    metrics: 3
+    tags:
+      - key: customer
+        cardinality: 1
+        grow_to: 1001
+        grow_over: 10s
+        distribution: zipf
+  - name: sampled
+    type: histogram
+    sample_rate: 0.5
+  - name: users
+    type: set
+    value: {min: 1, max: 1000}
+`))
+	require.NoError(t, err)
+	g := newGenerator(scenario)
+
+	customers := map[string]struct{}{}
+	for elapsed := time.Second; elapsed <= 10*time.Second; elapsed += time.Second {
+		for _, packet := range g.tick(elapsed, time.Second) {
+			assert.LessOrEqual(t, len(packet), 512)
+			for _, line := range strings.Split(string(packet), "\n") {
+				require.Regexp(t, statsdLine, line)
+				assert.True(t, strings.HasPrefix(line, "Test."), line)
+				assert.Contains(t, line, "|#env:test")
+				if strings.HasPrefix(line, "Test.sampled") {
+					assert.Contains(t, line, "|h|@0.5|")
+				}
+				if strings.HasPrefix(line, "Test.latency.") {
+					customers[line[strings.Index(line, "customer:"):]] = struct{}{}
+				}
+			}
+		}
+	}
+	assert.Equal(t, 10000, g.stats.Lines+g.stats.SampledOut)
+	assert.InDelta(t, 10000/3/2, g.stats.SampledOut, 200)
+	// The cardinality grows to 1001 customers, a few of them sending most lines
+	assert.Greater(t, len(customers), 100)
+	assert.LessOrEqual(t, len(customers), 1001)
+}
+
+func TestRunScenario(t *testing.T) {
+	t.Run("UDP", func(t *testing.T) {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer conn.Close()
+		runScenario(clock.Real(), scenarioTo(t, "udp", conn.LocalAddr().String()), time.Hour)
+
+		buf := make([]byte, 65536)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+		assert.Regexp(t, statsdLine, strings.Split(string(buf[:n]), "\n")[0])
+	})
+	t.Run("TCP", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+		lines := make(chan string, 1)
+		go func() {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			scanner := bufio.NewScanner(conn)
+			if scanner.Scan() {
+				lines <- scanner.Text()
+			}
+		}()
+		runScenario(clock.Real(), scenarioTo(t, "tcp", listener.Addr().String()), time.Hour)
+
+		select {
+		case line := <-lines:
+			assert.Regexp(t, statsdLine, line)
+		case <-time.After(time.Second):
+			t.Fatal("no line received")
+		}
+	})
+	t.Run("Unreachable", func(t *testing.T) {
+		// The generator keeps going when the agent is not listening
+		runScenario(clock.Real(), scenarioTo(t, "unixgram", filepath.Join(t.TempDir(), "missing.sock")), time.Hour)
+	})
+}
+
+func scenarioTo(t *testing.T, protocol, address string) *Scenario {
+	scenario, err := decodeScenario([]byte(`
+duration: 100ms
+traffic:
+  tick: 10ms
+  shape: {type: constant, rate: 1000}
+families: [{name: a, type: gauge}]
+`))
+	require.NoError(t, err)
+	scenario.Target = Target{Protocol: protocol, Address: address, Connections: 1}
+	return scenario
+}
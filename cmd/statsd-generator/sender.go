@@ -0,0 +1,106 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"log"
+	"net"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
+)
+
+const (
+	dialTimeout    = 5 * time.Second
+	reportInterval = 10 * time.Second
+)
+
+// sender writes the packets round robin over the connections of the target. A connection that fails is
+// dialed again on its next packet, so the generator keeps going while the agent restarts.
+type sender struct {
+	target Target
+	conns  []net.Conn
+	next   int
+	errors int
+}
+
+func newSender(target Target) *sender {
+	return &sender{target: target, conns: make([]net.Conn, target.Connections)}
+}
+
+func (s *sender) send(packet []byte) error {
+	i := s.next
+	s.next = (s.next + 1) % len(s.conns)
+	if s.conns[i] == nil {
+		conn, err := net.DialTimeout(s.target.Protocol, s.target.Address, dialTimeout)
+		if err != nil {
+			s.errors++
+			return err
+		}
+		s.conns[i] = conn
+	}
+	if s.target.Protocol == "tcp" {
+		// TCP is a stream, every line including the last one of the packet is terminated
+		packet = append(packet, '\n')
+	}
+	if _, err := s.conns[i].Write(packet); err != nil {
+		s.errors++
+		s.conns[i].Close()
+		s.conns[i] = nil
+		return err
+	}
+	return nil
+}
+
+func (s *sender) close() {
+	for i, conn := range s.conns {
+		if conn != nil {
+			conn.Close()
+			s.conns[i] = nil
+		}
+	}
+}
+
+// runScenario sends the traffic of the scenario until its duration (or runTime when the scenario has no
+// duration) has passed, logging the progress every reportInterval.
+func runScenario(clk clock.Clock, scenario *Scenario, runTime time.Duration) {
+	duration := scenario.Duration
+	if duration == 0 {
+		duration = runTime
+	}
+	log.Printf("Start statsd scenario to %s://%s for %v with a %s traffic shape...",
+		scenario.Target.Protocol, scenario.Target.Address, duration, scenario.Traffic.Shape.Type)
+
+	s := newSender(scenario.Target)
+	defer s.close()
+	g := newGenerator(scenario)
+	tick := scenario.Traffic.Tick
+	ticker := clk.NewTicker(tick)
+	defer ticker.Stop()
+
+	start := clk.Now()
+	lastReport := start
+	var lastErr error
+	for now := range ticker.C() {
+		elapsed := now.Sub(start)
+		for _, packet := range g.tick(elapsed, tick) {
+			if err := s.send(packet); err != nil {
+				lastErr = err
+			}
+		}
+		if now.Sub(lastReport) >= reportInterval || elapsed >= duration {
+			lastReport = now
+			log.Printf("After %v: rate %.0f/s, %d lines sent in %d packets (%d bytes), %d sampled out, %d send errors",
+				elapsed.Round(time.Second), scenario.Traffic.Shape.RateAt(elapsed), g.stats.Lines, g.stats.Packets,
+				g.stats.Bytes, g.stats.SampledOut, s.errors)
+			if lastErr != nil {
+				log.Printf("Last send error: %v", lastErr)
+				lastErr = nil
+			}
+		}
+		if elapsed >= duration {
+			return
+		}
+	}
+}
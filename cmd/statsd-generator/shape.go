@@ -0,0 +1,144 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// Shape is the rate of metric lines per second over the run:
+//   - constant sends Rate.
+//   - ramp goes linearly from Rate to To over Over, then stays at To.
+//   - step sends the Rate of the last step started, and Rate before the first one.
+//   - sine oscillates around Rate by Amplitude with the Period.
+//   - burst sends BurstRate for BurstDuration every Every, and Rate the rest of the time.
+//   - poisson sends Rate on average, with the number of lines of every tick following a Poisson
+//     distribution.
+type Shape struct {
+	Type          string        `yaml:"type"`
+	Rate          float64       `yaml:"rate"`
+	To            float64       `yaml:"to"`
+	Over          time.Duration `yaml:"over"`
+	Steps         []Step        `yaml:"steps"`
+	Amplitude     float64       `yaml:"amplitude"`
+	Period        time.Duration `yaml:"period"`
+	BurstRate     float64       `yaml:"burst_rate"`
+	BurstDuration time.Duration `yaml:"burst_duration"`
+	Every         time.Duration `yaml:"every"`
+}
+
+// Step changes the rate At a time since the start of the run.
+type Step struct {
+	At   time.Duration `yaml:"at"`
+	Rate float64       `yaml:"rate"`
+}
+
+func (s Shape) Validate() error {
+	var errs []error
+	addError := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("traffic.shape: "+format, args...))
+	}
+	if !slices.Contains(supportedShapes, s.Type) {
+		addError("type must be one of %v, got %q", supportedShapes, s.Type)
+	}
+	if s.Rate < 0 || s.To < 0 || s.BurstRate < 0 {
+		addError("rates must not be negative")
+	}
+	switch s.Type {
+	case "ramp":
+		if s.Over <= 0 {
+			addError("ramp needs a positive over")
+		}
+	case "step":
+		if len(s.Steps) == 0 {
+			addError("step needs steps")
+		}
+		for _, step := range s.Steps {
+			if step.At < 0 || step.Rate < 0 {
+				addError("step at %v must not be negative nor have a negative rate", step.At)
+			}
+		}
+	case "sine":
+		if s.Period <= 0 {
+			addError("sine needs a positive period")
+		}
+	case "burst":
+		if s.Every <= 0 || s.BurstDuration <= 0 || s.BurstDuration > s.Every {
+			addError("burst needs a positive every and a burst_duration within it")
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RateAt returns the lines per second the shape sends at the elapsed time since the start of the run.
+func (s Shape) RateAt(elapsed time.Duration) float64 {
+	switch s.Type {
+	case "ramp":
+		progress := math.Min(1, float64(elapsed)/float64(s.Over))
+		return s.Rate + (s.To-s.Rate)*progress
+	case "step":
+		steps := append([]Step(nil), s.Steps...)
+		sort.SliceStable(steps, func(i, j int) bool { return steps[i].At < steps[j].At })
+		rate := s.Rate
+		for _, step := range steps {
+			if step.At > elapsed {
+				break
+			}
+			rate = step.Rate
+		}
+		return rate
+	case "sine":
+		return math.Max(0, s.Rate+s.Amplitude*math.Sin(2*math.Pi*float64(elapsed)/float64(s.Period)))
+	case "burst":
+		if elapsed%s.Every < s.BurstDuration {
+			return s.BurstRate
+		}
+		return s.Rate
+	default:
+		return s.Rate
+	}
+}
+
+// scheduler turns the rate of the shape into a whole number of lines per tick, carrying the fraction of
+// a line over to the next tick so low rates are still sent.
+type scheduler struct {
+	shape Shape
+	rng   *rand.Rand
+	carry float64
+}
+
+// lines returns the number of lines to send for the tick of length tick ending at elapsed.
+func (s *scheduler) lines(elapsed, tick time.Duration) int {
+	expected := s.shape.RateAt(elapsed) * tick.Seconds()
+	if s.shape.Type == "poisson" {
+		return poisson(s.rng, expected)
+	}
+	expected += s.carry
+	lines := math.Floor(expected)
+	s.carry = expected - lines
+	return int(lines)
+}
+
+// poisson draws from a Poisson distribution of mean lambda, using the normal approximation for large means.
+func poisson(rng *rand.Rand, lambda float64) int {
+	if lambda <= 0 {
+		return 0
+	}
+	if lambda > 30 {
+		return int(math.Max(0, math.Round(lambda+math.Sqrt(lambda)*rng.NormFloat64())))
+	}
+	limit := math.Exp(-lambda)
+	count := 0
+	for product := rng.Float64(); product > limit; product *= rng.Float64() {
+		count++
+	}
+	return count
+}
@@ -11,6 +11,8 @@ import (
 	"time"
 
 	"github.com/DataDog/datadog-go/statsd"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
 )
 
 const operationNum = 5 // the number of operations done in one loop
@@ -20,13 +22,23 @@ var (
 	tps       = flag.Int("tps", 100, "Transaction per second for each statsd client.")
 	metricNum = flag.Int("metricNum", 100, "The number of unique metrics for each statsd client.")
 	runTime   = flag.Duration("runTime", 48*time.Hour, "Run time duration.")
+	scenario  = flag.String("scenario", "", "Scenario file describing the metrics, traffic shape and target. The other flags except runTime are ignored.")
 )
 
-// sample command:
+// sample commands:
 //
 //	statsdGen -clientNum 1 -tps 100 -metricNum 100
+//	statsdGen -scenario cmd/statsd-generator/scenarios/cardinality-explosion.yaml
 func main() {
 	flag.Parse()
+	if *scenario != "" {
+		s, err := LoadScenario(*scenario)
+		if err != nil {
+			log.Fatal(err)
+		}
+		runScenario(clock.Real(), s, *runTime)
+		return
+	}
 	log.Printf("Start statsd generator %d client, and each client sends %d tps with %d unique metrics...", *clientNum, *tps, *metricNum)
 	for i := 0; i < *clientNum; i++ {
 		go startStatsDClient(i, *tps, *metricNum)
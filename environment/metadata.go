@@ -69,6 +69,7 @@ type MetaData struct {
 	PerformanceMetricMapName                    string
 	PerformanceTestName                         string
 	IPFamily                                    string
+	ResultsDirectory                            string
 }
 
 type MetaDataStrings struct {
@@ -116,6 +117,7 @@ type MetaDataStrings struct {
 	PerformanceMetricMapName                    string
 	PerformanceTestName                         string
 	IPFamily                                    string
+	ResultsDirectory                            string
 }
 
 func registerComputeType(dataString *MetaDataStrings) {
@@ -313,6 +315,10 @@ func registerAmpWorkspaceId(dataString *MetaDataStrings) {
 	flag.StringVar(&(dataString.AmpWorkspaceId), "ampWorkspaceId", "", "workspace Id for Amazon Managed Prometheus (AMP)")
 }
 
+func registerResultsDirectory(dataString *MetaDataStrings) {
+	flag.StringVar(&(dataString.ResultsDirectory), "resultsDir", "", "Directory to write JUnit XML and JSON test reports to. Reports are not written if empty")
+}
+
 func registerAccountId(dataString *MetaDataStrings) {
 	flag.StringVar(&(dataString.AccountId), "accountId", "", "AWS account Id")
 }
@@ -336,6 +342,7 @@ func RegisterEnvironmentMetaDataFlags() *MetaDataStrings {
 	registerAgentStartCommand(registeredMetaDataStrings)
 	registerAmpWorkspaceId(registeredMetaDataStrings)
 	registerAccountId(registeredMetaDataStrings)
+	registerResultsDirectory(registeredMetaDataStrings)
 
 	return registeredMetaDataStrings
 }
@@ -382,6 +389,7 @@ func GetEnvironmentMetaData() *MetaData {
 	metaDataStorage.SampleApp = registeredMetaDataStrings.SampleApp
 	metaDataStorage.AccountId = registeredMetaDataStrings.AccountId
 	metaDataStorage.IPFamily = registeredMetaDataStrings.IPFamily
+	metaDataStorage.ResultsDirectory = registeredMetaDataStrings.ResultsDirectory
 	fillEKSInstallationType(metaDataStorage, registeredMetaDataStrings)
 
 	return metaDataStorage
@@ -0,0 +1,41 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+// otelcatalog generates the table-driven catalog test of every OTel suite from the metric catalog. It is
+// run by go generate in util/otelmetrics.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/otelmetrics"
+)
+
+func main() {
+	catalogPath := flag.String("catalog", "util/otelmetrics/catalog.yaml", "path of the metric catalog")
+	outDir := flag.String("out", "test/otel", "directory with a subdirectory per suite")
+	flag.Parse()
+
+	data, err := os.ReadFile(*catalogPath)
+	if err != nil {
+		log.Fatalf("Failed to read the catalog: %v", err)
+	}
+	catalog, err := otelmetrics.LoadCatalog(data)
+	if err != nil {
+		log.Fatalf("Invalid catalog %s: %v", *catalogPath, err)
+	}
+	for _, suite := range catalog.Suites {
+		source, err := catalog.GenerateSuiteTest(suite.Name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		path := filepath.Join(*outDir, suite.Name, otelmetrics.SuiteTestFile)
+		if err = os.WriteFile(path, source, 0644); err != nil {
+			log.Fatalf("Failed to write %s: %v", path, err)
+		}
+		log.Printf("Wrote %s", path)
+	}
+}
@@ -97,6 +97,14 @@ var testTypeToTestConfig = map[string][]testConfig{
 			testDir: "./test/log_state/logfile",
 			targets: map[string]map[string]struct{}{"os": {"al2": {}}},
 		},
+		{
+			testDir: "./test/log_loss",
+			targets: map[string]map[string]struct{}{"os": {"al2": {}}},
+		},
+		{
+			testDir: "./test/log_state/chaos",
+			targets: map[string]map[string]struct{}{"os": {"al2": {}}},
+		},
 		{
 			testDir: "./test/log_state/journald",
 			targets: map[string]map[string]struct{}{"os": {"al2": {}, "al2023": {}}},
@@ -313,6 +321,7 @@ var testTypeToTestConfig = map[string][]testConfig{
 		{testDir: "../../../test/feature/windows/eventid_logs"},
 		{testDir: "../../../test/feature/windows/event_regex_logs"},
 		{testDir: "../../../test/log_state/logfile"},
+		{testDir: "../../../test/log_loss"},
 		{testDir: "../../../test/log_state/windows_event_log"},
 		{
 			testDir: "../../../test/feature/windows/custom_start/userdata",
@@ -332,6 +341,7 @@ var testTypeToTestConfig = map[string][]testConfig{
 		{testDir: "../../test/performance/system"},
 		{testDir: "../../test/performance/statsd"},
 		{testDir: "../../test/performance/collectd"},
+		{testDir: "../../test/performance/mixed"},
 		{testDir: "../../test/performance/trace/xray", runMockServer: true},
 	},
 	"ec2_windows_performance": {
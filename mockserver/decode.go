@@ -0,0 +1,452 @@
+// Copyright 2023 Amazon.com, Inc. or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	protocolQuery = "query"
+	protocolJSON  = "json"
+
+	amzTargetHeader = "X-Amz-Target"
+)
+
+// readBody returns the request body, decompressing it if the agent gzipped it.
+func readBody(r *http.Request) ([]byte, error) {
+	defer r.Body.Close()
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	return decompress(body, r.Header.Get("Content-Encoding"))
+}
+
+func decompress(body []byte, contentEncoding string) ([]byte, error) {
+	if contentEncoding != "gzip" {
+		return body, nil
+	}
+	gzipReader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid gzip body: %w", err)
+	}
+	defer gzipReader.Close()
+	return io.ReadAll(gzipReader)
+}
+
+const (
+	kindPutMetricData = "put_metric_data"
+	kindPutLogEvents  = "put_log_events"
+	kindOtlpMetrics   = "otlp_metrics"
+	kindOtlpTraces    = "otlp_traces"
+)
+
+// Payload is a decoded request. Only the field matching the kind is set, and requests that are not
+// understood have no kind.
+type Payload struct {
+	Kind        string        `json:"kind,omitempty"`
+	Metrics     []MetricDatum `json:"metrics,omitempty"`
+	LogEvents   []LogEvent    `json:"log_events,omitempty"`
+	OtlpMetrics []OtlpMetric  `json:"otlp_metrics,omitempty"`
+	Spans       []Span        `json:"spans,omitempty"`
+}
+
+// decodePayload decodes the request into the store. Requests it does not understand are ignored since
+// they still count as transactions.
+func (s *payloadStore) decodePayload(r *http.Request, body []byte) error {
+	payload, err := decodeRequest(r, body)
+	if err != nil {
+		return err
+	}
+	s.add(payload)
+	return nil
+}
+
+// decodeRequest decodes the (decompressed) body of the request based on its target, content type and path.
+func decodeRequest(r *http.Request, body []byte) (Payload, error) {
+	target := r.Header.Get(amzTargetHeader)
+	contentType := r.Header.Get("Content-Type")
+	var (
+		payload Payload
+		err     error
+	)
+	switch {
+	case strings.HasSuffix(target, ".PutLogEvents"):
+		payload.Kind = kindPutLogEvents
+		payload.LogEvents, err = decodePutLogEvents(body)
+	case strings.HasSuffix(target, ".PutMetricData"):
+		payload.Kind = kindPutMetricData
+		payload.Metrics, err = decodeJSONPutMetricData(body)
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		payload.Kind = kindPutMetricData
+		payload.Metrics, err = decodeQueryPutMetricData(body)
+	case strings.HasSuffix(r.URL.Path, "/v1/metrics") || strings.HasSuffix(r.URL.Path, "/metric/v1"):
+		payload.Kind = kindOtlpMetrics
+		payload.OtlpMetrics, err = decodeOtlpMetrics(body, contentType)
+	case strings.HasSuffix(r.URL.Path, "/v1/traces") || strings.HasSuffix(r.URL.Path, "/trace/v1"):
+		payload.Kind = kindOtlpTraces
+		payload.Spans, err = decodeOtlpTraces(body, contentType)
+	}
+	if err != nil {
+		return Payload{}, err
+	}
+	return payload, nil
+}
+
+func decodePutLogEvents(body []byte) ([]LogEvent, error) {
+	var request struct {
+		LogGroupName  string `json:"logGroupName"`
+		LogStreamName string `json:"logStreamName"`
+		LogEvents     []struct {
+			Timestamp int64  `json:"timestamp"`
+			Message   string `json:"message"`
+		} `json:"logEvents"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, fmt.Errorf("invalid PutLogEvents payload: %w", err)
+	}
+	events := make([]LogEvent, 0, len(request.LogEvents))
+	for _, event := range request.LogEvents {
+		events = append(events, LogEvent{
+			LogGroup:  request.LogGroupName,
+			LogStream: request.LogStreamName,
+			Timestamp: event.Timestamp,
+			Message:   event.Message,
+		})
+	}
+	return events, nil
+}
+
+// jsonStatisticSet is the StatisticValues of the awsJson1_0 protocol. The keys do not match the snake case
+// keys StatisticSet is served with, which encoding/json does not map to each other.
+type jsonStatisticSet struct {
+	SampleCount float64 `json:"SampleCount"`
+	Sum         float64 `json:"Sum"`
+	Minimum     float64 `json:"Minimum"`
+	Maximum     float64 `json:"Maximum"`
+}
+
+// decodeJSONPutMetricData decodes the awsJson1_0 protocol, where timestamps are epoch seconds.
+func decodeJSONPutMetricData(body []byte) ([]MetricDatum, error) {
+	var request struct {
+		Namespace  string `json:"Namespace"`
+		MetricData []struct {
+			MetricName        string            `json:"MetricName"`
+			Dimensions        []Dimension       `json:"Dimensions"`
+			Timestamp         float64           `json:"Timestamp"`
+			Unit              string            `json:"Unit"`
+			StorageResolution int               `json:"StorageResolution"`
+			Value             *float64          `json:"Value"`
+			Values            []float64         `json:"Values"`
+			Counts            []float64         `json:"Counts"`
+			StatisticValues   *jsonStatisticSet `json:"StatisticValues"`
+		} `json:"MetricData"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, fmt.Errorf("invalid PutMetricData payload: %w", err)
+	}
+	data := make([]MetricDatum, 0, len(request.MetricData))
+	for _, d := range request.MetricData {
+		seconds, fraction := math.Modf(d.Timestamp)
+		var statisticValues *StatisticSet
+		if v := d.StatisticValues; v != nil {
+			statisticValues = &StatisticSet{SampleCount: v.SampleCount, Sum: v.Sum, Minimum: v.Minimum, Maximum: v.Maximum}
+		}
+		data = append(data, MetricDatum{
+			Protocol:          protocolJSON,
+			Namespace:         request.Namespace,
+			MetricName:        d.MetricName,
+			Dimensions:        d.Dimensions,
+			Timestamp:         time.Unix(int64(seconds), int64(fraction*float64(time.Second))).UTC(),
+			Unit:              d.Unit,
+			StorageResolution: d.StorageResolution,
+			Value:             d.Value,
+			Values:            d.Values,
+			Counts:            d.Counts,
+			StatisticValues:   statisticValues,
+		})
+	}
+	return data, nil
+}
+
+// decodeQueryPutMetricData decodes the awsQuery protocol, where lists are flattened into
+// MetricData.member.N.* form fields.
+func decodeQueryPutMetricData(body []byte) ([]MetricDatum, error) {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PutMetricData payload: %w", err)
+	}
+	if action := form.Get("Action"); action != "PutMetricData" {
+		return nil, fmt.Errorf("unsupported query action %q", action)
+	}
+	namespace := form.Get("Namespace")
+	var data []MetricDatum
+	for _, prefix := range members(form, "MetricData") {
+		datum := MetricDatum{
+			Protocol:   protocolQuery,
+			Namespace:  namespace,
+			MetricName: form.Get(prefix + ".MetricName"),
+			Unit:       form.Get(prefix + ".Unit"),
+		}
+		for _, dimension := range members(form, prefix+".Dimensions") {
+			datum.Dimensions = append(datum.Dimensions, Dimension{Name: form.Get(dimension + ".Name"), Value: form.Get(dimension + ".Value")})
+		}
+		if timestamp := form.Get(prefix + ".Timestamp"); timestamp != "" {
+			if datum.Timestamp, err = time.Parse(time.RFC3339Nano, timestamp); err != nil {
+				return nil, fmt.Errorf("invalid timestamp %q: %w", timestamp, err)
+			}
+		}
+		if resolution := form.Get(prefix + ".StorageResolution"); resolution != "" {
+			datum.StorageResolution, _ = strconv.Atoi(resolution)
+		}
+		if value := form.Get(prefix + ".Value"); value != "" {
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q: %w", value, err)
+			}
+			datum.Value = &v
+		}
+		if datum.Values, err = floatMembers(form, prefix+".Values"); err != nil {
+			return nil, err
+		}
+		if datum.Counts, err = floatMembers(form, prefix+".Counts"); err != nil {
+			return nil, err
+		}
+		if form.Has(prefix + ".StatisticValues.SampleCount") {
+			stats := &StatisticSet{}
+			for field, target := range map[string]*float64{
+				"SampleCount": &stats.SampleCount,
+				"Sum":         &stats.Sum,
+				"Minimum":     &stats.Minimum,
+				"Maximum":     &stats.Maximum,
+			} {
+				if *target, err = strconv.ParseFloat(form.Get(prefix+".StatisticValues."+field), 64); err != nil {
+					return nil, fmt.Errorf("invalid statistic %s: %w", field, err)
+				}
+			}
+			datum.StatisticValues = stats
+		}
+		data = append(data, datum)
+	}
+	return data, nil
+}
+
+// members returns the prefixes of the list members (e.g MetricData.member.1) in index order.
+func members(form url.Values, list string) []string {
+	prefix := list + ".member."
+	indexes := map[int]struct{}{}
+	for key := range form {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		index := strings.TrimPrefix(key, prefix)
+		if dot := strings.Index(index, "."); dot >= 0 {
+			index = index[:dot]
+		}
+		if i, err := strconv.Atoi(index); err == nil {
+			indexes[i] = struct{}{}
+		}
+	}
+	sorted := make([]int, 0, len(indexes))
+	for i := range indexes {
+		sorted = append(sorted, i)
+	}
+	sort.Ints(sorted)
+	result := make([]string, 0, len(sorted))
+	for _, i := range sorted {
+		result = append(result, prefix+strconv.Itoa(i))
+	}
+	return result
+}
+
+func floatMembers(form url.Values, list string) ([]float64, error) {
+	var result []float64
+	for _, member := range members(form, list) {
+		v, err := strconv.ParseFloat(form.Get(member), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", member, err)
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+// unmarshalOtlp decodes OTLP/HTTP payloads in either the protobuf or the JSON encoding.
+func unmarshalOtlp(body []byte, contentType string, message proto.Message) error {
+	if strings.HasPrefix(contentType, "application/json") {
+		return protojson.Unmarshal(body, message)
+	}
+	return proto.Unmarshal(body, message)
+}
+
+// decodeOtlpMetrics decodes an ExportMetricsServiceRequest, which has the same wire format as MetricsData.
+func decodeOtlpMetrics(body []byte, contentType string) ([]OtlpMetric, error) {
+	var request metricspb.MetricsData
+	if err := unmarshalOtlp(body, contentType, &request); err != nil {
+		return nil, fmt.Errorf("invalid OTLP metrics payload: %w", err)
+	}
+	var metrics []OtlpMetric
+	for _, resourceMetrics := range request.GetResourceMetrics() {
+		resource := attributesToMap(resourceMetrics.GetResource().GetAttributes())
+		for _, scopeMetrics := range resourceMetrics.GetScopeMetrics() {
+			for _, m := range scopeMetrics.GetMetrics() {
+				metric := OtlpMetric{
+					Resource: resource,
+					Scope:    scopeMetrics.GetScope().GetName(),
+					Name:     m.GetName(),
+					Unit:     m.GetUnit(),
+				}
+				switch data := m.GetData().(type) {
+				case *metricspb.Metric_Gauge:
+					metric.Type = "gauge"
+					metric.DataPoints = numberDataPoints(data.Gauge.GetDataPoints())
+				case *metricspb.Metric_Sum:
+					metric.Type = "sum"
+					metric.DataPoints = numberDataPoints(data.Sum.GetDataPoints())
+				case *metricspb.Metric_Histogram:
+					metric.Type = "histogram"
+					for _, dp := range data.Histogram.GetDataPoints() {
+						metric.DataPoints = append(metric.DataPoints, OtlpDataPoint{
+							Attributes: attributesToMap(dp.GetAttributes()),
+							Timestamp:  time.Unix(0, int64(dp.GetTimeUnixNano())).UTC(),
+							Count:      dp.GetCount(),
+							Sum:        dp.Sum,
+						})
+					}
+				case *metricspb.Metric_ExponentialHistogram:
+					metric.Type = "exponential_histogram"
+					for _, dp := range data.ExponentialHistogram.GetDataPoints() {
+						metric.DataPoints = append(metric.DataPoints, OtlpDataPoint{
+							Attributes: attributesToMap(dp.GetAttributes()),
+							Timestamp:  time.Unix(0, int64(dp.GetTimeUnixNano())).UTC(),
+							Count:      dp.GetCount(),
+							Sum:        dp.Sum,
+						})
+					}
+				case *metricspb.Metric_Summary:
+					metric.Type = "summary"
+					for _, dp := range data.Summary.GetDataPoints() {
+						sum := dp.GetSum()
+						metric.DataPoints = append(metric.DataPoints, OtlpDataPoint{
+							Attributes: attributesToMap(dp.GetAttributes()),
+							Timestamp:  time.Unix(0, int64(dp.GetTimeUnixNano())).UTC(),
+							Count:      dp.GetCount(),
+							Sum:        &sum,
+						})
+					}
+				}
+				metrics = append(metrics, metric)
+			}
+		}
+	}
+	return metrics, nil
+}
+
+func numberDataPoints(dataPoints []*metricspb.NumberDataPoint) []OtlpDataPoint {
+	result := make([]OtlpDataPoint, 0, len(dataPoints))
+	for _, dp := range dataPoints {
+		var value float64
+		switch v := dp.GetValue().(type) {
+		case *metricspb.NumberDataPoint_AsDouble:
+			value = v.AsDouble
+		case *metricspb.NumberDataPoint_AsInt:
+			value = float64(v.AsInt)
+		}
+		result = append(result, OtlpDataPoint{
+			Attributes: attributesToMap(dp.GetAttributes()),
+			Timestamp:  time.Unix(0, int64(dp.GetTimeUnixNano())).UTC(),
+			Value:      &value,
+		})
+	}
+	return result
+}
+
+// decodeOtlpTraces decodes an ExportTraceServiceRequest, which has the same wire format as TracesData.
+func decodeOtlpTraces(body []byte, contentType string) ([]Span, error) {
+	var request tracepb.TracesData
+	if err := unmarshalOtlp(body, contentType, &request); err != nil {
+		return nil, fmt.Errorf("invalid OTLP traces payload: %w", err)
+	}
+	var spans []Span
+	for _, resourceSpans := range request.GetResourceSpans() {
+		resource := attributesToMap(resourceSpans.GetResource().GetAttributes())
+		for _, scopeSpans := range resourceSpans.GetScopeSpans() {
+			for _, span := range scopeSpans.GetSpans() {
+				spans = append(spans, Span{
+					Resource:     resource,
+					TraceID:      hex.EncodeToString(span.GetTraceId()),
+					SpanID:       hex.EncodeToString(span.GetSpanId()),
+					ParentSpanID: hex.EncodeToString(span.GetParentSpanId()),
+					Name:         span.GetName(),
+					Kind:         span.GetKind().String(),
+					Attributes:   attributesToMap(span.GetAttributes()),
+					StartTime:    time.Unix(0, int64(span.GetStartTimeUnixNano())).UTC(),
+					EndTime:      time.Unix(0, int64(span.GetEndTimeUnixNano())).UTC(),
+				})
+			}
+		}
+	}
+	return spans, nil
+}
+
+func attributesToMap(attributes []*commonpb.KeyValue) map[string]string {
+	result := make(map[string]string, len(attributes))
+	for _, kv := range attributes {
+		result[kv.GetKey()] = anyValueString(kv.GetValue())
+	}
+	return result
+}
+
+func anyValueString(value *commonpb.AnyValue) string {
+	switch v := value.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return strconv.FormatBool(v.BoolValue)
+	case *commonpb.AnyValue_IntValue:
+		return strconv.FormatInt(v.IntValue, 10)
+	case *commonpb.AnyValue_DoubleValue:
+		return strconv.FormatFloat(v.DoubleValue, 'f', -1, 64)
+	case *commonpb.AnyValue_BytesValue:
+		return hex.EncodeToString(v.BytesValue)
+	case nil:
+		return ""
+	default:
+		encoded, err := protojson.Marshal(value)
+		if err != nil {
+			return ""
+		}
+		return string(encoded)
+	}
+}
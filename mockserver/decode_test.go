@@ -0,0 +1,220 @@
+// Copyright 2023 Amazon.com, Inc. or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func newTestServer() *transactionHttpServer {
+	return &transactionHttpServer{startTime: time.Now(), store: newPayloadStore(DefaultMaxStored), faults: newFaultInjector()}
+}
+
+func send(t *testing.T, ts *transactionHttpServer, path string, header http.Header, body []byte) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	ts.recordTransaction(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", rec.Code)
+	}
+}
+
+func query(t *testing.T, handler http.HandlerFunc, target string, v interface{}) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("invalid response %q: %v", rec.Body.String(), err)
+	}
+}
+
+func TestQueryPutMetricData(t *testing.T) {
+	ts := newTestServer()
+	form := url.Values{
+		"Action":                         {"PutMetricData"},
+		"Namespace":                      {"CWAgent"},
+		"MetricData.member.1.MetricName": {"cpu_usage_idle"},
+		"MetricData.member.1.Dimensions.member.1.Name":  {"InstanceId"},
+		"MetricData.member.1.Dimensions.member.1.Value": {"i-123"},
+		"MetricData.member.1.Timestamp":                 {"2023-08-01T10:00:00Z"},
+		"MetricData.member.1.Unit":                      {"Percent"},
+		"MetricData.member.1.Value":                     {"42.5"},
+		"MetricData.member.2.MetricName":                {"mem_used"},
+		"MetricData.member.2.Values.member.1":           {"1"},
+		"MetricData.member.2.Values.member.2":           {"2"},
+		"MetricData.member.2.Counts.member.1":           {"3"},
+		"MetricData.member.2.Counts.member.2":           {"4"},
+	}
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte(form.Encode()))
+	gz.Close()
+	send(t, ts, "/put-data", http.Header{
+		"Content-Type":     {"application/x-www-form-urlencoded; charset=utf-8"},
+		"Content-Encoding": {"gzip"},
+	}, compressed.Bytes())
+
+	var data []MetricDatum
+	query(t, ts.listMetrics, "/metrics?namespace=CWAgent&dimension=InstanceId:i-123", &data)
+	if len(data) != 1 {
+		t.Fatalf("expected 1 datum, got %d", len(data))
+	}
+	datum := data[0]
+	if datum.MetricName != "cpu_usage_idle" || datum.Unit != "Percent" || datum.Value == nil || *datum.Value != 42.5 {
+		t.Errorf("unexpected datum %+v", datum)
+	}
+	if !datum.Timestamp.Equal(time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected timestamp %s", datum.Timestamp)
+	}
+
+	query(t, ts.listMetrics, "/metrics?name=mem_used", &data)
+	if len(data) != 1 || len(data[0].Values) != 2 || data[0].Counts[1] != 4 {
+		t.Fatalf("unexpected values %+v", data)
+	}
+}
+
+func TestJSONPutMetricDataAndLogs(t *testing.T) {
+	ts := newTestServer()
+	send(t, ts, "/put-data", http.Header{
+		"X-Amz-Target": {"GraniteServiceVersion20100801.PutMetricData"},
+		"Content-Type": {"application/x-amz-json-1.0"},
+	}, []byte(`{"Namespace":"CWAgent","MetricData":[{"MetricName":"disk_used","Dimensions":[{"Name":"path","Value":"/"}],"Timestamp":1690884000.5,"StatisticValues":{"SampleCount":2,"Sum":10,"Minimum":4,"Maximum":6}}]}`))
+	send(t, ts, "/put-data", http.Header{
+		"X-Amz-Target": {"Logs_20140328.PutLogEvents"},
+		"Content-Type": {"application/x-amz-json-1.1"},
+	}, []byte(`{"logGroupName":"group","logStreamName":"stream","logEvents":[{"timestamp":1690884000000,"message":"hello"}]}`))
+
+	var data []MetricDatum
+	query(t, ts.listMetrics, "/metrics?dimension=path", &data)
+	if len(data) != 1 || data[0].StatisticValues == nil {
+		t.Fatalf("unexpected data %+v", data)
+	}
+	if want := (StatisticSet{SampleCount: 2, Sum: 10, Minimum: 4, Maximum: 6}); *data[0].StatisticValues != want {
+		t.Errorf("got statistic values %+v, want %+v", *data[0].StatisticValues, want)
+	}
+	if data[0].Timestamp.UnixMilli() != 1690884000500 {
+		t.Errorf("unexpected timestamp %s", data[0].Timestamp)
+	}
+
+	var events []LogEvent
+	query(t, ts.listLogEvents, "/logs?group=group&stream=stream", &events)
+	if len(events) != 1 || events[0].Message != "hello" {
+		t.Fatalf("unexpected events %+v", events)
+	}
+	query(t, ts.listLogEvents, "/logs?group=other", &events)
+	if len(events) != 0 {
+		t.Fatalf("expected no events, got %+v", events)
+	}
+
+	rec := httptest.NewRecorder()
+	ts.reset(rec, httptest.NewRequest(http.MethodPost, "/reset", nil))
+	query(t, ts.listMetrics, "/metrics", &data)
+	if len(data) != 0 {
+		t.Fatalf("expected reset to drop the data, got %+v", data)
+	}
+}
+
+func TestOtlpPayloads(t *testing.T) {
+	ts := newTestServer()
+	attributes := []*commonpb.KeyValue{{Key: "service.name", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "app"}}}}
+	metrics := &metricspb.MetricsData{ResourceMetrics: []*metricspb.ResourceMetrics{{
+		ScopeMetrics: []*metricspb.ScopeMetrics{{Metrics: []*metricspb.Metric{{
+			Name: "requests",
+			Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{DataPoints: []*metricspb.NumberDataPoint{{
+				Attributes: attributes,
+				Value:      &metricspb.NumberDataPoint_AsInt{AsInt: 7},
+			}}}},
+		}}}},
+	}}}
+	body, err := proto.Marshal(metrics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	send(t, ts, "/metric/v1", http.Header{"Content-Type": {"application/x-protobuf"}}, body)
+
+	traces := &tracepb.TracesData{ResourceSpans: []*tracepb.ResourceSpans{{
+		ScopeSpans: []*tracepb.ScopeSpans{{Spans: []*tracepb.Span{{
+			TraceId:    []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10},
+			SpanId:     []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08},
+			Name:       "GET /",
+			Kind:       tracepb.Span_SPAN_KIND_SERVER,
+			Attributes: attributes,
+		}}}},
+	}}}
+	body, err = protojson.Marshal(traces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	send(t, ts, "/trace/v1", http.Header{"Content-Type": {"application/json"}}, body)
+
+	var otlpMetrics []OtlpMetric
+	query(t, ts.listOtlpMetrics, "/otlp/metrics?name=requests", &otlpMetrics)
+	if len(otlpMetrics) != 1 || otlpMetrics[0].Type != "sum" || *otlpMetrics[0].DataPoints[0].Value != 7 {
+		t.Fatalf("unexpected metrics %+v", otlpMetrics)
+	}
+
+	var spans []Span
+	query(t, ts.listSpans, "/traces?trace_id=0102030405060708090a0b0c0d0e0f10", &spans)
+	if len(spans) != 1 || spans[0].Kind != "SPAN_KIND_SERVER" || spans[0].Attributes["service.name"] != "app" {
+		t.Fatalf("unexpected spans %+v", spans)
+	}
+}
+
+func TestUndecodablePayloadIsAcknowledged(t *testing.T) {
+	ts := newTestServer()
+	send(t, ts, "/put-data", http.Header{"X-Amz-Target": {"Logs_20140328.PutLogEvents"}}, []byte("not json"))
+	if ts.transactions != 1 {
+		t.Errorf("expected the transaction to be counted, got %d", ts.transactions)
+	}
+}
+
+func TestStoreKeepsLatestPayloads(t *testing.T) {
+	ts := newTestServer()
+	ts.store = newPayloadStore(2)
+	for _, message := range []string{"one", "two", "three"} {
+		send(t, ts, "/put-data", http.Header{"X-Amz-Target": {"Logs_20140328.PutLogEvents"}},
+			[]byte(`{"logGroupName":"group","logStreamName":"stream","logEvents":[{"timestamp":1690884000000,"message":"`+message+`"}]}`))
+	}
+	var events []LogEvent
+	query(t, ts.listLogEvents, "/logs", &events)
+	if len(events) != 2 || events[0].Message != "two" || events[1].Message != "three" {
+		t.Fatalf("expected the 2 latest events, got %+v", events)
+	}
+
+	ts.store = newPayloadStore(0)
+	send(t, ts, "/put-data", http.Header{"X-Amz-Target": {"Logs_20140328.PutLogEvents"}},
+		[]byte(`{"logGroupName":"group","logStreamName":"stream","logEvents":[{"timestamp":1690884000000,"message":"one"}]}`))
+	query(t, ts.listLogEvents, "/logs", &events)
+	if len(events) != 0 || ts.transactions != 4 {
+		t.Fatalf("expected the payload to be counted but not stored, got %+v and %d transactions", events, ts.transactions)
+	}
+}
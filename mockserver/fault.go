@@ -0,0 +1,324 @@
+// Copyright 2023 Amazon.com, Inc. or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultLatency is the built-in latency of every request when no fault profile sets one.
+const defaultLatency = 15 * time.Millisecond
+
+const (
+	faultDrop        = "drop"
+	faultPartialRead = "partial_read"
+	faultThrottle    = "throttle"
+	faultError       = "error"
+)
+
+// FaultConfig is the document accepted by the /admin/faults endpoint. The first profile whose route
+// matches the request path applies. Seeding the random source makes a run reproducible as long as the
+// agent sends the requests in the same order.
+type FaultConfig struct {
+	Seed     int64          `json:"seed"`
+	Profiles []FaultProfile `json:"profiles"`
+}
+
+// FaultProfile describes how requests to a route misbehave. The rates are the probabilities of each
+// fault and must not add up to more than 1; the remaining requests succeed.
+type FaultProfile struct {
+	// Route is a path prefix, empty matches every route.
+	Route string `json:"route"`
+	// DropRate closes the connection without a response.
+	DropRate float64 `json:"drop_rate"`
+	// PartialReadRate reads part of the body and then closes the connection.
+	PartialReadRate float64 `json:"partial_read_rate"`
+	// ThrottleRate responds with the throttling error of the protocol of the request.
+	ThrottleRate float64 `json:"throttle_rate"`
+	// ErrorRate responds with ErrorStatus, 500 by default.
+	ErrorRate   float64 `json:"error_rate"`
+	ErrorStatus int     `json:"error_status,omitempty"`
+	// RetryAfterSeconds sets the Retry-After header of throttling and error responses.
+	RetryAfterSeconds int `json:"retry_after_seconds,omitempty"`
+	// MaxFaults stops injecting faults once that many were injected for the profile, 0 is unlimited.
+	// This allows scenarios such as "throttle twice and then accept".
+	MaxFaults int      `json:"max_faults,omitempty"`
+	Latency   *Latency `json:"latency,omitempty"`
+}
+
+// Latency is a distribution of response latencies in milliseconds.
+type Latency struct {
+	// Distribution is one of fixed (MeanMs), uniform (MinMs to MaxMs), normal (MeanMs and StdDevMs) or
+	// exponential (MeanMs). Samples are clamped to MinMs and, if set, MaxMs.
+	Distribution string  `json:"distribution"`
+	MeanMs       float64 `json:"mean_ms,omitempty"`
+	StdDevMs     float64 `json:"stddev_ms,omitempty"`
+	MinMs        float64 `json:"min_ms,omitempty"`
+	MaxMs        float64 `json:"max_ms,omitempty"`
+}
+
+func (c FaultConfig) validate() error {
+	for i, p := range c.Profiles {
+		for name, rate := range map[string]float64{
+			"drop_rate":         p.DropRate,
+			"partial_read_rate": p.PartialReadRate,
+			"throttle_rate":     p.ThrottleRate,
+			"error_rate":        p.ErrorRate,
+		} {
+			if rate < 0 || rate > 1 {
+				return fmt.Errorf("profile %d: %s must be between 0 and 1", i, name)
+			}
+		}
+		if total := p.DropRate + p.PartialReadRate + p.ThrottleRate + p.ErrorRate; total > 1 {
+			return fmt.Errorf("profile %d: the fault rates add up to %g which is more than 1", i, total)
+		}
+		if p.ErrorStatus != 0 && (p.ErrorStatus < 400 || p.ErrorStatus > 599) {
+			return fmt.Errorf("profile %d: error_status %d is not an error status", i, p.ErrorStatus)
+		}
+		if p.Latency != nil {
+			if err := p.Latency.validate(); err != nil {
+				return fmt.Errorf("profile %d: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (l Latency) validate() error {
+	switch l.Distribution {
+	case "fixed", "normal", "exponential":
+	case "uniform":
+		if l.MaxMs < l.MinMs {
+			return errors.New("uniform latency needs max_ms >= min_ms")
+		}
+	default:
+		return fmt.Errorf("unknown latency distribution %q", l.Distribution)
+	}
+	if l.MeanMs < 0 || l.StdDevMs < 0 || l.MinMs < 0 || l.MaxMs < 0 {
+		return errors.New("latency values must not be negative")
+	}
+	return nil
+}
+
+func (l Latency) sample(random *rand.Rand) time.Duration {
+	var ms float64
+	switch l.Distribution {
+	case "fixed":
+		ms = l.MeanMs
+	case "uniform":
+		ms = l.MinMs + random.Float64()*(l.MaxMs-l.MinMs)
+	case "normal":
+		ms = random.NormFloat64()*l.StdDevMs + l.MeanMs
+	case "exponential":
+		ms = random.ExpFloat64() * l.MeanMs
+	}
+	ms = math.Max(ms, l.MinMs)
+	if l.MaxMs > 0 {
+		ms = math.Min(ms, l.MaxMs)
+	}
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// faultInjector holds the active fault configuration and how many faults of each kind it injected.
+type faultInjector struct {
+	mu       sync.Mutex
+	config   FaultConfig
+	random   *rand.Rand
+	injected []int
+	counts   map[string]int
+}
+
+func newFaultInjector() *faultInjector {
+	return &faultInjector{random: rand.New(rand.NewSource(0)), counts: map[string]int{}}
+}
+
+func (f *faultInjector) set(config FaultConfig) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.config = config
+	f.random = rand.New(rand.NewSource(config.Seed))
+	f.injected = make([]int, len(config.Profiles))
+	f.counts = map[string]int{}
+}
+
+type faultDecision struct {
+	latency    time.Duration
+	fault      string
+	status     int
+	retryAfter int
+}
+
+// decide picks the latency and the fault, if any, of a request to path.
+func (f *faultInjector) decide(path string) faultDecision {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	decision := faultDecision{latency: defaultLatency}
+	for i, p := range f.config.Profiles {
+		if !strings.HasPrefix(path, p.Route) {
+			continue
+		}
+		if p.Latency != nil {
+			decision.latency = p.Latency.sample(f.random)
+		}
+		if p.MaxFaults > 0 && f.injected[i] >= p.MaxFaults {
+			return decision
+		}
+		draw := f.random.Float64()
+		for _, fault := range []struct {
+			name string
+			rate float64
+		}{
+			{faultDrop, p.DropRate},
+			{faultPartialRead, p.PartialReadRate},
+			{faultThrottle, p.ThrottleRate},
+			{faultError, p.ErrorRate},
+		} {
+			if draw < fault.rate {
+				decision.fault = fault.name
+				break
+			}
+			draw -= fault.rate
+		}
+		if decision.fault != "" {
+			f.injected[i]++
+			f.counts[decision.fault]++
+			decision.retryAfter = p.RetryAfterSeconds
+			decision.status = p.ErrorStatus
+			if decision.status == 0 {
+				decision.status = http.StatusInternalServerError
+			}
+		}
+		return decision
+	}
+	return decision
+}
+
+// inject writes the fault to the response and returns whether the request is done.
+func (d faultDecision) inject(w http.ResponseWriter, r *http.Request) bool {
+	switch d.fault {
+	case faultDrop:
+		log.Printf("Dropping connection for %s", r.URL.Path)
+		panic(http.ErrAbortHandler)
+	case faultPartialRead:
+		log.Printf("Closing connection for %s after a partial read", r.URL.Path)
+		n := r.ContentLength / 2
+		if n <= 0 {
+			n = 512
+		}
+		io.CopyN(io.Discard, r.Body, n)
+		panic(http.ErrAbortHandler)
+	case faultThrottle:
+		log.Printf("Throttling %s", r.URL.Path)
+		d.writeError(w, r, true)
+		return true
+	case faultError:
+		log.Printf("Failing %s with %d", r.URL.Path, d.status)
+		d.writeError(w, r, false)
+		return true
+	}
+	return false
+}
+
+// writeError responds the way the service the request was meant for would, so the agent exercises the
+// same retry path as against the real endpoint.
+func (d faultDecision) writeError(w http.ResponseWriter, r *http.Request, throttle bool) {
+	if d.retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(d.retryAfter))
+	}
+	status := d.status
+	code, message := "InternalFailure", "The request processing has failed because of an unknown error."
+	if status == http.StatusServiceUnavailable {
+		code, message = "ServiceUnavailable", "The service is unavailable."
+	}
+	switch {
+	case r.Header.Get(amzTargetHeader) != "":
+		if throttle {
+			status, code, message = http.StatusBadRequest, "ThrottlingException", "Rate exceeded"
+		}
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		w.Header().Set("X-Amzn-ErrorType", code)
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"__type": code, "message": message})
+	case strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded"):
+		errorType := "Receiver"
+		if throttle {
+			status, code, message, errorType = http.StatusBadRequest, "Throttling", "Rate exceeded", "Sender"
+		}
+		w.Header().Set("Content-Type", "text/xml")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, "<ErrorResponse><Error><Type>%s</Type><Code>%s</Code><Message>%s</Message></Error><RequestId>mockserver</RequestId></ErrorResponse>", errorType, code, message)
+	default:
+		// OTLP/HTTP clients retry on 429, 502, 503 and 504 and honour Retry-After.
+		if throttle {
+			status = http.StatusTooManyRequests
+		}
+		w.WriteHeader(status)
+		io.WriteString(w, http.StatusText(status))
+	}
+}
+
+type faultStatus struct {
+	FaultConfig
+	Injected map[string]int `json:"injected"`
+}
+
+// handleFaults is the admin endpoint: GET returns the active configuration and the number of injected
+// faults by kind, PUT or POST replaces the configuration and DELETE turns fault injection off.
+func (ts *transactionHttpServer) handleFaults(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		ts.faults.mu.Lock()
+		status := faultStatus{FaultConfig: ts.faults.config, Injected: map[string]int{}}
+		for k, v := range ts.faults.counts {
+			status.Injected[k] = v
+		}
+		ts.faults.mu.Unlock()
+		writeJSON(w, status)
+	case http.MethodPut, http.MethodPost:
+		var config FaultConfig
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&config); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, fmt.Sprintf("invalid fault config: %v", err))
+			return
+		}
+		if err := config.validate(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, err.Error())
+			return
+		}
+		ts.faults.set(config)
+		log.Printf("Fault injection configured with %d profile(s)", len(config.Profiles))
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		ts.faults.set(FaultConfig{})
+		log.Println("Fault injection turned off")
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
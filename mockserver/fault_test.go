@@ -0,0 +1,157 @@
+// Copyright 2023 Amazon.com, Inc. or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func configureFaults(t *testing.T, ts *transactionHttpServer, config string) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	ts.handleFaults(rec, httptest.NewRequest(http.MethodPut, "/admin/faults", strings.NewReader(config)))
+	return rec.Code
+}
+
+func post(ts *transactionHttpServer, path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader([]byte("{}")))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	ts.recordTransaction(rec, req)
+	return rec
+}
+
+func TestThrottlingMatchesProtocol(t *testing.T) {
+	ts := newTestServer()
+	if code := configureFaults(t, ts, `{"profiles":[{"throttle_rate":1,"retry_after_seconds":3,"latency":{"distribution":"fixed"}}]}`); code != http.StatusOK {
+		t.Fatalf("unexpected status %d", code)
+	}
+
+	rec := post(ts, "/put-data", http.Header{"X-Amz-Target": {"Logs_20140328.PutLogEvents"}})
+	if rec.Code != http.StatusBadRequest || rec.Header().Get("X-Amzn-ErrorType") != "ThrottlingException" {
+		t.Errorf("unexpected JSON protocol response %d %v", rec.Code, rec.Header())
+	}
+	rec = post(ts, "/put-data", http.Header{"Content-Type": {"application/x-www-form-urlencoded"}})
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "<Code>Throttling</Code>") {
+		t.Errorf("unexpected query protocol response %d %s", rec.Code, rec.Body.String())
+	}
+	rec = post(ts, "/metric/v1", nil)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "3" {
+		t.Errorf("unexpected OTLP response %d %v", rec.Code, rec.Header())
+	}
+
+	// Throttled payloads are not accepted so they must not be stored.
+	if logs := ts.store.findLogEvents("", ""); len(logs) != 0 {
+		t.Errorf("expected no stored events, got %+v", logs)
+	}
+	rec = httptest.NewRecorder()
+	ts.handleFaults(rec, httptest.NewRequest(http.MethodGet, "/admin/faults", nil))
+	var status faultStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Injected[faultThrottle] != 3 {
+		t.Errorf("expected 3 throttles, got %v", status.Injected)
+	}
+	if ts.transactions != 0 {
+		t.Errorf("expected throttled requests not to count as transactions, got %d", ts.transactions)
+	}
+}
+
+func TestMaxFaultsAndRoutes(t *testing.T) {
+	ts := newTestServer()
+	configureFaults(t, ts, `{"profiles":[{"route":"/trace","error_rate":1,"error_status":503,"max_faults":2,"latency":{"distribution":"fixed"}}]}`)
+
+	var codes []int
+	for i := 0; i < 3; i++ {
+		codes = append(codes, post(ts, "/trace/v1", nil).Code)
+	}
+	if codes[0] != http.StatusServiceUnavailable || codes[1] != http.StatusServiceUnavailable || codes[2] != http.StatusOK {
+		t.Errorf("unexpected codes %v", codes)
+	}
+	if code := post(ts, "/metric/v1", nil).Code; code != http.StatusOK {
+		t.Errorf("expected other routes to be unaffected, got %d", code)
+	}
+	if ts.transactions != 2 {
+		t.Errorf("expected only the 2 accepted requests to count as transactions, got %d", ts.transactions)
+	}
+
+	rec := httptest.NewRecorder()
+	ts.handleFaults(rec, httptest.NewRequest(http.MethodDelete, "/admin/faults", nil))
+	if decision := ts.faults.decide("/trace/v1"); decision.fault != "" || decision.latency != defaultLatency {
+		t.Errorf("expected faults to be off, got %+v", decision)
+	}
+}
+
+func TestSeededDecisionsAreReproducible(t *testing.T) {
+	config := FaultConfig{Seed: 42, Profiles: []FaultProfile{{
+		ThrottleRate: 0.3,
+		ErrorRate:    0.3,
+		Latency:      &Latency{Distribution: "uniform", MinMs: 10, MaxMs: 20},
+	}}}
+	run := func() []faultDecision {
+		f := newFaultInjector()
+		f.set(config)
+		var decisions []faultDecision
+		for i := 0; i < 50; i++ {
+			decisions = append(decisions, f.decide("/put-data"))
+		}
+		return decisions
+	}
+	first, second := run(), run()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("decision %d differs: %+v != %+v", i, first[i], second[i])
+		}
+		if first[i].latency < 10*time.Millisecond || first[i].latency > 20*time.Millisecond {
+			t.Fatalf("latency %s is outside the distribution", first[i].latency)
+		}
+	}
+}
+
+func TestDroppedConnection(t *testing.T) {
+	ts := newTestServer()
+	configureFaults(t, ts, `{"profiles":[{"drop_rate":1,"latency":{"distribution":"fixed"}}]}`)
+	server := httptest.NewServer(http.HandlerFunc(ts.recordTransaction))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/put-data", "application/json", strings.NewReader("{}"))
+	if err == nil {
+		resp.Body.Close()
+		t.Fatalf("expected the connection to be dropped, got %d", resp.StatusCode)
+	}
+}
+
+func TestInvalidFaultConfig(t *testing.T) {
+	ts := newTestServer()
+	for _, config := range []string{
+		`{"profiles":[{"error_rate":0.6,"throttle_rate":0.6}]}`,
+		`{"profiles":[{"error_rate":1,"error_status":200}]}`,
+		`{"profiles":[{"latency":{"distribution":"pareto"}}]}`,
+		`{"profiles":[{"unknown":1}]}`,
+	} {
+		if code := configureFaults(t, ts, config); code != http.StatusBadRequest {
+			t.Errorf("expected %s to be rejected, got %d", config, code)
+		}
+	}
+}
@@ -2,4 +2,8 @@ module mockserver
 
 go 1.20
 
-require github.com/gorilla/mux v1.8.0
\ No newline at end of file
+require (
+	github.com/gorilla/mux v1.8.0
+	go.opentelemetry.io/proto/otlp v1.0.0
+	google.golang.org/protobuf v1.31.0
+)
@@ -0,0 +1,233 @@
+// Copyright 2023 Amazon.com, Inc. or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const ignoredValue = "<ignored>"
+
+// defaultIgnoredFields are payload fields that change on every run. They are matched case-insensitively
+// at any depth, including inside JSON log messages such as EMF.
+var defaultIgnoredFields = []string{"timestamp", "start_time", "end_time", "trace_id", "span_id", "parent_span_id", "request_id"}
+
+// defaultIgnoredHeaders are request headers that change on every run.
+var defaultIgnoredHeaders = []string{"Amz-Sdk-Invocation-Id", "Amz-Sdk-Request", "Content-Length", "User-Agent", "X-Amz-Date", "X-Amzn-Trace-Id"}
+
+type diffOptions struct {
+	ignoredFields  map[string]struct{}
+	ignoredHeaders map[string]struct{}
+	ordered        bool
+}
+
+// runDiff implements the diff subcommand and returns the exit code: 0 if the recording matches the
+// golden file, 1 if it does not and 2 on usage or read errors.
+func runDiff(args []string, out io.Writer) int {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	flags.SetOutput(out)
+	flags.Usage = func() {
+		fmt.Fprintln(out, "Usage: mockserver diff [flags] <golden.jsonl> <recording.jsonl>")
+		flags.PrintDefaults()
+	}
+	var (
+		ignore        = flags.String("ignore", "", "Comma separated payload fields to ignore on top of the defaults")
+		ignoreHeaders = flags.String("ignore-headers", "", "Comma separated headers to ignore on top of the defaults")
+		ordered       = flags.Bool("ordered", false, "Require the requests to be in the same order. By default the order is ignored since the agent sends concurrently")
+		update        = flags.Bool("update", false, "Overwrite the golden file with the recording instead of comparing")
+	)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return 2
+	}
+	goldenPath, recordingPath := flags.Arg(0), flags.Arg(1)
+
+	if *update {
+		content, err := os.ReadFile(recordingPath)
+		if err == nil {
+			err = os.WriteFile(goldenPath, content, 0644)
+		}
+		if err != nil {
+			fmt.Fprintf(out, "Unable to update %s: %v\n", goldenPath, err)
+			return 2
+		}
+		fmt.Fprintf(out, "Updated %s\n", goldenPath)
+		return 0
+	}
+
+	options := diffOptions{
+		ignoredFields:  toSet(append(defaultIgnoredFields, splitList(*ignore)...), strings.ToLower),
+		ignoredHeaders: toSet(append(defaultIgnoredHeaders, splitList(*ignoreHeaders)...), strings.ToLower),
+		ordered:        *ordered,
+	}
+	golden, err := readRecordings(goldenPath)
+	if err != nil {
+		fmt.Fprintf(out, "Unable to read %s: %v\n", goldenPath, err)
+		return 2
+	}
+	recordings, err := readRecordings(recordingPath)
+	if err != nil {
+		fmt.Fprintf(out, "Unable to read %s: %v\n", recordingPath, err)
+		return 2
+	}
+	differences := diffRecordings(golden, recordings, options)
+	for _, difference := range differences {
+		fmt.Fprintln(out, difference)
+	}
+	if len(differences) > 0 {
+		fmt.Fprintf(out, "%s does not match %s: %d difference(s)\n", recordingPath, goldenPath, len(differences))
+		return 1
+	}
+	fmt.Fprintf(out, "%s matches %s\n", recordingPath, goldenPath)
+	return 0
+}
+
+// diffRecordings returns the normalized requests that are only in the golden recording ("- ") or only in
+// the new one ("+ "). When the order matters, requests are compared position by position.
+func diffRecordings(golden, recordings []Recording, options diffOptions) []string {
+	expected := normalizeRecordings(golden, options)
+	actual := normalizeRecordings(recordings, options)
+	var differences []string
+	if options.ordered {
+		for i := 0; i < len(expected) || i < len(actual); i++ {
+			switch {
+			case i >= len(actual):
+				differences = append(differences, fmt.Sprintf("- [%d] %s", i, expected[i]))
+			case i >= len(expected):
+				differences = append(differences, fmt.Sprintf("+ [%d] %s", i, actual[i]))
+			case expected[i] != actual[i]:
+				differences = append(differences, fmt.Sprintf("- [%d] %s", i, expected[i]), fmt.Sprintf("+ [%d] %s", i, actual[i]))
+			}
+		}
+		return differences
+	}
+	remaining := map[string]int{}
+	for _, e := range expected {
+		remaining[e]++
+	}
+	var added []string
+	for _, a := range actual {
+		if remaining[a] > 0 {
+			remaining[a]--
+		} else {
+			added = append(added, a)
+		}
+	}
+	for _, e := range expected {
+		if remaining[e] > 0 {
+			remaining[e]--
+			differences = append(differences, "- "+e)
+		}
+	}
+	for _, a := range added {
+		differences = append(differences, "+ "+a)
+	}
+	return differences
+}
+
+func normalizeRecordings(recordings []Recording, options diffOptions) []string {
+	result := make([]string, 0, len(recordings))
+	for _, recording := range recordings {
+		result = append(result, normalizeRecording(recording, options))
+	}
+	return result
+}
+
+// normalizeRecording returns the recording as canonical JSON without the volatile fields.
+func normalizeRecording(recording Recording, options diffOptions) string {
+	headers := map[string]string{}
+	for name, value := range recording.Headers {
+		if _, ok := options.ignoredHeaders[strings.ToLower(name)]; !ok {
+			headers[name] = value
+		}
+	}
+	recording.Headers = headers
+	content, err := json.Marshal(recording)
+	if err != nil {
+		return err.Error()
+	}
+	var document map[string]interface{}
+	if err = json.Unmarshal(content, &document); err != nil {
+		return err.Error()
+	}
+	delete(document, "timestamp")
+	for key, value := range document {
+		if key != "headers" {
+			document[key] = normalizeValue(value, options)
+		}
+	}
+	content, err = json.Marshal(document)
+	if err != nil {
+		return err.Error()
+	}
+	return string(content)
+}
+
+func normalizeValue(value interface{}, options diffOptions) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if _, ok := options.ignoredFields[strings.ToLower(key)]; ok {
+				v[key] = ignoredValue
+			} else {
+				v[key] = normalizeValue(field, options)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, element := range v {
+			v[i] = normalizeValue(element, options)
+		}
+		return v
+	case string:
+		// Structured log messages (e.g EMF) are compared field by field so their timestamps are ignored.
+		var embedded map[string]interface{}
+		if strings.HasPrefix(strings.TrimSpace(v), "{") && json.Unmarshal([]byte(v), &embedded) == nil {
+			content, err := json.Marshal(normalizeValue(embedded, options))
+			if err == nil {
+				return string(content)
+			}
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func splitList(list string) []string {
+	var result []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func toSet(items []string, normalize func(string) string) map[string]struct{} {
+	set := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		set[normalize(item)] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,128 @@
+// Copyright 2023 Amazon.com, Inc. or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// recordRun sends an EMF log and a metric through a recording router and returns the recording file.
+func recordRun(t *testing.T, dimensions string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "recording.jsonl")
+	rec, err := newRecorder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rec.file.Close()
+	ts := newTestServer()
+	router := mux.NewRouter()
+	router.Use(rec.middleware)
+	router.PathPrefix("/put-data").HandlerFunc(ts.recordTransaction)
+
+	now := time.Now().UnixMilli()
+	emf := fmt.Sprintf(`{"_aws":{"Timestamp":%d,"CloudWatchMetrics":[{"Namespace":"EMF","Dimensions":[[%s]],"Metrics":[{"Name":"latency"}]}]},"service":"a","host":"b","latency":1}`, now, dimensions)
+	logs := fmt.Sprintf(`{"logGroupName":"group","logStreamName":"stream","logEvents":[{"timestamp":%d,"message":%q}]}`, now, emf)
+	metrics := fmt.Sprintf(`{"Namespace":"CWAgent","MetricData":[{"MetricName":"cpu","Timestamp":%d,"Value":1}]}`, now/1000)
+	for target, body := range map[string]string{"Logs_20140328.PutLogEvents": logs, "GraniteServiceVersion20100801.PutMetricData": metrics} {
+		req := httptest.NewRequest(http.MethodPost, "/put-data", strings.NewReader(body))
+		req.Header.Set(amzTargetHeader, target)
+		req.Header.Set("X-Amz-Date", time.Now().Format(time.RFC3339Nano))
+		req.Header.Set("Authorization", "secret")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("unexpected status %d", recorder.Code)
+		}
+	}
+	return path
+}
+
+func TestRecordingMatchesGolden(t *testing.T) {
+	golden := recordRun(t, `"service","host"`)
+	recording := recordRun(t, `"service","host"`)
+
+	recordings, err := readRecordings(recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recordings) != 2 {
+		t.Fatalf("expected 2 recordings, got %d", len(recordings))
+	}
+	for _, r := range recordings {
+		if _, ok := r.Headers["Authorization"]; ok {
+			t.Errorf("expected the Authorization header to be redacted")
+		}
+		if r.Payload.Kind == "" {
+			t.Errorf("expected the payload to be decoded, got %+v", r)
+		}
+	}
+
+	var out bytes.Buffer
+	if code := runDiff([]string{golden, recording}, &out); code != 0 {
+		t.Fatalf("expected the recordings to match, got %d: %s", code, out.String())
+	}
+}
+
+func TestRecordingDetectsDimensionOrder(t *testing.T) {
+	golden := recordRun(t, `"service","host"`)
+	recording := recordRun(t, `"host","service"`)
+
+	var out bytes.Buffer
+	if code := runDiff([]string{golden, recording}, &out); code != 1 {
+		t.Fatalf("expected a difference, got %d: %s", code, out.String())
+	}
+	if !strings.Contains(out.String(), "2 difference(s)") {
+		t.Errorf("unexpected output %s", out.String())
+	}
+
+	out.Reset()
+	if code := runDiff([]string{"-ignore", "dimensions", golden, recording}, &out); code != 0 {
+		t.Fatalf("expected ignored fields to match, got %d: %s", code, out.String())
+	}
+
+	out.Reset()
+	if code := runDiff([]string{"-update", golden, recording}, &out); code != 0 {
+		t.Fatalf("unexpected update failure %s", out.String())
+	}
+	if code := runDiff([]string{golden, recording}, &out); code != 0 {
+		t.Fatalf("expected the updated golden file to match, got %d: %s", code, out.String())
+	}
+}
+
+func TestDiffOrdering(t *testing.T) {
+	a := Recording{Path: "/a"}
+	b := Recording{Path: "/b"}
+	options := diffOptions{ignoredFields: toSet(defaultIgnoredFields, strings.ToLower), ignoredHeaders: map[string]struct{}{}}
+	if differences := diffRecordings([]Recording{a, b}, []Recording{b, a}, options); len(differences) != 0 {
+		t.Errorf("expected the order to be ignored, got %v", differences)
+	}
+	options.ordered = true
+	if differences := diffRecordings([]Recording{a, b}, []Recording{b, a}, options); len(differences) != 4 {
+		t.Errorf("expected the order to matter, got %v", differences)
+	}
+	if differences := diffRecordings([]Recording{a}, []Recording{a, b}, options); len(differences) != 1 || !strings.HasPrefix(differences[0], "+ [1]") {
+		t.Errorf("unexpected differences %v", differences)
+	}
+}
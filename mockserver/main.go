@@ -17,9 +17,11 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"flag"
 	"io"
 	"log"
 	"net/http"
+	"os"
 	"path"
 	"sync"
 	"sync/atomic"
@@ -41,6 +43,8 @@ var (
 type transactionHttpServer struct {
 	transactions uint32
 	startTime    time.Time
+	store        *payloadStore
+	faults       *faultInjector
 }
 
 type TransactionPayload struct {
@@ -72,12 +76,31 @@ func (ts *transactionHttpServer) checkTransactionCount(w http.ResponseWriter, _
 	w.WriteHeader(http.StatusOK)
 }
 
-func (ts *transactionHttpServer) recordTransaction(w http.ResponseWriter, _ *http.Request) {
-	atomic.AddUint32(&ts.transactions, 1)
+func (ts *transactionHttpServer) recordTransaction(w http.ResponseWriter, r *http.Request) {
+	decision := ts.faults.decide(r.URL.Path)
 
-	// Built-in latency
+	// Built-in latency, replaced by the latency of the matching fault profile
 	log.Printf("\033[31m Time: %s | transaction received \033[0m \n", time.Now().String())
-	time.Sleep(15 * time.Millisecond)
+	time.Sleep(decision.latency)
+	if decision.inject(w, r) {
+		return
+	}
+	// Only accepted requests are transactions, the injected faults are counted by /admin/faults
+	atomic.AddUint32(&ts.transactions, 1)
+
+	if !ts.store.capturing() {
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	// Payloads that fail to decode are still acknowledged so a decoding gap never fails the agent.
+	body, err := readBody(r)
+	if err == nil {
+		err = ts.store.decodePayload(r, body)
+	}
+	if err != nil {
+		log.Printf("Unable to decode payload for %s: %v", r.URL.Path, err)
+	}
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -96,13 +119,24 @@ func (ts *transactionHttpServer) GetNumberOfTransactionsPerMinute(w http.Respons
 	}
 }
 
-// Starts an HTTP server that receives request from validator only to verify the data ingestion
-func StartHttpServer() {
+// Starts an HTTP server that receives request from validator only to verify the data ingestion,
+// keeping the latest maxStored decoded entries of each kind, and, if recordPath is set, records every
+// request it receives to that file
+func StartHttpServer(recordPath string, maxStored int) {
 	var wg sync.WaitGroup
 	log.Println("\033[31m Starting Server \033[0m")
-	store := transactionHttpServer{startTime: time.Now()}
+	store := transactionHttpServer{startTime: time.Now(), store: newPayloadStore(maxStored), faults: newFaultInjector()}
 	//2 servers one for receiving the data , one for verify data
 	dataApp := mux.NewRouter()
+	var rec *recorder
+	if recordPath != "" {
+		var err error
+		if rec, err = newRecorder(recordPath); err != nil {
+			log.Fatalf("Unable to create recording %s: %v", recordPath, err)
+		}
+		log.Printf("Recording requests to %s", recordPath)
+		dataApp.Use(rec.middleware)
+	}
 	dataReceiverServer := &http.Server{Addr: ":443", Handler: dataApp}
 	verificationRequestServer := http.NewServeMux()
 	appServer := &http.Server{Addr: ":8080", Handler: verificationRequestServer}
@@ -125,6 +159,15 @@ func StartHttpServer() {
 		verificationRequestServer.HandleFunc("/ping", healthCheck)
 		verificationRequestServer.HandleFunc("/check-data", ts.checkTransactionCount)
 		verificationRequestServer.HandleFunc("/tpm", ts.GetNumberOfTransactionsPerMinute)
+		verificationRequestServer.HandleFunc("/metrics", ts.listMetrics)
+		verificationRequestServer.HandleFunc("/logs", ts.listLogEvents)
+		verificationRequestServer.HandleFunc("/otlp/metrics", ts.listOtlpMetrics)
+		verificationRequestServer.HandleFunc("/traces", ts.listSpans)
+		verificationRequestServer.HandleFunc("/reset", ts.reset)
+		verificationRequestServer.HandleFunc("/admin/faults", ts.handleFaults)
+		if rec != nil {
+			verificationRequestServer.HandleFunc("/recording", rec.serveRecording)
+		}
 		if err := appServer.ListenAndServe(); err != nil {
 			log.Printf("Verification server error: %v", err)
 			err := appServer.Shutdown(context.TODO())
@@ -136,5 +179,11 @@ func StartHttpServer() {
 }
 
 func main() {
-	StartHttpServer()
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		os.Exit(runDiff(os.Args[2:], os.Stdout))
+	}
+	recordPath := flag.String("record", "", "Record every request received to this file, one JSON document per line")
+	maxStored := flag.Int("max-stored", DefaultMaxStored, "Number of the latest decoded metrics, log events, OTLP metrics and spans each kept for the verification API, 0 to not decode the payloads")
+	flag.Parse()
+	StartHttpServer(*recordPath, *maxStored)
 }
@@ -0,0 +1,72 @@
+// Copyright 2023 Amazon.com, Inc. or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// listMetrics returns the stored PutMetricData datums. Supported query parameters are namespace, name and
+// dimension (repeatable, Name:Value or just Name to only require the dimension to be present).
+func (ts *transactionHttpServer) listMetrics(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var dimensions []Dimension
+	for _, dimension := range query["dimension"] {
+		name, value, _ := strings.Cut(dimension, ":")
+		dimensions = append(dimensions, Dimension{Name: name, Value: value})
+	}
+	writeJSON(w, ts.store.findMetrics(query.Get("namespace"), query.Get("name"), dimensions))
+}
+
+// listLogEvents returns the stored log events filtered by the group and stream query parameters.
+func (ts *transactionHttpServer) listLogEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	writeJSON(w, ts.store.findLogEvents(query.Get("group"), query.Get("stream")))
+}
+
+// listOtlpMetrics returns the stored OTLP metrics filtered by the name query parameter.
+func (ts *transactionHttpServer) listOtlpMetrics(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, ts.store.findOtlpMetrics(r.URL.Query().Get("name")))
+}
+
+// listSpans returns the stored OTLP spans filtered by the trace_id query parameter.
+func (ts *transactionHttpServer) listSpans(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, ts.store.findSpans(r.URL.Query().Get("trace_id")))
+}
+
+// reset drops every stored payload so a test can start from a clean state.
+func (ts *transactionHttpServer) reset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		io.WriteString(w, fmt.Sprintf("%s is not allowed, use POST", r.Method))
+		return
+	}
+	ts.store.reset()
+	w.WriteHeader(http.StatusOK)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		io.WriteString(w, err.Error())
+		log.Printf("Unable to write response: %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2023 Amazon.com, Inc. or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redactedHeaders are never written to a recording since they carry credentials.
+var redactedHeaders = map[string]struct{}{
+	"Authorization":        {},
+	"X-Amz-Security-Token": {},
+}
+
+// Recording is a single request as written to the recording file, one JSON document per line.
+type Recording struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Method    string            `json:"method"`
+	Path      string            `json:"path"`
+	Headers   map[string]string `json:"headers"`
+	Payload   Payload           `json:"payload"`
+	// Body is the raw body of requests that could not be decoded.
+	Body string `json:"body,omitempty"`
+}
+
+// recorder appends every request received by the data router to a file.
+type recorder struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+func newRecorder(path string) (*recorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &recorder{path: path, file: file}, nil
+}
+
+// middleware records the request before handing it to the next handler with the body intact. It is
+// registered on the data router so every route is recorded, including requests that get a fault
+// injected.
+func (rec *recorder) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ping" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		raw, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(raw))
+		if err != nil {
+			log.Printf("Unable to read body for the recording: %v", err)
+		} else {
+			rec.record(newRecording(r, raw))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func newRecording(r *http.Request, raw []byte) Recording {
+	recording := Recording{
+		Timestamp: time.Now().UTC(),
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		Headers:   map[string]string{},
+	}
+	for name, values := range r.Header {
+		if _, ok := redactedHeaders[name]; !ok {
+			recording.Headers[name] = strings.Join(values, ",")
+		}
+	}
+	body, err := decompress(raw, r.Header.Get("Content-Encoding"))
+	if err == nil {
+		recording.Payload, err = decodeRequest(r, body)
+	}
+	if err != nil || recording.Payload.Kind == "" {
+		recording.Body = string(raw)
+	}
+	return recording
+}
+
+func (rec *recorder) record(recording Recording) {
+	line, err := json.Marshal(recording)
+	if err != nil {
+		log.Printf("Unable to encode recording: %v", err)
+		return
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if _, err = rec.file.Write(append(line, '\n')); err != nil {
+		log.Printf("Unable to write recording: %v", err)
+	}
+}
+
+// serveRecording returns the recording file so it can be fetched from the container.
+func (rec *recorder) serveRecording(w http.ResponseWriter, r *http.Request) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	http.ServeFile(w, r, rec.path)
+}
+
+// readRecordings reads a recording file.
+func readRecordings(path string) ([]Recording, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var recordings []Recording
+	decoder := json.NewDecoder(file)
+	for {
+		var recording Recording
+		if err := decoder.Decode(&recording); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		recordings = append(recordings, recording)
+	}
+	return recordings, nil
+}
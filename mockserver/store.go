@@ -0,0 +1,237 @@
+// Copyright 2023 Amazon.com, Inc. or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+type Dimension struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type StatisticSet struct {
+	SampleCount float64 `json:"sample_count"`
+	Sum         float64 `json:"sum"`
+	Minimum     float64 `json:"minimum"`
+	Maximum     float64 `json:"maximum"`
+}
+
+// MetricDatum is a single datum of a PutMetricData call as the agent sent it.
+type MetricDatum struct {
+	Protocol          string        `json:"protocol"`
+	Namespace         string        `json:"namespace"`
+	MetricName        string        `json:"metric_name"`
+	Dimensions        []Dimension   `json:"dimensions"`
+	Timestamp         time.Time     `json:"timestamp"`
+	Unit              string        `json:"unit,omitempty"`
+	StorageResolution int           `json:"storage_resolution,omitempty"`
+	Value             *float64      `json:"value,omitempty"`
+	Values            []float64     `json:"values,omitempty"`
+	Counts            []float64     `json:"counts,omitempty"`
+	StatisticValues   *StatisticSet `json:"statistic_values,omitempty"`
+}
+
+type LogEvent struct {
+	LogGroup  string `json:"log_group"`
+	LogStream string `json:"log_stream"`
+	Timestamp int64  `json:"timestamp"`
+	Message   string `json:"message"`
+}
+
+type OtlpDataPoint struct {
+	Attributes map[string]string `json:"attributes"`
+	Timestamp  time.Time         `json:"timestamp"`
+	Value      *float64          `json:"value,omitempty"`
+	Count      uint64            `json:"count,omitempty"`
+	Sum        *float64          `json:"sum,omitempty"`
+}
+
+type OtlpMetric struct {
+	Resource   map[string]string `json:"resource"`
+	Scope      string            `json:"scope"`
+	Name       string            `json:"name"`
+	Type       string            `json:"type"`
+	Unit       string            `json:"unit,omitempty"`
+	DataPoints []OtlpDataPoint   `json:"data_points"`
+}
+
+type Span struct {
+	Resource     map[string]string `json:"resource"`
+	TraceID      string            `json:"trace_id"`
+	SpanID       string            `json:"span_id"`
+	ParentSpanID string            `json:"parent_span_id,omitempty"`
+	Name         string            `json:"name"`
+	Kind         string            `json:"kind"`
+	Attributes   map[string]string `json:"attributes"`
+	StartTime    time.Time         `json:"start_time"`
+	EndTime      time.Time         `json:"end_time"`
+}
+
+// DefaultMaxStored is the number of entries of each kind the payload store keeps by default.
+const DefaultMaxStored = 100000
+
+// capped keeps the latest max items it is given, in the order they were added, overwriting the oldest
+// ones once full.
+type capped[T any] struct {
+	max   int
+	items []T
+	// next is the index of the oldest item once the buffer is full
+	next int
+}
+
+func (c *capped[T]) add(items ...T) {
+	for _, item := range items {
+		if len(c.items) < c.max {
+			c.items = append(c.items, item)
+			continue
+		}
+		c.items[c.next] = item
+		c.next = (c.next + 1) % c.max
+	}
+}
+
+// each calls f on the items from the oldest to the latest.
+func (c *capped[T]) each(f func(T)) {
+	for i := range c.items {
+		f(c.items[(c.next+i)%len(c.items)])
+	}
+}
+
+func (c *capped[T]) reset() {
+	c.items, c.next = nil, 0
+}
+
+// payloadStore keeps the latest decoded payloads in memory so the verification API can return exactly what
+// the agent sent. It keeps at most maxStored entries of each kind, so a long running load only holds the
+// latest ones, and none with a maxStored of 0.
+type payloadStore struct {
+	mu          sync.RWMutex
+	maxStored   int
+	metrics     capped[MetricDatum]
+	logEvents   capped[LogEvent]
+	otlpMetrics capped[OtlpMetric]
+	spans       capped[Span]
+}
+
+func newPayloadStore(maxStored int) *payloadStore {
+	return &payloadStore{
+		maxStored:   maxStored,
+		metrics:     capped[MetricDatum]{max: maxStored},
+		logEvents:   capped[LogEvent]{max: maxStored},
+		otlpMetrics: capped[OtlpMetric]{max: maxStored},
+		spans:       capped[Span]{max: maxStored},
+	}
+}
+
+// capturing returns whether the store keeps any payload, the payloads need not be decoded otherwise.
+func (s *payloadStore) capturing() bool {
+	return s.maxStored > 0
+}
+
+func (s *payloadStore) add(payload Payload) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metrics.add(payload.Metrics...)
+	s.logEvents.add(payload.LogEvents...)
+	s.otlpMetrics.add(payload.OtlpMetrics...)
+	s.spans.add(payload.Spans...)
+}
+
+// findMetrics returns the data matching the namespace and metric name (empty matches all) that have
+// every one of the dimensions. A dimension with an empty value only needs to be present.
+func (s *payloadStore) findMetrics(namespace, metricName string, dimensions []Dimension) []MetricDatum {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]MetricDatum, 0)
+	s.metrics.each(func(datum MetricDatum) {
+		if namespace != "" && datum.Namespace != namespace {
+			return
+		}
+		if metricName != "" && datum.MetricName != metricName {
+			return
+		}
+		if hasDimensions(datum.Dimensions, dimensions) {
+			result = append(result, datum)
+		}
+	})
+	return result
+}
+
+func hasDimensions(actual, expected []Dimension) bool {
+	for _, e := range expected {
+		found := false
+		for _, a := range actual {
+			if a.Name == e.Name && (e.Value == "" || a.Value == e.Value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *payloadStore) findLogEvents(logGroup, logStream string) []LogEvent {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]LogEvent, 0)
+	s.logEvents.each(func(event LogEvent) {
+		if logGroup != "" && event.LogGroup != logGroup {
+			return
+		}
+		if logStream == "" || event.LogStream == logStream {
+			result = append(result, event)
+		}
+	})
+	return result
+}
+
+func (s *payloadStore) findOtlpMetrics(name string) []OtlpMetric {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]OtlpMetric, 0)
+	s.otlpMetrics.each(func(metric OtlpMetric) {
+		if name == "" || metric.Name == name {
+			result = append(result, metric)
+		}
+	})
+	return result
+}
+
+func (s *payloadStore) findSpans(traceID string) []Span {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]Span, 0)
+	s.spans.each(func(span Span) {
+		if traceID == "" || span.TraceID == traceID {
+			result = append(result, span)
+		}
+	})
+	return result
+}
+
+func (s *payloadStore) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metrics.reset()
+	s.logEvents.reset()
+	s.otlpMetrics.reset()
+	s.spans.reset()
+}
@@ -138,7 +138,7 @@ resource "null_resource" "validator_linux" {
       #mock server dependencies getting transfered.
       "git clone --branch ${var.github_test_repo_branch} ${var.github_test_repo}",
       var.run_mock_server ? "cd mockserver && sudo docker build -t mockserver . && cd .." : "echo skipping mock server build",
-      var.run_mock_server ? "sudo docker run --name mockserver -d -p 8080:8080 -p 443:443  mockserver" : "echo skipping mock server run",
+      var.run_mock_server ? "sudo docker run --name mockserver -d -p 8080:8080 -p 443:443  mockserver -max-stored 0" : "echo skipping mock server run",
       "cp -r amazon-cloudwatch-agent-test/test/xray/resources /home/ec2-user/",
       "export AWS_REGION=${var.region}",
       "cd ./validator/validators",
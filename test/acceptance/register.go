@@ -0,0 +1,16 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package acceptance
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/registry"
+)
+
+func init() {
+	registry.RegisterTest(registry.Test{
+		Name:        "acceptance",
+		Description: "Validate the permissions of the files installed by the agent",
+		Validate:    Validate,
+	})
+}
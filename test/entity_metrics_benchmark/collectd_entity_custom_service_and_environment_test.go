@@ -17,6 +17,7 @@ import (
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
 )
 
@@ -49,7 +50,7 @@ func (t *CollectDEntityCustomServiceAndEnvironmentRunner) GetAgentConfigFileName
 }
 
 func (t *CollectDEntityCustomServiceAndEnvironmentRunner) SetupAfterAgentRun() error {
-	return common.SendCollectDMetrics(2, time.Second, t.GetAgentRunDuration())
+	return common.SendCollectDMetrics(clock.Real(), 2, time.Second, t.GetAgentRunDuration())
 }
 
 func (t *CollectDEntityCustomServiceAndEnvironmentRunner) GetMeasuredMetrics() []string {
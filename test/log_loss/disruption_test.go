@@ -0,0 +1,70 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package log_loss
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
+)
+
+func TestPlan(t *testing.T) {
+	plan := Plan(rand.New(rand.NewSource(1)), 10*time.Minute)
+	assert.NotEmpty(t, plan)
+	var total time.Duration
+	kinds := map[string]int{}
+	for _, d := range plan {
+		assert.GreaterOrEqual(t, d.Uptime, minUptime)
+		assert.LessOrEqual(t, d.Uptime, maxUptime)
+		assert.GreaterOrEqual(t, d.Downtime, minDowntime)
+		assert.LessOrEqual(t, d.Downtime, maxDowntime)
+		kinds[d.Kind]++
+		total += d.Uptime + d.Downtime
+	}
+	assert.LessOrEqual(t, total, 10*time.Minute)
+	assert.Len(t, kinds, 2)
+	assert.Equal(t, plan, Plan(rand.New(rand.NewSource(1)), 10*time.Minute), "the same seed replays the same plan")
+	assert.Empty(t, Plan(rand.New(rand.NewSource(1)), minUptime))
+}
+
+func TestDisrupt(t *testing.T) {
+	clk := clock.NewFake(time.Unix(0, 0))
+	agent := &fakeAgent{failKill: true}
+	plan := []Disruption{
+		{Kind: DisruptionStop, Uptime: 10 * time.Second, Downtime: 2 * time.Second},
+		{Kind: DisruptionKill, Uptime: 20 * time.Second, Downtime: 5 * time.Second},
+	}
+	err := disrupt(clk, agent, plan)
+	assert.EqualError(t, err, "1 disruptions failed: kill 2: no such process")
+	assert.Equal(t, []string{"stop", "start", "kill", "start"}, agent.calls)
+	assert.Equal(t, time.Unix(37, 0), clk.Now())
+}
+
+type fakeAgent struct {
+	failKill bool
+	calls    []string
+}
+
+func (a *fakeAgent) Start() error {
+	a.calls = append(a.calls, "start")
+	return nil
+}
+
+func (a *fakeAgent) Stop() error {
+	a.calls = append(a.calls, "stop")
+	return nil
+}
+
+func (a *fakeAgent) Kill() error {
+	a.calls = append(a.calls, "kill")
+	if a.failKill {
+		return errors.New("no such process")
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package log_loss
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common/logs"
+)
+
+const (
+	linesPerSecond = 20
+	// chaosDuration is how long the agent is stopped, killed and restarted while the records are written
+	chaosDuration = 5 * time.Minute
+	minUptime     = 15 * time.Second
+	maxUptime     = 60 * time.Second
+	minDowntime   = 2 * time.Second
+	maxDowntime   = 20 * time.Second
+	// settleDuration is how long the records keep being written once the agent is left running
+	settleDuration = 30 * time.Second
+	// flushDuration is how long the agent gets to send the last records before it is stopped
+	flushDuration = 30 * time.Second
+
+	streamNamePrefix = "log_loss_stream"
+
+	DisruptionStop = "stop"
+	DisruptionKill = "kill"
+)
+
+// Disruption stops or kills the agent after it ran for Uptime, and starts it again after Downtime.
+type Disruption struct {
+	Kind     string
+	Uptime   time.Duration
+	Downtime time.Duration
+}
+
+// Plan returns random disruptions, half of them kills, until the duration is used up.
+func Plan(rng *rand.Rand, duration time.Duration) []Disruption {
+	var plan []Disruption
+	var total time.Duration
+	for {
+		d := Disruption{
+			Kind:     DisruptionStop,
+			Uptime:   between(rng, minUptime, maxUptime),
+			Downtime: between(rng, minDowntime, maxDowntime),
+		}
+		if rng.Intn(2) == 0 {
+			d.Kind = DisruptionKill
+		}
+		if total+d.Uptime+d.Downtime > duration {
+			return plan
+		}
+		total += d.Uptime + d.Downtime
+		plan = append(plan, d)
+	}
+}
+
+func between(rng *rand.Rand, min, max time.Duration) time.Duration {
+	return min + time.Duration(rng.Int63n(int64(max-min)+1))
+}
+
+type agentController interface {
+	Start() error
+	Stop() error
+	Kill() error
+}
+
+type cloudWatchAgent struct{}
+
+var _ agentController = cloudWatchAgent{}
+
+func (cloudWatchAgent) Start() error {
+	return common.StartAgent(common.ConfigOutputPath, false, false)
+}
+
+func (cloudWatchAgent) Stop() error {
+	return stopAgent()
+}
+
+func (cloudWatchAgent) Kill() error {
+	return common.KillAgent()
+}
+
+// disrupt runs the plan against the agent, always starting it again so it is running once the plan is
+// done. It returns the disruptions that failed.
+func disrupt(clk clock.Clock, agent agentController, plan []Disruption) error {
+	var failures []string
+	for i, d := range plan {
+		clk.Sleep(d.Uptime)
+		log.Printf("Disruption %d/%d: %s the agent for %v", i+1, len(plan), d.Kind, d.Downtime)
+		var err error
+		if d.Kind == DisruptionKill {
+			err = agent.Kill()
+		} else {
+			err = agent.Stop()
+		}
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s %d: %v", d.Kind, i+1, err))
+		}
+		clk.Sleep(d.Downtime)
+		if err = agent.Start(); err != nil {
+			failures = append(failures, fmt.Sprintf("start %d: %v", i+1, err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%d disruptions failed: %s", len(failures), strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// Validate writes sequenced records to several files while the agent is repeatedly stopped, killed and
+// restarted, then accounts for every record in CloudWatch Logs. Lost records fail the test, duplicated and
+// reordered records are only reported since the agent delivers at least once.
+func Validate() error {
+	if err := os.WriteFile(tmpConfigPath, []byte(testConfigJSON), 0644); err != nil {
+		return fmt.Errorf("could not create config file: %w", err)
+	}
+	instanceID := awsservice.GetInstanceId()
+	common.CopyFile(tmpConfigPath, common.ConfigOutputPath)
+	paths, err := common.GetLogFilePaths(common.ConfigOutputPath)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		os.RemoveAll(filepath.Dir(path))
+	}
+
+	agent := cloudWatchAgent{}
+	log.Print("Starting agent...")
+	if err = agent.Start(); err != nil {
+		return fmt.Errorf("could not start agent: %v", err)
+	}
+	time.Sleep(5 * time.Second)
+
+	var writers []*logs.FileWriter
+	var generators []*logs.Generator
+	for _, path := range paths {
+		writer, err := logs.NewFileWriter(logs.FileWriterConfig{Path: path})
+		if err != nil {
+			return err
+		}
+		generator, err := logs.NewGenerator(&logs.GeneratorConfig{
+			LinesPerSecond:  linesPerSecond,
+			LineLength:      100,
+			TimestampFormat: "2006-01-02T15:04:05.000",
+		}, writer)
+		if err != nil {
+			return err
+		}
+		writers = append(writers, writer)
+		generators = append(generators, generator)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var wg sync.WaitGroup
+	startTime := time.Now()
+	for _, generator := range generators {
+		wg.Add(1)
+		go generator.Generate(ctx, &wg)
+	}
+
+	seed := time.Now().UnixNano()
+	plan := Plan(rand.New(rand.NewSource(seed)), chaosDuration)
+	log.Printf("Disrupting the agent %d times over %v (seed %d)", len(plan), chaosDuration, seed)
+	disruptErr := disrupt(clock.Real(), agent, plan)
+	time.Sleep(settleDuration)
+
+	log.Print("Stopping the generators")
+	cancel()
+	wg.Wait()
+	for _, writer := range writers {
+		writer.Close()
+	}
+	time.Sleep(flushDuration)
+	log.Print("Shutting down agent")
+	agent.Stop()
+	endTime := time.Now()
+
+	var reports []logs.StreamReport
+	var lost int
+	for i, generator := range generators {
+		stream := fmt.Sprintf("%s%d", streamNamePrefix, i)
+		events, err := awsservice.GetLogsSince(instanceID, stream, &startTime, &endTime)
+		if err != nil {
+			return err
+		}
+		report := logs.Account(stream, generator.SequenceNumber(), events)
+		log.Print(report)
+		reports = append(reports, report)
+		lost += len(report.Lost)
+	}
+	if err = writeReport(reports); err != nil {
+		log.Printf("could not write the loss report: %v", err)
+	}
+
+	if disruptErr != nil {
+		return disruptErr
+	}
+	if lost > 0 {
+		return fmt.Errorf("lost %d records across %d disruptions of the agent", lost, len(plan))
+	}
+	return nil
+}
+
+func writeReport(reports []logs.StreamReport) error {
+	f, err := os.Create(reportPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	log.Printf("Writing the loss report to %s", reportPath)
+	return logs.WriteLossReport(f, reports)
+}
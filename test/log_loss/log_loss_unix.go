@@ -0,0 +1,25 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package log_loss
+
+import (
+	_ "embed"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+)
+
+const (
+	tmpConfigPath = "/tmp/config.json"
+	reportPath    = "/tmp/cwagent_log_loss_report.json"
+)
+
+//go:embed resources/config_unix.json
+var testConfigJSON string
+
+func stopAgent() error {
+	common.StopAgent()
+	return nil
+}
@@ -0,0 +1,24 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build windows
+
+package log_loss
+
+import (
+	_ "embed"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+)
+
+const (
+	tmpConfigPath = "C:\\Users\\Administrator\\AppData\\Local\\Temp\\config.json"
+	reportPath    = "C:\\Users\\Administrator\\AppData\\Local\\Temp\\cwagent_log_loss_report.json"
+)
+
+//go:embed resources/config_windows.json
+var testConfigJSON string
+
+func stopAgent() error {
+	return common.StopAgent()
+}
@@ -0,0 +1,16 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package log_loss
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/registry"
+)
+
+func init() {
+	registry.RegisterTest(registry.Test{
+		Name:        "log_loss",
+		Description: "Account for lost, duplicated and reordered log records while the agent is stopped, killed and restarted",
+		Validate:    Validate,
+	})
+}
@@ -0,0 +1,18 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package chaos
+
+const (
+	// ScenarioDiskFull fills the state directory so the agent cannot save its offsets.
+	ScenarioDiskFull = "disk_full"
+	// ScenarioChmod removes every permission from the monitored file.
+	ScenarioChmod = "chmod"
+	// ScenarioChown gives the monitored file to root and makes it readable by its owner only.
+	ScenarioChown = "chown"
+	// ScenarioRecreateDir deletes the directory of the monitored file and creates it again.
+	ScenarioRecreateDir = "recreate_dir"
+)
+
+// Scenarios lists every scenario, each registered as log_state_<scenario>.
+var Scenarios = []string{ScenarioDiskFull, ScenarioChmod, ScenarioChown, ScenarioRecreateDir}
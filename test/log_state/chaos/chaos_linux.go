@@ -0,0 +1,273 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build linux
+
+package chaos
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common/logs"
+)
+
+const (
+	tmpConfigPath = "/tmp/log_state_chaos_config.json"
+	logFilePath   = "/tmp/cwagent_log_state_chaos/app.log"
+	// stateDirSize leaves room for the state file before the directory is filled
+	stateDirSize = 64 * 1024
+	// agentUser is the run_as_user of the config, root reads any file whatever its permissions
+	agentUser = "cwagent"
+)
+
+//go:embed resources/config.json
+var testConfigJSON string
+
+// scenario disrupts the agent while it collects the file, restarts the agent into the disruption and repairs
+// it, after which the agent must resume from its saved state without losing a record.
+type scenario struct {
+	// setup runs before the agent starts
+	setup func(r *run) error
+	// disrupt runs while the agent collects the file
+	disrupt func(r *run) error
+	// repair runs once the restarted agent had time to fail on the disruption
+	repair func(r *run) error
+	// cleanup runs at the end whatever happened
+	cleanup func(r *run) error
+	// allowDuplicates is set when the agent cannot save its offsets, so it resends records after the restart
+	allowDuplicates bool
+}
+
+func newScenario(name string) (*scenario, error) {
+	noop := func(*run) error { return nil }
+	s := &scenario{setup: noop, disrupt: noop, repair: noop, cleanup: noop}
+	switch name {
+	case ScenarioDiskFull:
+		var fillPath string
+		s.allowDuplicates = true
+		s.setup = func(*run) error {
+			return common.MountTmpfs(common.AgentStateDir, stateDirSize)
+		}
+		s.disrupt = func(*run) (err error) {
+			fillPath, err = common.FillDir(common.AgentStateDir)
+			return err
+		}
+		s.repair = func(*run) error {
+			return common.DeleteFile(fillPath)
+		}
+		s.cleanup = func(*run) error {
+			return common.Unmount(common.AgentStateDir)
+		}
+	case ScenarioChmod:
+		s.disrupt = func(r *run) error {
+			return common.ChmodPath(r.path, 0)
+		}
+		s.repair = func(r *run) error {
+			return common.ChmodPath(r.path, 0644)
+		}
+	case ScenarioChown:
+		s.disrupt = func(r *run) error {
+			if err := common.ChownPath(r.path, "root"); err != nil {
+				return err
+			}
+			return common.ChmodPath(r.path, 0600)
+		}
+		s.repair = func(r *run) error {
+			return common.ChownPath(r.path, agentUser)
+		}
+	case ScenarioRecreateDir:
+		s.disrupt = func(r *run) error {
+			r.stopGenerating()
+			if err := r.writer.Close(); err != nil {
+				return err
+			}
+			return common.RemoveDir(filepath.Dir(r.path))
+		}
+		s.repair = func(r *run) error {
+			if err := r.writer.reopen(); err != nil {
+				return err
+			}
+			r.startGenerating()
+			return nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown scenario %q", name)
+	}
+	return s, nil
+}
+
+// Validate runs the scenario, then checks every record written reached CloudWatch Logs and the offset the agent
+// saved last is the end of the file.
+func Validate(name string) error {
+	s, err := newScenario(name)
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(tmpConfigPath, []byte(testConfigJSON), 0644); err != nil {
+		return fmt.Errorf("could not create config file: %w", err)
+	}
+	instanceID := awsservice.GetInstanceId()
+	common.CopyFile(tmpConfigPath, common.ConfigOutputPath)
+	if err = common.RemoveDir(filepath.Dir(logFilePath)); err != nil {
+		return err
+	}
+
+	r := &run{path: logFilePath}
+	if err = s.setup(r); err != nil {
+		return fmt.Errorf("could not set up %s: %w", name, err)
+	}
+	defer func() {
+		if err := s.cleanup(r); err != nil {
+			log.Printf("could not clean up %s: %v", name, err)
+		}
+	}()
+
+	r.writer = &reopeningWriter{path: r.path}
+	if err = r.writer.reopen(); err != nil {
+		return err
+	}
+	r.generator, err = logs.NewGenerator(&logs.GeneratorConfig{
+		LinesPerSecond:  2,
+		LineLength:      50,
+		TimestampFormat: "2006-01-02T15:04:05.000",
+	}, r.writer)
+	if err != nil {
+		return fmt.Errorf("could not create logs generator: %v", err)
+	}
+
+	log.Print("Starting agent...")
+	if err = common.StartAgent(common.ConfigOutputPath, true, false); err != nil {
+		return fmt.Errorf("could not start agent: %v", err)
+	}
+	time.Sleep(5 * time.Second)
+	startTime := time.Now()
+	r.startGenerating()
+	time.Sleep(30 * time.Second)
+
+	log.Printf("Disrupting the agent with %s", name)
+	if err = s.disrupt(r); err != nil {
+		return fmt.Errorf("could not disrupt with %s: %w", name, err)
+	}
+	time.Sleep(15 * time.Second)
+
+	log.Print("Restarting agent into the disruption...")
+	common.StopAgent()
+	time.Sleep(5 * time.Second)
+	if err = common.StartAgent(common.ConfigOutputPath, true, false); err != nil {
+		return fmt.Errorf("could not restart agent: %v", err)
+	}
+	time.Sleep(30 * time.Second)
+
+	log.Printf("Repairing %s", name)
+	if err = s.repair(r); err != nil {
+		return fmt.Errorf("could not repair %s: %w", name, err)
+	}
+	time.Sleep(30 * time.Second)
+
+	log.Print("Stopping logs generator")
+	r.stopGenerating()
+	r.writer.Close()
+	time.Sleep(10 * time.Second)
+
+	log.Print("Shutting down agent")
+	common.StopAgent()
+	endTime := time.Now()
+
+	events, err := awsservice.GetLogsSince(instanceID, instanceID, &startTime, &endTime)
+	if err != nil {
+		return err
+	}
+	report := logs.Account(instanceID, r.generator.SequenceNumber(), events)
+	log.Print(report)
+	var errs []error
+	if len(report.Lost) > 0 {
+		errs = append(errs, fmt.Errorf("lost %d records", len(report.Lost)))
+	}
+	if report.Duplicated > 0 && !s.allowDuplicates {
+		errs = append(errs, fmt.Errorf("duplicated %d records", report.Duplicated))
+	}
+	if err = checkStateOffset(r.path); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// checkStateOffset checks the agent saved the end of the file as its offset, so a restart resumes after the
+// last record.
+func checkStateOffset(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	offset, err := common.ReadStateOffset(path)
+	if err != nil {
+		return err
+	}
+	if offset != info.Size() {
+		return fmt.Errorf("the agent saved offset %d for %s, want the file size %d", offset, path, info.Size())
+	}
+	return nil
+}
+
+// run is the file collected by the agent and the generator writing to it.
+type run struct {
+	path      string
+	writer    *reopeningWriter
+	generator *logs.Generator
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+func (r *run) startGenerating() {
+	var ctx context.Context
+	ctx, r.cancel = context.WithCancel(context.Background())
+	r.wg.Add(1)
+	go r.generator.Generate(ctx, &r.wg)
+}
+
+func (r *run) stopGenerating() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// reopeningWriter lets the generator keep its sequence numbers while the file is recreated under it.
+type reopeningWriter struct {
+	path   string
+	mu     sync.Mutex
+	writer *logs.FileWriter
+}
+
+var _ logs.EntryWriter = (*reopeningWriter)(nil)
+
+func (w *reopeningWriter) reopen() error {
+	writer, err := logs.NewFileWriter(logs.FileWriterConfig{Path: w.path})
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writer = writer
+	return nil
+}
+
+func (w *reopeningWriter) Write(entry string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.writer.Write(entry)
+}
+
+func (w *reopeningWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.writer.Close()
+}
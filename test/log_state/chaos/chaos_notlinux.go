@@ -0,0 +1,12 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !linux
+
+package chaos
+
+import "errors"
+
+func Validate(_ string) error {
+	return errors.New("test unsupported by OS")
+}
@@ -0,0 +1,26 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build linux
+
+package chaos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+)
+
+func init() {
+	environment.RegisterEnvironmentMetaDataFlags()
+}
+
+func TestLogStateChaos(t *testing.T) {
+	for _, scenario := range Scenarios {
+		t.Run(scenario, func(t *testing.T) {
+			assert.NoError(t, Validate(scenario))
+		})
+	}
+}
@@ -0,0 +1,19 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package chaos
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/registry"
+)
+
+func init() {
+	for _, scenario := range Scenarios {
+		scenario := scenario
+		registry.RegisterTest(registry.Test{
+			Name:        "log_state_" + scenario,
+			Description: "Validate the agent recovers from the " + scenario + " disruption and resumes from its saved state",
+			Validate:    func() error { return Validate(scenario) },
+		})
+	}
+}
@@ -0,0 +1,16 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package journald
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/registry"
+)
+
+func init() {
+	registry.RegisterTest(registry.Test{
+		Name:        "journald",
+		Description: "Validate the agent resumes reading journald from its saved state",
+		Validate:    Validate,
+	})
+}
@@ -0,0 +1,16 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/registry"
+)
+
+func init() {
+	registry.RegisterTest(registry.Test{
+		Name:        "logfile",
+		Description: "Validate the agent resumes tailing log files from its saved state",
+		Validate:    Validate,
+	})
+}
@@ -0,0 +1,16 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package windows_event_log
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/registry"
+)
+
+func init() {
+	registry.RegisterTest(registry.Test{
+		Name:        "windows_event_log",
+		Description: "Validate the agent resumes reading Windows event logs from its saved state",
+		Validate:    Validate,
+	})
+}
@@ -4,11 +4,14 @@
 package metric
 
 import (
-	"fmt"
 	"log"
 	"math"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/metricassert"
 )
 
+const metricErrorBound = 0.15
+
 var CpuMetrics = []string{"cpu_time_active", "cpu_time_guest", "cpu_time_guest_nice", "cpu_time_idle", "cpu_time_iowait", "cpu_time_irq",
 	"cpu_time_nice", "cpu_time_softirq", "cpu_time_steal", "cpu_time_system", "cpu_time_user",
 	"cpu_usage_active", "cpu_usage_guest", "cpu_usage_guest_nice", "cpu_usage_idle", "cpu_usage_iowait",
@@ -18,34 +21,19 @@ var CpuMetrics = []string{"cpu_time_active", "cpu_time_guest", "cpu_time_guest_n
 // and check if the average value for the array is not la
 // https://github.com/aws/amazon-cloudwatch-agent-test/pull/162
 func IsAllValuesGreaterThanOrEqualToExpectedValueWithError(metricName string, values []float64, expectedValue float64) error {
-	if len(values) == 0 {
-		err := fmt.Errorf("No values found %v", metricName)
-		log.Println(err)
-		return err
+	expectation := metricassert.ExpectSeries(metricassert.FromValues(metricName, values)).NotEmpty()
+	if expectedValue >= 0 {
+		expectation.AllAtLeast(0)
 	}
-
-	totalSum := 0.0
-	for _, value := range values {
-		if value < 0 && expectedValue >= 0 {
-			err := fmt.Errorf("Values are not all greater than or equal to zero for %s", metricName)
-			log.Println(err)
-			return err
-		}
-		totalSum += value
+	if expectedValue > 0 {
+		expectation.MeanNear(expectedValue, metricErrorBound)
 	}
-	metricErrorBound := 0.15
-	metricAverageValue := totalSum / float64(len(values))
-	upperBoundValue := expectedValue * (1 + metricErrorBound)
-	lowerBoundValue := expectedValue * (1 - metricErrorBound)
-	if expectedValue > 0 && (metricAverageValue > upperBoundValue || metricAverageValue < lowerBoundValue) {
-		err := fmt.Errorf("The average value %f for metric %s are not within bound [%f, %f]",
-			metricAverageValue, metricName, lowerBoundValue, upperBoundValue)
+	if err := expectation.Err(); err != nil {
 		log.Println(err)
 		return err
 	}
 
-	log.Printf("The average value %f for metric %s are within bound [%f, %f]",
-		metricAverageValue, metricName, lowerBoundValue, upperBoundValue)
+	log.Printf("The values for metric %s are within bound of %f", metricName, expectedValue)
 	return nil
 }
 
@@ -12,6 +12,7 @@ import (
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
 )
 
@@ -52,7 +53,7 @@ func (t *CollectdAppendDimensionsTestRunner) GetAgentConfigFileName() string {
 }
 
 func (t *CollectdAppendDimensionsTestRunner) SetupAfterAgentRun() error {
-	return common.SendCollectDMetrics(2, time.Second, t.GetAgentRunDuration())
+	return common.SendCollectDMetrics(clock.Real(), 2, time.Second, t.GetAgentRunDuration())
 }
 
 func (t *CollectdAppendDimensionsTestRunner) GetMeasuredMetrics() []string {
@@ -12,6 +12,7 @@ import (
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
 )
 
@@ -53,7 +54,7 @@ func (t *CollectdFleetAggregationTestRunner) GetAgentConfigFileName() string {
 }
 
 func (t *CollectdFleetAggregationTestRunner) SetupAfterAgentRun() error {
-	return common.SendCollectDMetrics(2, time.Second, t.GetAgentRunDuration())
+	return common.SendCollectDMetrics(clock.Real(), 2, time.Second, t.GetAgentRunDuration())
 }
 
 func (t *CollectdFleetAggregationTestRunner) GetMeasuredMetrics() []string {
@@ -12,6 +12,7 @@ import (
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
 )
 
@@ -51,7 +52,7 @@ func (t *CollectdGlobalAppendDimensionsTestRunner) GetAgentConfigFileName() stri
 }
 
 func (t *CollectdGlobalAppendDimensionsTestRunner) SetupAfterAgentRun() error {
-	return common.SendCollectDMetrics(2, time.Second, t.GetAgentRunDuration())
+	return common.SendCollectDMetrics(clock.Real(), 2, time.Second, t.GetAgentRunDuration())
 }
 
 func (t *CollectdGlobalAppendDimensionsTestRunner) GetMeasuredMetrics() []string {
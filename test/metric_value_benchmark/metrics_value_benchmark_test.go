@@ -35,6 +35,7 @@ func (suite *MetricBenchmarkTestSuite) SetupSuite() {
 
 func (suite *MetricBenchmarkTestSuite) TearDownSuite() {
 	suite.Result.Print()
+	suite.ExportResult("MetricBenchmarkTestSuite")
 	fmt.Println(">>>> Finished MetricBenchmarkTestSuite")
 }
 
@@ -13,6 +13,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/otelmetrics"
 )
 
 // ---------------------------------------------------------------------------
@@ -191,6 +193,11 @@ func TestProtectedAttributesSurvive(t *testing.T) {
 
 const attributeLimit = 150
 
+// maxExpectedAttributes allows 20 fixed system/instrumentation attributes
+// beyond the 150-attr cap (e.g. __name__, cluster name, host.type,
+// instrumentation scope labels, and OTel-injected resource attrs).
+const maxExpectedAttributes = attributeLimit + 20
+
 func TestAttributeCountLimit(t *testing.T) {
 	t.Parallel()
 	for _, metricName := range daemonsetMetricNames() {
@@ -207,13 +214,9 @@ func TestAttributeCountLimit(t *testing.T) {
 					len(r.Labels.Instrumentation) +
 					len(r.Labels.AWS) +
 					len(r.Labels.AWSCloudWatch)
-				// maxExpected allows 20 fixed system/instrumentation attributes
-				// beyond the 150-attr cap (e.g. __name__, cluster name, host.type,
-				// instrumentation scope labels, and OTel-injected resource attrs).
-				const maxExpected = attributeLimit + 20
-				if total > maxExpected {
+				if total > maxExpectedAttributes {
 					t.Errorf("%s exceeds expected max: got %d, max %d (node: %s, pod: %s)",
-						metricName, total, maxExpected,
+						metricName, total, maxExpectedAttributes,
 						r.Labels.Resource["k8s.node.name"],
 						r.Labels.Resource["k8s.pod.name"])
 				}
@@ -222,6 +225,31 @@ func TestAttributeCountLimit(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// TestCardinalityBudget
+// ---------------------------------------------------------------------------
+
+// TestCardinalityBudget measures the attribute counts and series of the
+// metrics of every source, logs the per-source report and fails on a metric
+// over the attribute limit. Metrics approaching it are only logged.
+func TestCardinalityBudget(t *testing.T) {
+	t.Parallel()
+	sources, err := metricCatalog.SourceMappings(suiteName)
+	require.NoError(t, err)
+	report, err := otelmetrics.AnalyzeCardinality(context.Background(), queryCache, sources,
+		otelmetrics.CardinalityLimits{Attributes: maxExpectedAttributes})
+	require.NoError(t, err, "analyzing the cardinality of the %s metrics", suiteName)
+
+	var text strings.Builder
+	require.NoError(t, report.WriteText(&text))
+	t.Logf("cardinality report:\n%s", text.String())
+	for _, m := range report.Flagged() {
+		if m.Exceeded() {
+			t.Errorf("%s exceeds its cardinality budget: %v", m.Metric, m.Flags)
+		}
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Phase 2 tier-dropping sentinel keys.
 // ---------------------------------------------------------------------------
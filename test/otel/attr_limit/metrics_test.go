@@ -7,45 +7,20 @@ package attr_limit
 
 import "github.com/aws/amazon-cloudwatch-agent-test/util/otelmetrics"
 
-var nodeExporterMetrics = []otelmetrics.MetricDefinition{
-	{Name: "node_cpu_seconds_total", MetricType: "counter", Scope: otelmetrics.ScopeNode, ExpectedLabels: []string{"cpu", "mode"}, Unit: "s"},
-	{Name: "node_memory_MemAvailable_bytes", MetricType: "gauge", Scope: otelmetrics.ScopeNode, Unit: "By"},
-	{Name: "node_filesystem_avail_bytes", MetricType: "gauge", Scope: otelmetrics.ScopeNode, ExpectedLabels: []string{"device", "mountpoint", "fstype"}, Unit: "By"},
-	{Name: "node_network_receive_bytes_total", MetricType: "counter", Scope: otelmetrics.ScopeNode, ExpectedLabels: []string{"device"}, Unit: "By"},
-	{Name: "node_load1", MetricType: "gauge", Scope: otelmetrics.ScopeNode},
-}
+// metricCatalog holds the metric definitions, see util/otelmetrics/catalog.yaml.
+var metricCatalog = otelmetrics.DefaultCatalog()
 
-var cadvisorMetrics = []otelmetrics.MetricDefinition{
-	{Name: "container_cpu_usage_seconds_total", MetricType: "counter", Scope: otelmetrics.ScopeContainer, ExpectedLabels: []string{"cpu"}, Unit: "s"},
-	{Name: "container_memory_working_set_bytes", MetricType: "gauge", Scope: otelmetrics.ScopeContainer, Unit: "By"},
-	{Name: "container_memory_usage_bytes", MetricType: "gauge", Scope: otelmetrics.ScopeContainer, Unit: "By"},
-	{Name: "container_network_receive_bytes_total", MetricType: "counter", Scope: otelmetrics.ScopePod, ExpectedLabels: []string{"interface"}, Unit: "By"},
-}
+const suiteName = "attr_limit"
 
-var kubeletstatsNodeMetrics = []otelmetrics.MetricDefinition{
-	{Name: "k8s.node.cpu.utilization", MetricType: "gauge", Scope: otelmetrics.ScopeNode, Unit: "1"},
-	{Name: "k8s.node.memory.working_set", MetricType: "gauge", Scope: otelmetrics.ScopeNode, Unit: "By"},
-	{Name: "k8s.node.filesystem.available", MetricType: "gauge", Scope: otelmetrics.ScopeNode, Unit: "By"},
-	// k8s.node.network.io is not emitted on EKS AL2023 + kubelet 1.35: kubelet's
-	// /stats/summary returns no top-level default-interface aggregate, and the
-	// kubeletstatsreceiver only emits this metric from the top-level fields.
-	// Fixed upstream by collect_all_network_interfaces (contrib v0.131+, PR
-	// open-telemetry/opentelemetry-collector-contrib#38737); the agent currently
-	// pins contrib v0.124.1, so re-enable once the contrib bump lands.
-	// {Name: "k8s.node.network.io", MetricType: "counter", Scope: otelmetrics.ScopeNode, ExpectedLabels: []string{"interface", "direction"}, Unit: "By"},
-}
+var nodeExporterMetrics = metricCatalog.Metrics(suiteName, otelmetrics.SourceNodeExporter)
 
-var kubeletstatsPodMetrics = []otelmetrics.MetricDefinition{
-	{Name: "k8s.pod.cpu.utilization", MetricType: "gauge", Scope: otelmetrics.ScopePod, Unit: "1"},
-	{Name: "k8s.pod.memory.working_set", MetricType: "gauge", Scope: otelmetrics.ScopePod, Unit: "By"},
-	{Name: "k8s.pod.network.io", MetricType: "counter", Scope: otelmetrics.ScopePod, ExpectedLabels: []string{"interface", "direction"}, Unit: "By"},
-}
+var cadvisorMetrics = metricCatalog.Metrics(suiteName, otelmetrics.SourceCadvisor)
 
-var kubeletstatsContainerMetrics = []otelmetrics.MetricDefinition{
-	{Name: "container.cpu.utilization", MetricType: "gauge", Scope: otelmetrics.ScopeContainer, Unit: "1"},
-	{Name: "container.memory.working_set", MetricType: "gauge", Scope: otelmetrics.ScopeContainer, Unit: "By"},
-	{Name: "container.memory.usage", MetricType: "gauge", Scope: otelmetrics.ScopeContainer, Unit: "By"},
-}
+var kubeletstatsNodeMetrics = otelmetrics.MetricsWithScope(metricCatalog.Metrics(suiteName, otelmetrics.SourceKubeletstats), otelmetrics.ScopeNode)
+
+var kubeletstatsPodMetrics = otelmetrics.MetricsWithScope(metricCatalog.Metrics(suiteName, otelmetrics.SourceKubeletstats), otelmetrics.ScopePod)
+
+var kubeletstatsContainerMetrics = otelmetrics.MetricsWithScope(metricCatalog.Metrics(suiteName, otelmetrics.SourceKubeletstats), otelmetrics.ScopeContainer)
 
 var kubeletstatsMetrics = func() []otelmetrics.MetricDefinition {
 	var all []otelmetrics.MetricDefinition
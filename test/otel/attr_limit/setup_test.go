@@ -26,7 +26,7 @@ var (
 	queryCache *otelmetrics.QueryCache
 )
 
-var clusterHostTypes = []string{"t3.medium"}
+var clusterHostTypes = metricCatalog.HostTypes(suiteName)
 
 func TestMain(m *testing.M) {
 	environment.RegisterEnvironmentMetaDataFlags()
@@ -80,18 +80,12 @@ func TestMain(m *testing.M) {
 		os.Exit(1)
 	}
 
-	hostMappings := []otelmetrics.SourceHostMapping{
-		{Source: otelmetrics.SourceNodeExporter, HostTypes: clusterHostTypes},
-		{Source: otelmetrics.SourceCadvisor, HostTypes: clusterHostTypes},
-		{Source: otelmetrics.SourceKubeletstats, HostTypes: clusterHostTypes},
+	registry, err := metricCatalog.SourceRegistry(suiteName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Source registry error: %v\n", err)
+		os.Exit(1)
 	}
 
-	registry := otelmetrics.NewSourceRegistry(clusterHostTypes, hostMappings,
-		otelmetrics.SourceMapping{Source: otelmetrics.SourceNodeExporter, Metrics: nodeExporterMetrics},
-		otelmetrics.SourceMapping{Source: otelmetrics.SourceCadvisor, Metrics: cadvisorMetrics},
-		otelmetrics.SourceMapping{Source: otelmetrics.SourceKubeletstats, Metrics: kubeletstatsMetrics},
-	)
-
 	queryCache = otelmetrics.NewQueryCache(client, cfg.ClusterName,
 		otelmetrics.WithHostTypes(clusterHostTypes),
 		otelmetrics.WithSourceRegistry(registry),
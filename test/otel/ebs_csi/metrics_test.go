@@ -7,13 +7,12 @@ package ebs_csi
 
 import "github.com/aws/amazon-cloudwatch-agent-test/util/otelmetrics"
 
-var ebsCsiMetrics = []otelmetrics.MetricDefinition{
-	{Name: "aws_ebs_csi_read_ops_total", MetricType: "counter"},
-	{Name: "aws_ebs_csi_write_ops_total", MetricType: "counter"},
-	{Name: "aws_ebs_csi_read_bytes_total", MetricType: "counter", Unit: "By"},
-	{Name: "aws_ebs_csi_write_bytes_total", MetricType: "counter", Unit: "By"},
-	{Name: "aws_ebs_csi_volume_queue_length", MetricType: "gauge"},
-}
+// metricCatalog holds the metric definitions, see util/otelmetrics/catalog.yaml.
+var metricCatalog = otelmetrics.DefaultCatalog()
+
+const suiteName = "ebs_csi"
+
+var ebsCsiMetrics = metricCatalog.Metrics(suiteName, otelmetrics.SourceEBSCSI)
 
 func ebsCsiMetricNames() []string {
 	names := make([]string, len(ebsCsiMetrics))
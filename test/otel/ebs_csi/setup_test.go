@@ -30,7 +30,7 @@ var (
 const scopePrometheus = "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusreceiver"
 
 // Instance types in the EBS CSI cluster.
-var clusterHostTypes = []string{"t3.medium"}
+var clusterHostTypes = metricCatalog.HostTypes(suiteName)
 
 func TestMain(m *testing.M) {
 	environment.RegisterEnvironmentMetaDataFlags()
@@ -84,14 +84,12 @@ func TestMain(m *testing.M) {
 		os.Exit(1)
 	}
 
-	hostMappings := []otelmetrics.SourceHostMapping{
-		{Source: otelmetrics.SourceEBSCSI, HostTypes: nil},
+	registry, err := metricCatalog.SourceRegistry(suiteName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Source registry error: %v\n", err)
+		os.Exit(1)
 	}
 
-	registry := otelmetrics.NewSourceRegistry(clusterHostTypes, hostMappings,
-		otelmetrics.SourceMapping{Source: otelmetrics.SourceEBSCSI, Metrics: ebsCsiMetrics},
-	)
-
 	queryCache = otelmetrics.NewQueryCache(client, cfg.ClusterName,
 		otelmetrics.WithHostTypes(clusterHostTypes),
 		otelmetrics.WithSourceRegistry(registry),
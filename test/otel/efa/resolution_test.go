@@ -22,17 +22,14 @@ import (
 const (
 	expectedEFAResolution     = 30 * time.Second
 	efaResolutionInstanceType = "c5n.9xlarge"
+	resolutionWindow          = 5 * time.Minute
 )
 
 // TestEFAResolution validates efa_rx_bytes is scraped at ~30s intervals on
-// the c5n.9xlarge EFA nodes.
+// the c5n.9xlarge EFA nodes, from the spacing of its raw samples over 5 minutes.
 func TestEFAResolution(t *testing.T) {
 	t.Parallel()
-	end := time.Now()
-	start := end.Add(-5 * time.Minute)
-	step := expectedEFAResolution
-
-	expectedSamples := int(5*time.Minute/expectedEFAResolution) + 1 // 11 for 30s
+	expectedSamples := int(resolutionWindow / expectedEFAResolution) // 10 for 30s
 	minSamples := expectedSamples / 2
 
 	escaped := escapePromQL(cfg.ClusterName)
@@ -40,22 +37,21 @@ func TestEFAResolution(t *testing.T) {
 		`efa_rx_bytes{"@resource.k8s.cluster.name"="%s","@resource.host.type"="%s"}`,
 		escaped, efaResolutionInstanceType)
 
-	results, err := client.QueryRange(context.Background(), promql, start, end, step)
-	require.NoError(t, err, "range querying efa_rx_bytes")
-	require.True(t, len(results) > 0,
-		"No efa_rx_bytes range results from %s", efaResolutionInstanceType)
-
-	var bestSeries *otelmetrics.RangeResult
-	for i := range results {
-		if bestSeries == nil || len(results[i].Timestamps) > len(bestSeries.Timestamps) {
-			bestSeries = &results[i]
-		}
-	}
+	results, err := client.QuerySamples(context.Background(), promql, resolutionWindow, time.Now())
+	require.NoError(t, err, "querying the samples of efa_rx_bytes")
+	require.True(t, len(results) > 0, "No efa_rx_bytes samples from %s", efaResolutionInstanceType)
 
+	// Check the series with the most samples (best coverage).
+	best := otelmetrics.MostSamples(results)
 	t.Logf("efa_rx_bytes: %d samples in 5-minute window (expected ~%d for %v resolution)",
-		len(bestSeries.Timestamps), expectedSamples, expectedEFAResolution)
-
-	require.True(t, len(bestSeries.Timestamps) >= minSamples,
-		"efa_rx_bytes: got %d samples, expected at least %d for %v resolution",
-		len(bestSeries.Timestamps), minSamples, expectedEFAResolution)
+		len(best.Timestamps), expectedSamples, expectedEFAResolution)
+
+	// Tolerate a single gap of up to two missed collections, like a
+	// delayed batch, but no duplicates or repeated gaps
+	err = otelmetrics.ExpectRange(best).
+		SampleCountBetween(minSamples, 2*expectedSamples).
+		SpacingWithin(expectedEFAResolution/2, 3*expectedEFAResolution).
+		GapsAtMost(1, 2*expectedEFAResolution).
+		Err()
+	require.NoError(t, err, "efa_rx_bytes is not collected at %v resolution", expectedEFAResolution)
 }
@@ -7,44 +7,25 @@ package gpu
 
 import "github.com/aws/amazon-cloudwatch-agent-test/util/otelmetrics"
 
+// metricCatalog holds the metric definitions, see util/otelmetrics/catalog.yaml.
+var metricCatalog = otelmetrics.DefaultCatalog()
+
+const suiteName = "gpu"
+
 // Instance types in the GPU cluster.
-var clusterHostTypes = []string{"g4dn.xlarge", "g4dn.12xlarge"}
+var clusterHostTypes = metricCatalog.HostTypes(suiteName)
 
 // --- Metric definitions ---
 
-var nodeExporterMetrics = []otelmetrics.MetricDefinition{
-	{Name: "node_cpu_seconds_total", MetricType: "counter", Scope: otelmetrics.ScopeNode, ExpectedLabels: []string{"cpu", "mode"}, Unit: "s"},
-	{Name: "node_memory_MemAvailable_bytes", MetricType: "gauge", Scope: otelmetrics.ScopeNode, Unit: "By"},
-	{Name: "node_filesystem_avail_bytes", MetricType: "gauge", Scope: otelmetrics.ScopeNode, ExpectedLabels: []string{"device", "mountpoint", "fstype"}, Unit: "By"},
-	{Name: "node_network_receive_bytes_total", MetricType: "counter", Scope: otelmetrics.ScopeNode, ExpectedLabels: []string{"device"}, Unit: "By"},
-	{Name: "node_load1", MetricType: "gauge", Scope: otelmetrics.ScopeNode},
-}
+var nodeExporterMetrics = metricCatalog.Metrics(suiteName, otelmetrics.SourceNodeExporter)
 
-var cadvisorMetrics = []otelmetrics.MetricDefinition{
-	{Name: "container_cpu_usage_seconds_total", MetricType: "counter", Scope: otelmetrics.ScopeContainer, ExpectedLabels: []string{"cpu"}, Unit: "s"},
-	{Name: "container_memory_working_set_bytes", MetricType: "gauge", Scope: otelmetrics.ScopeContainer, Unit: "By"},
-	{Name: "container_memory_usage_bytes", MetricType: "gauge", Scope: otelmetrics.ScopeContainer, Unit: "By"},
-	{Name: "container_network_receive_bytes_total", MetricType: "counter", Scope: otelmetrics.ScopePod, ExpectedLabels: []string{"interface"}, Unit: "By"},
-}
+var cadvisorMetrics = metricCatalog.Metrics(suiteName, otelmetrics.SourceCadvisor)
 
-var kubeletstatsNodeMetrics = []otelmetrics.MetricDefinition{
-	{Name: "k8s.node.cpu.utilization", MetricType: "gauge", Scope: otelmetrics.ScopeNode, Unit: "1"},
-	{Name: "k8s.node.memory.working_set", MetricType: "gauge", Scope: otelmetrics.ScopeNode, Unit: "By"},
-	{Name: "k8s.node.filesystem.available", MetricType: "gauge", Scope: otelmetrics.ScopeNode, Unit: "By"},
-	{Name: "k8s.node.network.io", MetricType: "counter", Scope: otelmetrics.ScopeNode, ExpectedLabels: []string{"interface", "direction"}, Unit: "By"},
-}
+var kubeletstatsNodeMetrics = otelmetrics.MetricsWithScope(metricCatalog.Metrics(suiteName, otelmetrics.SourceKubeletstats), otelmetrics.ScopeNode)
 
-var kubeletstatsPodMetrics = []otelmetrics.MetricDefinition{
-	{Name: "k8s.pod.cpu.utilization", MetricType: "gauge", Scope: otelmetrics.ScopePod, Unit: "1"},
-	{Name: "k8s.pod.memory.working_set", MetricType: "gauge", Scope: otelmetrics.ScopePod, Unit: "By"},
-	{Name: "k8s.pod.network.io", MetricType: "counter", Scope: otelmetrics.ScopePod, ExpectedLabels: []string{"interface", "direction"}, Unit: "By"},
-}
+var kubeletstatsPodMetrics = otelmetrics.MetricsWithScope(metricCatalog.Metrics(suiteName, otelmetrics.SourceKubeletstats), otelmetrics.ScopePod)
 
-var kubeletstatsContainerMetrics = []otelmetrics.MetricDefinition{
-	{Name: "container.cpu.utilization", MetricType: "gauge", Scope: otelmetrics.ScopeContainer, Unit: "1"},
-	{Name: "container.memory.working_set", MetricType: "gauge", Scope: otelmetrics.ScopeContainer, Unit: "By"},
-	{Name: "container.memory.usage", MetricType: "gauge", Scope: otelmetrics.ScopeContainer, Unit: "By"},
-}
+var kubeletstatsContainerMetrics = otelmetrics.MetricsWithScope(metricCatalog.Metrics(suiteName, otelmetrics.SourceKubeletstats), otelmetrics.ScopeContainer)
 
 var kubeletstatsMetrics = func() []otelmetrics.MetricDefinition {
 	var all []otelmetrics.MetricDefinition
@@ -54,14 +35,7 @@ var kubeletstatsMetrics = func() []otelmetrics.MetricDefinition {
 	return all
 }()
 
-var dcgmMetrics = []otelmetrics.MetricDefinition{
-	{Name: "DCGM_FI_DEV_GPU_UTIL", MetricType: "gauge", Scope: otelmetrics.ScopePod, Unit: "%"},
-	{Name: "DCGM_FI_DEV_MEM_COPY_UTIL", MetricType: "gauge", Scope: otelmetrics.ScopePod, Unit: "%"},
-	{Name: "DCGM_FI_DEV_FB_USED", MetricType: "gauge", Scope: otelmetrics.ScopePod, Unit: "MiBy"},
-	{Name: "DCGM_FI_DEV_GPU_TEMP", MetricType: "gauge", Scope: otelmetrics.ScopePod, Unit: "Cel"},
-	{Name: "DCGM_FI_DEV_POWER_USAGE", MetricType: "gauge", Scope: otelmetrics.ScopePod, Unit: "W"},
-	{Name: "DCGM_FI_DEV_FB_FREE", MetricType: "gauge", Scope: otelmetrics.ScopePod, Unit: "MiBy"},
-}
+var dcgmMetrics = metricCatalog.Metrics(suiteName, otelmetrics.SourceDCGM)
 
 // --- Helper functions ---
 
@@ -19,17 +19,16 @@ import (
 	"github.com/aws/amazon-cloudwatch-agent-test/util/otelmetrics"
 )
 
-const expectedDCGMResolution = 30 * time.Second
+const (
+	expectedDCGMResolution = 30 * time.Second
+	resolutionWindow       = 5 * time.Minute
+)
 
 // TestDCGMResolution validates DCGM_FI_DEV_GPU_UTIL is scraped at ~30s intervals
-// by querying a 5-minute range and checking sample counts on the multi-GPU node.
+// from the spacing of its raw samples over 5 minutes on the multi-GPU node.
 func TestDCGMResolution(t *testing.T) {
 	t.Parallel()
-	end := time.Now()
-	start := end.Add(-5 * time.Minute)
-	step := expectedDCGMResolution
-
-	expectedSamples := int(5*time.Minute/expectedDCGMResolution) + 1 // 11 for 30s
+	expectedSamples := int(resolutionWindow / expectedDCGMResolution) // 10 for 30s
 	minSamples := expectedSamples / 2
 
 	escaped := otelmetrics.EscapePromQLValue(cfg.ClusterName)
@@ -37,22 +36,21 @@ func TestDCGMResolution(t *testing.T) {
 		`DCGM_FI_DEV_GPU_UTIL{"@resource.k8s.cluster.name"="%s","@resource.host.type"="%s"}`,
 		escaped, multiGpuInstanceType)
 
-	results, err := client.QueryRange(context.Background(), promql, start, end, step)
-	require.NoError(t, err, "range querying DCGM_FI_DEV_GPU_UTIL")
-	require.True(t, len(results) > 0, "No DCGM_FI_DEV_GPU_UTIL range results from %s", multiGpuInstanceType)
-
-	// Pick the series with the most samples (best coverage).
-	var bestSeries *otelmetrics.RangeResult
-	for i := range results {
-		if bestSeries == nil || len(results[i].Timestamps) > len(bestSeries.Timestamps) {
-			bestSeries = &results[i]
-		}
-	}
+	results, err := client.QuerySamples(context.Background(), promql, resolutionWindow, time.Now())
+	require.NoError(t, err, "querying the samples of DCGM_FI_DEV_GPU_UTIL")
+	require.True(t, len(results) > 0, "No DCGM_FI_DEV_GPU_UTIL samples from %s", multiGpuInstanceType)
 
+	// Check the series with the most samples (best coverage).
+	best := otelmetrics.MostSamples(results)
 	t.Logf("DCGM_FI_DEV_GPU_UTIL: %d samples in 5-minute window (expected ~%d for %v resolution)",
-		len(bestSeries.Timestamps), expectedSamples, expectedDCGMResolution)
-
-	require.True(t, len(bestSeries.Timestamps) >= minSamples,
-		"DCGM_FI_DEV_GPU_UTIL: got %d samples, expected at least %d for %v resolution",
-		len(bestSeries.Timestamps), minSamples, expectedDCGMResolution)
+		len(best.Timestamps), expectedSamples, expectedDCGMResolution)
+
+	// Tolerate a single gap of up to two missed collections, like a
+	// delayed batch, but no duplicates or repeated gaps
+	err = otelmetrics.ExpectRange(best).
+		SampleCountBetween(minSamples, 2*expectedSamples).
+		SpacingWithin(expectedDCGMResolution/2, 3*expectedDCGMResolution).
+		GapsAtMost(1, 2*expectedDCGMResolution).
+		Err()
+	require.NoError(t, err, "DCGM_FI_DEV_GPU_UTIL is not collected at %v resolution", expectedDCGMResolution)
 }
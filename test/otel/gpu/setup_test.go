@@ -78,20 +78,12 @@ func TestMain(m *testing.M) {
 		os.Exit(1)
 	}
 
-	hostMappings := []otelmetrics.SourceHostMapping{
-		{Source: otelmetrics.SourceNodeExporter, HostTypes: clusterHostTypes},
-		{Source: otelmetrics.SourceCadvisor, HostTypes: clusterHostTypes},
-		{Source: otelmetrics.SourceKubeletstats, HostTypes: clusterHostTypes},
-		{Source: otelmetrics.SourceDCGM, HostTypes: clusterHostTypes},
+	registry, err := metricCatalog.SourceRegistry(suiteName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Source registry error: %v\n", err)
+		os.Exit(1)
 	}
 
-	registry := otelmetrics.NewSourceRegistry(clusterHostTypes, hostMappings,
-		otelmetrics.SourceMapping{Source: otelmetrics.SourceNodeExporter, Metrics: nodeExporterMetrics},
-		otelmetrics.SourceMapping{Source: otelmetrics.SourceCadvisor, Metrics: cadvisorMetrics},
-		otelmetrics.SourceMapping{Source: otelmetrics.SourceKubeletstats, Metrics: kubeletstatsMetrics},
-		otelmetrics.SourceMapping{Source: otelmetrics.SourceDCGM, Metrics: dcgmMetrics},
-	)
-
 	queryCache = otelmetrics.NewQueryCache(client, cfg.ClusterName,
 		otelmetrics.WithHostTypes(clusterHostTypes),
 		otelmetrics.WithSourceRegistry(registry),
@@ -0,0 +1,40 @@
+//go:build integration
+
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+// Temporal test compares the rate of a counter across the GPU node groups,
+// catching a node group whose pipeline drops or delays samples.
+
+package gpu
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/otelmetrics"
+)
+
+// TestCPURateAcrossNodeGroups validates node_cpu_seconds_total has the same
+// mean rate per series on every GPU node group. Every CPU accrues one second
+// per second across its modes, so the mean rate of the cpu/mode series does
+// not depend on the instance size.
+func TestCPURateAcrossNodeGroups(t *testing.T) {
+	t.Parallel()
+	promql := fmt.Sprintf(`node_cpu_seconds_total{"@resource.k8s.cluster.name"="%s"}`,
+		otelmetrics.EscapePromQLValue(cfg.ClusterName))
+	results, err := client.QuerySamples(context.Background(), promql, 10*time.Minute, time.Now())
+	require.NoError(t, err, "querying the samples of node_cpu_seconds_total")
+
+	var nodeGroups []string
+	for _, it := range gpuInstanceTypes {
+		nodeGroups = append(nodeGroups, it.InstanceType)
+	}
+	rates := otelmetrics.GroupRates(results, otelmetrics.ByResource("host.type"))
+	t.Logf("node_cpu_seconds_total mean rate per series by node group: %v", rates)
+	require.NoError(t, otelmetrics.ExpectComparableRates(rates, 1.5, nodeGroups...))
+}
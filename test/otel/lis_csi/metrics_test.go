@@ -7,26 +7,16 @@ package lis_csi
 
 import "github.com/aws/amazon-cloudwatch-agent-test/util/otelmetrics"
 
+// metricCatalog holds the metric definitions, see util/otelmetrics/catalog.yaml.
+var metricCatalog = otelmetrics.DefaultCatalog()
+
+const suiteName = "lis_csi"
+
 // lisCsiVolumeMetrics are per-volume metrics that carry instance_id and volume_id.
-var lisCsiVolumeMetrics = []otelmetrics.MetricDefinition{
-	{Name: "aws_ec2_instance_store_csi_read_ops_total", MetricType: "counter", Scope: otelmetrics.ScopePod},
-	{Name: "aws_ec2_instance_store_csi_write_ops_total", MetricType: "counter", Scope: otelmetrics.ScopePod},
-	{Name: "aws_ec2_instance_store_csi_read_bytes_total", MetricType: "counter", Scope: otelmetrics.ScopePod, Unit: "By"},
-	{Name: "aws_ec2_instance_store_csi_write_bytes_total", MetricType: "counter", Scope: otelmetrics.ScopePod, Unit: "By"},
-	{Name: "aws_ec2_instance_store_csi_read_seconds_total", MetricType: "counter", Scope: otelmetrics.ScopePod},
-	{Name: "aws_ec2_instance_store_csi_write_seconds_total", MetricType: "counter", Scope: otelmetrics.ScopePod},
-	{Name: "aws_ec2_instance_store_csi_ec2_exceeded_iops_seconds_total", MetricType: "counter", Scope: otelmetrics.ScopePod},
-	{Name: "aws_ec2_instance_store_csi_ec2_exceeded_tp_seconds_total", MetricType: "counter", Scope: otelmetrics.ScopePod},
-	{Name: "aws_ec2_instance_store_csi_volume_queue_length", MetricType: "gauge", Scope: otelmetrics.ScopePod},
-	{Name: "aws_ec2_instance_store_csi_read_io_latency_seconds", MetricType: "histogram", Scope: otelmetrics.ScopePod},
-	{Name: "aws_ec2_instance_store_csi_write_io_latency_seconds", MetricType: "histogram", Scope: otelmetrics.ScopePod},
-}
+var lisCsiVolumeMetrics = otelmetrics.MetricsWithScope(metricCatalog.Metrics(suiteName, otelmetrics.SourceLISCSI), otelmetrics.ScopePod)
 
 // lisCsiCollectorMetrics are collector-internal metrics that do not carry volume_id/instance_id.
-var lisCsiCollectorMetrics = []otelmetrics.MetricDefinition{
-	{Name: "aws_ec2_instance_store_csi_nvme_collector_errors_total", MetricType: "counter", Scope: otelmetrics.ScopeNode},
-	{Name: "aws_ec2_instance_store_csi_nvme_collector_scrapes_total", MetricType: "counter", Scope: otelmetrics.ScopeNode},
-}
+var lisCsiCollectorMetrics = otelmetrics.MetricsWithScope(metricCatalog.Metrics(suiteName, otelmetrics.SourceLISCSI), otelmetrics.ScopeNode)
 
 func lisCsiMetricNames() []string {
 	all := append(lisCsiVolumeMetrics, lisCsiCollectorMetrics...)
@@ -28,7 +28,7 @@ var (
 
 const scopePrometheus = "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusreceiver"
 
-var clusterHostTypes = []string{"i7i.xlarge"}
+var clusterHostTypes = metricCatalog.HostTypes(suiteName)
 
 func TestMain(m *testing.M) {
 	environment.RegisterEnvironmentMetaDataFlags()
@@ -83,20 +83,12 @@ func TestMain(m *testing.M) {
 		os.Exit(1)
 	}
 
-	hostMappings := []otelmetrics.SourceHostMapping{
-		{Source: otelmetrics.SourceNodeExporter, HostTypes: clusterHostTypes},
-		{Source: otelmetrics.SourceCadvisor, HostTypes: clusterHostTypes},
-		{Source: otelmetrics.SourceKubeletstats, HostTypes: clusterHostTypes},
-		{Source: otelmetrics.SourceControlPlane, HostTypes: nil},
-		{Source: otelmetrics.SourceKubeStateMetrics, HostTypes: nil},
-		{Source: otelmetrics.SourceKSMNodeScoped, HostTypes: nil},
-		{Source: otelmetrics.SourceLISCSI, HostTypes: nil},
+	registry, err := metricCatalog.SourceRegistry(suiteName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Source registry error: %v\n", err)
+		os.Exit(1)
 	}
 
-	registry := otelmetrics.NewSourceRegistry(clusterHostTypes, hostMappings,
-		otelmetrics.SourceMapping{Source: otelmetrics.SourceLISCSI, Metrics: append(lisCsiVolumeMetrics, lisCsiCollectorMetrics...)},
-	)
-
 	queryCache = otelmetrics.NewQueryCache(client, cfg.ClusterName,
 		otelmetrics.WithHostTypes(clusterHostTypes),
 		otelmetrics.WithSourceRegistry(registry),
@@ -7,12 +7,12 @@ package multi_efa
 
 import "github.com/aws/amazon-cloudwatch-agent-test/util/otelmetrics"
 
-var efaMetrics = []otelmetrics.MetricDefinition{
-	{Name: "efa_rx_bytes", MetricType: "counter", Scope: otelmetrics.ScopePod, Unit: "By"},
-	{Name: "efa_tx_bytes", MetricType: "counter", Scope: otelmetrics.ScopePod, Unit: "By"},
-	{Name: "efa_rx_dropped", MetricType: "counter", Scope: otelmetrics.ScopePod},
-	{Name: "efa_rdma_read_bytes", MetricType: "counter", Scope: otelmetrics.ScopePod, Unit: "By"},
-}
+// metricCatalog holds the metric definitions, see util/otelmetrics/catalog.yaml.
+var metricCatalog = otelmetrics.DefaultCatalog()
+
+const suiteName = "multi_efa"
+
+var efaMetrics = metricCatalog.Metrics(suiteName, otelmetrics.SourceEFA)
 
 var efaMetricNamesList = metricNames(efaMetrics)
 
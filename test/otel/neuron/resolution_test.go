@@ -22,17 +22,15 @@ import (
 const (
 	expectedNeuronResolution     = 30 * time.Second
 	neuronResolutionInstanceType = "inf2.xlarge"
+	resolutionWindow             = 5 * time.Minute
 )
 
 // TestNeuronResolution validates neuroncore_utilization_ratio is scraped at
-// ~30s intervals on the workload inf2.xlarge node.
+// ~30s intervals on the workload inf2.xlarge node, from the spacing of its raw
+// samples over 5 minutes.
 func TestNeuronResolution(t *testing.T) {
 	t.Parallel()
-	end := time.Now()
-	start := end.Add(-5 * time.Minute)
-	step := expectedNeuronResolution
-
-	expectedSamples := int(5*time.Minute/expectedNeuronResolution) + 1 // 11 for 30s
+	expectedSamples := int(resolutionWindow / expectedNeuronResolution) // 10 for 30s
 	minSamples := expectedSamples / 2
 
 	escaped := escapePromQL(cfg.ClusterName)
@@ -40,23 +38,21 @@ func TestNeuronResolution(t *testing.T) {
 		`neuroncore_utilization_ratio{"@resource.k8s.cluster.name"="%s","@resource.host.type"="%s"}`,
 		escaped, neuronResolutionInstanceType)
 
-	results, err := client.QueryRange(context.Background(), promql, start, end, step)
-	require.NoError(t, err, "range querying neuroncore_utilization_ratio")
-	require.True(t, len(results) > 0,
-		"No neuroncore_utilization_ratio range results from %s", neuronResolutionInstanceType)
-
-	// Pick the series with the most samples (best coverage).
-	var bestSeries *otelmetrics.RangeResult
-	for i := range results {
-		if bestSeries == nil || len(results[i].Timestamps) > len(bestSeries.Timestamps) {
-			bestSeries = &results[i]
-		}
-	}
+	results, err := client.QuerySamples(context.Background(), promql, resolutionWindow, time.Now())
+	require.NoError(t, err, "querying the samples of neuroncore_utilization_ratio")
+	require.True(t, len(results) > 0, "No neuroncore_utilization_ratio samples from %s", neuronResolutionInstanceType)
 
+	// Check the series with the most samples (best coverage).
+	best := otelmetrics.MostSamples(results)
 	t.Logf("neuroncore_utilization_ratio: %d samples in 5-minute window (expected ~%d for %v resolution)",
-		len(bestSeries.Timestamps), expectedSamples, expectedNeuronResolution)
-
-	require.True(t, len(bestSeries.Timestamps) >= minSamples,
-		"neuroncore_utilization_ratio: got %d samples, expected at least %d for %v resolution",
-		len(bestSeries.Timestamps), minSamples, expectedNeuronResolution)
+		len(best.Timestamps), expectedSamples, expectedNeuronResolution)
+
+	// Tolerate a single gap of up to two missed collections, like a
+	// delayed batch, but no duplicates or repeated gaps
+	err = otelmetrics.ExpectRange(best).
+		SampleCountBetween(minSamples, 2*expectedSamples).
+		SpacingWithin(expectedNeuronResolution/2, 3*expectedNeuronResolution).
+		GapsAtMost(1, 2*expectedNeuronResolution).
+		Err()
+	require.NoError(t, err, "neuroncore_utilization_ratio is not collected at %v resolution", expectedNeuronResolution)
 }
@@ -7,8 +7,13 @@ package standard
 
 import "github.com/aws/amazon-cloudwatch-agent-test/util/otelmetrics"
 
+// metricCatalog holds the metric definitions, see util/otelmetrics/catalog.yaml.
+var metricCatalog = otelmetrics.DefaultCatalog()
+
+const suiteName = "standard"
+
 // Instance types in the standard cluster.
-var clusterHostTypes = []string{"t3.medium"}
+var clusterHostTypes = metricCatalog.HostTypes(suiteName)
 
 var clusterNodeGroups = []struct {
 	InstanceType string
@@ -19,45 +24,15 @@ var clusterNodeGroups = []struct {
 
 // --- Metric definitions (standard cluster only) ---
 
-var nodeExporterMetrics = []otelmetrics.MetricDefinition{
-	{Name: "node_cpu_seconds_total", MetricType: "counter", Scope: otelmetrics.ScopeNode, ExpectedLabels: []string{"cpu", "mode"}, Unit: "s"},
-	{Name: "node_memory_MemAvailable_bytes", MetricType: "gauge", Scope: otelmetrics.ScopeNode, Unit: "By"},
-	{Name: "node_filesystem_avail_bytes", MetricType: "gauge", Scope: otelmetrics.ScopeNode, ExpectedLabels: []string{"device", "mountpoint", "fstype"}, Unit: "By"},
-	{Name: "node_network_receive_bytes_total", MetricType: "counter", Scope: otelmetrics.ScopeNode, ExpectedLabels: []string{"device"}, Unit: "By"},
-	{Name: "node_load1", MetricType: "gauge", Scope: otelmetrics.ScopeNode},
-}
+var nodeExporterMetrics = metricCatalog.Metrics(suiteName, otelmetrics.SourceNodeExporter)
 
-var cadvisorMetrics = []otelmetrics.MetricDefinition{
-	{Name: "container_cpu_usage_seconds_total", MetricType: "counter", Scope: otelmetrics.ScopeContainer, ExpectedLabels: []string{"cpu"}, Unit: "s"},
-	{Name: "container_memory_working_set_bytes", MetricType: "gauge", Scope: otelmetrics.ScopeContainer, Unit: "By"},
-	{Name: "container_memory_usage_bytes", MetricType: "gauge", Scope: otelmetrics.ScopeContainer, Unit: "By"},
-	{Name: "container_network_receive_bytes_total", MetricType: "counter", Scope: otelmetrics.ScopePod, ExpectedLabels: []string{"interface"}, Unit: "By"},
-}
+var cadvisorMetrics = metricCatalog.Metrics(suiteName, otelmetrics.SourceCadvisor)
 
-var kubeletstatsNodeMetrics = []otelmetrics.MetricDefinition{
-	{Name: "k8s.node.cpu.utilization", MetricType: "gauge", Scope: otelmetrics.ScopeNode, Unit: "1"},
-	{Name: "k8s.node.memory.working_set", MetricType: "gauge", Scope: otelmetrics.ScopeNode, Unit: "By"},
-	{Name: "k8s.node.filesystem.available", MetricType: "gauge", Scope: otelmetrics.ScopeNode, Unit: "By"},
-	// k8s.node.network.io is not emitted on EKS AL2023 + kubelet 1.35: kubelet's
-	// /stats/summary returns no top-level default-interface aggregate, and the
-	// kubeletstatsreceiver only emits this metric from the top-level fields.
-	// Fixed upstream by collect_all_network_interfaces (contrib v0.131+, PR
-	// open-telemetry/opentelemetry-collector-contrib#38737); the agent currently
-	// pins contrib v0.124.1, so re-enable once the contrib bump lands.
-	// {Name: "k8s.node.network.io", MetricType: "counter", Scope: otelmetrics.ScopeNode, ExpectedLabels: []string{"interface", "direction"}, Unit: "By"},
-}
+var kubeletstatsNodeMetrics = otelmetrics.MetricsWithScope(metricCatalog.Metrics(suiteName, otelmetrics.SourceKubeletstats), otelmetrics.ScopeNode)
 
-var kubeletstatsPodMetrics = []otelmetrics.MetricDefinition{
-	{Name: "k8s.pod.cpu.utilization", MetricType: "gauge", Scope: otelmetrics.ScopePod, Unit: "1"},
-	{Name: "k8s.pod.memory.working_set", MetricType: "gauge", Scope: otelmetrics.ScopePod, Unit: "By"},
-	{Name: "k8s.pod.network.io", MetricType: "counter", Scope: otelmetrics.ScopePod, ExpectedLabels: []string{"interface", "direction"}, Unit: "By"},
-}
+var kubeletstatsPodMetrics = otelmetrics.MetricsWithScope(metricCatalog.Metrics(suiteName, otelmetrics.SourceKubeletstats), otelmetrics.ScopePod)
 
-var kubeletstatsContainerMetrics = []otelmetrics.MetricDefinition{
-	{Name: "container.cpu.utilization", MetricType: "gauge", Scope: otelmetrics.ScopeContainer, Unit: "1"},
-	{Name: "container.memory.working_set", MetricType: "gauge", Scope: otelmetrics.ScopeContainer, Unit: "By"},
-	{Name: "container.memory.usage", MetricType: "gauge", Scope: otelmetrics.ScopeContainer, Unit: "By"},
-}
+var kubeletstatsContainerMetrics = otelmetrics.MetricsWithScope(metricCatalog.Metrics(suiteName, otelmetrics.SourceKubeletstats), otelmetrics.ScopeContainer)
 
 var kubeletstatsMetrics = func() []otelmetrics.MetricDefinition {
 	var all []otelmetrics.MetricDefinition
@@ -67,32 +42,11 @@ var kubeletstatsMetrics = func() []otelmetrics.MetricDefinition {
 	return all
 }()
 
-var controlPlaneMetrics = []otelmetrics.MetricDefinition{
-	{Name: "apiserver_request_total", MetricType: "counter", Scope: otelmetrics.ScopeCluster, ExpectedLabels: []string{"verb", "code"}, Unit: "1"},
-	{Name: "apiserver_request_duration_seconds", MetricType: "histogram", Scope: otelmetrics.ScopeCluster, ExpectedLabels: []string{"verb"}, Unit: "s"},
-	{Name: "rest_client_requests_total", MetricType: "counter", Scope: otelmetrics.ScopeCluster, ExpectedLabels: []string{"code", "method"}, Unit: "1"},
-	{Name: "apiserver_current_inflight_requests", MetricType: "gauge", Scope: otelmetrics.ScopeCluster, ExpectedLabels: []string{"request_kind"}},
-}
+var controlPlaneMetrics = metricCatalog.Metrics(suiteName, otelmetrics.SourceControlPlane)
 
-var ksmNodeScopedMetrics = []otelmetrics.MetricDefinition{
-	{Name: "kube_node_status_condition", MetricType: "gauge", Scope: otelmetrics.ScopeCluster, ExpectedLabels: []string{"condition", "status"}},
-	{Name: "kube_node_info", MetricType: "gauge", Scope: otelmetrics.ScopeCluster},
-	{Name: "kube_node_status_allocatable", MetricType: "gauge", Scope: otelmetrics.ScopeCluster, ExpectedLabels: []string{"resource", "unit"}},
-	{Name: "kube_node_status_capacity", MetricType: "gauge", Scope: otelmetrics.ScopeCluster, ExpectedLabels: []string{"resource", "unit"}},
-	{Name: "kube_pod_status_phase", MetricType: "gauge", Scope: otelmetrics.ScopeCluster, ExpectedLabels: []string{"phase"}},
-	{Name: "kube_pod_container_status_running", MetricType: "gauge", Scope: otelmetrics.ScopeCluster},
-}
+var ksmNodeScopedMetrics = metricCatalog.Metrics(suiteName, otelmetrics.SourceKSMNodeScoped)
 
-var ksmClusterScopedMetrics = []otelmetrics.MetricDefinition{
-	{Name: "kube_deployment_status_replicas_ready", MetricType: "gauge", Scope: otelmetrics.ScopeCluster},
-	{Name: "kube_deployment_status_replicas", MetricType: "gauge", Scope: otelmetrics.ScopeCluster},
-	{Name: "kube_daemonset_status_desired_number_scheduled", MetricType: "gauge", Scope: otelmetrics.ScopeCluster},
-	{Name: "kube_statefulset_replicas", MetricType: "gauge", Scope: otelmetrics.ScopeCluster},
-	{Name: "kube_statefulset_status_replicas_ready", MetricType: "gauge", Scope: otelmetrics.ScopeCluster},
-	{Name: "kube_job_status_active", MetricType: "gauge", Scope: otelmetrics.ScopeCluster},
-	{Name: "kube_cronjob_status_active", MetricType: "gauge", Scope: otelmetrics.ScopeCluster},
-	{Name: "kube_namespace_status_phase", MetricType: "gauge", Scope: otelmetrics.ScopeCluster, ExpectedLabels: []string{"phase"}},
-}
+var ksmClusterScopedMetrics = metricCatalog.Metrics(suiteName, otelmetrics.SourceKubeStateMetrics)
 
 // --- Aggregate slices ---
 
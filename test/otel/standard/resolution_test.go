@@ -20,7 +20,10 @@ import (
 	"github.com/aws/amazon-cloudwatch-agent-test/util/otelmetrics"
 )
 
-const expectedResolution = 30 * time.Second
+const (
+	expectedResolution = 30 * time.Second
+	resolutionWindow   = 5 * time.Minute
+)
 
 var resolutionTestMetrics = []struct {
 	name     string
@@ -31,15 +34,12 @@ var resolutionTestMetrics = []struct {
 	{"container_cpu_usage_seconds_total", "cadvisor", "t3.medium"},
 }
 
-// TestMetricResolution validates that metrics are scraped at ~30s intervals by
-// querying a 5-minute range and checking sample counts.
+// TestMetricResolution validates that metrics are scraped at ~30s intervals
+// from the spacing of their raw samples over 5 minutes.
 func TestMetricResolution(t *testing.T) {
 	t.Parallel()
 	end := time.Now()
-	start := end.Add(-5 * time.Minute)
-	step := expectedResolution
-
-	expectedSamples := int(5*time.Minute/expectedResolution) + 1 // 11 for 30s
+	expectedSamples := int(resolutionWindow / expectedResolution) // 10 for 30s
 	minSamples := expectedSamples / 2
 
 	for _, tm := range resolutionTestMetrics {
@@ -50,23 +50,22 @@ func TestMetricResolution(t *testing.T) {
 			promql := fmt.Sprintf(`%s{"@resource.k8s.cluster.name"="%s","@resource.host.type"="%s"}`,
 				tm.name, escaped, tm.hostType)
 
-			results, err := client.QueryRange(context.Background(), promql, start, end, step)
-			require.NoError(t, err, "range querying %s", tm.name)
-			require.True(t, len(results) > 0, "No %s range results", tm.name)
-
-			var bestSeries *otelmetrics.RangeResult
-			for i := range results {
-				if bestSeries == nil || len(results[i].Timestamps) > len(bestSeries.Timestamps) {
-					bestSeries = &results[i]
-				}
-			}
+			results, err := client.QuerySamples(context.Background(), promql, resolutionWindow, end)
+			require.NoError(t, err, "querying the samples of %s", tm.name)
+			require.True(t, len(results) > 0, "No %s samples", tm.name)
 
+			best := otelmetrics.MostSamples(results)
 			t.Logf("%s: %d samples in 5-minute window (expected ~%d for %v resolution)",
-				tm.name, len(bestSeries.Timestamps), expectedSamples, expectedResolution)
+				tm.name, len(best.Timestamps), expectedSamples, expectedResolution)
 
-			require.True(t, len(bestSeries.Timestamps) >= minSamples,
-				"%s: got %d samples, expected at least %d for %v resolution",
-				tm.name, len(bestSeries.Timestamps), minSamples, expectedResolution)
+			// Tolerate a single gap of up to two missed collections, like a
+			// delayed batch, but no duplicates or repeated gaps
+			err = otelmetrics.ExpectRange(best).
+				SampleCountBetween(minSamples, 2*expectedSamples).
+				SpacingWithin(expectedResolution/2, 3*expectedResolution).
+				GapsAtMost(1, 2*expectedResolution).
+				Err()
+			require.NoError(t, err, "%s is not scraped at %v resolution", tm.name, expectedResolution)
 		})
 	}
 }
@@ -0,0 +1,74 @@
+//go:build integration
+
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+// Temporal tests check the raw samples of a metric over a window, catching
+// intermittent pipeline issues (restarts, dropped batches) that the instant
+// queries of the other tests miss.
+
+package standard
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/otelmetrics"
+)
+
+const temporalWindow = 10 * time.Minute
+
+func temporalSelector(metricName string) string {
+	return fmt.Sprintf(`{"__name__"="%s","@resource.k8s.cluster.name"="%s"}`,
+		metricName, otelmetrics.EscapePromQLValue(cfg.ClusterName))
+}
+
+// TestNodeExporterCountersDoNotReset validates the node_exporter counters
+// never decrease: node_exporter restarts and out-of-order samples show up as
+// counter resets.
+func TestNodeExporterCountersDoNotReset(t *testing.T) {
+	t.Parallel()
+	end := time.Now()
+	for _, md := range nodeExporterMetrics {
+		if md.MetricType != "counter" {
+			continue
+		}
+		md := md
+		t.Run(md.Name, func(t *testing.T) {
+			t.Parallel()
+			results, err := client.QuerySamples(context.Background(), temporalSelector(md.Name), temporalWindow, end)
+			require.NoError(t, err, "querying the samples of %s", md.Name)
+			require.NotEmpty(t, results, "No %s samples", md.Name)
+			for _, r := range results {
+				require.NoError(t, otelmetrics.ExpectRange(r).ResetsAtMost(0).Err())
+			}
+		})
+	}
+}
+
+// TestNodeSeriesNotStale validates every node series seen in the window is
+// still collected: a series whose last sample is two collection intervals
+// older than the newest sample of the metric went stale while its node kept
+// running. Comparing with the newest sample rather than the query time leaves
+// out the export and ingestion lag every series shares.
+func TestNodeSeriesNotStale(t *testing.T) {
+	t.Parallel()
+	end := time.Now()
+	for _, md := range kubeletstatsNodeMetrics {
+		md := md
+		t.Run(md.Name, func(t *testing.T) {
+			t.Parallel()
+			results, err := client.QuerySamples(context.Background(), temporalSelector(md.Name), temporalWindow, end)
+			require.NoError(t, err, "querying the samples of %s", md.Name)
+			require.NotEmpty(t, results, "No %s samples", md.Name)
+			latest := otelmetrics.LatestSample(results)
+			for _, r := range otelmetrics.StaleSeries(results, latest, 2*expectedResolution) {
+				t.Errorf("%s series went stale:\n%v", md.Name, otelmetrics.ExpectRange(r).FreshAt(latest, 2*expectedResolution).Err())
+			}
+		})
+	}
+}
@@ -31,7 +31,7 @@ func TestEKSPerformance(t *testing.T) {
 		dimensions := GetMetricDimensions(metric, env)
 
 		log.Printf("Fetching metric from CloudWatch : %v", metric)
-		testResults = append(testResults, ValidatePerformanceMetrics(metric.Name, metric.Threshold, metric.Statistic, dimensions))
+		testResults = append(testResults, ValidatePerformanceMetrics(metric.Name, metric.Threshold, metric.Statistic, metric.Aggregate, dimensions))
 	}
 
 	res := status.TestGroupResult{
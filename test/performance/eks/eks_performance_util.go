@@ -18,8 +18,12 @@ import (
 	"github.com/aws/amazon-cloudwatch-agent-test/environment"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/summary"
 )
 
+// performanceErrorBound is how far (+/- 15%) the aggregate of the values can be from the threshold
+const performanceErrorBound = 0.15
+
 // PerformanceMetrics represents a collection of performance metrics and their associated dimensions
 type PerformanceMetrics struct {
 	Metrics []Metric `json:"metrics"`
@@ -31,6 +35,9 @@ type Metric struct {
 	Dimensions map[string]string `json:"dimensions"`
 	Threshold  float64           `json:"threshold"`
 	Statistic  string            `json:"stat"`
+	// Aggregate is how the fetched values are compared to the threshold, either their average (the
+	// default) or a percentile of them (e.g p90 or p99.9)
+	Aggregate string `json:"aggregate,omitempty"`
 }
 
 // GetEKSPerformanceMetrics - Gets desired EKS performance metrics based on json file
@@ -66,8 +73,9 @@ func GetMetricDimensions(metric Metric, env *environment.MetaData) []types.Dimen
 	return dimensions
 }
 
-// ValidatePerformanceMetrics - Validates that the metric exists and is within the expected threshold (+/- 15%)
-func ValidatePerformanceMetrics(name string, threshold float64, stat string, dimensions []types.Dimension) status.TestResult {
+// ValidatePerformanceMetrics - Validates that the metric exists and that the aggregate of its values (the average by
+// default, or a percentile such as p99) is within the expected threshold (+/- 15%)
+func ValidatePerformanceMetrics(name string, threshold float64, stat string, aggregate string, dimensions []types.Dimension) status.TestResult {
 	testResult := status.TestResult{
 		Name:   name,
 		Status: status.FAILED,
@@ -81,10 +89,49 @@ func ValidatePerformanceMetrics(name string, threshold float64, stat string, dim
 		return testResult
 	}
 
-	if !metric.IsAllValuesGreaterThanOrEqualToExpectedValue(name, values, threshold) {
+	if s, err := summary.Summarize(values, summary.DefaultMethod); err == nil {
+		log.Printf("Summary of %d values for metric %s: mean %f, min %f, max %f, std %f, quantiles %v",
+			s.Count, name, s.Mean, s.Min, s.Max, s.Std, s.Quantiles)
+	}
+
+	if aggregate == "" || aggregate == "average" {
+		if !metric.IsAllValuesGreaterThanOrEqualToExpectedValue(name, values, threshold) {
+			return testResult
+		}
+	} else if err = validatePercentile(name, values, threshold, aggregate); err != nil {
+		log.Println(err)
 		return testResult
 	}
 
 	testResult.Status = status.SUCCESSFUL
 	return testResult
 }
+
+// validatePercentile checks that the values are not negative and that their percentile is within the threshold
+// (+/- 15%) when the threshold is positive
+func validatePercentile(name string, values []float64, threshold float64, percentile string) error {
+	q, err := summary.ParseQuantile(percentile)
+	if err != nil {
+		return fmt.Errorf("invalid aggregate for metric %s: %v", name, err)
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("No values found %v", name)
+	}
+	for _, value := range values {
+		if value < 0 && threshold >= 0 {
+			return fmt.Errorf("Values are not all greater than or equal to zero for %s", name)
+		}
+	}
+	value, err := summary.Quantile(values, q, summary.DefaultMethod)
+	if err != nil {
+		return err
+	}
+	upperBoundValue := threshold * (1 + performanceErrorBound)
+	lowerBoundValue := threshold * (1 - performanceErrorBound)
+	if threshold > 0 && (value > upperBoundValue || value < lowerBoundValue) {
+		return fmt.Errorf("The %s value %f for metric %s is not within bound [%f, %f]",
+			percentile, value, name, lowerBoundValue, upperBoundValue)
+	}
+	log.Printf("The %s value %f for metric %s is within bound [%f, %f]", percentile, value, name, lowerBoundValue, upperBoundValue)
+	return nil
+}
@@ -0,0 +1,207 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package status
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+)
+
+// Report is the structured form of one or more test suite results, written as JSON or JUnit XML so CI
+// dashboards can ingest results without scraping the logs.
+type Report struct {
+	GeneratedAt    time.Time             `json:"generated_at"`
+	AgentCommitSha string                `json:"agent_commit_sha,omitempty"`
+	Environment    *environment.MetaData `json:"environment,omitempty"`
+	Suites         []SuiteReport         `json:"suites"`
+}
+
+type SuiteReport struct {
+	Name            string        `json:"name"`
+	Status          TestStatus    `json:"status"`
+	DurationSeconds float64       `json:"duration_seconds"`
+	Groups          []GroupReport `json:"groups"`
+}
+
+type GroupReport struct {
+	Name            string       `json:"name"`
+	Status          TestStatus   `json:"status"`
+	DurationSeconds float64      `json:"duration_seconds"`
+	Tests           []TestReport `json:"tests"`
+}
+
+type TestReport struct {
+	Name            string     `json:"name"`
+	Status          TestStatus `json:"status"`
+	Reason          string     `json:"reason,omitempty"`
+	DurationSeconds float64    `json:"duration_seconds"`
+}
+
+// NewReport builds a report of the suites. The environment metadata is optional.
+func NewReport(env *environment.MetaData, suites ...TestSuiteResult) Report {
+	report := Report{
+		GeneratedAt: time.Now().UTC(),
+		Environment: env,
+		Suites:      make([]SuiteReport, 0, len(suites)),
+	}
+	if env != nil {
+		report.AgentCommitSha = env.CwaCommitSha
+	}
+	for _, suite := range suites {
+		suiteReport := SuiteReport{
+			Name:            suite.Name,
+			Status:          suite.GetStatus(),
+			DurationSeconds: suite.GetDuration().Seconds(),
+			Groups:          make([]GroupReport, 0, len(suite.TestGroupResults)),
+		}
+		for _, group := range suite.TestGroupResults {
+			groupReport := GroupReport{
+				Name:            group.Name,
+				Status:          group.GetStatus(),
+				DurationSeconds: group.Duration.Seconds(),
+				Tests:           make([]TestReport, 0, len(group.TestResults)),
+			}
+			for _, result := range group.TestResults {
+				testReport := TestReport{
+					Name:            result.Name,
+					Status:          result.Status,
+					DurationSeconds: result.Duration.Seconds(),
+				}
+				if result.Reason != nil {
+					testReport.Reason = result.Reason.Error()
+				}
+				groupReport.Tests = append(groupReport.Tests, testReport)
+			}
+			suiteReport.Groups = append(suiteReport.Groups, groupReport)
+		}
+		report.Suites = append(report.Suites, suiteReport)
+	}
+	return report
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr,omitempty"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as JUnit XML. Each test group becomes a <testsuite> named
+// "<suite>/<group>" and each test result a <testcase>.
+func (r Report) WriteJUnit(w io.Writer) error {
+	root := junitTestSuites{}
+	if len(r.Suites) == 1 {
+		root.Name = r.Suites[0].Name
+	}
+	var total float64
+	for _, suite := range r.Suites {
+		for _, group := range suite.Groups {
+			junitSuite := junitTestSuite{
+				Name:       group.Name,
+				Time:       formatSeconds(group.DurationSeconds),
+				Timestamp:  r.GeneratedAt.Format("2006-01-02T15:04:05"),
+				Properties: r.junitProperties(),
+			}
+			if suite.Name != "" {
+				junitSuite.Name = suite.Name + "/" + group.Name
+			}
+			for _, test := range group.Tests {
+				testCase := junitTestCase{
+					Name:      test.Name,
+					ClassName: junitSuite.Name,
+					Time:      formatSeconds(test.DurationSeconds),
+				}
+				if test.Status == FAILED {
+					testCase.Failure = &junitFailure{Message: test.Reason, Type: string(FAILED), Text: test.Reason}
+					junitSuite.Failures++
+				}
+				junitSuite.TestCases = append(junitSuite.TestCases, testCase)
+			}
+			junitSuite.Tests = len(junitSuite.TestCases)
+			root.Tests += junitSuite.Tests
+			root.Failures += junitSuite.Failures
+			root.Suites = append(root.Suites, junitSuite)
+		}
+		total += suite.DurationSeconds
+	}
+	root.Time = formatSeconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(root); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func (r Report) junitProperties() []junitProperty {
+	var properties []junitProperty
+	add := func(name, value string) {
+		if value != "" {
+			properties = append(properties, junitProperty{Name: name, Value: value})
+		}
+	}
+	add("agent_commit_sha", r.AgentCommitSha)
+	if r.Environment != nil {
+		add("compute_type", string(r.Environment.ComputeType))
+		add("region", r.Environment.Region)
+		add("instance_id", r.Environment.InstanceId)
+		add("instance_platform", r.Environment.InstancePlatform)
+		add("eks_cluster_name", r.Environment.EKSClusterName)
+		add("ecs_cluster_arn", r.Environment.EcsClusterArn)
+	}
+	return properties
+}
+
+func formatSeconds(seconds float64) string {
+	return fmt.Sprintf("%.3f", seconds)
+}
@@ -0,0 +1,83 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package status
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/environment/computetype"
+)
+
+func testSuiteResult() TestSuiteResult {
+	return TestSuiteResult{
+		Name: "MetricBenchmarkTestSuite",
+		TestGroupResults: []TestGroupResult{
+			{
+				Name:     "CPU",
+				Duration: 90 * time.Second,
+				TestResults: []TestResult{
+					{Name: "cpu_time_active", Status: SUCCESSFUL},
+					{Name: "cpu_time_idle", Status: FAILED, Reason: errors.New("no datapoints")},
+				},
+			},
+			{
+				Name:        "Disk",
+				Duration:    30 * time.Second,
+				TestResults: []TestResult{{Name: "disk_free", Status: SUCCESSFUL, Duration: time.Second}},
+			},
+		},
+	}
+}
+
+func TestReportJSON(t *testing.T) {
+	env := &environment.MetaData{ComputeType: computetype.EC2, CwaCommitSha: "abc123"}
+	var buf bytes.Buffer
+	require.NoError(t, NewReport(env, testSuiteResult()).WriteJSON(&buf))
+
+	var report Report
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+	assert.Equal(t, "abc123", report.AgentCommitSha)
+	assert.Equal(t, computetype.EC2, report.Environment.ComputeType)
+	require.Len(t, report.Suites, 1)
+	suite := report.Suites[0]
+	assert.Equal(t, FAILED, suite.Status)
+	assert.Equal(t, 120.0, suite.DurationSeconds)
+	require.Len(t, suite.Groups, 2)
+	assert.Equal(t, "no datapoints", suite.Groups[0].Tests[1].Reason)
+	assert.Equal(t, SUCCESSFUL, suite.Groups[1].Status)
+}
+
+func TestReportJUnit(t *testing.T) {
+	env := &environment.MetaData{ComputeType: computetype.EC2, CwaCommitSha: "abc123"}
+	var buf bytes.Buffer
+	require.NoError(t, NewReport(env, testSuiteResult()).WriteJUnit(&buf))
+
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &suites))
+	assert.Equal(t, "MetricBenchmarkTestSuite", suites.Name)
+	assert.Equal(t, 3, suites.Tests)
+	assert.Equal(t, 1, suites.Failures)
+	assert.Equal(t, "120.000", suites.Time)
+	require.Len(t, suites.Suites, 2)
+
+	cpu := suites.Suites[0]
+	assert.Equal(t, "MetricBenchmarkTestSuite/CPU", cpu.Name)
+	assert.Equal(t, 1, cpu.Failures)
+	assert.Contains(t, cpu.Properties, junitProperty{Name: "agent_commit_sha", Value: "abc123"})
+	assert.Nil(t, cpu.TestCases[0].Failure)
+	require.NotNil(t, cpu.TestCases[1].Failure)
+	assert.Equal(t, "no datapoints", cpu.TestCases[1].Failure.Message)
+	assert.Equal(t, "1.000", suites.Suites[1].TestCases[0].Time)
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"log"
 	"text/tabwriter"
+	"time"
 )
 
 type TestSuiteResult struct {
@@ -16,6 +17,15 @@ type TestSuiteResult struct {
 	TestGroupResults []TestGroupResult
 }
 
+// GetDuration returns the total time spent running the test groups of the suite.
+func (r TestSuiteResult) GetDuration() time.Duration {
+	var duration time.Duration
+	for _, result := range r.TestGroupResults {
+		duration += result.Duration
+	}
+	return duration
+}
+
 func (r TestSuiteResult) GetStatus() TestStatus {
 	for _, result := range r.TestGroupResults {
 		if result.GetStatus() == FAILED {
@@ -37,6 +47,8 @@ func (r TestSuiteResult) Print() {
 type TestGroupResult struct {
 	Name        string
 	TestResults []TestResult
+	// Duration is how long the test group ran, including starting the agent. Zero if not measured.
+	Duration time.Duration
 }
 
 func (r TestGroupResult) GetStatus() TestStatus {
@@ -67,4 +79,6 @@ type TestResult struct {
 	Name   string
 	Status TestStatus
 	Reason error
+	// Duration is how long the validation took. Zero if not measured.
+	Duration time.Duration
 }
@@ -115,6 +115,13 @@ func (t *BaseTestRunner) Cleanup() {
 }
 
 func (t *TestRunner) Run() status.TestGroupResult {
+	startTime := time.Now()
+	result := t.run()
+	result.Duration = time.Since(startTime)
+	return result
+}
+
+func (t *TestRunner) run() status.TestGroupResult {
 	defer t.TestRunner.Cleanup()
 	testName := t.TestRunner.GetTestName()
 	log.Printf("Running %v", testName)
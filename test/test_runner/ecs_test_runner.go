@@ -62,6 +62,7 @@ type ECSTestRunner struct {
 func (t *ECSTestRunner) Run(s ITestSuite, e *environment.MetaData) {
 	name := t.Runner.GetTestName()
 	log.Printf("Running %s", name)
+	startTime := time.Now()
 
 	//runs agent restart with given config only when it's available
 	agentConfigFileName := t.Runner.GetAgentConfigFileName()
@@ -75,8 +76,10 @@ func (t *ECSTestRunner) Run(s ITestSuite, e *environment.MetaData) {
 					{
 						Name:   "Starting Agent",
 						Status: status.FAILED,
+						Reason: err,
 					},
 				},
+				Duration: time.Since(startTime),
 			})
 			return
 		}
@@ -85,6 +88,7 @@ func (t *ECSTestRunner) Run(s ITestSuite, e *environment.MetaData) {
 	}
 
 	testGroupResult := t.Runner.Validate()
+	testGroupResult.Duration = time.Since(startTime)
 
 	s.AddToSuiteResult(testGroupResult)
 	if testGroupResult.GetStatus() != status.SUCCESSFUL {
@@ -0,0 +1,58 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package test_runner
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+)
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// ExportSuiteResult writes <name>.junit.xml and <name>.json for the suite result into the -resultsDir
+// directory. It does nothing if -resultsDir is not set. The name is used when the result has none.
+func ExportSuiteResult(name string, result status.TestSuiteResult) error {
+	env := environment.GetEnvironmentMetaData()
+	if env.ResultsDirectory == "" {
+		return nil
+	}
+	if result.Name == "" {
+		result.Name = name
+	}
+	if err := os.MkdirAll(env.ResultsDirectory, 0755); err != nil {
+		return fmt.Errorf("failed to create results directory %s: %w", env.ResultsDirectory, err)
+	}
+
+	report := status.NewReport(env, result)
+	baseName := filepath.Join(env.ResultsDirectory, unsafeFileNameChars.ReplaceAllString(result.Name, "_"))
+	if err := writeReportFile(baseName+".junit.xml", report.WriteJUnit); err != nil {
+		return err
+	}
+	if err := writeReportFile(baseName+".json", report.WriteJSON); err != nil {
+		return err
+	}
+	log.Printf("Wrote %s test reports to %s", result.Name, env.ResultsDirectory)
+	return nil
+}
+
+func writeReportFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report %s: %w", path, err)
+	}
+	defer f.Close()
+	if err := write(f); err != nil {
+		return fmt.Errorf("failed to write report %s: %w", path, err)
+	}
+	return nil
+}
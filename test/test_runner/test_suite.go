@@ -7,6 +7,7 @@ package test_runner
 
 import (
 	"fmt"
+	"log"
 
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
 )
@@ -30,6 +31,7 @@ func (suite *TestSuite) SetupSuite() {
 
 func (suite *TestSuite) TearDownSuite() {
 	suite.Result.Print()
+	suite.ExportResult(suite.GetSuiteName())
 	fmt.Printf(">>>> Finished %s TestSuite", suite.GetSuiteName())
 }
 
@@ -37,6 +39,13 @@ func (suite *TestSuite) GetSuiteName() string {
 	return "Base"
 }
 
+// ExportResult writes the suite result as JUnit XML and JSON when -resultsDir is set.
+func (suite *TestSuite) ExportResult(name string) {
+	if err := ExportSuiteResult(name, suite.Result); err != nil {
+		log.Printf("Failed to export %s test results: %v", name, err)
+	}
+}
+
 func (suite *TestSuite) AddToSuiteResult(r status.TestGroupResult) {
 	suite.Result.TestGroupResults = append(suite.Result.TestGroupResults, r)
 }
@@ -56,6 +56,26 @@ func ValidateMetric(metricName, namespace string, dimensionsFilter []types.Dimen
 	return nil
 }
 
+// ListMetrics returns the metrics recently published with the name in the namespace that have the dimensions
+// of the filter, among others.
+func ListMetrics(metricName, namespace string, dimensionsFilter []types.DimensionFilter) ([]types.Metric, error) {
+	var metrics []types.Metric
+	paginator := cloudwatch.NewListMetricsPaginator(CwmClient, &cloudwatch.ListMetricsInput{
+		MetricName:     aws.String(metricName),
+		Namespace:      aws.String(namespace),
+		RecentlyActive: "PT3H",
+		Dimensions:     dimensionsFilter,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing metric %s in %s: %w", metricName, namespace, err)
+		}
+		metrics = append(metrics, page.Metrics...)
+	}
+	return metrics, nil
+}
+
 // ValidateMetricWithTest takes the metric name, metric dimension and corresponding namespace that contains the metric
 func ValidateMetricWithTest(t *testing.T, metricName, namespace string, dimensionsFilter []types.DimensionFilter, retries int, retryTime time.Duration) {
 	var err error
@@ -93,3 +93,39 @@ func GetItemInDatabase(databaseName, indexName string, checkingAttribute, checki
 
 	return packets[0], nil
 }
+
+// QueryItemsInDatabase returns every item of the index whose hash key attribute equals the value, in
+// ascending order of the range key.
+func QueryItemsInDatabase(databaseName, indexName, attribute, value string) ([]map[string]interface{}, error) {
+	var (
+		packets           []map[string]interface{}
+		exclusiveStartKey map[string]types.AttributeValue
+	)
+	for {
+		data, err := DynamodbClient.Query(ctx, &dynamodb.QueryInput{
+			TableName:                aws.String(databaseName),
+			IndexName:                aws.String(indexName),
+			KeyConditionExpression:   aws.String("#attribute = :attribute"),
+			ExpressionAttributeNames: map[string]string{"#attribute": attribute},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":attribute": &types.AttributeValueMemberS{Value: value},
+			},
+			ScanIndexForward:  aws.Bool(true),
+			ExclusiveStartKey: exclusiveStartKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var page []map[string]interface{}
+		if err = attributevalue.UnmarshalListOfMaps(data.Items, &page); err != nil {
+			return nil, err
+		}
+		packets = append(packets, page...)
+
+		if len(data.LastEvaluatedKey) == 0 {
+			return packets, nil
+		}
+		exclusiveStartKey = data.LastEvaluatedKey
+	}
+}
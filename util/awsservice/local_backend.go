@@ -0,0 +1,46 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package awsservice
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/xray"
+)
+
+// ConfigureLocalBackend points the CloudWatch, CloudWatch Logs and X-Ray clients at a local backend
+// (see util/localbackend) instead of AWS. The instance ID is preset since there is no IMDS to ask when
+// running outside of EC2.
+func ConfigureLocalBackend(endpoint, region, instanceID string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	// The local backend does not verify signatures, but the clients need credentials to sign with.
+	awsCfg := aws.Config{
+		Region: region,
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "local", SecretAccessKey: "local", Source: "LocalBackend"}, nil
+		}),
+	}
+	CwmClient = cloudwatch.NewFromConfig(awsCfg, func(o *cloudwatch.Options) {
+		o.BaseEndpoint = aws.String(endpoint)
+	})
+	CwlClient = cloudwatchlogs.NewFromConfig(awsCfg, func(o *cloudwatchlogs.Options) {
+		o.BaseEndpoint = aws.String(endpoint)
+	})
+	XrayClient = xray.NewFromConfig(awsCfg, func(o *xray.Options) {
+		o.BaseEndpoint = aws.String(endpoint)
+	})
+
+	identityDoc = &imds.GetInstanceIdentityDocumentOutput{
+		InstanceIdentityDocument: imds.InstanceIdentityDocument{
+			InstanceID: instanceID,
+			Region:     region,
+		},
+	}
+}
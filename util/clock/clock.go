@@ -0,0 +1,195 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time and waiting for the validator and the load generators. Production code
+// uses Real, Accelerated shortens every wait for quicker manual runs, and Fake lets unit tests drive the
+// whole GenerateLoad -> CheckData -> Cleanup flow without waiting on the wall clock.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	After(d time.Duration) <-chan time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker mirrors time.Ticker behind an interface so fake clocks can deliver ticks.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+type realClock struct{}
+
+var _ Clock = realClock{}
+
+// Real returns a Clock backed by the time package.
+func Real() Clock {
+	return realClock{}
+}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) NewTicker(d time.Duration) Ticker       { return &realTicker{ticker: time.NewTicker(d)} }
+
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+func (t *realTicker) C() <-chan time.Time { return t.ticker.C }
+func (t *realTicker) Stop()               { t.ticker.Stop() }
+
+type acceleratedClock struct {
+	factor float64
+}
+
+var _ Clock = (*acceleratedClock)(nil)
+
+// Accelerated returns a Clock whose waits are factor times shorter than on the wall clock: a one minute
+// Sleep returns after 60/factor seconds. Now and the values delivered on After and ticker channels stay on
+// the wall clock, which the agent and the load generators stamp the data with. A factor of 1 or less
+// behaves like Real.
+func Accelerated(factor float64) Clock {
+	if factor <= 1 {
+		return Real()
+	}
+	return &acceleratedClock{factor: factor}
+}
+
+func (c *acceleratedClock) scale(d time.Duration) time.Duration {
+	return time.Duration(float64(d) / c.factor)
+}
+
+func (c *acceleratedClock) Now() time.Time { return time.Now() }
+
+func (c *acceleratedClock) Sleep(d time.Duration) { time.Sleep(c.scale(d)) }
+
+func (c *acceleratedClock) After(d time.Duration) <-chan time.Time { return time.After(c.scale(d)) }
+
+func (c *acceleratedClock) NewTicker(d time.Duration) Ticker {
+	return &realTicker{ticker: time.NewTicker(c.scale(d))}
+}
+
+// Fake is a manually driven Clock. Time only moves on Advance or Sleep; Sleep advances the clock by the
+// given duration and returns immediately, so code that only sleeps runs instantly. Timers and tickers
+// created from the clock fire as the time passes their deadlines. Like time.Ticker, a ticker that is not
+// drained drops ticks.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	slept   time.Duration
+	waiters []*fakeWaiter
+}
+
+var _ Clock = (*Fake)(nil)
+
+type fakeWaiter struct {
+	deadline time.Time
+	period   time.Duration
+	ch       chan time.Time
+}
+
+// NewFake creates a Fake clock starting at the given time.
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Sleep advances the clock by d without blocking.
+func (f *Fake) Sleep(d time.Duration) {
+	f.mu.Lock()
+	f.slept += d
+	f.mu.Unlock()
+	f.Advance(d)
+}
+
+// Slept returns the total duration passed to Sleep, which is how long the code under test would have
+// waited on a real clock.
+func (f *Fake) Slept() time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.slept
+}
+
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.addWaiter(d, 0).ch
+}
+
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for clock.Fake.NewTicker")
+	}
+	return &fakeTicker{clock: f, waiter: f.addWaiter(d, d)}
+}
+
+// Advance moves the clock forward by d, firing every timer and ticker whose deadline is reached in
+// deadline order.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	target := f.now.Add(d)
+	for len(f.waiters) > 0 {
+		sort.SliceStable(f.waiters, func(i, j int) bool {
+			return f.waiters[i].deadline.Before(f.waiters[j].deadline)
+		})
+		next := f.waiters[0]
+		if next.deadline.After(target) {
+			break
+		}
+		f.now = next.deadline
+		select {
+		case next.ch <- f.now:
+		default:
+		}
+		if next.period > 0 {
+			next.deadline = next.deadline.Add(next.period)
+		} else {
+			f.waiters = f.waiters[1:]
+		}
+	}
+	f.now = target
+}
+
+func (f *Fake) addWaiter(d, period time.Duration) *fakeWaiter {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w := &fakeWaiter{deadline: f.now.Add(d), period: period, ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		// Like time.After, a non-positive duration fires right away.
+		w.ch <- f.now
+		return w
+	}
+	f.waiters = append(f.waiters, w)
+	return w
+}
+
+func (f *Fake) removeWaiter(w *fakeWaiter) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, waiter := range f.waiters {
+		if waiter == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+type fakeTicker struct {
+	clock  *Fake
+	waiter *fakeWaiter
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.waiter.ch }
+func (t *fakeTicker) Stop()               { t.clock.removeWaiter(t.waiter) }
@@ -0,0 +1,57 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeAfterAndTicker(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := NewFake(start)
+	after := clk.After(90 * time.Second)
+	ticker := clk.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	clk.Advance(59 * time.Second)
+	assert.Empty(t, ticker.C())
+	assert.Empty(t, after)
+
+	clk.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Minute), <-ticker.C())
+	assert.Empty(t, after)
+
+	clk.Sleep(time.Minute)
+	assert.Equal(t, start.Add(90*time.Second), <-after)
+	assert.Equal(t, start.Add(2*time.Minute), <-ticker.C())
+	assert.Equal(t, start.Add(2*time.Minute), clk.Now())
+	assert.Equal(t, time.Minute, clk.Slept())
+}
+
+func TestFakeTickerDropsUndrainedTicks(t *testing.T) {
+	clk := NewFake(time.Unix(0, 0))
+	ticker := clk.NewTicker(time.Second)
+
+	clk.Advance(10 * time.Second)
+	assert.Equal(t, time.Unix(1, 0), <-ticker.C())
+	assert.Empty(t, ticker.C())
+
+	ticker.Stop()
+	clk.Advance(10 * time.Second)
+	assert.Empty(t, ticker.C())
+}
+
+func TestAccelerated(t *testing.T) {
+	assert.Equal(t, Real(), Accelerated(1))
+
+	clk := Accelerated(600)
+	before := time.Now()
+	clk.Sleep(time.Minute)
+	// The wait is scaled but Now stays on the wall clock
+	assert.Less(t, time.Since(before), time.Minute)
+	assert.WithinDuration(t, time.Now(), clk.Now(), time.Second)
+}
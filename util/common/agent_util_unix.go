@@ -215,6 +215,22 @@ func StopAgent() {
 	log.Printf("Agent is stopped")
 }
 
+// KillAgent kills the agent process with SIGKILL, leaving it no chance to flush its buffers or save its state.
+// systemd restarts a killed agent after its RestartSec, so StartAgent may find it running again.
+func KillAgent() error {
+	out, err := exec.
+		Command("bash", "-c", "sudo pkill -KILL -f '/opt/aws/amazon-cloudwatch-agent/bin/amazon-cloudwatch-agent( |$)'").
+		Output()
+
+	if err != nil {
+		log.Printf("Kill agent failed: %v; the output is: %s", err, string(out))
+		return err
+	}
+
+	log.Printf("Agent is killed")
+	return nil
+}
+
 func ReadAgentLogfile(logfile string) string {
 	out, err := os.ReadFile(logfile)
 	if err != nil {
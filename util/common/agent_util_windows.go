@@ -90,6 +90,19 @@ func StopAgent() error {
 	return nil
 }
 
+// KillAgent terminates the agent process forcefully, leaving it no chance to flush its buffers or save its state.
+func KillAgent() error {
+	out, err := exec.Command("taskkill", "/F", "/IM", "amazon-cloudwatch-agent.exe").Output()
+
+	if err != nil {
+		log.Printf("Kill agent failed: %v; the output is: %s", err, string(out))
+		return err
+	}
+
+	log.Printf("Agent is killed")
+	return nil
+}
+
 func RunShellScript(path string, args ...string) (string, error) {
 	ps, err := exec.LookPath("powershell.exe")
 	if err != nil {
@@ -0,0 +1,115 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build linux
+
+package common
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/filesystem"
+)
+
+const (
+	// AgentStateDir is where the agent saves the offset of every file it collects.
+	AgentStateDir = "/opt/aws/amazon-cloudwatch-agent/logs/state"
+
+	fillFileName = ".chaos_fill"
+)
+
+// MountTmpfs mounts a tmpfs of sizeBytes over the directory, hiding what it contained until Unmount. Anyone can
+// write to it so the agent can use it whatever its run_as_user.
+func MountTmpfs(dir string, sizeBytes int64) error {
+	log.Printf("Mounting a %d bytes tmpfs on %s", sizeBytes, dir)
+	if err := MkdirAll(dir); err != nil {
+		return err
+	}
+	cmd := fmt.Sprintf("sudo mount -t tmpfs -o size=%d,mode=0777 tmpfs %s", sizeBytes, dir)
+	if out, err := exec.Command("bash", "-c", cmd).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to mount tmpfs on %s: %w: %s", dir, err, out)
+	}
+	return nil
+}
+
+// Unmount lazily unmounts the directory, so it succeeds while the agent still has files open in it.
+func Unmount(dir string) error {
+	log.Printf("Unmounting %s", dir)
+	if out, err := exec.Command("bash", "-c", "sudo umount -l "+dir).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to unmount %s: %w: %s", dir, err, out)
+	}
+	return nil
+}
+
+// FillDir writes zeros to a file in the directory until its filesystem has no space left, and returns the file
+// to remove with DeleteFile to free the space.
+func FillDir(dir string) (string, error) {
+	fillPath := filepath.Join(dir, fillFileName)
+	log.Printf("Filling the filesystem of %s", dir)
+	out, err := exec.Command("bash", "-c", fmt.Sprintf("sudo dd if=/dev/zero of=%s bs=4096", fillPath)).CombinedOutput()
+	// dd only stops once the filesystem is full, anything else means it is not
+	if err == nil || !strings.Contains(string(out), "No space left on device") {
+		DeleteFile(fillPath)
+		return "", fmt.Errorf("failed to fill %s: %v: %s", dir, err, out)
+	}
+	return fillPath, nil
+}
+
+// ChmodPath changes the mode of the path and checks the change took effect.
+func ChmodPath(path string, mode os.FileMode) error {
+	log.Printf("Changing the mode of %s to %o", path, mode)
+	if out, err := exec.Command("bash", "-c", fmt.Sprintf("sudo chmod %o %s", mode.Perm(), path)).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to chmod %s: %w: %s", path, err, out)
+	}
+	actual, err := filesystem.GetFileStatPermission(path)
+	if err != nil {
+		return err
+	}
+	if os.FileMode(actual).Perm() != mode.Perm() {
+		return fmt.Errorf("mode of %s is %o after chmod, want %o", path, os.FileMode(actual).Perm(), mode.Perm())
+	}
+	return nil
+}
+
+// ChownPath changes the owner and group of the path to owner and checks the change took effect.
+func ChownPath(path string, owner string) error {
+	log.Printf("Changing the owner of %s to %s", path, owner)
+	if out, err := exec.Command("bash", "-c", fmt.Sprintf("sudo chown %s:%s %s", owner, owner, path)).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to chown %s: %w: %s", path, err, out)
+	}
+	return filesystem.CheckFileOwnerRights(path, owner)
+}
+
+// RemoveDir removes the directory and everything in it.
+func RemoveDir(dir string) error {
+	log.Printf("Removing directory %s", dir)
+	if out, err := exec.Command("bash", "-c", "sudo rm -rf "+dir).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to remove %s: %w: %s", dir, err, out)
+	}
+	return nil
+}
+
+// ReadStateOffset returns the offset the agent saved in the state directory for the file it collects, or -1
+// if it saved none.
+func ReadStateOffset(filePath string) (int64, error) {
+	escaped := strings.NewReplacer("/", "_", " ", "_", ":", "_").Replace(filePath)
+	content, err := RunCommand("sudo cat " + filepath.Join(AgentStateDir, escaped) + " 2>/dev/null || true")
+	if err != nil {
+		return 0, err
+	}
+	// The first line is the offset, the next ones the file path and the ranges of newer agents
+	line, _, _ := strings.Cut(content, "\n")
+	if line == "" {
+		return -1, nil
+	}
+	var offset int64
+	if _, err = fmt.Sscan(line, &offset); err != nil {
+		return 0, fmt.Errorf("invalid state file for %s: %w", filePath, err)
+	}
+	return offset, nil
+}
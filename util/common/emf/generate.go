@@ -0,0 +1,248 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package emf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Limits of a single EMF directive. The agent splits events going above them and CloudWatch drops what
+// it cannot split.
+// https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/CloudWatch_Embedded_Metric_Format_Specification.html
+const (
+	MaxMetricsPerDirective = 100
+	MaxDimensionsPerSet    = 30
+	MaxValuesPerMetric     = 100
+)
+
+// Mode is a preset of the shape of the generated events.
+type Mode string
+
+const (
+	// ModeBasic sends one metric with the fixed dimensions, like the static generators.
+	ModeBasic Mode = "basic"
+	// ModeHighCardinality draws the dimension values from 100000 values so almost every event is a new
+	// time series.
+	ModeHighCardinality Mode = "high-cardinality"
+	// ModeDimensionExplosion sends many dimension sets per event, every metric being published once per set.
+	ModeDimensionExplosion Mode = "dimension-explosion"
+	// ModeMixedUnits cycles the metrics through every unit.
+	ModeMixedUnits Mode = "mixed-units"
+	// ModeHighResolution sends the metrics with a StorageResolution of 1 second.
+	ModeHighResolution Mode = "high-resolution"
+	// ModeValueArrays sends arrays of values for every metric.
+	ModeValueArrays Mode = "value-arrays"
+	// ModeOversized goes above the metrics, dimensions and values limits of a directive.
+	ModeOversized Mode = "oversized"
+)
+
+// Modes lists every mode.
+var Modes = []Mode{ModeBasic, ModeHighCardinality, ModeDimensionExplosion, ModeMixedUnits, ModeHighResolution, ModeValueArrays, ModeOversized}
+
+// Units are the units supported by EMF.
+var Units = []string{
+	"Seconds", "Microseconds", "Milliseconds", "Bytes", "Kilobytes", "Megabytes", "Gigabytes", "Terabytes",
+	"Bits", "Kilobits", "Megabits", "Gigabits", "Terabits", "Percent", "Count", "Bytes/Second",
+	"Kilobytes/Second", "Megabytes/Second", "Gigabytes/Second", "Terabytes/Second", "Bits/Second",
+	"Kilobits/Second", "Megabits/Second", "Gigabits/Second", "Terabits/Second", "Count/Second", "None",
+}
+
+// Config is the shape of the generated events.
+type Config struct {
+	Namespace string
+	LogGroup  string
+	// FixedDimensions are added to every dimension set with the same value (e.g InstanceId)
+	FixedDimensions map[string]string
+	// DimensionSets is the number of dimension sets of an event, each with DimensionsPerSet generated
+	// dimensions on top of the fixed ones
+	DimensionSets    int
+	DimensionsPerSet int
+	// DimensionValues is the number of values a generated dimension takes, 1 keeps them constant
+	DimensionValues int
+	MetricsPerEvent int
+	// Units are cycled through the metrics
+	Units []string
+	// StorageResolution is 1 for high resolution metrics, or 0 to leave the default of 60 seconds
+	StorageResolution int
+	// ValuesPerMetric sends an array of values per metric when above 1
+	ValuesPerMetric int
+}
+
+// ModeConfig returns the config of the mode.
+func ModeConfig(mode Mode) (Config, error) {
+	cfg := Config{
+		DimensionSets:   1,
+		DimensionValues: 1,
+		MetricsPerEvent: 1,
+		Units:           []string{"Milliseconds"},
+		ValuesPerMetric: 1,
+	}
+	switch mode {
+	case ModeBasic, "":
+	case ModeHighCardinality:
+		cfg.DimensionsPerSet = 3
+		cfg.DimensionValues = 100000
+		cfg.MetricsPerEvent = 5
+	case ModeDimensionExplosion:
+		cfg.DimensionSets = 20
+		cfg.DimensionsPerSet = 5
+		cfg.DimensionValues = 10
+		cfg.MetricsPerEvent = 10
+	case ModeMixedUnits:
+		cfg.MetricsPerEvent = len(Units)
+		cfg.Units = Units
+	case ModeHighResolution:
+		cfg.MetricsPerEvent = 10
+		cfg.StorageResolution = 1
+	case ModeValueArrays:
+		cfg.MetricsPerEvent = 5
+		cfg.ValuesPerMetric = MaxValuesPerMetric
+	case ModeOversized:
+		cfg.DimensionSets = 2
+		cfg.DimensionsPerSet = MaxDimensionsPerSet + 5
+		cfg.MetricsPerEvent = MaxMetricsPerDirective + 50
+		cfg.ValuesPerMetric = MaxValuesPerMetric + 50
+	default:
+		return Config{}, fmt.Errorf("unsupported emf mode %q, must be one of %v", mode, Modes)
+	}
+	return cfg, nil
+}
+
+func (c Config) validate() error {
+	var errs []error
+	if c.DimensionSets < 1 || c.MetricsPerEvent < 1 || c.ValuesPerMetric < 1 || c.DimensionValues < 1 {
+		errs = append(errs, errors.New("DimensionSets, DimensionValues, MetricsPerEvent and ValuesPerMetric must be at least 1"))
+	}
+	if c.DimensionsPerSet < 0 {
+		errs = append(errs, errors.New("DimensionsPerSet must not be negative"))
+	}
+	if len(c.Units) == 0 {
+		errs = append(errs, errors.New("Units must not be empty"))
+	}
+	if c.StorageResolution != 0 && c.StorageResolution != 1 && c.StorageResolution != 60 {
+		errs = append(errs, fmt.Errorf("StorageResolution must be 1 or 60, got %d", c.StorageResolution))
+	}
+	return errors.Join(errs...)
+}
+
+// ExceededLimits describes the limits of a directive the events of the config go above, which is
+// expected for ModeOversized.
+func (c Config) ExceededLimits() []string {
+	var exceeded []string
+	if c.MetricsPerEvent > MaxMetricsPerDirective {
+		exceeded = append(exceeded, fmt.Sprintf("%d metrics per directive is above %d", c.MetricsPerEvent, MaxMetricsPerDirective))
+	}
+	if dimensions := len(c.FixedDimensions) + c.DimensionsPerSet; dimensions > MaxDimensionsPerSet {
+		exceeded = append(exceeded, fmt.Sprintf("%d dimensions per set is above %d", dimensions, MaxDimensionsPerSet))
+	}
+	if c.ValuesPerMetric > MaxValuesPerMetric {
+		exceeded = append(exceeded, fmt.Sprintf("%d values per metric is above %d", c.ValuesPerMetric, MaxValuesPerMetric))
+	}
+	return exceeded
+}
+
+// Generator builds EMF events of a config. It is safe for concurrent use.
+type Generator struct {
+	cfg           Config
+	mu            sync.Mutex
+	rng           *rand.Rand
+	dimensionSets [][]string
+}
+
+func NewGenerator(cfg Config, seed int64) (*Generator, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %v", err)
+	}
+	fixed := make([]string, 0, len(cfg.FixedDimensions))
+	for name := range cfg.FixedDimensions {
+		fixed = append(fixed, name)
+	}
+	sort.Strings(fixed)
+
+	dimensionSets := make([][]string, cfg.DimensionSets)
+	for i := range dimensionSets {
+		dimensionSets[i] = append([]string(nil), fixed...)
+		for j := 0; j < cfg.DimensionsPerSet; j++ {
+			dimensionSets[i] = append(dimensionSets[i], dimensionName(i, j))
+		}
+	}
+	return &Generator{cfg: cfg, rng: rand.New(rand.NewSource(seed)), dimensionSets: dimensionSets}, nil
+}
+
+// Config returns the config of the generator.
+func (g *Generator) Config() Config {
+	return g.cfg
+}
+
+// Event returns an EMF event at the timestamp as a single line of JSON, without the trailing newline.
+func (g *Generator) Event(timestamp time.Time) ([]byte, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	event := map[string]interface{}{}
+	for name, value := range g.cfg.FixedDimensions {
+		event[name] = value
+	}
+	for _, dimensionSet := range g.dimensionSets {
+		for _, name := range dimensionSet[len(g.cfg.FixedDimensions):] {
+			event[name] = "value" + strconv.Itoa(g.rng.Intn(g.cfg.DimensionValues))
+		}
+	}
+
+	metrics := make([]map[string]interface{}, g.cfg.MetricsPerEvent)
+	for i := range metrics {
+		name := MetricName(i)
+		metrics[i] = map[string]interface{}{
+			"Name": name,
+			"Unit": g.cfg.Units[i%len(g.cfg.Units)],
+		}
+		if g.cfg.StorageResolution != 0 {
+			metrics[i]["StorageResolution"] = g.cfg.StorageResolution
+		}
+		if g.cfg.ValuesPerMetric == 1 {
+			event[name] = g.value()
+			continue
+		}
+		values := make([]float64, g.cfg.ValuesPerMetric)
+		for j := range values {
+			values[j] = g.value()
+		}
+		event[name] = values
+	}
+
+	directive := map[string]interface{}{
+		"Namespace":  g.cfg.Namespace,
+		"Dimensions": g.dimensionSets,
+		"Metrics":    metrics,
+	}
+	metadata := map[string]interface{}{
+		"Timestamp":         timestamp.UnixMilli(),
+		"CloudWatchMetrics": []interface{}{directive},
+	}
+	if g.cfg.LogGroup != "" {
+		metadata["LogGroupName"] = g.cfg.LogGroup
+	}
+	event["_aws"] = metadata
+	return json.Marshal(event)
+}
+
+func (g *Generator) value() float64 {
+	return float64(g.rng.Intn(100000)) / 100
+}
+
+// MetricName is the name of the i-th metric of an event.
+func MetricName(i int) string {
+	return "emf_metric_" + strconv.Itoa(i)
+}
+
+func dimensionName(set, index int) string {
+	return fmt.Sprintf("Dimension%d_%d", set, index)
+}
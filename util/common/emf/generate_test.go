@@ -0,0 +1,128 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package emf
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type event struct {
+	AWS struct {
+		Timestamp         int64
+		LogGroupName      string
+		CloudWatchMetrics []struct {
+			Namespace  string
+			Dimensions [][]string
+			Metrics    []struct {
+				Name              string
+				Unit              string
+				StorageResolution int
+			}
+		}
+	} `json:"_aws"`
+}
+
+func TestModes(t *testing.T) {
+	timestamp := time.UnixMilli(1700000000000)
+	for _, mode := range Modes {
+		t.Run(string(mode), func(t *testing.T) {
+			cfg, err := ModeConfig(mode)
+			require.NoError(t, err)
+			cfg.Namespace = "EMFTest"
+			cfg.LogGroup = "i-0123456789"
+			cfg.FixedDimensions = map[string]string{"InstanceId": "i-0123456789"}
+			generator, err := NewGenerator(cfg, 1)
+			require.NoError(t, err)
+
+			content, err := generator.Event(timestamp)
+			require.NoError(t, err)
+			var parsed event
+			require.NoError(t, json.Unmarshal(content, &parsed))
+			var fields map[string]interface{}
+			require.NoError(t, json.Unmarshal(content, &fields))
+
+			assert.Equal(t, timestamp.UnixMilli(), parsed.AWS.Timestamp)
+			assert.Equal(t, "i-0123456789", parsed.AWS.LogGroupName)
+			require.Len(t, parsed.AWS.CloudWatchMetrics, 1)
+			directive := parsed.AWS.CloudWatchMetrics[0]
+			assert.Equal(t, "EMFTest", directive.Namespace)
+			assert.Len(t, directive.Dimensions, cfg.DimensionSets)
+			assert.Len(t, directive.Metrics, cfg.MetricsPerEvent)
+			for _, dimensionSet := range directive.Dimensions {
+				assert.Len(t, dimensionSet, cfg.DimensionsPerSet+1)
+				assert.Equal(t, "InstanceId", dimensionSet[0])
+				for _, dimension := range dimensionSet {
+					assert.IsType(t, "", fields[dimension], dimension)
+				}
+			}
+			for i, metric := range directive.Metrics {
+				assert.Equal(t, MetricName(i), metric.Name)
+				assert.Contains(t, Units, metric.Unit)
+				assert.Equal(t, cfg.StorageResolution, metric.StorageResolution)
+				if cfg.ValuesPerMetric > 1 {
+					assert.Len(t, fields[metric.Name], cfg.ValuesPerMetric)
+				} else {
+					assert.IsType(t, float64(0), fields[metric.Name])
+				}
+			}
+			assert.Equal(t, mode == ModeOversized, len(cfg.ExceededLimits()) > 0, cfg.ExceededLimits())
+		})
+	}
+}
+
+func TestModeDetails(t *testing.T) {
+	cfg, err := ModeConfig(ModeOversized)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"150 metrics per directive is above 100",
+		"35 dimensions per set is above 30",
+		"150 values per metric is above 100",
+	}, cfg.ExceededLimits())
+
+	cfg, err = ModeConfig(ModeMixedUnits)
+	require.NoError(t, err)
+	generator, err := NewGenerator(cfg, 1)
+	require.NoError(t, err)
+	content, err := generator.Event(time.Now())
+	require.NoError(t, err)
+	var parsed event
+	require.NoError(t, json.Unmarshal(content, &parsed))
+	units := map[string]struct{}{}
+	for _, metric := range parsed.AWS.CloudWatchMetrics[0].Metrics {
+		units[metric.Unit] = struct{}{}
+	}
+	assert.Len(t, units, len(Units))
+
+	cfg, err = ModeConfig(ModeHighCardinality)
+	require.NoError(t, err)
+	generator, err = NewGenerator(cfg, 1)
+	require.NoError(t, err)
+	values := map[string]struct{}{}
+	for i := 0; i < 100; i++ {
+		var fields map[string]interface{}
+		content, err = generator.Event(time.Now())
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(content, &fields))
+		values[fields["Dimension0_0"].(string)] = struct{}{}
+	}
+	assert.Greater(t, len(values), 95)
+}
+
+func TestInvalidConfig(t *testing.T) {
+	_, err := ModeConfig("huge")
+	assert.EqualError(t, err, `unsupported emf mode "huge", must be one of [basic high-cardinality dimension-explosion mixed-units high-resolution value-arrays oversized]`)
+
+	cfg, err := ModeConfig(ModeBasic)
+	require.NoError(t, err)
+	cfg.StorageResolution = 5
+	cfg.MetricsPerEvent = 0
+	_, err = NewGenerator(cfg, 1)
+	assert.ErrorContains(t, err, "MetricsPerEvent and ValuesPerMetric must be at least 1")
+	assert.ErrorContains(t, err, "StorageResolution must be 1 or 60, got 5")
+}
@@ -16,14 +16,15 @@ import (
 
 	"go.uber.org/multierr"
 
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
 	"github.com/aws/amazon-cloudwatch-agent-test/validator/models"
 )
 
 const logLine = "# %d - This is a log line. \n"
 
-func GenerateLogs(configFilePath string, duration time.Duration, sendingInterval time.Duration, logLinesPerMinute int, validationLog []models.LogValidation) error {
+func GenerateLogs(clk clock.Clock, configFilePath string, duration time.Duration, sendingInterval time.Duration, logLinesPerMinute int, validationLog []models.LogValidation) error {
 	var multiErr error
-	if err := StartLogWrite(configFilePath, duration, sendingInterval, logLinesPerMinute); err != nil {
+	if err := StartLogWrite(clk, configFilePath, duration, sendingInterval, logLinesPerMinute); err != nil {
 		multiErr = multierr.Append(multiErr, err)
 	}
 	if err := GenerateWindowsEvents(validationLog); err != nil {
@@ -63,7 +64,7 @@ func CreateWindowsEvent(eventLogName string, eventLogLevel string, eventID strin
 
 // StartLogWrite starts go routines to write logs to each of the logs that are monitored by CW Agent according to
 // the config provided
-func StartLogWrite(configFilePath string, duration time.Duration, sendingInterval time.Duration, logLinesPerMinute int) error {
+func StartLogWrite(clk clock.Clock, configFilePath string, duration time.Duration, sendingInterval time.Duration, logLinesPerMinute int) error {
 	var multiErr error
 
 	logPaths, err := GetLogFilePaths(configFilePath)
@@ -74,7 +75,7 @@ func StartLogWrite(configFilePath string, duration time.Duration, sendingInterva
 	for _, logPath := range logPaths {
 		log.Printf("StartLogWrite: dispatching writer goroutine for log file %q", logPath)
 		go func(logPath string) {
-			if err := writeToLogs(logPath, duration, sendingInterval, logLinesPerMinute); err != nil {
+			if err := writeToLogs(clk, logPath, duration, sendingInterval, logLinesPerMinute); err != nil {
 				log.Printf("StartLogWrite: writeToLogs for %q returned error: %v", logPath, err)
 				multiErr = multierr.Append(multiErr, err)
 			}
@@ -86,7 +87,7 @@ func StartLogWrite(configFilePath string, duration time.Duration, sendingInterva
 
 // writeToLogs opens a file at the specified file path and writes the specified number of lines per second (tps)
 // for the specified duration
-func writeToLogs(filePath string, duration, sendingInterval time.Duration, logLinesPerMinute int) error {
+func writeToLogs(clk clock.Clock, filePath string, duration, sendingInterval time.Duration, logLinesPerMinute int) error {
 	log.Printf("writeToLogs: creating %q (duration=%s, sendingInterval=%s, linesPerMinute=%d)", filePath, duration, sendingInterval, logLinesPerMinute)
 	f, err := os.Create(filePath)
 	if err != nil {
@@ -102,9 +103,9 @@ func writeToLogs(filePath string, duration, sendingInterval time.Duration, logLi
 		}
 	}()
 
-	ticker := time.NewTicker(sendingInterval)
+	ticker := clk.NewTicker(sendingInterval)
 	defer ticker.Stop()
-	endTimeout := time.After(duration)
+	endTimeout := clk.After(duration)
 
 	// Sending the logs within the first minute before the ticker kicks in the next minute
 	for i := 0; i < logLinesPerMinute; i++ {
@@ -116,7 +117,7 @@ func writeToLogs(filePath string, duration, sendingInterval time.Duration, logLi
 
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			for i := 0; i < logLinesPerMinute; i++ {
 				f.WriteString(fmt.Sprintf(logLine, i))
 			}
@@ -0,0 +1,122 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// StreamReport accounts for the records a sequenced generator wrote to a stream against the events
+// received, unlike AssertNoMissingLogs which only sees gaps between the first and last event received.
+type StreamReport struct {
+	Stream string `json:"stream"`
+	// Written is the number of records written, numbered seq=1 to seq=Written
+	Written  uint64 `json:"written"`
+	Received int    `json:"received"`
+	// Lost are the sequence numbers written and never received
+	Lost []uint64 `json:"lost,omitempty"`
+	// Duplicated is the number of extra copies of records received more than once
+	Duplicated int `json:"duplicated"`
+	// Reordered is the number of records received after a record with a higher sequence number
+	Reordered int `json:"reordered"`
+	// Unexpected is the number of events without a sequence number or above Written
+	Unexpected int `json:"unexpected"`
+}
+
+// Account builds the report of a stream from the events in the order they were received.
+func Account(stream string, written uint64, events []types.OutputLogEvent) StreamReport {
+	report := StreamReport{Stream: stream, Written: written}
+	seen := make(map[uint64]struct{}, len(events))
+	var highest uint64
+	for _, event := range events {
+		if event.Message == nil {
+			report.Unexpected++
+			continue
+		}
+		match := seqNumRegex.FindStringSubmatch(*event.Message)
+		if len(match) != 2 {
+			report.Unexpected++
+			continue
+		}
+		seqNum, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || seqNum == 0 || seqNum > written {
+			report.Unexpected++
+			continue
+		}
+		report.Received++
+		if _, ok := seen[seqNum]; ok {
+			report.Duplicated++
+			continue
+		}
+		seen[seqNum] = struct{}{}
+		if seqNum < highest {
+			report.Reordered++
+		}
+		if seqNum > highest {
+			highest = seqNum
+		}
+	}
+	for seqNum := uint64(1); seqNum <= written; seqNum++ {
+		if _, ok := seen[seqNum]; !ok {
+			report.Lost = append(report.Lost, seqNum)
+		}
+	}
+	return report
+}
+
+// LossRate returns the fraction of the written records that were lost.
+func (r StreamReport) LossRate() float64 {
+	if r.Written == 0 {
+		return 0
+	}
+	return float64(len(r.Lost)) / float64(r.Written)
+}
+
+// DuplicationRate returns the number of extra copies received per written record.
+func (r StreamReport) DuplicationRate() float64 {
+	if r.Written == 0 {
+		return 0
+	}
+	return float64(r.Duplicated) / float64(r.Written)
+}
+
+func (r StreamReport) String() string {
+	s := fmt.Sprintf("%s: written %d, received %d, lost %d (%.3f%%), duplicated %d (%.3f%%), reordered %d, unexpected %d",
+		r.Stream, r.Written, r.Received, len(r.Lost), 100*r.LossRate(), r.Duplicated, 100*r.DuplicationRate(), r.Reordered, r.Unexpected)
+	if len(r.Lost) > 0 {
+		s += ", lost seq " + seqRanges(r.Lost)
+	}
+	return s
+}
+
+// WriteLossReport writes the reports as indented JSON.
+func WriteLossReport(w io.Writer, reports []StreamReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(reports)
+}
+
+// seqRanges compresses sorted sequence numbers into ranges (e.g 3-7, 12).
+func seqRanges(seqNums []uint64) string {
+	var ranges []string
+	for i := 0; i < len(seqNums); {
+		j := i
+		for j+1 < len(seqNums) && seqNums[j+1] == seqNums[j]+1 {
+			j++
+		}
+		if i == j {
+			ranges = append(ranges, strconv.FormatUint(seqNums[i], 10))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", seqNums[i], seqNums[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(ranges, ", ")
+}
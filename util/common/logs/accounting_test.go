@@ -0,0 +1,72 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccount(t *testing.T) {
+	testCases := map[string]struct {
+		written  uint64
+		received []uint64
+		want     StreamReport
+	}{
+		"Complete": {
+			written:  5,
+			received: []uint64{1, 2, 3, 4, 5},
+			want:     StreamReport{Written: 5, Received: 5},
+		},
+		"LostAtTheEdges": {
+			// AssertNoMissingLogs cannot see records lost before the first or after the last one received
+			written:  8,
+			received: []uint64{3, 4, 6},
+			want:     StreamReport{Written: 8, Received: 3, Lost: []uint64{1, 2, 5, 7, 8}},
+		},
+		"ResentAfterRestart": {
+			written:  6,
+			received: []uint64{1, 2, 3, 4, 3, 4, 5, 6},
+			want:     StreamReport{Written: 6, Received: 8, Duplicated: 2},
+		},
+		"Reordered": {
+			written:  5,
+			received: []uint64{1, 3, 2, 5, 4},
+			want:     StreamReport{Written: 5, Received: 5, Reordered: 2},
+		},
+		"Unexpected": {
+			written:  2,
+			received: []uint64{1, 2, 9},
+			want:     StreamReport{Written: 2, Received: 2, Unexpected: 1},
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var events []types.OutputLogEvent
+			for _, seqNum := range testCase.received {
+				events = append(events, types.OutputLogEvent{Message: aws.String(fmt.Sprintf("12:00:00 seq=%d abc", seqNum))})
+			}
+			testCase.want.Stream = "stream"
+			assert.Equal(t, testCase.want, Account("stream", testCase.written, events))
+		})
+	}
+}
+
+func TestStreamReportString(t *testing.T) {
+	report := StreamReport{Stream: "stream0", Written: 1000, Received: 996, Lost: []uint64{3, 4, 5, 9, 11, 12}, Duplicated: 2, Reordered: 1}
+	assert.Equal(t, "stream0: written 1000, received 996, lost 6 (0.600%), duplicated 2 (0.200%), reordered 1, unexpected 0, lost seq 3-5, 9, 11-12", report.String())
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteLossReport(&buf, []StreamReport{report}))
+	var decoded []StreamReport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, []StreamReport{report}, decoded)
+}
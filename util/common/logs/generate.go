@@ -12,6 +12,7 @@ import (
 	"regexp"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -23,6 +24,15 @@ import (
 const (
 	charset          = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	logEntryTemplate = "%s seq=%d %s\n"
+	// cjkCharset are characters encodable in UTF-16, Shift-JIS and GBK, so entries written with
+	// CharsetCJK survive every supported encoding
+	cjkCharset = "日本中文字東京北京山川海空雨雪花月人口"
+
+	CharsetASCII = "ascii"
+	CharsetCJK   = "cjk"
+
+	LineEndingLF   = "\n"
+	LineEndingCRLF = "\r\n"
 )
 
 var (
@@ -37,6 +47,15 @@ type GeneratorConfig struct {
 	LinesPerSecond  int
 	LineLength      int
 	TimestampFormat string
+	// Charset of the random content of the entries, CharsetASCII when empty
+	Charset string
+	// LineEnding terminates every line of the entries, LineEndingLF when empty
+	LineEnding string
+	// MultilineEvery makes every MultilineEvery-th entry a multiline record: the entry line followed by
+	// MultilineLines indented lines of a stack trace. Only the first line has the seq= number, so the
+	// agent has to join the record for AssertNoMissingLogs to count it once.
+	MultilineEvery int
+	MultilineLines int
 }
 
 func (c *GeneratorConfig) validate() error {
@@ -49,6 +68,15 @@ func (c *GeneratorConfig) validate() error {
 	if c.TimestampFormat == "" {
 		return errors.New("TimestampFormat must be set")
 	}
+	if c.Charset != "" && c.Charset != CharsetASCII && c.Charset != CharsetCJK {
+		return fmt.Errorf("Charset must be %s or %s", CharsetASCII, CharsetCJK)
+	}
+	if c.LineEnding != "" && c.LineEnding != LineEndingLF && c.LineEnding != LineEndingCRLF {
+		return errors.New("LineEnding must be LF or CRLF")
+	}
+	if c.MultilineEvery < 0 || c.MultilineLines < 0 || (c.MultilineEvery > 0) != (c.MultilineLines > 0) {
+		return errors.New("MultilineEvery and MultilineLines must both be positive or both be zero")
+	}
 	return nil
 }
 
@@ -98,8 +126,32 @@ func (g *Generator) SequenceNumber() uint64 {
 func (g *Generator) generateEntry() string {
 	sequenceNumber := g.sequenceNumber.Add(1)
 	timestamp := time.Now().Format(g.cfg.TimestampFormat)
-	randomContent := generateRandomString(g.cfg.LineLength - len(timestamp) - 20)
-	return fmt.Sprintf(logEntryTemplate, timestamp, sequenceNumber, randomContent)
+	length := g.cfg.LineLength - len(timestamp) - 20
+	var randomContent string
+	if g.cfg.Charset == CharsetCJK {
+		randomContent = generateRandomCJKString(length)
+	} else {
+		randomContent = generateRandomString(length)
+	}
+	entry := fmt.Sprintf(logEntryTemplate, timestamp, sequenceNumber, randomContent)
+	if g.cfg.MultilineEvery > 0 && sequenceNumber%uint64(g.cfg.MultilineEvery) == 0 {
+		entry += stackTrace(g.cfg.MultilineLines)
+	}
+	if g.cfg.LineEnding == LineEndingCRLF {
+		entry = strings.ReplaceAll(entry, "\n", LineEndingCRLF)
+	}
+	return entry
+}
+
+// stackTrace returns the lines of a Java exception, each line starting with a space or a tab so a
+// multi_line_start_pattern matching the timestamp does not split the record.
+func stackTrace(lines int) string {
+	var b strings.Builder
+	b.WriteString("  java.lang.IllegalStateException: generated failure\n")
+	for i := 1; i < lines; i++ {
+		fmt.Fprintf(&b, "\tat com.example.generator.Worker.step%d(Worker.java:%d)\n", i, 10*i)
+	}
+	return b.String()
 }
 
 func generateRandomString(length int) string {
@@ -113,6 +165,19 @@ func generateRandomString(length int) string {
 	return string(b)
 }
 
+// generateRandomCJKString returns length characters, each 3 bytes in UTF-8.
+func generateRandomCJKString(length int) string {
+	if length <= 0 {
+		return ""
+	}
+	runes := []rune(cjkCharset)
+	b := make([]rune, length)
+	for i := range b {
+		b[i] = runes[rand.Intn(len(runes))]
+	}
+	return string(b)
+}
+
 func AssertNoMissingLogs(events []types.OutputLogEvent) error {
 	var skipped int
 	seqNums := make([]uint64, 0, len(events))
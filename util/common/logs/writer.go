@@ -0,0 +1,329 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// RotationStrategy is how a FileWriter rotates its file, following what logrotate and logging libraries do.
+type RotationStrategy string
+
+const (
+	// RotationNone never rotates.
+	RotationNone RotationStrategy = "none"
+	// RotationRenameCreate renames the file to <path>.1, shifting the older backups, and creates a new file.
+	RotationRenameCreate RotationStrategy = "rename-create"
+	// RotationCopyTruncate copies the file to <path>.1 and truncates it in place.
+	RotationCopyTruncate RotationStrategy = "copytruncate"
+	// RotationGzip renames and creates like RotationRenameCreate, then compresses the rotated file to
+	// <path>.1.gz and removes it, possibly while the agent is still reading it.
+	RotationGzip RotationStrategy = "gzip"
+	// RotationSymlink writes to <path>.<generation> with <path> a symlink to it, and rotates by
+	// atomically swapping the symlink to a new generation.
+	RotationSymlink RotationStrategy = "symlink"
+
+	defaultMaxBackups = 5
+)
+
+// RotationStrategies lists every strategy.
+var RotationStrategies = []RotationStrategy{RotationNone, RotationRenameCreate, RotationCopyTruncate, RotationGzip, RotationSymlink}
+
+// Encodings supported by NewEncodingWriter, named like the encoding option of the agent's files collection.
+var encodings = map[string]encoding.Encoding{
+	"utf-8":     encoding.Nop,
+	"utf-16le":  unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM),
+	"utf-16be":  unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM),
+	"shift_jis": japanese.ShiftJIS,
+	"gbk":       simplifiedchinese.GBK,
+}
+
+type FileWriterConfig struct {
+	Path     string
+	Rotation RotationStrategy
+	// MaxBytes rotates the file before a write would take it above MaxBytes, 0 only rotates on Rotate
+	MaxBytes int64
+	// MaxBackups is the number of rotated files kept, 5 when zero
+	MaxBackups int
+}
+
+func (c *FileWriterConfig) validate() error {
+	if c.Path == "" {
+		return errors.New("Path must be set")
+	}
+	if !slices.Contains(RotationStrategies, c.Rotation) {
+		return fmt.Errorf("Rotation must be one of %v, got %q", RotationStrategies, c.Rotation)
+	}
+	if c.MaxBytes < 0 || c.MaxBackups < 0 {
+		return errors.New("MaxBytes and MaxBackups must not be negative")
+	}
+	return nil
+}
+
+// FileWriter appends the entries to a file, rotating it with the strategy of its config.
+type FileWriter struct {
+	cfg        FileWriterConfig
+	mu         sync.Mutex
+	file       *os.File
+	size       int64
+	generation int
+	rotations  int
+}
+
+var _ EntryWriter = (*FileWriter)(nil)
+
+func NewFileWriter(cfg FileWriterConfig) (*FileWriter, error) {
+	if cfg.Rotation == "" {
+		cfg.Rotation = RotationNone
+	}
+	if cfg.MaxBackups == 0 {
+		cfg.MaxBackups = defaultMaxBackups
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directories: %w", err)
+	}
+	w := &FileWriter{cfg: cfg}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *FileWriter) Write(entry string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return os.ErrClosed
+	}
+	if w.cfg.MaxBytes > 0 && w.size > 0 && w.size+int64(len(entry)) > w.cfg.MaxBytes {
+		if err := w.rotate(); err != nil {
+			return fmt.Errorf("failed to rotate %s: %w", w.cfg.Path, err)
+		}
+	}
+	n, err := w.file.WriteString(entry)
+	w.size += int64(n)
+	return err
+}
+
+// Rotate rotates the file now.
+func (w *FileWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return os.ErrClosed
+	}
+	return w.rotate()
+}
+
+// Rotations returns the number of rotations done.
+func (w *FileWriter) Rotations() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.rotations
+}
+
+func (w *FileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+func (w *FileWriter) open() error {
+	path := w.cfg.Path
+	if w.cfg.Rotation == RotationSymlink {
+		path = w.generationPath(w.generation)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file, w.size = f, info.Size()
+	if w.cfg.Rotation == RotationSymlink {
+		return w.swapSymlink(path)
+	}
+	return nil
+}
+
+func (w *FileWriter) rotate() error {
+	switch w.cfg.Rotation {
+	case RotationNone:
+		return nil
+	case RotationCopyTruncate:
+		if err := w.shiftBackups(""); err != nil {
+			return err
+		}
+		if err := copyFile(w.cfg.Path, w.backupPath(1, "")); err != nil {
+			return err
+		}
+		// The file is opened with O_APPEND, so the next write lands at the start of the truncated file
+		if err := w.file.Truncate(0); err != nil {
+			return err
+		}
+		w.size = 0
+	case RotationRenameCreate, RotationGzip:
+		suffix := ""
+		if w.cfg.Rotation == RotationGzip {
+			suffix = ".gz"
+		}
+		if err := w.file.Close(); err != nil {
+			return err
+		}
+		w.file = nil
+		if err := w.shiftBackups(suffix); err != nil {
+			return err
+		}
+		if err := os.Rename(w.cfg.Path, w.backupPath(1, "")); err != nil {
+			return err
+		}
+		if err := w.open(); err != nil {
+			return err
+		}
+		if w.cfg.Rotation == RotationGzip {
+			if err := gzipFile(w.backupPath(1, ""), w.backupPath(1, suffix)); err != nil {
+				return err
+			}
+		}
+	case RotationSymlink:
+		if err := w.file.Close(); err != nil {
+			return err
+		}
+		w.file = nil
+		w.generation++
+		if err := w.open(); err != nil {
+			return err
+		}
+		if old := w.generation - w.cfg.MaxBackups - 1; old >= 0 {
+			if err := os.Remove(w.generationPath(old)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	w.rotations++
+	return nil
+}
+
+// shiftBackups renames <path>.i<suffix> to <path>.i+1<suffix>, dropping the oldest backup.
+func (w *FileWriter) shiftBackups(suffix string) error {
+	for i := w.cfg.MaxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(w.backupPath(i, suffix), w.backupPath(i+1, suffix)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *FileWriter) backupPath(i int, suffix string) string {
+	return fmt.Sprintf("%s.%d%s", w.cfg.Path, i, suffix)
+}
+
+func (w *FileWriter) generationPath(generation int) string {
+	return fmt.Sprintf("%s.gen%d", w.cfg.Path, generation)
+}
+
+// swapSymlink points the path at target by renaming a new symlink over it, so readers never see the
+// path missing.
+func (w *FileWriter) swapSymlink(target string) error {
+	tmp := w.cfg.Path + ".tmp"
+	os.Remove(tmp)
+	if err := os.Symlink(filepath.Base(target), tmp); err != nil {
+		return err
+	}
+	return os.Rename(tmp, w.cfg.Path)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func gzipFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err = io.Copy(zw, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+type encodingWriter struct {
+	writer  EntryWriter
+	encoder *encoding.Encoder
+	mu      sync.Mutex
+}
+
+// NewEncodingWriter encodes the entries from UTF-8 to the encoding (utf-8, utf-16le, utf-16be, shift_jis
+// or gbk) before writing them. Entries with characters the encoding lacks fail to write.
+func NewEncodingWriter(writer EntryWriter, name string) (EntryWriter, error) {
+	enc, ok := encodings[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported encoding %q", name)
+	}
+	if enc == encoding.Nop {
+		return writer, nil
+	}
+	return &encodingWriter{writer: writer, encoder: enc.NewEncoder()}, nil
+}
+
+func (w *encodingWriter) Write(entry string) error {
+	w.mu.Lock()
+	encoded, err := w.encoder.String(entry)
+	w.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return w.writer.Write(encoded)
+}
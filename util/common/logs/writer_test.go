@@ -0,0 +1,183 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestFileWriterRotation(t *testing.T) {
+	const entries = 200
+	for _, rotation := range RotationStrategies {
+		t.Run(string(rotation), func(t *testing.T) {
+			if rotation == RotationSymlink && runtime.GOOS == "windows" {
+				t.Skip("symlinks need privileges on windows")
+			}
+			path := filepath.Join(t.TempDir(), "logs", "app.log")
+			writer, err := NewFileWriter(FileWriterConfig{Path: path, Rotation: rotation, MaxBytes: 1000, MaxBackups: 100})
+			require.NoError(t, err)
+			generator, err := NewGenerator(&GeneratorConfig{LinesPerSecond: 1, LineLength: 50, TimestampFormat: "15:04:05"}, writer)
+			require.NoError(t, err)
+			for i := 0; i < entries; i++ {
+				require.NoError(t, writer.Write(generator.generateEntry()))
+			}
+			require.NoError(t, writer.Close())
+
+			if rotation == RotationNone {
+				assert.Equal(t, 0, writer.Rotations())
+			} else {
+				assert.Greater(t, writer.Rotations(), 3)
+			}
+			if rotation == RotationSymlink {
+				target, err := os.Readlink(path)
+				require.NoError(t, err)
+				assert.Equal(t, "app.log.gen"+strconv.Itoa(writer.Rotations()), target)
+			}
+
+			events := readEvents(t, filepath.Dir(path), rotation)
+			assert.Len(t, events, entries)
+			assert.NoError(t, AssertNoMissingLogs(events))
+		})
+	}
+}
+
+func TestFileWriterMaxBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	writer, err := NewFileWriter(FileWriterConfig{Path: path, Rotation: RotationGzip, MaxBackups: 2})
+	require.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		require.NoError(t, writer.Write("line\n"))
+		require.NoError(t, writer.Rotate())
+	}
+	require.NoError(t, writer.Close())
+	files, err := filepath.Glob(path + "*")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{path, path + ".1.gz", path + ".2.gz"}, files)
+
+	_, err = NewFileWriter(FileWriterConfig{Path: path, Rotation: "daily"})
+	assert.ErrorContains(t, err, `Rotation must be one of [none rename-create copytruncate gzip symlink], got "daily"`)
+}
+
+func TestGeneratorEntries(t *testing.T) {
+	generator, err := NewGenerator(&GeneratorConfig{
+		LinesPerSecond:  1,
+		LineLength:      60,
+		TimestampFormat: "2006-01-02T15:04:05",
+		Charset:         CharsetCJK,
+		LineEnding:      LineEndingCRLF,
+		MultilineEvery:  2,
+		MultilineLines:  4,
+	}, &memoryWriter{})
+	require.NoError(t, err)
+
+	first := generator.generateEntry()
+	assert.Contains(t, first, " seq=1 ")
+	assert.True(t, strings.HasSuffix(first, "\r\n"))
+	assert.Equal(t, 1, strings.Count(first, "\n"))
+	assert.NotRegexp(t, `[a-zA-Z]{5}`, first[strings.Index(first, "seq=1 "):])
+
+	second := generator.generateEntry()
+	lines := strings.Split(strings.TrimSuffix(second, "\r\n"), "\r\n")
+	require.Len(t, lines, 5)
+	assert.Contains(t, lines[0], " seq=2 ")
+	for _, line := range lines[1:] {
+		assert.Regexp(t, `^\s`, line)
+		assert.NotContains(t, line, "seq=")
+	}
+	assert.Equal(t, 5, strings.Count(second, "\r\n"))
+	assert.Equal(t, 5, strings.Count(second, "\n"))
+
+	_, err = NewGenerator(&GeneratorConfig{LinesPerSecond: 1, LineLength: 60, TimestampFormat: "15:04", MultilineEvery: 2}, &memoryWriter{})
+	assert.ErrorContains(t, err, "MultilineEvery and MultilineLines must both be positive or both be zero")
+}
+
+func TestEncodingWriter(t *testing.T) {
+	entry := "2024-01-01 seq=7 日本中文字東京北京\n"
+	decoders := map[string]func([]byte) (string, error){
+		"utf-8": func(b []byte) (string, error) { return string(b), nil },
+		"utf-16le": func(b []byte) (string, error) {
+			return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewDecoder().String(string(b))
+		},
+		"utf-16be": func(b []byte) (string, error) {
+			return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewDecoder().String(string(b))
+		},
+		"shift_jis": func(b []byte) (string, error) { return japanese.ShiftJIS.NewDecoder().String(string(b)) },
+		"gbk":       func(b []byte) (string, error) { return simplifiedchinese.GBK.NewDecoder().String(string(b)) },
+	}
+	for name, decode := range decoders {
+		t.Run(name, func(t *testing.T) {
+			memory := &memoryWriter{}
+			writer, err := NewEncodingWriter(memory, name)
+			require.NoError(t, err)
+			require.NoError(t, writer.Write(entry))
+			require.Len(t, memory.entries, 1)
+			if name != "utf-8" {
+				assert.NotEqual(t, entry, memory.entries[0])
+			}
+			decoded, err := decode([]byte(memory.entries[0]))
+			require.NoError(t, err)
+			assert.Equal(t, entry, decoded)
+		})
+	}
+	_, err := NewEncodingWriter(&memoryWriter{}, "latin-1")
+	assert.EqualError(t, err, `unsupported encoding "latin-1"`)
+}
+
+type memoryWriter struct {
+	entries []string
+}
+
+func (w *memoryWriter) Write(entry string) error {
+	w.entries = append(w.entries, entry)
+	return nil
+}
+
+// readEvents returns a log event per line of every file of the directory, decompressing the gzip ones.
+// The symlink is skipped since its target is read directly.
+func readEvents(t *testing.T, dir string, rotation RotationStrategy) []types.OutputLogEvent {
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var events []types.OutputLogEvent
+	for _, file := range files {
+		if file.Type()&os.ModeSymlink != 0 {
+			continue
+		}
+		f, err := os.Open(filepath.Join(dir, file.Name()))
+		require.NoError(t, err)
+		var reader io.Reader = f
+		if strings.HasSuffix(file.Name(), ".gz") {
+			reader, err = gzip.NewReader(f)
+			require.NoError(t, err)
+		}
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		f.Close()
+		for _, line := range strings.Split(strings.TrimSuffix(string(content), "\n"), "\n") {
+			if line != "" {
+				events = append(events, types.OutputLogEvent{Message: aws.String(line)})
+			}
+		}
+	}
+	if rotation == RotationGzip {
+		for _, file := range files {
+			assert.False(t, strings.HasSuffix(file.Name(), ".1"), "the rotated file is removed once compressed")
+		}
+	}
+	return events
+}
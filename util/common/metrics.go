@@ -32,6 +32,8 @@ import (
 	"github.com/prozz/aws-embedded-metrics-golang/emf"
 
 	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
+	emfgen "github.com/aws/amazon-cloudwatch-agent-test/util/common/emf"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/common/prometheus_helper"
 )
 
@@ -52,18 +54,23 @@ type PrometheusConfig struct {
 	InstanceID     string        `json:"instance_id"`
 }
 
-// StartSendingMetrics will generate metrics load based on the receiver (e.g 5000 statsd metrics per minute)
-func StartSendingMetrics(receiver string, duration, sendingInterval time.Duration, metricPerInterval int, metricLogGroup, metricNamespace string) (err error) {
+// StartSendingMetrics will generate metrics load based on the receiver (e.g 5000 statsd metrics per minute). emfMode
+// selects the shape of the emf events (e.g high-cardinality), the basic one-metric events are sent when it is empty.
+func StartSendingMetrics(clk clock.Clock, receiver string, duration, sendingInterval time.Duration, metricPerInterval int, metricLogGroup, metricNamespace, emfMode string) (err error) {
 	go func() {
 		switch receiver {
 		case "statsd":
-			err = SendStatsdMetrics(metricPerInterval, []string{}, sendingInterval, duration)
+			err = SendStatsdMetrics(clk, metricPerInterval, []string{}, sendingInterval, duration)
 		case "collectd":
-			err = SendCollectDMetrics(metricPerInterval, sendingInterval, duration)
+			err = SendCollectDMetrics(clk, metricPerInterval, sendingInterval, duration)
 		case "emf":
-			err = SendEMFMetrics(metricPerInterval, metricLogGroup, metricNamespace, sendingInterval, duration)
+			if emfMode == "" || emfgen.Mode(emfMode) == emfgen.ModeBasic {
+				err = SendEMFMetrics(clk, metricPerInterval, metricLogGroup, metricNamespace, sendingInterval, duration)
+			} else {
+				err = SendEMFMetricsWithMode(clk, emfgen.Mode(emfMode), metricPerInterval, metricLogGroup, metricNamespace, sendingInterval, duration)
+			}
 		case "app_signals":
-			err = SendAppSignalMetrics(duration) //does app signals have dimension for metric?
+			err = SendAppSignalMetrics(clk, duration) //does app signals have dimension for metric?
 		case "prometheus":
 			cfg := PrometheusConfig{
 				MetricCount:    metricPerInterval,
@@ -72,9 +79,9 @@ func StartSendingMetrics(receiver string, duration, sendingInterval time.Duratio
 				ScrapeInterval: int(sendingInterval.Seconds()),
 				InstanceID:     metricLogGroup,
 			}
-			err = SendPrometheusMetrics(cfg, duration)
+			err = SendPrometheusMetrics(clk, cfg, duration)
 		case "traces":
-			err = SendAppSignalsTraceMetrics(duration) //does app signals have dimension for metric?
+			err = SendAppSignalsTraceMetrics(clk, duration) //does app signals have dimension for metric?
 
 		default:
 		}
@@ -83,7 +90,7 @@ func StartSendingMetrics(receiver string, duration, sendingInterval time.Duratio
 	return err
 }
 
-func SendAppSignalsTraceMetrics(duration time.Duration) error {
+func SendAppSignalsTraceMetrics(clk clock.Clock, duration time.Duration) error {
 	baseDir := getAppSignalsResourceDir("traces")
 
 	for i := 0; i < int(duration/(5*time.Second)); i++ {
@@ -97,13 +104,13 @@ func SendAppSignalsTraceMetrics(duration time.Duration) error {
 			return err
 		}
 
-		time.Sleep(5 * time.Second)
+		clk.Sleep(5 * time.Second)
 	}
 
 	return nil
 }
 
-func SendPrometheusMetrics(config PrometheusConfig, agentCollectionDuration time.Duration) error {
+func SendPrometheusMetrics(clk clock.Clock, config PrometheusConfig, agentCollectionDuration time.Duration) error {
 	var namespaceAndLogGroup string
 
 	defer func() {
@@ -147,7 +154,7 @@ func SendPrometheusMetrics(config PrometheusConfig, agentCollectionDuration time
 		if i == 2 {
 			return fmt.Errorf("Avalanche failed to start after retries: %v\nOutput: %s", err, string(output))
 		}
-		time.Sleep(5 * time.Second)
+		clk.Sleep(5 * time.Second)
 	}
 
 	if err := prometheus_helper.CreatePrometheusConfig(prometheusTemplate, config.ScrapeInterval); err != nil {
@@ -167,7 +174,7 @@ func SendPrometheusMetrics(config PrometheusConfig, agentCollectionDuration time
 		return fmt.Errorf("failed to start CloudWatch agent: %v", err)
 	}
 
-	time.Sleep(agentCollectionDuration)
+	clk.Sleep(agentCollectionDuration)
 	count, err := awsservice.CountMetricsInEMFLogs(namespaceAndLogGroup)
 
 	if err != nil {
@@ -215,7 +222,7 @@ func processTraceFile(filePath string, startTime int64, traceID string) error {
 	return nil
 }
 
-func SendCollectDMetrics(metricPerInterval int, sendingInterval, duration time.Duration) error {
+func SendCollectDMetrics(clk clock.Clock, metricPerInterval int, sendingInterval, duration time.Duration) error {
 	// https://github.com/collectd/go-collectd/tree/92e86f95efac5eb62fa84acc6033e7a57218b606
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -232,9 +239,9 @@ func SendCollectDMetrics(metricPerInterval int, sendingInterval, duration time.D
 
 	defer client.Close()
 
-	ticker := time.NewTicker(sendingInterval)
+	ticker := clk.NewTicker(sendingInterval)
 	defer ticker.Stop()
-	endTimeout := time.After(duration)
+	endTimeout := clk.After(duration)
 
 	// Sending the collectd metric within the first minute before the ticker kicks in the next minute
 	for t := 1; t <= metricPerInterval/2; t++ {
@@ -265,7 +272,7 @@ func SendCollectDMetrics(metricPerInterval int, sendingInterval, duration time.D
 		}
 	}
 
-	time.Sleep(30 * time.Second)
+	clk.Sleep(30 * time.Second)
 
 	if err := client.Flush(); err != nil {
 		return err
@@ -273,7 +280,7 @@ func SendCollectDMetrics(metricPerInterval int, sendingInterval, duration time.D
 
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			for t := 1; t <= metricPerInterval/2; t++ {
 				_ = client.Write(ctx, &api.ValueList{
 					Identifier: api.Identifier{
@@ -335,7 +342,7 @@ func processFile(filePath string, startTime int64) error {
 
 }
 
-func SendAppSignalMetrics(duration time.Duration) error {
+func SendAppSignalMetrics(clk clock.Clock, duration time.Duration) error {
 	// The bash script to be executed asynchronously.
 	dir, err := os.Getwd()
 	if err != nil {
@@ -366,14 +373,14 @@ func SendAppSignalMetrics(duration time.Duration) error {
 			return err
 		}
 
-		time.Sleep(5 * time.Second)
+		clk.Sleep(5 * time.Second)
 	}
 
 	return nil
 
 }
 
-func SendStatsdMetrics(metricPerInterval int, metricDimension []string, sendingInterval, duration time.Duration) error {
+func SendStatsdMetrics(clk clock.Clock, metricPerInterval int, metricDimension []string, sendingInterval, duration time.Duration) error {
 	// https://github.com/DataDog/datadog-go#metrics
 	client, err := statsd.New("127.0.0.1:8125", statsd.WithMaxMessagesPerPayload(100), statsd.WithNamespace("statsd"), statsd.WithoutTelemetry())
 
@@ -383,9 +390,9 @@ func SendStatsdMetrics(metricPerInterval int, metricDimension []string, sendingI
 
 	defer client.Close()
 
-	ticker := time.NewTicker(sendingInterval)
+	ticker := clk.NewTicker(sendingInterval)
 	defer ticker.Stop()
-	endTimeout := time.After(duration)
+	endTimeout := clk.After(duration)
 
 	// Sending the statsd metric within the first minute before the ticker kicks in the next minute
 	for t := 1; t <= metricPerInterval/2; t++ {
@@ -399,7 +406,7 @@ func SendStatsdMetrics(metricPerInterval int, metricDimension []string, sendingI
 
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			for t := 1; t <= metricPerInterval/2; t++ {
 				client.Count(fmt.Sprint("counter_", t), int64(t), metricDimension, 1.0)
 				client.Gauge(fmt.Sprint("gauge_", t), float64(t), metricDimension, 1.0)
@@ -410,7 +417,7 @@ func SendStatsdMetrics(metricPerInterval int, metricDimension []string, sendingI
 	}
 }
 
-func SendEMFMetrics(metricPerInterval int, metricLogGroup, metricNamespace string, sendingInterval, duration time.Duration) error {
+func SendEMFMetrics(clk clock.Clock, metricPerInterval int, metricLogGroup, metricNamespace string, sendingInterval, duration time.Duration) error {
 	// github.com/prozz/aws-embedded-metrics-golang/emf
 	conn, err := net.DialTimeout("tcp", "127.0.0.1:25888", time.Millisecond*10000)
 	if err != nil {
@@ -419,9 +426,9 @@ func SendEMFMetrics(metricPerInterval int, metricLogGroup, metricNamespace strin
 
 	defer conn.Close()
 
-	ticker := time.NewTicker(60 * time.Second)
+	ticker := clk.NewTicker(60 * time.Second)
 	defer ticker.Stop()
-	endTimeout := time.After(duration)
+	endTimeout := clk.After(duration)
 
 	for t := 1; t <= metricPerInterval; t++ {
 		emf.New(emf.WithWriter(conn), emf.WithLogGroup(metricLogGroup)).
@@ -436,7 +443,7 @@ func SendEMFMetrics(metricPerInterval int, metricLogGroup, metricNamespace strin
 
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			for t := 1; t <= metricPerInterval; t++ {
 				emf.New(emf.WithWriter(conn), emf.WithLogGroup(metricLogGroup)).
 					Namespace(metricNamespace).
@@ -454,6 +461,63 @@ func SendEMFMetrics(metricPerInterval int, metricLogGroup, metricNamespace strin
 
 }
 
+// SendEMFMetricsWithMode sends emf events shaped by the mode (e.g dimension-explosion) every sendingInterval, enough
+// events to send metricPerInterval metrics. The events carry the InstanceId dimension like the basic ones.
+func SendEMFMetricsWithMode(clk clock.Clock, mode emfgen.Mode, metricPerInterval int, metricLogGroup, metricNamespace string, sendingInterval, duration time.Duration) error {
+	cfg, err := emfgen.ModeConfig(mode)
+	if err != nil {
+		return err
+	}
+	cfg.Namespace = metricNamespace
+	cfg.LogGroup = metricLogGroup
+	cfg.FixedDimensions = map[string]string{"InstanceId": metricLogGroup}
+	generator, err := emfgen.NewGenerator(cfg, time.Now().UnixNano())
+	if err != nil {
+		return err
+	}
+	if exceeded := cfg.ExceededLimits(); len(exceeded) > 0 {
+		log.Printf("emf mode %s goes above the limits of EMF: %s", mode, strings.Join(exceeded, ", "))
+	}
+
+	conn, err := net.DialTimeout("tcp", "127.0.0.1:25888", time.Millisecond*10000)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	eventsPerInterval := (metricPerInterval + cfg.MetricsPerEvent - 1) / cfg.MetricsPerEvent
+	sendEvents := func() error {
+		for i := 0; i < eventsPerInterval; i++ {
+			event, err := generator.Event(clk.Now())
+			if err != nil {
+				return err
+			}
+			if _, err = conn.Write(append(event, '\n')); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	ticker := clk.NewTicker(sendingInterval)
+	defer ticker.Stop()
+	endTimeout := clk.After(duration)
+
+	if err = sendEvents(); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ticker.C():
+			if err = sendEvents(); err != nil {
+				return err
+			}
+		case <-endTimeout:
+			return nil
+		}
+	}
+}
+
 // This function builds and signs an ListEntitiesForMetric call, essentially trying to replicate this curl command:
 //
 //	curl -i -X POST monitoring.us-west-2.amazonaws.com -H 'Content-Type: application/json' \
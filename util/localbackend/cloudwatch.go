@@ -0,0 +1,424 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package localbackend
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	cloudWatchXMLNamespace = "http://monitoring.amazonaws.com/doc/2010-08-01/"
+	scanByAscending        = "TimestampAscending"
+	cwTimestampFormat      = "2006-01-02T15:04:05.000Z"
+)
+
+type cwDimension struct {
+	Name  string `xml:"Name"`
+	Value string `xml:"Value"`
+}
+
+type cwMetric struct {
+	Namespace  string        `xml:"Namespace"`
+	MetricName string        `xml:"MetricName"`
+	Dimensions []cwDimension `xml:"Dimensions>member"`
+}
+
+type cwMetricDataResult struct {
+	Id         string    `xml:"Id"`
+	Label      string    `xml:"Label"`
+	Timestamps []string  `xml:"Timestamps>member"`
+	Values     []float64 `xml:"Values>member"`
+	StatusCode string    `xml:"StatusCode"`
+}
+
+type cwDatapoint struct {
+	Timestamp   string   `xml:"Timestamp"`
+	SampleCount *float64 `xml:"SampleCount,omitempty"`
+	Average     *float64 `xml:"Average,omitempty"`
+	Sum         *float64 `xml:"Sum,omitempty"`
+	Minimum     *float64 `xml:"Minimum,omitempty"`
+	Maximum     *float64 `xml:"Maximum,omitempty"`
+	Unit        string   `xml:"Unit,omitempty"`
+}
+
+type cwResponseMetadata struct {
+	RequestId string `xml:"RequestId"`
+}
+
+type putMetricDataResponse struct {
+	XMLName          xml.Name           `xml:"PutMetricDataResponse"`
+	Xmlns            string             `xml:"xmlns,attr"`
+	ResponseMetadata cwResponseMetadata `xml:"ResponseMetadata"`
+}
+
+type getMetricDataResponse struct {
+	XMLName          xml.Name             `xml:"GetMetricDataResponse"`
+	Xmlns            string               `xml:"xmlns,attr"`
+	Results          []cwMetricDataResult `xml:"GetMetricDataResult>MetricDataResults>member"`
+	ResponseMetadata cwResponseMetadata   `xml:"ResponseMetadata"`
+}
+
+type getMetricStatisticsResponse struct {
+	XMLName          xml.Name           `xml:"GetMetricStatisticsResponse"`
+	Xmlns            string             `xml:"xmlns,attr"`
+	Label            string             `xml:"GetMetricStatisticsResult>Label"`
+	Datapoints       []cwDatapoint      `xml:"GetMetricStatisticsResult>Datapoints>member"`
+	ResponseMetadata cwResponseMetadata `xml:"ResponseMetadata"`
+}
+
+type listMetricsResponse struct {
+	XMLName          xml.Name           `xml:"ListMetricsResponse"`
+	Xmlns            string             `xml:"xmlns,attr"`
+	Metrics          []cwMetric         `xml:"ListMetricsResult>Metrics>member"`
+	ResponseMetadata cwResponseMetadata `xml:"ResponseMetadata"`
+}
+
+type cwErrorResponse struct {
+	XMLName   xml.Name `xml:"ErrorResponse"`
+	Xmlns     string   `xml:"xmlns,attr"`
+	Type      string   `xml:"Error>Type"`
+	Code      string   `xml:"Error>Code"`
+	Message   string   `xml:"Error>Message"`
+	RequestId string   `xml:"RequestId"`
+}
+
+func (s *Server) handleCloudWatch(w http.ResponseWriter, body []byte) {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		writeCloudWatchError(w, "MalformedQueryString", err.Error())
+		return
+	}
+
+	action := form.Get("Action")
+	switch action {
+	case "PutMetricData":
+		err = s.putMetricData(form)
+		if err == nil {
+			writeXML(w, putMetricDataResponse{Xmlns: cloudWatchXMLNamespace, ResponseMetadata: newResponseMetadata()})
+		}
+	case "GetMetricData":
+		var response *getMetricDataResponse
+		if response, err = s.getMetricData(form); err == nil {
+			writeXML(w, response)
+		}
+	case "GetMetricStatistics":
+		var response *getMetricStatisticsResponse
+		if response, err = s.getMetricStatistics(form); err == nil {
+			writeXML(w, response)
+		}
+	case "ListMetrics":
+		writeXML(w, s.listMetrics(form))
+	default:
+		err = fmt.Errorf("action %q is not supported by the local backend", action)
+	}
+
+	if err != nil {
+		log.Printf("Local backend CloudWatch %s failed: %v", action, err)
+		writeCloudWatchError(w, "InvalidParameterValue", err.Error())
+	}
+}
+
+func (s *Server) putMetricData(form url.Values) error {
+	namespace := form.Get("Namespace")
+	if namespace == "" {
+		return fmt.Errorf("namespace is required")
+	}
+	receivedAt := time.Now()
+
+	for _, prefix := range queryMembers(form, "MetricData") {
+		identity := MetricIdentity{
+			Namespace:  namespace,
+			MetricName: form.Get(prefix + ".MetricName"),
+			Dimensions: queryDimensions(form, prefix+".Dimensions"),
+		}
+		timestamp := receivedAt
+		if raw := form.Get(prefix + ".Timestamp"); raw != "" {
+			parsed, err := parseQueryTime(raw)
+			if err != nil {
+				return err
+			}
+			timestamp = parsed
+		}
+		unit := form.Get(prefix + ".Unit")
+
+		samples, err := querySamples(form, prefix)
+		if err != nil {
+			return fmt.Errorf("metric %s: %w", identity.MetricName, err)
+		}
+		for _, sample := range samples {
+			sample.Timestamp = timestamp
+			sample.Unit = unit
+			s.store.PutMetricSample(identity, sample)
+		}
+	}
+	return nil
+}
+
+// querySamples reads the value of a MetricDatum, which can be set through exactly one of Value,
+// Values/Counts or StatisticValues.
+func querySamples(form url.Values, prefix string) ([]Sample, error) {
+	if raw := form.Get(prefix + ".Value"); raw != "" {
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, err
+		}
+		return []Sample{{Sum: value, Min: value, Max: value, SampleCount: 1}}, nil
+	}
+
+	if form.Get(prefix+".StatisticValues.SampleCount") != "" {
+		var stats [4]float64
+		for i, name := range []string{"Sum", "Minimum", "Maximum", "SampleCount"} {
+			value, err := strconv.ParseFloat(form.Get(prefix+".StatisticValues."+name), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid StatisticValues.%s: %w", name, err)
+			}
+			stats[i] = value
+		}
+		return []Sample{{Sum: stats[0], Min: stats[1], Max: stats[2], SampleCount: stats[3]}}, nil
+	}
+
+	values := queryMembers(form, prefix+".Values")
+	counts := queryMembers(form, prefix+".Counts")
+	if len(counts) != 0 && len(counts) != len(values) {
+		return nil, fmt.Errorf("got %d values and %d counts", len(values), len(counts))
+	}
+	samples := make([]Sample, 0, len(values))
+	for i, valueKey := range values {
+		value, err := strconv.ParseFloat(form.Get(valueKey), 64)
+		if err != nil {
+			return nil, err
+		}
+		count := 1.0
+		if len(counts) != 0 {
+			if count, err = strconv.ParseFloat(form.Get(counts[i]), 64); err != nil {
+				return nil, err
+			}
+		}
+		samples = append(samples, Sample{Sum: value * count, Min: value, Max: value, SampleCount: count})
+	}
+	return samples, nil
+}
+
+func (s *Server) getMetricData(form url.Values) (*getMetricDataResponse, error) {
+	start, err := parseQueryTime(form.Get("StartTime"))
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseQueryTime(form.Get("EndTime"))
+	if err != nil {
+		return nil, err
+	}
+	ascending := form.Get("ScanBy") == scanByAscending
+
+	response := &getMetricDataResponse{Xmlns: cloudWatchXMLNamespace, ResponseMetadata: newResponseMetadata()}
+	for _, prefix := range queryMembers(form, "MetricDataQueries") {
+		if form.Get(prefix+".ReturnData") == "false" {
+			continue
+		}
+		if form.Get(prefix+".Expression") != "" {
+			return nil, fmt.Errorf("metric math expressions are not supported by the local backend")
+		}
+		identity := MetricIdentity{
+			Namespace:  form.Get(prefix + ".MetricStat.Metric.Namespace"),
+			MetricName: form.Get(prefix + ".MetricStat.Metric.MetricName"),
+			Dimensions: queryDimensions(form, prefix+".MetricStat.Metric.Dimensions"),
+		}
+		period, err := strconv.Atoi(form.Get(prefix + ".MetricStat.Period"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid period for query %s: %w", form.Get(prefix+".Id"), err)
+		}
+		stat := form.Get(prefix + ".MetricStat.Stat")
+
+		label := form.Get(prefix + ".Label")
+		if label == "" {
+			label = identity.MetricName
+		}
+		result := cwMetricDataResult{
+			Id:         form.Get(prefix + ".Id"),
+			Label:      label,
+			StatusCode: "Complete",
+		}
+
+		datapoints := s.store.GetDatapoints(identity, start, end, time.Duration(period)*time.Second)
+		if !ascending {
+			reverseDatapoints(datapoints)
+		}
+		for _, dp := range datapoints {
+			value, ok := dp.Statistic(stat)
+			if !ok {
+				return nil, fmt.Errorf("statistic %q is not supported by the local backend", stat)
+			}
+			result.Timestamps = append(result.Timestamps, dp.Timestamp.UTC().Format(cwTimestampFormat))
+			result.Values = append(result.Values, value)
+		}
+		response.Results = append(response.Results, result)
+	}
+	return response, nil
+}
+
+func (s *Server) getMetricStatistics(form url.Values) (*getMetricStatisticsResponse, error) {
+	start, err := parseQueryTime(form.Get("StartTime"))
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseQueryTime(form.Get("EndTime"))
+	if err != nil {
+		return nil, err
+	}
+	period, err := strconv.Atoi(form.Get("Period"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid period: %w", err)
+	}
+	identity := MetricIdentity{
+		Namespace:  form.Get("Namespace"),
+		MetricName: form.Get("MetricName"),
+		Dimensions: queryDimensions(form, "Dimensions"),
+	}
+	var statistics []string
+	for _, key := range queryMembers(form, "Statistics") {
+		statistics = append(statistics, form.Get(key))
+	}
+	if len(queryMembers(form, "ExtendedStatistics")) > 0 {
+		return nil, fmt.Errorf("extended statistics are not supported by the local backend")
+	}
+
+	response := &getMetricStatisticsResponse{
+		Xmlns:            cloudWatchXMLNamespace,
+		Label:            identity.MetricName,
+		ResponseMetadata: newResponseMetadata(),
+	}
+	for _, dp := range s.store.GetDatapoints(identity, start, end, time.Duration(period)*time.Second) {
+		datapoint := cwDatapoint{Timestamp: dp.Timestamp.UTC().Format(cwTimestampFormat), Unit: dp.Unit}
+		for _, stat := range statistics {
+			value, ok := dp.Statistic(stat)
+			if !ok {
+				return nil, fmt.Errorf("statistic %q is not supported by the local backend", stat)
+			}
+			switch stat {
+			case "SampleCount":
+				datapoint.SampleCount = &value
+			case "Average":
+				datapoint.Average = &value
+			case "Sum":
+				datapoint.Sum = &value
+			case "Minimum":
+				datapoint.Minimum = &value
+			case "Maximum":
+				datapoint.Maximum = &value
+			}
+		}
+		response.Datapoints = append(response.Datapoints, datapoint)
+	}
+	return response, nil
+}
+
+func (s *Server) listMetrics(form url.Values) *listMetricsResponse {
+	response := &listMetricsResponse{Xmlns: cloudWatchXMLNamespace, ResponseMetadata: newResponseMetadata()}
+	for _, id := range s.store.ListMetrics(form.Get("Namespace"), form.Get("MetricName"), queryDimensions(form, "Dimensions")) {
+		metric := cwMetric{Namespace: id.Namespace, MetricName: id.MetricName}
+		for _, d := range id.Dimensions {
+			metric.Dimensions = append(metric.Dimensions, cwDimension{Name: d.Name, Value: d.Value})
+		}
+		response.Metrics = append(response.Metrics, metric)
+	}
+	return response
+}
+
+// queryMembers returns the prefixes of the list members (e.g MetricData.member.1, MetricData.member.2)
+// for a list serialized with the query protocol, ordered by member index.
+func queryMembers(form url.Values, prefix string) []string {
+	memberPrefix := prefix + ".member."
+	indices := make(map[int]struct{})
+	for key := range form {
+		if !strings.HasPrefix(key, memberPrefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(key, memberPrefix)
+		if dot := strings.Index(rest, "."); dot >= 0 {
+			rest = rest[:dot]
+		}
+		if index, err := strconv.Atoi(rest); err == nil {
+			indices[index] = struct{}{}
+		}
+	}
+	sorted := make([]int, 0, len(indices))
+	for index := range indices {
+		sorted = append(sorted, index)
+	}
+	sort.Ints(sorted)
+	members := make([]string, 0, len(sorted))
+	for _, index := range sorted {
+		members = append(members, memberPrefix+strconv.Itoa(index))
+	}
+	return members
+}
+
+func queryDimensions(form url.Values, prefix string) []Dimension {
+	var dims []Dimension
+	for _, member := range queryMembers(form, prefix) {
+		dims = append(dims, Dimension{Name: form.Get(member + ".Name"), Value: form.Get(member + ".Value")})
+	}
+	return dims
+}
+
+func parseQueryTime(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, fmt.Errorf("missing timestamp")
+	}
+	if t, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+		return t, nil
+	}
+	// The v1 SDK used by the agent serializes timestamps without a timezone designator.
+	t, err := time.Parse("2006-01-02T15:04:05", raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q: %w", raw, err)
+	}
+	return t, nil
+}
+
+func reverseDatapoints(datapoints []Datapoint) {
+	for i, j := 0, len(datapoints)-1; i < j; i, j = i+1, j-1 {
+		datapoints[i], datapoints[j] = datapoints[j], datapoints[i]
+	}
+}
+
+func newResponseMetadata() cwResponseMetadata {
+	return cwResponseMetadata{RequestId: uuid.NewString()}
+}
+
+func writeXML(w http.ResponseWriter, response interface{}) {
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		log.Printf("Unable to write response: %v", err)
+		return
+	}
+	if err := xml.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Unable to write response: %v", err)
+	}
+}
+
+func writeCloudWatchError(w http.ResponseWriter, code, message string) {
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(http.StatusBadRequest)
+	if err := xml.NewEncoder(w).Encode(cwErrorResponse{
+		Xmlns:     cloudWatchXMLNamespace,
+		Type:      "Sender",
+		Code:      code,
+		Message:   message,
+		RequestId: uuid.NewString(),
+	}); err != nil {
+		log.Printf("Unable to write response: %v", err)
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package localbackend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	logsContentType       = "application/x-amz-json-1.1"
+	forwardTokenPrefix    = "f/"
+	backwardTokenPrefix   = "b/"
+	defaultLogEventsLimit = 10000
+)
+
+var (
+	errResourceNotFound      = errors.New("ResourceNotFoundException")
+	errResourceAlreadyExists = errors.New("ResourceAlreadyExistsException")
+)
+
+type logGroupRequest struct {
+	LogGroupName       string `json:"logGroupName"`
+	LogGroupNamePrefix string `json:"logGroupNamePrefix"`
+	LogStreamName      string `json:"logStreamName"`
+}
+
+type putLogEventsRequest struct {
+	LogGroupName  string `json:"logGroupName"`
+	LogStreamName string `json:"logStreamName"`
+	LogEvents     []struct {
+		Timestamp int64  `json:"timestamp"`
+		Message   string `json:"message"`
+	} `json:"logEvents"`
+}
+
+type getLogEventsRequest struct {
+	LogGroupName  string `json:"logGroupName"`
+	LogStreamName string `json:"logStreamName"`
+	StartTime     int64  `json:"startTime"`
+	EndTime       int64  `json:"endTime"`
+	NextToken     string `json:"nextToken"`
+	Limit         int    `json:"limit"`
+}
+
+type outputLogEvent struct {
+	Timestamp     int64  `json:"timestamp"`
+	Message       string `json:"message"`
+	IngestionTime int64  `json:"ingestionTime"`
+}
+
+type getLogEventsResponse struct {
+	Events            []outputLogEvent `json:"events"`
+	NextForwardToken  string           `json:"nextForwardToken"`
+	NextBackwardToken string           `json:"nextBackwardToken"`
+}
+
+type describeLogStreamsRequest struct {
+	LogGroupName        string `json:"logGroupName"`
+	LogStreamNamePrefix string `json:"logStreamNamePrefix"`
+	OrderBy             string `json:"orderBy"`
+	Descending          bool   `json:"descending"`
+	Limit               int    `json:"limit"`
+}
+
+type logStreamDescription struct {
+	LogStreamName       string `json:"logStreamName"`
+	CreationTime        int64  `json:"creationTime"`
+	FirstEventTimestamp int64  `json:"firstEventTimestamp,omitempty"`
+	LastEventTimestamp  int64  `json:"lastEventTimestamp,omitempty"`
+}
+
+type logGroupDescription struct {
+	LogGroupName  string `json:"logGroupName"`
+	LogGroupClass string `json:"logGroupClass"`
+}
+
+type logsError struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+func (s *Server) handleLogs(w http.ResponseWriter, operation string, body []byte) {
+	var (
+		response interface{}
+		err      error
+	)
+	switch operation {
+	case "CreateLogGroup":
+		var request logGroupRequest
+		if err = json.Unmarshal(body, &request); err == nil && !s.store.CreateLogGroup(request.LogGroupName) {
+			err = errResourceAlreadyExists
+		}
+	case "CreateLogStream":
+		var request logGroupRequest
+		if err = json.Unmarshal(body, &request); err == nil {
+			err = s.store.CreateLogStream(request.LogGroupName, request.LogStreamName)
+		}
+	case "DeleteLogGroup":
+		var request logGroupRequest
+		if err = json.Unmarshal(body, &request); err == nil {
+			err = s.store.DeleteLogGroup(request.LogGroupName)
+		}
+	case "DeleteLogStream":
+		var request logGroupRequest
+		if err = json.Unmarshal(body, &request); err == nil {
+			err = s.store.DeleteLogStream(request.LogGroupName, request.LogStreamName)
+		}
+	case "PutRetentionPolicy", "TagResource", "TagLogGroup":
+		// Accepted for compatibility with the agent, retention is meaningless for an in-memory store.
+	case "PutLogEvents":
+		response, err = s.putLogEvents(body)
+	case "GetLogEvents":
+		response, err = s.getLogEvents(body)
+	case "DescribeLogGroups":
+		response, err = s.describeLogGroups(body)
+	case "DescribeLogStreams":
+		response, err = s.describeLogStreams(body)
+	default:
+		writeLogsError(w, "UnknownOperationException", fmt.Sprintf("operation %q is not supported by the local backend", operation))
+		return
+	}
+
+	if err != nil {
+		log.Printf("Local backend CloudWatch Logs %s failed: %v", operation, err)
+		writeLogsError(w, logsErrorType(err), err.Error())
+		return
+	}
+	if response == nil {
+		response = struct{}{}
+	}
+	w.Header().Set("Content-Type", logsContentType)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Unable to write response: %v", err)
+	}
+}
+
+func (s *Server) putLogEvents(body []byte) (interface{}, error) {
+	var request putLogEventsRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, err
+	}
+	ingestionTime := time.Now().UnixMilli()
+	events := make([]LogEvent, 0, len(request.LogEvents))
+	for _, event := range request.LogEvents {
+		events = append(events, LogEvent{Timestamp: event.Timestamp, Message: event.Message, IngestionTime: ingestionTime})
+	}
+	if err := s.store.PutLogEvents(request.LogGroupName, request.LogStreamName, events); err != nil {
+		return nil, err
+	}
+	return map[string]string{"nextSequenceToken": uuid.NewString()}, nil
+}
+
+// getLogEvents returns the events after the offset encoded in nextToken. Like the service, the forward
+// token returned at the end of the stream is the same token that was passed in, which is how callers
+// (e.g awsservice.GetLogsSince) know to stop paginating.
+func (s *Server) getLogEvents(body []byte) (interface{}, error) {
+	var request getLogEventsRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, err
+	}
+	events, err := s.store.GetLogEvents(request.LogGroupName, request.LogStreamName, request.StartTime, request.EndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := 0
+	if request.NextToken != "" {
+		if offset, err = strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(request.NextToken, forwardTokenPrefix), backwardTokenPrefix)); err != nil {
+			return nil, fmt.Errorf("invalid next token %q", request.NextToken)
+		}
+	}
+	if offset > len(events) {
+		offset = len(events)
+	}
+	limit := request.Limit
+	if limit <= 0 {
+		limit = defaultLogEventsLimit
+	}
+	page := events[offset:]
+	if len(page) > limit {
+		page = page[:limit]
+	}
+
+	response := getLogEventsResponse{
+		Events:            make([]outputLogEvent, 0, len(page)),
+		NextForwardToken:  forwardTokenPrefix + strconv.Itoa(offset+len(page)),
+		NextBackwardToken: backwardTokenPrefix + strconv.Itoa(offset),
+	}
+	for _, event := range page {
+		response.Events = append(response.Events, outputLogEvent(event))
+	}
+	return response, nil
+}
+
+func (s *Server) describeLogGroups(body []byte) (interface{}, error) {
+	var request logGroupRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, err
+	}
+	groups := make([]logGroupDescription, 0)
+	for _, name := range s.store.LogGroupNames(request.LogGroupNamePrefix) {
+		groups = append(groups, logGroupDescription{LogGroupName: name, LogGroupClass: "STANDARD"})
+	}
+	return map[string]interface{}{"logGroups": groups}, nil
+}
+
+func (s *Server) describeLogStreams(body []byte) (interface{}, error) {
+	var request describeLogStreamsRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, err
+	}
+	infos, err := s.store.LogStreams(request.LogGroupName)
+	if err != nil {
+		return nil, err
+	}
+	if request.OrderBy == "LastEventTime" {
+		sort.SliceStable(infos, func(i, j int) bool {
+			return infos[i].LastEventTimestamp < infos[j].LastEventTimestamp
+		})
+	}
+	if request.Descending {
+		for i, j := 0, len(infos)-1; i < j; i, j = i+1, j-1 {
+			infos[i], infos[j] = infos[j], infos[i]
+		}
+	}
+
+	streams := make([]logStreamDescription, 0, len(infos))
+	for _, info := range infos {
+		if !strings.HasPrefix(info.Name, request.LogStreamNamePrefix) {
+			continue
+		}
+		streams = append(streams, logStreamDescription{
+			LogStreamName:       info.Name,
+			CreationTime:        info.CreationTime,
+			FirstEventTimestamp: info.FirstEventTime,
+			LastEventTimestamp:  info.LastEventTimestamp,
+		})
+		if request.Limit > 0 && len(streams) == request.Limit {
+			break
+		}
+	}
+	return map[string]interface{}{"logStreams": streams}, nil
+}
+
+func logsErrorType(err error) string {
+	switch {
+	case errors.Is(err, errResourceNotFound):
+		return "ResourceNotFoundException"
+	case errors.Is(err, errResourceAlreadyExists):
+		return "ResourceAlreadyExistsException"
+	default:
+		return "InvalidParameterException"
+	}
+}
+
+func writeLogsError(w http.ResponseWriter, errorType, message string) {
+	w.Header().Set("Content-Type", logsContentType)
+	w.WriteHeader(http.StatusBadRequest)
+	if err := json.NewEncoder(w).Encode(logsError{Type: errorType, Message: message}); err != nil {
+		log.Printf("Unable to write response: %v", err)
+	}
+}
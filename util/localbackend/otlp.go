@@ -0,0 +1,257 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package localbackend
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	otlpMetricsPath     = "/v1/metrics"
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJSON     = "application/json"
+
+	metricNameLabel      = "__name__"
+	unitLabel            = "__unit__"
+	resourceLabelPrefix  = "@resource."
+	scopeLabelPrefix     = "@instrumentation."
+	scopeNameLabel       = scopeLabelPrefix + "@name"
+	scopeVersionLabel    = scopeLabelPrefix + "@version"
+	summaryQuantileLabel = "quantile"
+	summarySumSuffix     = "_sum"
+	summaryCountSuffix   = "_count"
+	noRecordedValueFlag  = uint32(metricspb.DataPointFlags_DATA_POINT_FLAGS_NO_RECORDED_VALUE_MASK)
+)
+
+// exportMetrics handles OTLP/HTTP metric exports in protobuf or JSON. Every data point becomes a sample of
+// the series labelled the way the CloudWatch PromQL API labels OTLP metrics: resource attributes under
+// @resource., the scope under @instrumentation., the unit under __unit__ and the data point attributes
+// without a prefix.
+func (s *Server) exportMetrics(w http.ResponseWriter, r *http.Request, body []byte) {
+	contentType := strings.TrimSpace(strings.Split(r.Header.Get("Content-Type"), ";")[0])
+	var request collectormetrics.ExportMetricsServiceRequest
+	var err error
+	switch contentType {
+	case contentTypeProtobuf:
+		err = proto.Unmarshal(body, &request)
+	case contentTypeJSON:
+		err = protojson.Unmarshal(body, &request)
+	default:
+		http.Error(w, fmt.Sprintf("unsupported content type %q, expected %s or %s", contentType, contentTypeProtobuf, contentTypeJSON), http.StatusUnsupportedMediaType)
+		return
+	}
+	if err != nil {
+		http.Error(w, "invalid OTLP metrics request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, resourceMetrics := range request.GetResourceMetrics() {
+		resourceLabels := attributeLabels(resourceLabelPrefix, resourceMetrics.GetResource().GetAttributes(), nil)
+		for _, scopeMetrics := range resourceMetrics.GetScopeMetrics() {
+			scope := scopeMetrics.GetScope()
+			scopeLabels := attributeLabels(scopeLabelPrefix, scope.GetAttributes(), resourceLabels)
+			if scope.GetName() != "" {
+				scopeLabels[scopeNameLabel] = scope.GetName()
+			}
+			if scope.GetVersion() != "" {
+				scopeLabels[scopeVersionLabel] = scope.GetVersion()
+			}
+			for _, metric := range scopeMetrics.GetMetrics() {
+				s.putOTLPMetric(metric, scopeLabels)
+			}
+		}
+	}
+
+	response := &collectormetrics.ExportMetricsServiceResponse{}
+	var payload []byte
+	if contentType == contentTypeJSON {
+		payload, err = protojson.Marshal(response)
+	} else {
+		payload, err = proto.Marshal(response)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(payload)
+}
+
+// putOTLPMetric stores the data points of the metric. Summaries are stored the way Prometheus exposes
+// them: a series per quantile, plus the _sum and _count series.
+func (s *Server) putOTLPMetric(metric *metricspb.Metric, scopeLabels map[string]string) {
+	name := metric.GetName()
+	if unit := metric.GetUnit(); unit != "" {
+		scopeLabels = attributeLabels("", nil, scopeLabels)
+		scopeLabels[unitLabel] = unit
+	}
+	switch data := metric.GetData().(type) {
+	case *metricspb.Metric_Gauge:
+		for _, point := range data.Gauge.GetDataPoints() {
+			s.putNumberDataPoint(name, point, scopeLabels)
+		}
+	case *metricspb.Metric_Sum:
+		for _, point := range data.Sum.GetDataPoints() {
+			s.putNumberDataPoint(name, point, scopeLabels)
+		}
+	case *metricspb.Metric_Histogram:
+		for _, point := range data.Histogram.GetDataPoints() {
+			if point.GetFlags()&noRecordedValueFlag != 0 {
+				continue
+			}
+			histogram := &PromHistogram{Count: float64(point.GetCount()), Sum: point.GetSum()}
+			bounds := point.GetExplicitBounds()
+			for i, count := range point.GetBucketCounts() {
+				if count == 0 {
+					continue
+				}
+				bucket := PromBucket{Lower: math.Inf(-1), Upper: math.Inf(1), Count: float64(count)}
+				if i > 0 && i-1 < len(bounds) {
+					bucket.Lower = bounds[i-1]
+				}
+				if i < len(bounds) {
+					bucket.Upper = bounds[i]
+				}
+				histogram.Buckets = append(histogram.Buckets, bucket)
+			}
+			s.store.PutPromSample(pointLabels(name, point.GetAttributes(), scopeLabels), PromSample{Timestamp: unixNano(point.GetTimeUnixNano()), Histogram: histogram})
+		}
+	case *metricspb.Metric_ExponentialHistogram:
+		for _, point := range data.ExponentialHistogram.GetDataPoints() {
+			if point.GetFlags()&noRecordedValueFlag != 0 {
+				continue
+			}
+			s.store.PutPromSample(pointLabels(name, point.GetAttributes(), scopeLabels), PromSample{Timestamp: unixNano(point.GetTimeUnixNano()), Histogram: exponentialHistogram(point)})
+		}
+	case *metricspb.Metric_Summary:
+		for _, point := range data.Summary.GetDataPoints() {
+			if point.GetFlags()&noRecordedValueFlag != 0 {
+				continue
+			}
+			timestamp := unixNano(point.GetTimeUnixNano())
+			for _, quantile := range point.GetQuantileValues() {
+				labels := pointLabels(name, point.GetAttributes(), scopeLabels)
+				labels[summaryQuantileLabel] = strconv.FormatFloat(quantile.GetQuantile(), 'f', -1, 64)
+				s.store.PutPromSample(labels, PromSample{Timestamp: timestamp, Value: quantile.GetValue()})
+			}
+			s.store.PutPromSample(pointLabels(name+summarySumSuffix, point.GetAttributes(), scopeLabels), PromSample{Timestamp: timestamp, Value: point.GetSum()})
+			s.store.PutPromSample(pointLabels(name+summaryCountSuffix, point.GetAttributes(), scopeLabels), PromSample{Timestamp: timestamp, Value: float64(point.GetCount())})
+		}
+	}
+}
+
+func (s *Server) putNumberDataPoint(name string, point *metricspb.NumberDataPoint, scopeLabels map[string]string) {
+	if point.GetFlags()&noRecordedValueFlag != 0 {
+		return
+	}
+	value := point.GetAsDouble()
+	if v, ok := point.GetValue().(*metricspb.NumberDataPoint_AsInt); ok {
+		value = float64(v.AsInt)
+	}
+	s.store.PutPromSample(pointLabels(name, point.GetAttributes(), scopeLabels), PromSample{Timestamp: unixNano(point.GetTimeUnixNano()), Value: value})
+}
+
+// exponentialHistogram converts the buckets of the data point to explicit bounds. The bucket at index i
+// counts the values within (base^i, base^(i+1)], where base is 2^(2^-scale).
+func exponentialHistogram(point *metricspb.ExponentialHistogramDataPoint) *PromHistogram {
+	histogram := &PromHistogram{Count: float64(point.GetCount()), Sum: point.GetSum()}
+	base := math.Pow(2, math.Pow(2, -float64(point.GetScale())))
+	negative := point.GetNegative()
+	for i := len(negative.GetBucketCounts()) - 1; i >= 0; i-- {
+		if count := negative.GetBucketCounts()[i]; count > 0 {
+			index := float64(negative.GetOffset()) + float64(i)
+			histogram.Buckets = append(histogram.Buckets, PromBucket{Boundaries: 1, Lower: -math.Pow(base, index+1), Upper: -math.Pow(base, index), Count: float64(count)})
+		}
+	}
+	if point.GetZeroCount() > 0 {
+		bucket := PromBucket{Boundaries: 3, Upper: point.GetZeroThreshold(), Count: float64(point.GetZeroCount())}
+		if bucket.Upper > 0 {
+			bucket.Lower = -bucket.Upper
+		}
+		histogram.Buckets = append(histogram.Buckets, bucket)
+	}
+	positive := point.GetPositive()
+	for i, count := range positive.GetBucketCounts() {
+		if count > 0 {
+			index := float64(positive.GetOffset()) + float64(i)
+			histogram.Buckets = append(histogram.Buckets, PromBucket{Lower: math.Pow(base, index), Upper: math.Pow(base, index+1), Count: float64(count)})
+		}
+	}
+	return histogram
+}
+
+// pointLabels returns the labels of a data point: the name, the resource and scope labels, and the data
+// point attributes.
+func pointLabels(name string, attributes []*commonpb.KeyValue, scopeLabels map[string]string) map[string]string {
+	labels := attributeLabels("", attributes, scopeLabels)
+	labels[metricNameLabel] = name
+	return labels
+}
+
+// attributeLabels copies parent and adds the attributes with the prefix.
+func attributeLabels(prefix string, attributes []*commonpb.KeyValue, parent map[string]string) map[string]string {
+	labels := make(map[string]string, len(parent)+len(attributes))
+	for name, value := range parent {
+		labels[name] = value
+	}
+	for _, attribute := range attributes {
+		labels[prefix+attribute.GetKey()] = attributeString(attribute.GetValue())
+	}
+	return labels
+}
+
+// attributeString renders an attribute value as a label value. Arrays and maps are rendered as JSON.
+func attributeString(value *commonpb.AnyValue) string {
+	if s, ok := value.GetValue().(*commonpb.AnyValue_StringValue); ok {
+		return s.StringValue
+	}
+	encoded, _ := json.Marshal(attributeValue(value))
+	return string(encoded)
+}
+
+func attributeValue(value *commonpb.AnyValue) interface{} {
+	switch v := value.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return v.BoolValue
+	case *commonpb.AnyValue_IntValue:
+		return v.IntValue
+	case *commonpb.AnyValue_DoubleValue:
+		return v.DoubleValue
+	case *commonpb.AnyValue_BytesValue:
+		return base64.StdEncoding.EncodeToString(v.BytesValue)
+	case *commonpb.AnyValue_ArrayValue:
+		values := make([]interface{}, 0, len(v.ArrayValue.GetValues()))
+		for _, element := range v.ArrayValue.GetValues() {
+			values = append(values, attributeValue(element))
+		}
+		return values
+	case *commonpb.AnyValue_KvlistValue:
+		values := make(map[string]interface{}, len(v.KvlistValue.GetValues()))
+		for _, kv := range v.KvlistValue.GetValues() {
+			values[kv.GetKey()] = attributeValue(kv.GetValue())
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+func unixNano(nanos uint64) time.Time {
+	return time.Unix(0, int64(nanos))
+}
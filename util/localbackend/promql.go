@@ -0,0 +1,525 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package localbackend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	promqlQueryPath      = "/api/v1/query"
+	promqlQueryRangePath = "/api/v1/query_range"
+	promqlSeriesPath     = "/api/v1/series"
+	// promqlLabelPath prefixes the label values path, /api/v1/label/<name>/values
+	promqlLabelPath    = "/api/v1/label/"
+	promqlValuesSuffix = "/values"
+
+	// lookbackDelta is how far back a query looks for the latest sample of a series, like Prometheus
+	lookbackDelta = 5 * time.Minute
+	// maxRangePoints is the most points per series Prometheus returns for a range query
+	maxRangePoints = 11000
+)
+
+type matchOp string
+
+const (
+	matchEqual     matchOp = "="
+	matchNotEqual  matchOp = "!="
+	matchRegexp    matchOp = "=~"
+	matchNotRegexp matchOp = "!~"
+)
+
+// labelMatcher is a single matcher of a vector selector. A label the series does not have matches as "".
+type labelMatcher struct {
+	name  string
+	op    matchOp
+	value string
+	re    *regexp.Regexp
+}
+
+func newLabelMatcher(name string, op matchOp, value string) (labelMatcher, error) {
+	m := labelMatcher{name: name, op: op, value: value}
+	if op == matchRegexp || op == matchNotRegexp {
+		re, err := regexp.Compile("^(?:" + value + ")$")
+		if err != nil {
+			return m, fmt.Errorf("invalid regular expression %q: %w", value, err)
+		}
+		m.re = re
+	}
+	return m, nil
+}
+
+func (m labelMatcher) matchesValue(value string) bool {
+	switch m.op {
+	case matchEqual:
+		return value == m.value
+	case matchNotEqual:
+		return value != m.value
+	case matchRegexp:
+		return m.re.MatchString(value)
+	default:
+		return !m.re.MatchString(value)
+	}
+}
+
+func matchesAll(matchers []labelMatcher) func(labels map[string]string) bool {
+	return func(labels map[string]string) bool {
+		for _, m := range matchers {
+			if !m.matchesValue(labels[m.name]) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// parseSelector parses the vector selectors the test suites query, e.g cpu{"@resource.host.name"=~"ip-.*"}
+// or {"__name__"="k8s.pod.cpu.usage"}. Functions and operators are not supported, and the range of a range
+// vector selector is split off by splitRange.
+func parseSelector(query string) ([]labelMatcher, error) {
+	p := &selectorParser{input: strings.TrimSpace(query)}
+	var matchers []labelMatcher
+	if name := p.identifier(); name != "" {
+		matchers = append(matchers, labelMatcher{name: metricNameLabel, op: matchEqual, value: name})
+	}
+	p.skipSpace()
+	if p.consume("{") {
+		for {
+			p.skipSpace()
+			if p.consume("}") {
+				break
+			}
+			m, err := p.matcher()
+			if err != nil {
+				return nil, err
+			}
+			matchers = append(matchers, m)
+			p.skipSpace()
+			if p.consume(",") {
+				continue
+			}
+			if !p.consume("}") {
+				return nil, p.errorf("expected , or }")
+			}
+			break
+		}
+	}
+	p.skipSpace()
+	if p.pos != len(p.input) || len(matchers) == 0 {
+		return nil, fmt.Errorf("unsupported query %q: only vector selectors are served locally", query)
+	}
+	for _, m := range matchers {
+		if !m.matchesValue("") {
+			return matchers, nil
+		}
+	}
+	return nil, fmt.Errorf("vector selector %q must contain at least one non-empty matcher", query)
+}
+
+type selectorParser struct {
+	input string
+	pos   int
+}
+
+func (p *selectorParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("parse error at char %d of %q: %s", p.pos+1, p.input, fmt.Sprintf(format, args...))
+}
+
+func (p *selectorParser) skipSpace() {
+	for p.pos < len(p.input) && strings.ContainsRune(" \t\n\r", rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *selectorParser) consume(token string) bool {
+	if strings.HasPrefix(p.input[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+// identifier consumes a metric or label name, or returns "" when there is none.
+func (p *selectorParser) identifier() string {
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if c == '_' || c == ':' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (p.pos > start && c >= '0' && c <= '9') {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return p.input[start:p.pos]
+}
+
+// quoted consumes a double quoted string with Go escapes or a raw string in backticks.
+func (p *selectorParser) quoted() (string, error) {
+	if p.pos >= len(p.input) || (p.input[p.pos] != '"' && p.input[p.pos] != '`') {
+		return "", p.errorf("expected a quoted string")
+	}
+	quote := p.input[p.pos]
+	for end := p.pos + 1; end < len(p.input); end++ {
+		if p.input[end] == '\\' && quote == '"' {
+			end++
+			continue
+		}
+		if p.input[end] == quote {
+			value, err := strconv.Unquote(p.input[p.pos : end+1])
+			if err != nil {
+				return "", p.errorf("invalid string: %v", err)
+			}
+			p.pos = end + 1
+			return value, nil
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *selectorParser) matcher() (labelMatcher, error) {
+	name := p.identifier()
+	if name == "" {
+		var err error
+		if name, err = p.quoted(); err != nil {
+			return labelMatcher{}, p.errorf("expected a label name")
+		}
+	}
+	p.skipSpace()
+	var op matchOp
+	for _, candidate := range []matchOp{matchRegexp, matchNotRegexp, matchNotEqual, matchEqual} {
+		if p.consume(string(candidate)) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		return labelMatcher{}, p.errorf("expected a match operator after %s", name)
+	}
+	p.skipSpace()
+	value, err := p.quoted()
+	if err != nil {
+		return labelMatcher{}, err
+	}
+	return newLabelMatcher(name, op, value)
+}
+
+type promqlResponse struct {
+	Status    string      `json:"status"`
+	Data      *promqlData `json:"data,omitempty"`
+	ErrorType string      `json:"errorType,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// promqlListResponse is the response of the metadata endpoints, whose data is a list of label sets or values.
+type promqlListResponse struct {
+	Status string      `json:"status"`
+	Data   interface{} `json:"data"`
+}
+
+type promqlData struct {
+	ResultType string         `json:"resultType"`
+	Result     []promqlSeries `json:"result"`
+}
+
+// promqlSeries is a series of a vector (Value or Histogram) or of a matrix (Values and Histograms).
+type promqlSeries struct {
+	Metric     map[string]string `json:"metric"`
+	Value      []interface{}     `json:"value,omitempty"`
+	Histogram  []interface{}     `json:"histogram,omitempty"`
+	Values     [][]interface{}   `json:"values,omitempty"`
+	Histograms [][]interface{}   `json:"histograms,omitempty"`
+}
+
+type promqlHistogram struct {
+	Count   string          `json:"count"`
+	Sum     string          `json:"sum"`
+	Buckets [][]interface{} `json:"buckets,omitempty"`
+}
+
+// queryInstant evaluates the selector at the time parameter (default now): the latest sample of each series
+// within the lookback, stamped with the evaluation time. A range vector selector (e.g node_load1[5m]) returns
+// the raw samples of each series within the range instead.
+func (s *Server) queryInstant(w http.ResponseWriter, params url.Values) {
+	selector, window, err := splitRange(params.Get("query"))
+	if err != nil {
+		writePromQLError(w, err)
+		return
+	}
+	matchers, err := parseSelector(selector)
+	if err != nil {
+		writePromQLError(w, err)
+		return
+	}
+	at, err := parsePromQLTime(params.Get("time"), time.Now())
+	if err != nil {
+		writePromQLError(w, fmt.Errorf("invalid parameter \"time\": %w", err))
+		return
+	}
+	if window > 0 {
+		s.queryRawSamples(w, matchers, at.Add(-window), at)
+		return
+	}
+	result := make([]promqlSeries, 0)
+	for _, series := range s.store.SelectPromSeries(matchesAll(matchers)) {
+		sample, ok := latestSample(series.Samples, at)
+		if !ok {
+			continue
+		}
+		out := promqlSeries{Metric: series.Labels}
+		if sample.Histogram != nil {
+			out.Histogram = []interface{}{promqlTimestamp(at), formatHistogram(sample.Histogram)}
+		} else {
+			out.Value = []interface{}{promqlTimestamp(at), formatValue(sample.Value)}
+		}
+		result = append(result, out)
+	}
+	writePromQLData(w, promqlData{ResultType: "vector", Result: result})
+}
+
+// queryRawSamples returns the matrix of the samples of each series within (start, end], with their own
+// timestamps.
+func (s *Server) queryRawSamples(w http.ResponseWriter, matchers []labelMatcher, start, end time.Time) {
+	result := make([]promqlSeries, 0)
+	for _, series := range s.store.SelectPromSeries(matchesAll(matchers)) {
+		out := promqlSeries{Metric: series.Labels}
+		for _, sample := range series.Samples {
+			if !sample.Timestamp.After(start) || sample.Timestamp.After(end) {
+				continue
+			}
+			if sample.Histogram != nil {
+				out.Histograms = append(out.Histograms, []interface{}{promqlTimestamp(sample.Timestamp), formatHistogram(sample.Histogram)})
+			} else {
+				out.Values = append(out.Values, []interface{}{promqlTimestamp(sample.Timestamp), formatValue(sample.Value)})
+			}
+		}
+		if out.Values != nil || out.Histograms != nil {
+			result = append(result, out)
+		}
+	}
+	writePromQLData(w, promqlData{ResultType: "matrix", Result: result})
+}
+
+// splitRange splits the range of a range vector selector from the selector, returning a zero range for an
+// instant vector selector.
+func splitRange(query string) (string, time.Duration, error) {
+	query = strings.TrimSpace(query)
+	if !strings.HasSuffix(query, "]") {
+		return query, 0, nil
+	}
+	open := strings.LastIndex(query, "[")
+	if open < 0 {
+		return "", 0, fmt.Errorf("unsupported query %q: unbalanced ]", query)
+	}
+	window, err := parsePromQLDuration(query[open+1 : len(query)-1])
+	if err != nil || window <= 0 {
+		return "", 0, fmt.Errorf("invalid range %q in query %q", query[open+1:len(query)-1], query)
+	}
+	return query[:open], window, nil
+}
+
+// queryRange evaluates the selector at every step within [start, end].
+func (s *Server) queryRange(w http.ResponseWriter, params url.Values) {
+	matchers, err := parseSelector(params.Get("query"))
+	if err != nil {
+		writePromQLError(w, err)
+		return
+	}
+	start, err := parsePromQLTime(params.Get("start"), time.Time{})
+	if err != nil || start.IsZero() {
+		writePromQLError(w, fmt.Errorf("invalid parameter \"start\": %q", params.Get("start")))
+		return
+	}
+	end, err := parsePromQLTime(params.Get("end"), time.Time{})
+	if err != nil || end.IsZero() || end.Before(start) {
+		writePromQLError(w, fmt.Errorf("invalid parameter \"end\": %q", params.Get("end")))
+		return
+	}
+	step, err := parsePromQLDuration(params.Get("step"))
+	if err != nil || step <= 0 {
+		writePromQLError(w, fmt.Errorf("invalid parameter \"step\": %q", params.Get("step")))
+		return
+	}
+	if end.Sub(start)/step >= maxRangePoints {
+		writePromQLError(w, fmt.Errorf("exceeded maximum resolution of %d points per timeseries", maxRangePoints))
+		return
+	}
+
+	result := make([]promqlSeries, 0)
+	for _, series := range s.store.SelectPromSeries(matchesAll(matchers)) {
+		out := promqlSeries{Metric: series.Labels}
+		for at := start; !at.After(end); at = at.Add(step) {
+			sample, ok := latestSample(series.Samples, at)
+			if !ok {
+				continue
+			}
+			if sample.Histogram != nil {
+				out.Histograms = append(out.Histograms, []interface{}{promqlTimestamp(at), formatHistogram(sample.Histogram)})
+			} else {
+				out.Values = append(out.Values, []interface{}{promqlTimestamp(at), formatValue(sample.Value)})
+			}
+		}
+		if out.Values != nil || out.Histograms != nil {
+			result = append(result, out)
+		}
+	}
+	writePromQLData(w, promqlData{ResultType: "matrix", Result: result})
+}
+
+// querySeries returns the label sets of the series matching any of the match[] selectors with a sample
+// within [start, end].
+func (s *Server) querySeries(w http.ResponseWriter, params url.Values) {
+	series, err := s.matchSeries(params, true)
+	if err != nil {
+		writePromQLError(w, err)
+		return
+	}
+	labelSets := make([]map[string]string, 0, len(series))
+	for _, ps := range series {
+		labelSets = append(labelSets, ps.Labels)
+	}
+	writePromQLList(w, labelSets)
+}
+
+// queryLabelValues returns the sorted values of the label on the series with a sample within [start, end],
+// restricted to those matching any of the optional match[] selectors.
+func (s *Server) queryLabelValues(w http.ResponseWriter, name string, params url.Values) {
+	series, err := s.matchSeries(params, false)
+	if err != nil {
+		writePromQLError(w, err)
+		return
+	}
+	seen := map[string]struct{}{}
+	values := make([]string, 0)
+	for _, ps := range series {
+		value, ok := ps.Labels[name]
+		if _, dup := seen[value]; !ok || dup {
+			continue
+		}
+		seen[value] = struct{}{}
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	writePromQLList(w, values)
+}
+
+// matchSeries selects the series of the metadata endpoints. Without start and end, every sample counts.
+func (s *Server) matchSeries(params url.Values, requireMatch bool) ([]PromSeries, error) {
+	selectors := params["match[]"]
+	if requireMatch && len(selectors) == 0 {
+		return nil, errors.New("no match[] parameter provided")
+	}
+	var matchers [][]labelMatcher
+	for _, selector := range selectors {
+		m, err := parseSelector(selector)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, m)
+	}
+	start, err := parsePromQLTime(params.Get("start"), time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameter \"start\": %w", err)
+	}
+	end, err := parsePromQLTime(params.Get("end"), time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameter \"end\": %w", err)
+	}
+
+	var selected []PromSeries
+	for _, series := range s.store.SelectPromSeries(func(labels map[string]string) bool {
+		if len(matchers) == 0 {
+			return true
+		}
+		for _, m := range matchers {
+			if matchesAll(m)(labels) {
+				return true
+			}
+		}
+		return false
+	}) {
+		for _, sample := range series.Samples {
+			if !sample.Timestamp.Before(start) && (end.IsZero() || !sample.Timestamp.After(end)) {
+				selected = append(selected, series)
+				break
+			}
+		}
+	}
+	return selected, nil
+}
+
+// latestSample returns the last of the samples, which are in timestamp order, within (at-lookbackDelta, at].
+func latestSample(samples []PromSample, at time.Time) (PromSample, bool) {
+	for i := len(samples) - 1; i >= 0; i-- {
+		if samples[i].Timestamp.After(at) {
+			continue
+		}
+		if samples[i].Timestamp.After(at.Add(-lookbackDelta)) {
+			return samples[i], true
+		}
+		break
+	}
+	return PromSample{}, false
+}
+
+// parsePromQLTime parses a unix timestamp in seconds or an RFC 3339 time, returning fallback when empty.
+func parsePromQLTime(value string, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		whole, fraction := math.Modf(seconds)
+		return time.Unix(int64(whole), int64(fraction*1e9)), nil
+	}
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+// parsePromQLDuration parses a duration (e.g 60s) or a number of seconds.
+func parsePromQLDuration(value string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	return time.ParseDuration(value)
+}
+
+func promqlTimestamp(t time.Time) float64 {
+	return float64(t.UnixMilli()) / 1000
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func formatHistogram(histogram *PromHistogram) promqlHistogram {
+	out := promqlHistogram{Count: formatValue(histogram.Count), Sum: formatValue(histogram.Sum)}
+	for _, bucket := range histogram.Buckets {
+		out.Buckets = append(out.Buckets, []interface{}{bucket.Boundaries, formatValue(bucket.Lower), formatValue(bucket.Upper), formatValue(bucket.Count)})
+	}
+	return out
+}
+
+func writePromQLData(w http.ResponseWriter, data promqlData) {
+	w.Header().Set("Content-Type", contentTypeJSON)
+	_ = json.NewEncoder(w).Encode(promqlResponse{Status: "success", Data: &data})
+}
+
+func writePromQLList(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", contentTypeJSON)
+	_ = json.NewEncoder(w).Encode(promqlListResponse{Status: "success", Data: data})
+}
+
+func writePromQLError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(promqlResponse{Status: "error", ErrorType: "bad_data", Error: err.Error()})
+}
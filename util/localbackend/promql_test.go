@@ -0,0 +1,177 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package localbackend
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestParseSelector(t *testing.T) {
+	labels := map[string]string{
+		metricNameLabel:                      "k8s.pod.cpu.usage",
+		"@resource.k8s.cluster.name":         "test-cluster",
+		"@resource.k8s.pod.name":             "nginx-test-abc",
+		"cpu":                                "cpu0",
+		"@instrumentation.cloudwatch.source": `say "hi"`,
+	}
+	testCases := map[string]struct {
+		query   string
+		matches bool
+		err     string
+	}{
+		"DottedName":      {query: `{"__name__"="k8s.pod.cpu.usage"}`, matches: true},
+		"ScopedLabels":    {query: `{"__name__"="k8s.pod.cpu.usage","@resource.k8s.cluster.name"="test-cluster", "@resource.k8s.pod.name"=~"nginx-test.*",}`, matches: true},
+		"BareLabel":       {query: `{cpu=~"cpu.", cpu!~"cpu[1-9]", pod!="a"}`, matches: true},
+		"Escapes":         {query: "{\"@instrumentation.cloudwatch.source\"=\"say \\\"hi\\\"\"}", matches: true},
+		"RegexIsAnchored": {query: `{"@resource.k8s.pod.name"=~"nginx"}`},
+		"OtherName":       {query: `container_memory_working_set_bytes{cpu="cpu0"}`},
+		"MissingLabel":    {query: `{"__name__"="k8s.pod.cpu.usage",pod=""}`, matches: true},
+		"Function":        {query: `rate(cpu[5m])`, err: "only vector selectors are served locally"},
+		"Unterminated":    {query: `cpu{pod="a}`, err: "unterminated string"},
+		"BadRegex":        {query: `{pod=~"("}`, err: "invalid regular expression"},
+		"EmptyMatchers":   {query: `{pod=""}`, err: "at least one non-empty matcher"},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			matchers, err := parseSelector(testCase.query)
+			if testCase.err != "" {
+				assert.ErrorContains(t, err, testCase.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.matches, matchesAll(matchers)(labels))
+		})
+	}
+}
+
+func stringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
+
+func TestOTLPRoundTrip(t *testing.T) {
+	server, _ := startTestServer(t)
+	now := time.Now().Truncate(time.Second)
+	var points []*metricspb.NumberDataPoint
+	for i := 0; i < 3; i++ {
+		points = append(points, &metricspb.NumberDataPoint{
+			TimeUnixNano: uint64(now.Add(time.Duration(i-2) * time.Minute).UnixNano()),
+			Attributes:   []*commonpb.KeyValue{stringAttribute("cpu", "cpu0")},
+			Value:        &metricspb.NumberDataPoint_AsInt{AsInt: int64(i + 1)},
+		})
+	}
+	request := &collectormetrics.ExportMetricsServiceRequest{ResourceMetrics: []*metricspb.ResourceMetrics{{
+		Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{stringAttribute("host.name", "ip-10-0-0-1")}},
+		ScopeMetrics: []*metricspb.ScopeMetrics{{
+			Scope: &commonpb.InstrumentationScope{Name: "hostmetricsreceiver", Version: "1.2.3"},
+			Metrics: []*metricspb.Metric{
+				{Name: "system.cpu.time", Unit: "s", Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{DataPoints: points}}},
+				{Name: "http.server.duration", Data: &metricspb.Metric_ExponentialHistogram{ExponentialHistogram: &metricspb.ExponentialHistogram{
+					DataPoints: []*metricspb.ExponentialHistogramDataPoint{{
+						TimeUnixNano: uint64(now.UnixNano()),
+						Count:        4,
+						Sum:          proto.Float64(10),
+						ZeroCount:    1,
+						Positive:     &metricspb.ExponentialHistogramDataPoint_Buckets{Offset: 1, BucketCounts: []uint64{2, 1}},
+					}},
+				}}},
+			},
+		}},
+	}}}
+	payload, err := protojson.Marshal(request)
+	require.NoError(t, err)
+	resp, err := http.Post(server.Endpoint()+otlpMetricsPath, contentTypeJSON, bytes.NewReader(payload))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	query := func(path string, params url.Values) promqlResponse {
+		resp, err := http.Get(server.Endpoint() + path + "?" + params.Encode())
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var response promqlResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return response
+	}
+
+	response := query(promqlQueryPath, url.Values{"query": {`{"__name__"="system.cpu.time","@resource.host.name"=~"ip-.*"}`}})
+	require.Equal(t, "success", response.Status)
+	require.Len(t, response.Data.Result, 1)
+	assert.Equal(t, map[string]string{
+		metricNameLabel:             "system.cpu.time",
+		unitLabel:                   "s",
+		"@resource.host.name":       "ip-10-0-0-1",
+		"@instrumentation.@name":    "hostmetricsreceiver",
+		"@instrumentation.@version": "1.2.3",
+		"cpu":                       "cpu0",
+	}, response.Data.Result[0].Metric)
+	assert.Equal(t, "3", response.Data.Result[0].Value[1])
+
+	response = query(promqlQueryRangePath, url.Values{
+		"query": {`{"__name__"="system.cpu.time"}`},
+		"start": {formatValue(float64(now.Add(-3 * time.Minute).Unix()))},
+		"end":   {formatValue(float64(now.Unix()))},
+		"step":  {"60s"},
+	})
+	require.Equal(t, "matrix", response.Data.ResultType)
+	require.Len(t, response.Data.Result, 1)
+	var values []interface{}
+	for _, pair := range response.Data.Result[0].Values {
+		values = append(values, pair[1])
+	}
+	assert.Equal(t, []interface{}{"1", "2", "3"}, values)
+
+	response = query(promqlQueryPath, url.Values{
+		"query": {`{"__name__"="system.cpu.time"}[90s]`},
+		"time":  {formatValue(float64(now.Unix()))},
+	})
+	require.Equal(t, "matrix", response.Data.ResultType)
+	require.Len(t, response.Data.Result, 1)
+	assert.Equal(t, [][]interface{}{
+		{float64(now.Add(-time.Minute).Unix()), "2"},
+		{float64(now.Unix()), "3"},
+	}, response.Data.Result[0].Values)
+
+	response = query(promqlQueryPath, url.Values{"query": {`{"__name__"="http.server.duration"}`}})
+	require.Len(t, response.Data.Result, 1)
+	assert.Equal(t, map[string]interface{}{
+		"count":   "4",
+		"sum":     "10",
+		"buckets": []interface{}{[]interface{}{3.0, "0", "0", "1"}, []interface{}{0.0, "2", "4", "2"}, []interface{}{0.0, "4", "8", "1"}},
+	}, response.Data.Result[0].Histogram[1])
+
+	response = query(promqlQueryPath, url.Values{"query": {`sum(system_cpu_time)`}})
+	assert.Equal(t, "error", response.Status)
+	assert.Equal(t, "bad_data", response.ErrorType)
+
+	list := func(path string, params url.Values) promqlListResponse {
+		resp, err := http.Get(server.Endpoint() + path + "?" + params.Encode())
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var response promqlListResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return response
+	}
+	assert.Equal(t, []interface{}{"http.server.duration", "system.cpu.time"}, list(promqlLabelPath+metricNameLabel+promqlValuesSuffix, nil).Data)
+	assert.Equal(t, []interface{}{}, list(promqlLabelPath+metricNameLabel+promqlValuesSuffix, url.Values{
+		"start": {formatValue(float64(now.Add(time.Minute).Unix()))},
+	}).Data)
+	series := list(promqlSeriesPath, url.Values{"match[]": {`{"__name__"="system.cpu.time"}`, `{cpu="cpu1"}`}})
+	require.Len(t, series.Data, 1)
+	assert.Equal(t, "cpu0", series.Data.([]interface{})[0].(map[string]interface{})["cpu"])
+	assert.Equal(t, "error", list(promqlSeriesPath, nil).Status)
+}
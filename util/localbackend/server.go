@@ -0,0 +1,143 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package localbackend
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultAddress = "127.0.0.1:4599"
+
+	contentEncodingGzip = "gzip"
+	xrayPutSegmentsPath = "/TraceSegments"
+	xrayBatchTracesPath = "/Traces"
+	xrayTraceSummaries  = "/TraceSummaries"
+	logsTargetPrefix    = "Logs_20140328."
+	amzTargetHeader     = "X-Amz-Target"
+	shutdownGracePeriod = 5 * time.Second
+)
+
+// Server is an in-process fake for the subset of CloudWatch, CloudWatch Logs and X-Ray that the agent
+// writes to and the validator reads from. The agent is pointed at it through endpoint_override and the
+// validator through awsservice.ConfigureLocalBackend, so both sides talk the real wire protocols.
+// It also accepts OTLP/HTTP metrics and serves them through the PromQL query API the OTel suites use.
+type Server struct {
+	store    *Store
+	listener net.Listener
+	server   *http.Server
+}
+
+// NewServer creates a Server listening on the given address. Use "127.0.0.1:0" to pick a free port.
+func NewServer(address string) (*Server, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+	s := &Server{
+		store:    NewStore(),
+		listener: listener,
+	}
+	s.server = &http.Server{Handler: s}
+	return s, nil
+}
+
+// Start serves requests in the background until Close is called.
+func (s *Server) Start() {
+	go func() {
+		log.Printf("Local backend listening on %s", s.Endpoint())
+		if err := s.server.Serve(s.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Local backend server error: %v", err)
+		}
+	}()
+}
+
+// Close stops the server. The store remains readable afterwards.
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownGracePeriod)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+// Endpoint returns the base URL of the server (e.g http://127.0.0.1:4599)
+func (s *Server) Endpoint() string {
+	return "http://" + s.listener.Addr().String()
+}
+
+// Store returns the backing store so callers can inspect what the agent sent.
+func (s *Server) Store() *Store {
+	return s.store
+}
+
+// ServeHTTP routes requests by protocol: OTLP/HTTP, the PromQL API and X-Ray are routed by path,
+// CloudWatch Logs is JSON 1.1 and routed by X-Amz-Target, and CloudWatch is the query protocol and routed
+// by Action.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := readBody(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case r.URL.Path == otlpMetricsPath:
+		s.exportMetrics(w, r, body)
+	case r.URL.Path == promqlQueryPath:
+		s.queryInstant(w, queryParams(r, body))
+	case r.URL.Path == promqlQueryRangePath:
+		s.queryRange(w, queryParams(r, body))
+	case r.URL.Path == promqlSeriesPath:
+		s.querySeries(w, queryParams(r, body))
+	case strings.HasPrefix(r.URL.Path, promqlLabelPath) && strings.HasSuffix(r.URL.Path, promqlValuesSuffix):
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, promqlLabelPath), promqlValuesSuffix)
+		s.queryLabelValues(w, name, queryParams(r, body))
+	case r.URL.Path == xrayPutSegmentsPath:
+		s.putTraceSegments(w, body)
+	case r.URL.Path == xrayBatchTracesPath:
+		s.batchGetTraces(w, body)
+	case r.URL.Path == xrayTraceSummaries:
+		s.getTraceSummaries(w, body)
+	case strings.HasPrefix(r.Header.Get(amzTargetHeader), logsTargetPrefix):
+		s.handleLogs(w, strings.TrimPrefix(r.Header.Get(amzTargetHeader), logsTargetPrefix), body)
+	default:
+		s.handleCloudWatch(w, body)
+	}
+}
+
+func readBody(r *http.Request) ([]byte, error) {
+	defer r.Body.Close()
+	var reader io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == contentEncodingGzip {
+		gzipReader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+	return io.ReadAll(reader)
+}
+
+// queryParams returns the URL parameters merged with a form encoded body, as the PromQL API accepts both.
+func queryParams(r *http.Request, body []byte) url.Values {
+	params := r.URL.Query()
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		if form, err := url.ParseQuery(string(body)); err == nil {
+			for name, values := range form {
+				params[name] = append(params[name], values...)
+			}
+		}
+	}
+	return params
+}
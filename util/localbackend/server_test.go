@@ -0,0 +1,203 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package localbackend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	cwltypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/aws-sdk-go-v2/service/xray"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startTestServer(t *testing.T) (*Server, aws.Config) {
+	server, err := NewServer("127.0.0.1:0")
+	require.NoError(t, err)
+	server.Start()
+	t.Cleanup(func() { _ = server.Close() })
+	return server, aws.Config{
+		Region: "us-west-2",
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "test", SecretAccessKey: "test"}, nil
+		}),
+	}
+}
+
+func TestMetricsRoundTrip(t *testing.T) {
+	server, cfg := startTestServer(t)
+	client := cloudwatch.NewFromConfig(cfg, func(o *cloudwatch.Options) {
+		o.BaseEndpoint = aws.String(server.Endpoint())
+	})
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Minute)
+	dims := []cwtypes.Dimension{{Name: aws.String("InstanceId"), Value: aws.String("i-123")}}
+
+	for i := 0; i < 3; i++ {
+		_, err := client.PutMetricData(ctx, &cloudwatch.PutMetricDataInput{
+			Namespace: aws.String("CWAgent"),
+			MetricData: []cwtypes.MetricDatum{{
+				MetricName: aws.String("cpu"),
+				Dimensions: dims,
+				Timestamp:  aws.Time(now.Add(time.Duration(i) * time.Second)),
+				Value:      aws.Float64(float64(i + 1)),
+			}},
+		})
+		require.NoError(t, err)
+	}
+
+	listed, err := client.ListMetrics(ctx, &cloudwatch.ListMetricsInput{
+		Namespace:  aws.String("CWAgent"),
+		MetricName: aws.String("cpu"),
+		Dimensions: []cwtypes.DimensionFilter{{Name: aws.String("InstanceId"), Value: aws.String("i-123")}},
+	})
+	require.NoError(t, err)
+	assert.Len(t, listed.Metrics, 1)
+
+	stats, err := client.GetMetricStatistics(ctx, &cloudwatch.GetMetricStatisticsInput{
+		Namespace:  aws.String("CWAgent"),
+		MetricName: aws.String("cpu"),
+		Dimensions: dims,
+		StartTime:  aws.Time(now.Add(-time.Minute)),
+		EndTime:    aws.Time(now.Add(time.Minute)),
+		Period:     aws.Int32(60),
+		Statistics: []cwtypes.Statistic{cwtypes.StatisticSampleCount, cwtypes.StatisticAverage},
+	})
+	require.NoError(t, err)
+	require.Len(t, stats.Datapoints, 1)
+	assert.Equal(t, 3.0, *stats.Datapoints[0].SampleCount)
+	assert.Equal(t, 2.0, *stats.Datapoints[0].Average)
+
+	data, err := client.GetMetricData(ctx, &cloudwatch.GetMetricDataInput{
+		StartTime: aws.Time(now.Add(-time.Minute)),
+		EndTime:   aws.Time(now.Add(time.Minute)),
+		MetricDataQueries: []cwtypes.MetricDataQuery{{
+			Id: aws.String("m1"),
+			MetricStat: &cwtypes.MetricStat{
+				Metric: &cwtypes.Metric{Namespace: aws.String("CWAgent"), MetricName: aws.String("cpu"), Dimensions: dims},
+				Period: aws.Int32(60),
+				Stat:   aws.String("Maximum"),
+			},
+		}},
+	})
+	require.NoError(t, err)
+	require.Len(t, data.MetricDataResults, 1)
+	assert.Equal(t, []float64{3}, data.MetricDataResults[0].Values)
+}
+
+func TestLogsRoundTrip(t *testing.T) {
+	server, cfg := startTestServer(t)
+	client := cloudwatchlogs.NewFromConfig(cfg, func(o *cloudwatchlogs.Options) {
+		o.BaseEndpoint = aws.String(server.Endpoint())
+	})
+	ctx := context.Background()
+	group, stream := aws.String("group"), aws.String("stream")
+
+	_, err := client.GetLogEvents(ctx, &cloudwatchlogs.GetLogEventsInput{LogGroupName: group, LogStreamName: stream})
+	var notFound *cwltypes.ResourceNotFoundException
+	assert.ErrorAs(t, err, &notFound)
+
+	_, err = client.CreateLogGroup(ctx, &cloudwatchlogs.CreateLogGroupInput{LogGroupName: group})
+	require.NoError(t, err)
+	_, err = client.CreateLogStream(ctx, &cloudwatchlogs.CreateLogStreamInput{LogGroupName: group, LogStreamName: stream})
+	require.NoError(t, err)
+
+	now := time.Now().UnixMilli()
+	var events []cwltypes.InputLogEvent
+	for i := 0; i < 5; i++ {
+		events = append(events, cwltypes.InputLogEvent{Timestamp: aws.Int64(now + int64(i)), Message: aws.String(fmt.Sprintf("line %d", i))})
+	}
+	_, err = client.PutLogEvents(ctx, &cloudwatchlogs.PutLogEventsInput{LogGroupName: group, LogStreamName: stream, LogEvents: events})
+	require.NoError(t, err)
+
+	// Paginate the same way awsservice.GetLogsSince does, stopping once the token stops changing.
+	var (
+		messages  []string
+		nextToken *string
+	)
+	for {
+		output, err := client.GetLogEvents(ctx, &cloudwatchlogs.GetLogEventsInput{
+			LogGroupName:  group,
+			LogStreamName: stream,
+			StartFromHead: aws.Bool(true),
+			NextToken:     nextToken,
+			Limit:         aws.Int32(2),
+		})
+		require.NoError(t, err)
+		for _, event := range output.Events {
+			messages = append(messages, *event.Message)
+		}
+		if nextToken != nil && *output.NextForwardToken == *nextToken {
+			break
+		}
+		nextToken = output.NextForwardToken
+	}
+	assert.Equal(t, []string{"line 0", "line 1", "line 2", "line 3", "line 4"}, messages)
+
+	streams, err := client.DescribeLogStreams(ctx, &cloudwatchlogs.DescribeLogStreamsInput{LogGroupName: group})
+	require.NoError(t, err)
+	require.Len(t, streams.LogStreams, 1)
+	assert.Equal(t, now+4, *streams.LogStreams[0].LastEventTimestamp)
+
+	_, err = client.DeleteLogGroup(ctx, &cloudwatchlogs.DeleteLogGroupInput{LogGroupName: group})
+	require.NoError(t, err)
+	assert.Empty(t, server.Store().LogGroupNames(""))
+}
+
+func TestTracesRoundTrip(t *testing.T) {
+	server, cfg := startTestServer(t)
+	client := xray.NewFromConfig(cfg, func(o *xray.Options) {
+		o.BaseEndpoint = aws.String(server.Endpoint())
+	})
+	ctx := context.Background()
+	now := float64(time.Now().Unix())
+
+	document, err := json.Marshal(map[string]interface{}{
+		"trace_id":    "1-5f84c7a1-1e1e1e1e1e1e1e1e1e1e1e1e",
+		"id":          "70de5b6f19ff9a0a",
+		"name":        "service-name",
+		"origin":      "AWS::EC2::Instance",
+		"start_time":  now,
+		"end_time":    now + 1,
+		"annotations": map[string]interface{}{"test_id": "abc"},
+	})
+	require.NoError(t, err)
+	_, err = client.PutTraceSegments(ctx, &xray.PutTraceSegmentsInput{TraceSegmentDocuments: []string{string(document)}})
+	require.NoError(t, err)
+
+	for _, filter := range []string{
+		`annotation.test_id = "abc"`,
+		`service(id(name: "service-name", type: "AWS::EC2::Instance"))`,
+	} {
+		summaries, err := client.GetTraceSummaries(ctx, &xray.GetTraceSummariesInput{
+			StartTime:        aws.Time(time.Now().Add(-time.Minute)),
+			EndTime:          aws.Time(time.Now().Add(time.Minute)),
+			FilterExpression: aws.String(filter),
+		})
+		require.NoError(t, err)
+		require.Len(t, summaries.TraceSummaries, 1, filter)
+	}
+
+	summaries, err := client.GetTraceSummaries(ctx, &xray.GetTraceSummariesInput{
+		StartTime:        aws.Time(time.Now().Add(-time.Minute)),
+		EndTime:          aws.Time(time.Now().Add(time.Minute)),
+		FilterExpression: aws.String(`annotation.test_id = "other"`),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, summaries.TraceSummaries)
+
+	traces, err := client.BatchGetTraces(ctx, &xray.BatchGetTracesInput{TraceIds: []string{"1-5f84c7a1-1e1e1e1e1e1e1e1e1e1e1e1e"}})
+	require.NoError(t, err)
+	require.Len(t, traces.Traces, 1)
+	require.Len(t, traces.Traces[0].Segments, 1)
+	assert.JSONEq(t, string(document), *traces.Traces[0].Segments[0].Document)
+}
@@ -0,0 +1,462 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package localbackend
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dimension is a single CloudWatch metric dimension.
+type Dimension struct {
+	Name  string
+	Value string
+}
+
+// MetricIdentity identifies a CloudWatch metric the same way the service does: namespace, name and the
+// full set of dimensions.
+type MetricIdentity struct {
+	Namespace  string
+	MetricName string
+	Dimensions []Dimension
+}
+
+func (m MetricIdentity) key() string {
+	dims := make([]string, 0, len(m.Dimensions))
+	for _, d := range m.Dimensions {
+		dims = append(dims, d.Name+"="+d.Value)
+	}
+	sort.Strings(dims)
+	return m.Namespace + "|" + m.MetricName + "|" + strings.Join(dims, ",")
+}
+
+// Sample is a pre-aggregated set of values received at one timestamp. A single Value is stored as a
+// sample with a count of 1, Values/Counts and StatisticValues keep their aggregate form.
+type Sample struct {
+	Timestamp   time.Time
+	Sum         float64
+	Min         float64
+	Max         float64
+	SampleCount float64
+	Unit        string
+}
+
+type metricSeries struct {
+	identity MetricIdentity
+	samples  []Sample
+}
+
+// LogEvent is a single event stored in a log stream.
+type LogEvent struct {
+	Timestamp     int64
+	Message       string
+	IngestionTime int64
+}
+
+type logStream struct {
+	name         string
+	creationTime int64
+	events       []LogEvent
+}
+
+type logGroup struct {
+	name         string
+	creationTime int64
+	streams      map[string]*logStream
+}
+
+// TraceSegment is a single X-Ray segment document as sent by the agent.
+type TraceSegment struct {
+	TraceID   string
+	ID        string
+	StartTime float64
+	EndTime   float64
+	Document  string
+}
+
+// Store keeps everything the agent sent to the local backend in memory.
+type Store struct {
+	mu        sync.RWMutex
+	metrics   map[string]*metricSeries
+	logGroups map[string]*logGroup
+	traces    map[string][]TraceSegment
+	prom      map[string]*PromSeries
+}
+
+func NewStore() *Store {
+	return &Store{
+		metrics:   make(map[string]*metricSeries),
+		logGroups: make(map[string]*logGroup),
+		traces:    make(map[string][]TraceSegment),
+		prom:      make(map[string]*PromSeries),
+	}
+}
+
+// PutMetricSample records a sample for the metric, creating the metric on first use.
+func (s *Store) PutMetricSample(identity MetricIdentity, sample Sample) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := identity.key()
+	series, ok := s.metrics[key]
+	if !ok {
+		series = &metricSeries{identity: identity}
+		s.metrics[key] = series
+	}
+	series.samples = append(series.samples, sample)
+}
+
+// ListMetrics returns the metrics matching the namespace, metric name and dimension filters. Empty
+// filters match everything and a filter with an empty value only requires the dimension name.
+func (s *Store) ListMetrics(namespace, metricName string, filters []Dimension) []MetricIdentity {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var identities []MetricIdentity
+	for _, series := range s.metrics {
+		id := series.identity
+		if namespace != "" && id.Namespace != namespace {
+			continue
+		}
+		if metricName != "" && id.MetricName != metricName {
+			continue
+		}
+		if !matchesDimensionFilters(id.Dimensions, filters) {
+			continue
+		}
+		identities = append(identities, id)
+	}
+	sort.Slice(identities, func(i, j int) bool {
+		return identities[i].key() < identities[j].key()
+	})
+	return identities
+}
+
+func matchesDimensionFilters(dims []Dimension, filters []Dimension) bool {
+	for _, filter := range filters {
+		found := false
+		for _, d := range dims {
+			if d.Name == filter.Name && (filter.Value == "" || d.Value == filter.Value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Datapoint is the aggregate of all samples within one period.
+type Datapoint struct {
+	Timestamp   time.Time
+	Sum         float64
+	Min         float64
+	Max         float64
+	SampleCount float64
+	Unit        string
+}
+
+// Statistic returns the named CloudWatch statistic for the datapoint.
+func (d Datapoint) Statistic(stat string) (float64, bool) {
+	switch stat {
+	case "Sum":
+		return d.Sum, true
+	case "Minimum":
+		return d.Min, true
+	case "Maximum":
+		return d.Max, true
+	case "SampleCount":
+		return d.SampleCount, true
+	case "Average":
+		return d.Sum / d.SampleCount, true
+	default:
+		return 0, false
+	}
+}
+
+// GetDatapoints aggregates the samples of the metric into periods within [start, end). Like CloudWatch,
+// dimensions must match exactly and periods are aligned to the period boundary before start.
+// Datapoints are returned in ascending timestamp order.
+func (s *Store) GetDatapoints(identity MetricIdentity, start, end time.Time, period time.Duration) []Datapoint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	series, ok := s.metrics[identity.key()]
+	if !ok || period <= 0 {
+		return nil
+	}
+
+	origin := start.Truncate(period)
+	buckets := make(map[int64]*Datapoint)
+	for _, sample := range series.samples {
+		if sample.Timestamp.Before(start) || !sample.Timestamp.Before(end) {
+			continue
+		}
+		bucketStart := origin.Add(sample.Timestamp.Sub(origin) / period * period)
+		dp, ok := buckets[bucketStart.UnixNano()]
+		if !ok {
+			dp = &Datapoint{Timestamp: bucketStart, Min: math.Inf(1), Max: math.Inf(-1), Unit: sample.Unit}
+			buckets[bucketStart.UnixNano()] = dp
+		}
+		dp.Sum += sample.Sum
+		dp.SampleCount += sample.SampleCount
+		dp.Min = math.Min(dp.Min, sample.Min)
+		dp.Max = math.Max(dp.Max, sample.Max)
+	}
+
+	datapoints := make([]Datapoint, 0, len(buckets))
+	for _, dp := range buckets {
+		datapoints = append(datapoints, *dp)
+	}
+	sort.Slice(datapoints, func(i, j int) bool {
+		return datapoints[i].Timestamp.Before(datapoints[j].Timestamp)
+	})
+	return datapoints
+}
+
+// CreateLogGroup returns false if the log group already exists.
+func (s *Store) CreateLogGroup(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.logGroups[name]; ok {
+		return false
+	}
+	s.logGroups[name] = &logGroup{name: name, creationTime: time.Now().UnixMilli(), streams: make(map[string]*logStream)}
+	return true
+}
+
+// CreateLogStream returns errResourceNotFound if the group does not exist and errResourceAlreadyExists
+// if the stream does.
+func (s *Store) CreateLogStream(groupName, streamName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	group, ok := s.logGroups[groupName]
+	if !ok {
+		return errResourceNotFound
+	}
+	if _, ok := group.streams[streamName]; ok {
+		return errResourceAlreadyExists
+	}
+	group.streams[streamName] = &logStream{name: streamName, creationTime: time.Now().UnixMilli()}
+	return nil
+}
+
+func (s *Store) DeleteLogGroup(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.logGroups[name]; !ok {
+		return errResourceNotFound
+	}
+	delete(s.logGroups, name)
+	return nil
+}
+
+func (s *Store) DeleteLogStream(groupName, streamName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	group, ok := s.logGroups[groupName]
+	if !ok {
+		return errResourceNotFound
+	}
+	if _, ok := group.streams[streamName]; !ok {
+		return errResourceNotFound
+	}
+	delete(group.streams, streamName)
+	return nil
+}
+
+// PutLogEvents appends the events to an existing stream.
+func (s *Store) PutLogEvents(groupName, streamName string, events []LogEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	group, ok := s.logGroups[groupName]
+	if !ok {
+		return errResourceNotFound
+	}
+	stream, ok := group.streams[streamName]
+	if !ok {
+		return errResourceNotFound
+	}
+	stream.events = append(stream.events, events...)
+	return nil
+}
+
+// GetLogEvents returns the events of the stream within [start, end) in timestamp order. Zero start or
+// end leave that side of the range open.
+func (s *Store) GetLogEvents(groupName, streamName string, start, end int64) ([]LogEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	group, ok := s.logGroups[groupName]
+	if !ok {
+		return nil, errResourceNotFound
+	}
+	stream, ok := group.streams[streamName]
+	if !ok {
+		return nil, errResourceNotFound
+	}
+	var events []LogEvent
+	for _, event := range stream.events {
+		if start != 0 && event.Timestamp < start {
+			continue
+		}
+		if end != 0 && event.Timestamp >= end {
+			continue
+		}
+		events = append(events, event)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp < events[j].Timestamp
+	})
+	return events, nil
+}
+
+// LogGroupNames returns the names of the log groups starting with prefix, sorted.
+func (s *Store) LogGroupNames(prefix string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var names []string
+	for name := range s.logGroups {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LogStreamInfo summarizes a log stream for DescribeLogStreams.
+type LogStreamInfo struct {
+	Name               string
+	CreationTime       int64
+	FirstEventTime     int64
+	LastEventTimestamp int64
+}
+
+// LogStreams returns the streams of the group sorted by name.
+func (s *Store) LogStreams(groupName string) ([]LogStreamInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	group, ok := s.logGroups[groupName]
+	if !ok {
+		return nil, errResourceNotFound
+	}
+	infos := make([]LogStreamInfo, 0, len(group.streams))
+	for _, stream := range group.streams {
+		info := LogStreamInfo{Name: stream.name, CreationTime: stream.creationTime}
+		for _, event := range stream.events {
+			if info.FirstEventTime == 0 || event.Timestamp < info.FirstEventTime {
+				info.FirstEventTime = event.Timestamp
+			}
+			if event.Timestamp > info.LastEventTimestamp {
+				info.LastEventTimestamp = event.Timestamp
+			}
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos, nil
+}
+
+func (s *Store) PutTraceSegment(segment TraceSegment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traces[segment.TraceID] = append(s.traces[segment.TraceID], segment)
+}
+
+// GetTrace returns the segments recorded for the trace ID.
+func (s *Store) GetTrace(traceID string) ([]TraceSegment, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	segments, ok := s.traces[traceID]
+	return append([]TraceSegment(nil), segments...), ok
+}
+
+// TraceIDs returns the IDs of all recorded traces, sorted.
+func (s *Store) TraceIDs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.traces))
+	for id := range s.traces {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// PromSample is a sample of an OTLP series as the PromQL API serves it. Histogram is set instead of Value
+// for histogram data points.
+type PromSample struct {
+	Timestamp time.Time
+	Value     float64
+	Histogram *PromHistogram
+}
+
+// PromHistogram is a histogram sample. Each bucket counts the values within its bounds.
+type PromHistogram struct {
+	Count   float64
+	Sum     float64
+	Buckets []PromBucket
+}
+
+// PromBucket is a histogram bucket. Boundaries follows the PromQL API: 0 is (Lower, Upper], 1 is
+// [Lower, Upper) and 3 is [Lower, Upper].
+type PromBucket struct {
+	Boundaries int
+	Lower      float64
+	Upper      float64
+	Count      float64
+}
+
+// PromSeries is an OTLP series identified by its full label set, including __name__.
+type PromSeries struct {
+	Labels  map[string]string
+	Samples []PromSample
+}
+
+func promKey(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for name, value := range labels {
+		pairs = append(pairs, name+"\xff"+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "\xfe")
+}
+
+// PutPromSample records a sample for the series with the labels, creating the series on first use.
+func (s *Store) PutPromSample(labels map[string]string, sample PromSample) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := promKey(labels)
+	series, ok := s.prom[key]
+	if !ok {
+		series = &PromSeries{Labels: labels}
+		s.prom[key] = series
+	}
+	series.Samples = append(series.Samples, sample)
+}
+
+// SelectPromSeries returns copies of the series whose labels match, with the samples in timestamp order.
+func (s *Store) SelectPromSeries(match func(labels map[string]string) bool) []PromSeries {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var selected []PromSeries
+	for _, series := range s.prom {
+		if !match(series.Labels) {
+			continue
+		}
+		samples := append([]PromSample(nil), series.Samples...)
+		sort.SliceStable(samples, func(i, j int) bool {
+			return samples[i].Timestamp.Before(samples[j].Timestamp)
+		})
+		selected = append(selected, PromSeries{Labels: series.Labels, Samples: samples})
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return promKey(selected[i].Labels) < promKey(selected[j].Labels)
+	})
+	return selected
+}
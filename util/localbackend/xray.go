@@ -0,0 +1,229 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package localbackend
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	annotationFilterRegex = regexp.MustCompile(`^annotation\.([\w.-]+)\s*=\s*(.+)$`)
+	serviceFilterRegex    = regexp.MustCompile(`^\(?service\(id\(name:\s*"([^"]*)"(?:\s*,\s*type:\s*"([^"]*)")?\)\)\)?$`)
+)
+
+type segmentDocument struct {
+	TraceID     string                 `json:"trace_id"`
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Origin      string                 `json:"origin"`
+	StartTime   float64                `json:"start_time"`
+	EndTime     float64                `json:"end_time"`
+	Annotations map[string]interface{} `json:"annotations"`
+}
+
+type putTraceSegmentsRequest struct {
+	TraceSegmentDocuments []string `json:"TraceSegmentDocuments"`
+}
+
+type unprocessedTraceSegment struct {
+	Id        string `json:"Id"`
+	ErrorCode string `json:"ErrorCode"`
+	Message   string `json:"Message"`
+}
+
+type batchGetTracesRequest struct {
+	TraceIds []string `json:"TraceIds"`
+}
+
+type xraySegment struct {
+	Id       string `json:"Id"`
+	Document string `json:"Document"`
+}
+
+type xrayTrace struct {
+	Id       string        `json:"Id"`
+	Duration float64       `json:"Duration"`
+	Segments []xraySegment `json:"Segments"`
+}
+
+type getTraceSummariesRequest struct {
+	StartTime        float64 `json:"StartTime"`
+	EndTime          float64 `json:"EndTime"`
+	FilterExpression string  `json:"FilterExpression"`
+}
+
+type traceSummary struct {
+	Id       string  `json:"Id"`
+	Duration float64 `json:"Duration"`
+}
+
+func (s *Server) putTraceSegments(w http.ResponseWriter, body []byte) {
+	var request putTraceSegmentsRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		writeXrayError(w, "InvalidRequestException", err.Error())
+		return
+	}
+	unprocessed := make([]unprocessedTraceSegment, 0)
+	for _, document := range request.TraceSegmentDocuments {
+		var segment segmentDocument
+		if err := json.Unmarshal([]byte(document), &segment); err != nil || segment.TraceID == "" || segment.ID == "" {
+			unprocessed = append(unprocessed, unprocessedTraceSegment{Id: segment.ID, ErrorCode: "InvalidSegment", Message: "segment must be a JSON document with trace_id and id"})
+			continue
+		}
+		s.store.PutTraceSegment(TraceSegment{
+			TraceID:   segment.TraceID,
+			ID:        segment.ID,
+			StartTime: segment.StartTime,
+			EndTime:   segment.EndTime,
+			Document:  document,
+		})
+	}
+	writeJSON(w, map[string]interface{}{"UnprocessedTraceSegments": unprocessed})
+}
+
+func (s *Server) batchGetTraces(w http.ResponseWriter, body []byte) {
+	var request batchGetTracesRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		writeXrayError(w, "InvalidRequestException", err.Error())
+		return
+	}
+	traces := make([]xrayTrace, 0, len(request.TraceIds))
+	unprocessed := make([]string, 0)
+	for _, traceID := range request.TraceIds {
+		segments, ok := s.store.GetTrace(traceID)
+		if !ok {
+			unprocessed = append(unprocessed, traceID)
+			continue
+		}
+		trace := xrayTrace{Id: traceID, Duration: traceDuration(segments)}
+		for _, segment := range segments {
+			trace.Segments = append(trace.Segments, xraySegment{Id: segment.ID, Document: segment.Document})
+		}
+		traces = append(traces, trace)
+	}
+	writeJSON(w, map[string]interface{}{"Traces": traces, "UnprocessedTraceIds": unprocessed})
+}
+
+// getTraceSummaries returns the traces with at least one segment starting within the time range that
+// matches the filter expression. Only the filters used by this repository are understood: AND-ed
+// annotation equality (see awsservice.FilterExpression) and service(id(name, type)).
+func (s *Server) getTraceSummaries(w http.ResponseWriter, body []byte) {
+	var request getTraceSummariesRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		writeXrayError(w, "InvalidRequestException", err.Error())
+		return
+	}
+	filter, err := parseTraceFilter(request.FilterExpression)
+	if err != nil {
+		writeXrayError(w, "InvalidRequestException", err.Error())
+		return
+	}
+
+	summaries := make([]traceSummary, 0)
+	for _, traceID := range s.store.TraceIDs() {
+		segments, _ := s.store.GetTrace(traceID)
+		for _, segment := range segments {
+			if segment.StartTime < request.StartTime || segment.StartTime > request.EndTime {
+				continue
+			}
+			if filter(segment) {
+				summaries = append(summaries, traceSummary{Id: traceID, Duration: traceDuration(segments)})
+				break
+			}
+		}
+	}
+	writeJSON(w, map[string]interface{}{
+		"TraceSummaries":       summaries,
+		"ApproximateTime":      float64(time.Now().Unix()),
+		"TracesProcessedCount": len(summaries),
+	})
+}
+
+type traceFilter func(segment TraceSegment) bool
+
+func parseTraceFilter(expression string) (traceFilter, error) {
+	var clauses []traceFilter
+	for _, clause := range strings.Split(expression, " AND ") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+		if match := annotationFilterRegex.FindStringSubmatch(clause); match != nil {
+			key := match[1]
+			var expected interface{}
+			if err := json.Unmarshal([]byte(match[2]), &expected); err != nil {
+				return nil, fmt.Errorf("invalid annotation value in filter %q: %w", clause, err)
+			}
+			clauses = append(clauses, func(segment TraceSegment) bool {
+				actual, ok := parseSegment(segment).Annotations[key]
+				return ok && reflect.DeepEqual(actual, expected)
+			})
+			continue
+		}
+		if match := serviceFilterRegex.FindStringSubmatch(clause); match != nil {
+			name, origin := match[1], match[2]
+			clauses = append(clauses, func(segment TraceSegment) bool {
+				doc := parseSegment(segment)
+				return doc.Name == name && (origin == "" || doc.Origin == origin)
+			})
+			continue
+		}
+		return nil, fmt.Errorf("filter %q is not supported by the local backend", clause)
+	}
+	return func(segment TraceSegment) bool {
+		for _, clause := range clauses {
+			if !clause(segment) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func parseSegment(segment TraceSegment) segmentDocument {
+	var doc segmentDocument
+	if err := json.Unmarshal([]byte(segment.Document), &doc); err != nil {
+		log.Printf("Local backend failed to parse stored segment %s: %v", segment.ID, err)
+	}
+	return doc
+}
+
+func traceDuration(segments []TraceSegment) float64 {
+	var start, end float64
+	for i, segment := range segments {
+		if i == 0 || segment.StartTime < start {
+			start = segment.StartTime
+		}
+		if segment.EndTime > end {
+			end = segment.EndTime
+		}
+	}
+	if end < start {
+		return 0
+	}
+	return end - start
+}
+
+func writeJSON(w http.ResponseWriter, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Unable to write response: %v", err)
+	}
+}
+
+func writeXrayError(w http.ResponseWriter, errorType, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Amzn-ErrorType", errorType)
+	w.WriteHeader(http.StatusBadRequest)
+	if err := json.NewEncoder(w).Encode(map[string]string{"Message": message}); err != nil {
+		log.Printf("Unable to write response: %v", err)
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+// Package metricassert declares expectations on metric time series, e.g
+//
+//	err := metricassert.Expect(result).AllWithin(0, 100).SampleCount(60).NoGaps(time.Minute).Err()
+//
+// Every expectation is checked, and Err reports all the failures along with the series.
+package metricassert
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// Expectation collects the failures of the expectations chained on a series.
+type Expectation struct {
+	series   Series
+	failures []string
+	// failed are the indexes of the points that failed an expectation
+	failed map[int]struct{}
+}
+
+// Expect starts the expectations on the result of a GetMetricData query.
+func Expect(result types.MetricDataResult) *Expectation {
+	return ExpectSeries(FromMetricDataResult(result))
+}
+
+// ExpectSeries starts the expectations on a series.
+func ExpectSeries(series Series) *Expectation {
+	return &Expectation{series: series, failed: map[int]struct{}{}}
+}
+
+func (e *Expectation) fail(format string, args ...any) {
+	e.failures = append(e.failures, fmt.Sprintf(format, args...))
+}
+
+// eachValue fails the points for which check returns a failure.
+func (e *Expectation) eachValue(check func(value float64) string) *Expectation {
+	for i, value := range e.series.Values {
+		if failure := check(value); failure != "" {
+			e.failed[i] = struct{}{}
+			e.fail("value %g at %s %s", value, e.series.point(i), failure)
+		}
+	}
+	return e
+}
+
+// NotEmpty expects at least one point.
+func (e *Expectation) NotEmpty() *Expectation {
+	if e.series.Len() == 0 {
+		e.fail("no data points")
+	}
+	return e
+}
+
+// AllWithin expects every value to be in [lo, hi].
+func (e *Expectation) AllWithin(lo, hi float64) *Expectation {
+	return e.eachValue(func(value float64) string {
+		if value < lo || value > hi || math.IsNaN(value) {
+			return fmt.Sprintf("is outside [%g, %g]", lo, hi)
+		}
+		return ""
+	})
+}
+
+// AllAtLeast expects every value to be at least min.
+func (e *Expectation) AllAtLeast(min float64) *Expectation {
+	return e.AllWithin(min, math.Inf(1))
+}
+
+// AllAtMost expects every value to be at most max.
+func (e *Expectation) AllAtMost(max float64) *Expectation {
+	return e.AllWithin(math.Inf(-1), max)
+}
+
+// AllNear expects every value to be within a relative bound of expected (e.g 0.1 for ±10%). A zero expected
+// value only matches zero.
+func (e *Expectation) AllNear(expected, bound float64) *Expectation {
+	lo, hi := relativeBounds(expected, bound)
+	return e.AllWithin(lo, hi)
+}
+
+// MeanNear expects the average of the values to be within a relative bound of expected.
+func (e *Expectation) MeanNear(expected, bound float64) *Expectation {
+	if e.series.Len() == 0 {
+		e.fail("no data points to average")
+		return e
+	}
+	var sum float64
+	for _, value := range e.series.Values {
+		sum += value
+	}
+	mean := sum / float64(e.series.Len())
+	if lo, hi := relativeBounds(expected, bound); mean < lo || mean > hi || math.IsNaN(mean) {
+		e.fail("mean %g is outside [%g, %g]", mean, lo, hi)
+	}
+	return e
+}
+
+// SampleCount expects exactly count points.
+func (e *Expectation) SampleCount(count int) *Expectation {
+	return e.SampleCountBetween(count, count)
+}
+
+// SampleCountBetween expects the number of points to be in [lo, hi].
+func (e *Expectation) SampleCountBetween(lo, hi int) *Expectation {
+	if n := e.series.Len(); n < lo || n > hi {
+		if lo == hi {
+			e.fail("%d data points, want %d", n, lo)
+		} else {
+			e.fail("%d data points, want between %d and %d", n, lo, hi)
+		}
+	}
+	return e
+}
+
+// NoGaps expects consecutive points to be at most period apart.
+func (e *Expectation) NoGaps(period time.Duration) *Expectation {
+	if e.series.Len() > 0 && e.series.Timestamps == nil {
+		e.fail("no timestamps to look for gaps")
+		return e
+	}
+	for i := 1; i < len(e.series.Timestamps); i++ {
+		if gap := e.series.Timestamps[i].Sub(e.series.Timestamps[i-1]); gap > period {
+			e.failed[i] = struct{}{}
+			e.fail("gap of %v before %s, want at most %v", gap, e.series.point(i), period)
+		}
+	}
+	return e
+}
+
+// GapsAtMost expects at most n gaps longer than period between consecutive points, e.g to tolerate an
+// occasional missed scrape that NoGaps would fail on.
+func (e *Expectation) GapsAtMost(n int, period time.Duration) *Expectation {
+	if e.series.Len() > 0 && e.series.Timestamps == nil {
+		e.fail("no timestamps to look for gaps")
+		return e
+	}
+	var gaps []int
+	for i := 1; i < len(e.series.Timestamps); i++ {
+		if e.series.Timestamps[i].Sub(e.series.Timestamps[i-1]) > period {
+			gaps = append(gaps, i)
+		}
+	}
+	if len(gaps) <= n {
+		return e
+	}
+	descriptions := make([]string, len(gaps))
+	for j, i := range gaps {
+		e.failed[i] = struct{}{}
+		descriptions[j] = fmt.Sprintf("%v before %s", e.series.Timestamps[i].Sub(e.series.Timestamps[i-1]), e.series.point(i))
+	}
+	e.fail("%d gaps longer than %v, want at most %d: %s", len(gaps), period, n, strings.Join(descriptions, ", "))
+	return e
+}
+
+// SpacingWithin expects consecutive points to be between lo and hi apart, e.g around the collection interval
+// of the raw samples of a metric. Closer points are duplicates and further ones gaps.
+func (e *Expectation) SpacingWithin(lo, hi time.Duration) *Expectation {
+	if e.series.Len() > 0 && e.series.Timestamps == nil {
+		e.fail("no timestamps to check the spacing")
+		return e
+	}
+	for i := 1; i < len(e.series.Timestamps); i++ {
+		if spacing := e.series.Timestamps[i].Sub(e.series.Timestamps[i-1]); spacing < lo || spacing > hi {
+			e.failed[i] = struct{}{}
+			e.fail("spacing of %v before %s is outside [%v, %v]", spacing, e.series.point(i), lo, hi)
+		}
+	}
+	return e
+}
+
+// FreshAt expects the last point to be at most maxAge before at, so the series has not gone stale.
+func (e *Expectation) FreshAt(at time.Time, maxAge time.Duration) *Expectation {
+	if e.series.Len() > 0 && e.series.Timestamps == nil {
+		e.fail("no timestamps to check the freshness")
+		return e
+	}
+	if e.series.Len() == 0 {
+		e.fail("no data points to check the freshness")
+		return e
+	}
+	last := e.series.Len() - 1
+	if age := at.Sub(e.series.Timestamps[last]); age > maxAge {
+		e.failed[last] = struct{}{}
+		e.fail("last point at %s is %v old at %s, want at most %v", e.series.point(last), age, at.UTC().Format(time.RFC3339), maxAge)
+	}
+	return e
+}
+
+// MonotonicIncreasing expects every value to be at least the one before it, like a counter that never resets.
+func (e *Expectation) MonotonicIncreasing() *Expectation {
+	for i := 1; i < e.series.Len(); i++ {
+		if e.series.Values[i] < e.series.Values[i-1] {
+			e.failed[i] = struct{}{}
+			e.fail("value %g at %s decreases from %g", e.series.Values[i], e.series.point(i), e.series.Values[i-1])
+		}
+	}
+	return e
+}
+
+// MonotonicDecreasing expects every value to be at most the one before it.
+func (e *Expectation) MonotonicDecreasing() *Expectation {
+	for i := 1; i < e.series.Len(); i++ {
+		if e.series.Values[i] > e.series.Values[i-1] {
+			e.failed[i] = struct{}{}
+			e.fail("value %g at %s increases from %g", e.series.Values[i], e.series.point(i), e.series.Values[i-1])
+		}
+	}
+	return e
+}
+
+// ResetsAtMost expects a counter to decrease at most n times, e.g 0 on a process that must not restart.
+func (e *Expectation) ResetsAtMost(n int) *Expectation {
+	var resets []int
+	for i := 1; i < e.series.Len(); i++ {
+		if e.series.Values[i] < e.series.Values[i-1] {
+			resets = append(resets, i)
+		}
+	}
+	if len(resets) > n {
+		var points []string
+		for _, i := range resets {
+			e.failed[i] = struct{}{}
+			points = append(points, e.series.point(i))
+		}
+		e.fail("%d counter resets at %s, want at most %d", len(resets), strings.Join(points, ", "), n)
+	}
+	return e
+}
+
+// RateNear expects the change per second between consecutive points to be within a relative bound of expected,
+// e.g the rate of a counter.
+func (e *Expectation) RateNear(expected, bound float64) *Expectation {
+	if e.series.Len() > 0 && e.series.Timestamps == nil {
+		e.fail("no timestamps to compute the rate")
+		return e
+	}
+	if e.series.Len() < 2 {
+		e.fail("%d data points, want at least 2 to compute the rate", e.series.Len())
+		return e
+	}
+	lo, hi := relativeBounds(expected, bound)
+	for i := 1; i < e.series.Len(); i++ {
+		elapsed := e.series.Timestamps[i].Sub(e.series.Timestamps[i-1]).Seconds()
+		if elapsed <= 0 {
+			continue
+		}
+		if rate := (e.series.Values[i] - e.series.Values[i-1]) / elapsed; rate < lo || rate > hi {
+			e.failed[i] = struct{}{}
+			e.fail("rate %g/s at %s is outside [%g, %g]", rate, e.series.point(i), lo, hi)
+		}
+	}
+	return e
+}
+
+// Satisfies expects check to accept the series, for expectations this package does not have.
+func (e *Expectation) Satisfies(name string, check func(Series) error) *Expectation {
+	if err := check(e.series); err != nil {
+		e.fail("%s: %v", name, err)
+	}
+	return e
+}
+
+// Err returns nil when every expectation held, otherwise an error listing the failures followed by the series.
+func (e *Expectation) Err() error {
+	if len(e.failures) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d expectations failed on %q:", len(e.failures), e.series.Label)
+	for _, failure := range e.failures {
+		b.WriteString("\n  - ")
+		b.WriteString(failure)
+	}
+	b.WriteString("\n")
+	b.WriteString(e.series.render(e.failed))
+	return errors.New(b.String())
+}
+
+func relativeBounds(expected, bound float64) (float64, float64) {
+	lo, hi := expected*(1-bound), expected*(1+bound)
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	return lo, hi
+}
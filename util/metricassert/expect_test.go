@@ -0,0 +1,167 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package metricassert
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func minutes(values ...float64) Series {
+	s := Series{Label: "cpu", Values: values}
+	for i := range values {
+		s.Timestamps = append(s.Timestamps, start.Add(time.Duration(i)*time.Minute))
+	}
+	return s
+}
+
+func TestFromMetricDataResult(t *testing.T) {
+	s := FromMetricDataResult(types.MetricDataResult{
+		Id:         aws.String("m1"),
+		Label:      aws.String("cpu_usage_idle"),
+		Timestamps: []time.Time{start.Add(2 * time.Minute), start.Add(time.Minute), start},
+		Values:     []float64{3, 2, 1},
+		StatusCode: types.StatusCodePartialData,
+	})
+	assert.Equal(t, Series{
+		Label:      "cpu_usage_idle",
+		Timestamps: []time.Time{start, start.Add(time.Minute), start.Add(2 * time.Minute)},
+		Values:     []float64{1, 2, 3},
+		Partial:    true,
+	}, s)
+	assert.Equal(t, "m1", FromMetricDataResult(types.MetricDataResult{Id: aws.String("m1")}).Label)
+}
+
+func TestExpectations(t *testing.T) {
+	testCases := map[string]struct {
+		expectation *Expectation
+		want        []string
+	}{
+		"AllHold": {
+			expectation: ExpectSeries(minutes(1, 2, 2, 3)).NotEmpty().AllWithin(0, 3).SampleCount(4).NoGaps(time.Minute).MonotonicIncreasing(),
+		},
+		"Empty": {
+			expectation: ExpectSeries(minutes()).NotEmpty().MeanNear(1, 0.1).AllWithin(0, 1),
+			want:        []string{"no data points", "no data points to average"},
+		},
+		"Bounds": {
+			expectation: ExpectSeries(minutes(-1, 50, 101)).AllAtLeast(0).AllAtMost(100),
+			want: []string{
+				"value -1 at 2024-01-01T00:00:00Z is outside [0, +Inf]",
+				"value 101 at 2024-01-01T00:02:00Z is outside [-Inf, 100]",
+			},
+		},
+		"Near": {
+			expectation: ExpectSeries(minutes(9, 10, 12)).AllNear(10, 0.1).MeanNear(10, 0.1),
+			want:        []string{"value 12 at 2024-01-01T00:02:00Z is outside [9, 11]"},
+		},
+		"NegativeExpectedValue": {
+			expectation: ExpectSeries(minutes(-10)).AllNear(-10, 0.1),
+		},
+		"SampleCount": {
+			expectation: ExpectSeries(minutes(1, 2)).SampleCount(3).SampleCountBetween(3, 5),
+			want:        []string{"2 data points, want 3", "2 data points, want between 3 and 5"},
+		},
+		"Gaps": {
+			expectation: ExpectSeries(Series{
+				Label:      "cpu",
+				Timestamps: []time.Time{start, start.Add(time.Minute), start.Add(4 * time.Minute)},
+				Values:     []float64{1, 1, 1},
+			}).NoGaps(time.Minute),
+			want: []string{"gap of 3m0s before 2024-01-01T00:04:00Z, want at most 1m0s"},
+		},
+		"GapsWithoutTimestamps": {
+			expectation: ExpectSeries(FromValues("cpu", []float64{1})).NoGaps(time.Minute),
+			want:        []string{"no timestamps to look for gaps"},
+		},
+		"Monotonic": {
+			expectation: ExpectSeries(FromValues("requests", []float64{1, 3, 2})).MonotonicIncreasing().MonotonicDecreasing(),
+			want:        []string{"value 2 at #2 decreases from 3", "value 3 at #1 increases from 1"},
+		},
+		"Spacing": {
+			expectation: ExpectSeries(Series{
+				Label:      "cpu",
+				Timestamps: []time.Time{start, start.Add(time.Minute), start.Add(70 * time.Second), start.Add(4 * time.Minute)},
+				Values:     []float64{1, 1, 1, 1},
+			}).SpacingWithin(30*time.Second, 2*time.Minute),
+			want: []string{
+				"spacing of 10s before 2024-01-01T00:01:10Z is outside [30s, 2m0s]",
+				"spacing of 2m50s before 2024-01-01T00:04:00Z is outside [30s, 2m0s]",
+			},
+		},
+		"GapsAtMost": {
+			expectation: ExpectSeries(Series{Label: "cpu", Timestamps: []time.Time{start, start.Add(time.Minute), start.Add(3 * time.Minute), start.Add(4 * time.Minute)}, Values: []float64{1, 1, 1, 1}}).
+				GapsAtMost(1, time.Minute).GapsAtMost(0, 2*time.Minute),
+		},
+		"TooManyGaps": {
+			expectation: ExpectSeries(Series{Label: "cpu", Timestamps: []time.Time{start, start.Add(2 * time.Minute), start.Add(3 * time.Minute), start.Add(5 * time.Minute)}, Values: []float64{1, 1, 1, 1}}).
+				GapsAtMost(1, time.Minute),
+			want: []string{"2 gaps longer than 1m0s, want at most 1: 2m0s before 2024-01-01T00:02:00Z, 2m0s before 2024-01-01T00:05:00Z"},
+		},
+		"Fresh": {
+			expectation: ExpectSeries(minutes(1, 2)).FreshAt(start.Add(2*time.Minute), time.Minute).FreshAt(start.Add(5*time.Minute), 2*time.Minute),
+			want:        []string{"last point at 2024-01-01T00:01:00Z is 4m0s old at 2024-01-01T00:05:00Z, want at most 2m0s"},
+		},
+		"FreshWithoutPoints": {
+			expectation: ExpectSeries(minutes()).FreshAt(start, time.Minute),
+			want:        []string{"no data points to check the freshness"},
+		},
+		"Resets": {
+			expectation: ExpectSeries(minutes(5, 10, 2, 4, 1)).ResetsAtMost(2).ResetsAtMost(1),
+			want:        []string{"2 counter resets at 2024-01-01T00:02:00Z, 2024-01-01T00:04:00Z, want at most 1"},
+		},
+		"Rate": {
+			// 60 per minute is 1/s, then the counter stalls
+			expectation: ExpectSeries(minutes(0, 60, 120, 120)).RateNear(1, 0.1),
+			want:        []string{"rate 0/s at 2024-01-01T00:03:00Z is outside [0.9, 1.1]"},
+		},
+		"RateOfSinglePoint": {
+			expectation: ExpectSeries(minutes(1)).RateNear(1, 0.1),
+			want:        []string{"1 data points, want at least 2 to compute the rate"},
+		},
+		"Satisfies": {
+			expectation: ExpectSeries(minutes(1)).Satisfies("even", func(Series) error { return errors.New("1 is odd") }),
+			want:        []string{"even: 1 is odd"},
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testCase.want, testCase.expectation.failures)
+			if len(testCase.want) == 0 {
+				assert.NoError(t, testCase.expectation.Err())
+			} else {
+				assert.Error(t, testCase.expectation.Err())
+			}
+		})
+	}
+}
+
+func TestErrRendersSeries(t *testing.T) {
+	err := ExpectSeries(minutes(1, 2, 200)).AllWithin(0, 100).Err()
+	require.Error(t, err)
+	assert.Equal(t, `1 expectations failed on "cpu":
+  - value 200 at 2024-01-01T00:02:00Z is outside [0, 100]
+series "cpu" (3 points):
+  2024-01-01T00:00:00Z 1
+  2024-01-01T00:01:00Z 2
+  2024-01-01T00:02:00Z 200  <--`, err.Error())
+
+	values := make([]float64, 100)
+	values[50] = -1
+	err = ExpectSeries(minutes(values...)).AllAtLeast(0).Err()
+	require.Error(t, err)
+	lines := strings.Split(err.Error(), "\n")
+	// the failure, the header, the head and the tail of the series, the failed point and two elisions
+	assert.Len(t, lines, 2+1+maxRenderedPoints+1+2)
+	assert.Contains(t, err.Error(), "2024-01-01T00:50:00Z -1  <--")
+}
@@ -0,0 +1,102 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package metricassert
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// maxRenderedPoints is the number of points rendered in full, longer series only render their head, their tail
+// and the points failing an expectation.
+const maxRenderedPoints = 30
+
+// Series is a time series sorted by ascending timestamp. Timestamps may be nil when only the values are known.
+type Series struct {
+	Label      string
+	Timestamps []time.Time
+	Values     []float64
+	// Partial is set when CloudWatch had more data points than it returned
+	Partial bool
+}
+
+// FromMetricDataResult converts the result of a GetMetricData query, which is sorted by descending timestamp
+// by default, to a series.
+func FromMetricDataResult(result types.MetricDataResult) Series {
+	s := Series{Partial: result.StatusCode == types.StatusCodePartialData}
+	if result.Label != nil {
+		s.Label = *result.Label
+	} else if result.Id != nil {
+		s.Label = *result.Id
+	}
+	s.Values = append([]float64(nil), result.Values...)
+	if len(result.Timestamps) == len(result.Values) {
+		s.Timestamps = append([]time.Time(nil), result.Timestamps...)
+		sort.Sort(byTimestamp(s))
+	}
+	return s
+}
+
+// FromValues returns a series of values without timestamps.
+func FromValues(label string, values []float64) Series {
+	return Series{Label: label, Values: values}
+}
+
+func (s Series) Len() int {
+	return len(s.Values)
+}
+
+// point returns the timestamp of the i-th point, or its index when the series has no timestamps.
+func (s Series) point(i int) string {
+	if s.Timestamps == nil {
+		return fmt.Sprintf("#%d", i)
+	}
+	return s.Timestamps[i].UTC().Format(time.RFC3339)
+}
+
+// render lists the points of the series, marking the failed ones.
+func (s Series) render(failed map[int]struct{}) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "series %q (%d points", s.Label, s.Len())
+	if s.Partial {
+		b.WriteString(", partial data")
+	}
+	b.WriteString("):")
+	skipping := false
+	for i, value := range s.Values {
+		_, isFailed := failed[i]
+		if s.Len() > maxRenderedPoints && !isFailed && i >= maxRenderedPoints/2 && i < s.Len()-maxRenderedPoints/2 {
+			if !skipping {
+				b.WriteString("\n  ...")
+				skipping = true
+			}
+			continue
+		}
+		skipping = false
+		fmt.Fprintf(&b, "\n  %-20s %g", s.point(i), value)
+		if isFailed {
+			b.WriteString("  <--")
+		}
+	}
+	return b.String()
+}
+
+type byTimestamp Series
+
+func (s byTimestamp) Len() int {
+	return len(s.Values)
+}
+
+func (s byTimestamp) Less(i, j int) bool {
+	return s.Timestamps[i].Before(s.Timestamps[j])
+}
+
+func (s byTimestamp) Swap(i, j int) {
+	s.Timestamps[i], s.Timestamps[j] = s.Timestamps[j], s.Timestamps[i]
+	s.Values[i], s.Values[j] = s.Values[j], s.Values[i]
+}
@@ -0,0 +1,270 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package otelmetrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Label scopes of MetricLabels, as named in a CardinalityReport.
+const (
+	LabelScopeResource        = "resource"
+	LabelScopeInstrumentation = "instrumentation"
+	LabelScopeDatapoint       = "datapoint"
+	LabelScopeAWS             = "aws"
+	LabelScopeAWSCloudWatch   = "aws.cloudwatch"
+)
+
+// DefaultWarnRatio is the fraction of a limit from which a metric is flagged as approaching it.
+const DefaultWarnRatio = 0.8
+
+// topKeyCount is the number of attribute keys with the most values a MetricCardinality lists.
+const topKeyCount = 5
+
+var labelScopes = []string{LabelScopeResource, LabelScopeInstrumentation, LabelScopeDatapoint, LabelScopeAWS, LabelScopeAWSCloudWatch}
+
+// MetricResultSource returns the current series of a metric: a QueryCache reads them from the PromQL API
+// and RecordedResults from saved responses.
+type MetricResultSource interface {
+	Get(ctx context.Context, metricName string) ([]MetricResult, error)
+}
+
+// Scopes returns the labels of each scope by scope name.
+func (ml MetricLabels) Scopes() map[string]map[string]string {
+	return map[string]map[string]string{
+		LabelScopeResource:        ml.Resource,
+		LabelScopeInstrumentation: ml.Instrumentation,
+		LabelScopeDatapoint:       ml.Datapoint,
+		LabelScopeAWS:             ml.AWS,
+		LabelScopeAWSCloudWatch:   ml.AWSCloudWatch,
+	}
+}
+
+// CardinalityLimits are the budgets the metrics are checked against. A zero limit is not checked.
+type CardinalityLimits struct {
+	// Attributes is the most attributes of a series over all scopes.
+	Attributes int `json:"attributes,omitempty"`
+	// ScopeAttributes is the most attributes of a series in a scope, by scope name (e.g resource).
+	ScopeAttributes map[string]int `json:"scope_attributes,omitempty"`
+	// Series is the most series of a metric.
+	Series int `json:"series,omitempty"`
+	// WarnRatio is the fraction of a limit from which a metric is flagged, DefaultWarnRatio when zero.
+	WarnRatio float64 `json:"warn_ratio,omitempty"`
+}
+
+// Distribution summarizes the attribute counts of the series of a metric.
+type Distribution struct {
+	Min  int     `json:"min"`
+	Max  int     `json:"max"`
+	Mean float64 `json:"mean"`
+	P95  int     `json:"p95"`
+}
+
+// KeyCardinality is the number of distinct values of an attribute key, prefixed by its scope like in PromQL.
+type KeyCardinality struct {
+	Key    string `json:"key"`
+	Values int    `json:"values"`
+}
+
+// CardinalityFlag is a limit a metric approaches or exceeds.
+type CardinalityFlag struct {
+	Limit    string `json:"limit"`
+	Value    int    `json:"value"`
+	Max      int    `json:"max"`
+	Exceeded bool   `json:"exceeded"`
+}
+
+func (f CardinalityFlag) String() string {
+	if f.Exceeded {
+		return fmt.Sprintf("%s %d exceeds %d", f.Limit, f.Value, f.Max)
+	}
+	return fmt.Sprintf("%s %d is %.0f%% of %d", f.Limit, f.Value, 100*float64(f.Value)/float64(f.Max), f.Max)
+}
+
+// MetricCardinality is the series cardinality of a metric and the distribution of the attribute counts of its
+// series, in total and per scope.
+type MetricCardinality struct {
+	Metric     string                  `json:"metric"`
+	Series     int                     `json:"series"`
+	Attributes Distribution            `json:"attributes"`
+	Scopes     map[string]Distribution `json:"scopes"`
+	// TopKeys are the attribute keys with the most distinct values, which drive the series cardinality.
+	TopKeys []KeyCardinality  `json:"top_keys"`
+	Flags   []CardinalityFlag `json:"flags,omitempty"`
+}
+
+// Exceeded returns whether the metric exceeds any of the limits.
+func (m MetricCardinality) Exceeded() bool {
+	for _, flag := range m.Flags {
+		if flag.Exceeded {
+			return true
+		}
+	}
+	return false
+}
+
+// SourceCardinality is the cardinality of the metrics of a source.
+type SourceCardinality struct {
+	Source  string              `json:"source"`
+	Series  int                 `json:"series"`
+	Metrics []MetricCardinality `json:"metrics"`
+}
+
+// CardinalityReport is the cardinality of the metrics of each source, checked against the limits.
+type CardinalityReport struct {
+	Limits  CardinalityLimits   `json:"limits"`
+	Sources []SourceCardinality `json:"sources"`
+}
+
+// AnalyzeCardinality reads the series of the metrics of each source and measures their cardinality. A
+// metric without series is reported with zero series.
+func AnalyzeCardinality(ctx context.Context, results MetricResultSource, sources []SourceMapping, limits CardinalityLimits) (*CardinalityReport, error) {
+	report := &CardinalityReport{Limits: limits}
+	for _, mapping := range sources {
+		source := SourceCardinality{Source: mapping.Source.String()}
+		for _, md := range mapping.Metrics {
+			series, err := results.Get(ctx, md.Name)
+			if err != nil {
+				return nil, fmt.Errorf("querying %s: %w", md.Name, err)
+			}
+			m := MeasureCardinality(md.Name, series, limits)
+			source.Series += m.Series
+			source.Metrics = append(source.Metrics, m)
+		}
+		report.Sources = append(report.Sources, source)
+	}
+	return report, nil
+}
+
+// MeasureCardinality measures the cardinality of the series of a metric and flags the limits it approaches.
+// Series with the same labels are counted once.
+func MeasureCardinality(metricName string, results []MetricResult, limits CardinalityLimits) MetricCardinality {
+	m := MetricCardinality{Metric: metricName, Scopes: map[string]Distribution{}}
+	seen := map[string]struct{}{}
+	var totals []int
+	scopeCounts := map[string][]int{}
+	values := map[string]map[string]struct{}{}
+	for _, r := range results {
+		all := r.Labels.AllLabels()
+		key := seriesKey(all)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		totals = append(totals, len(all))
+		for scope, labels := range r.Labels.Scopes() {
+			scopeCounts[scope] = append(scopeCounts[scope], len(labels))
+		}
+		for name, value := range all {
+			if values[name] == nil {
+				values[name] = map[string]struct{}{}
+			}
+			values[name][value] = struct{}{}
+		}
+	}
+	m.Series = len(seen)
+	m.Attributes = distribution(totals)
+	for _, scope := range labelScopes {
+		m.Scopes[scope] = distribution(scopeCounts[scope])
+	}
+	for name, v := range values {
+		m.TopKeys = append(m.TopKeys, KeyCardinality{Key: name, Values: len(v)})
+	}
+	sort.Slice(m.TopKeys, func(i, j int) bool {
+		if m.TopKeys[i].Values != m.TopKeys[j].Values {
+			return m.TopKeys[i].Values > m.TopKeys[j].Values
+		}
+		return m.TopKeys[i].Key < m.TopKeys[j].Key
+	})
+	if len(m.TopKeys) > topKeyCount {
+		m.TopKeys = m.TopKeys[:topKeyCount]
+	}
+
+	warnRatio := limits.WarnRatio
+	if warnRatio == 0 {
+		warnRatio = DefaultWarnRatio
+	}
+	flag := func(limit string, value, max int) {
+		if max > 0 && float64(value) >= warnRatio*float64(max) {
+			m.Flags = append(m.Flags, CardinalityFlag{Limit: limit, Value: value, Max: max, Exceeded: value > max})
+		}
+	}
+	flag("attributes", m.Attributes.Max, limits.Attributes)
+	for _, scope := range labelScopes {
+		flag(scope+" attributes", m.Scopes[scope].Max, limits.ScopeAttributes[scope])
+	}
+	flag("series", m.Series, limits.Series)
+	return m
+}
+
+func seriesKey(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for name, value := range labels {
+		pairs = append(pairs, name+"\xff"+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "\xfe")
+}
+
+func distribution(counts []int) Distribution {
+	if len(counts) == 0 {
+		return Distribution{}
+	}
+	sorted := append([]int(nil), counts...)
+	sort.Ints(sorted)
+	sum := 0
+	for _, count := range sorted {
+		sum += count
+	}
+	return Distribution{
+		Min:  sorted[0],
+		Max:  sorted[len(sorted)-1],
+		Mean: float64(sum) / float64(len(sorted)),
+		P95:  sorted[int(math.Ceil(0.95*float64(len(sorted))))-1],
+	}
+}
+
+// Flagged returns the metrics approaching or exceeding a limit.
+func (r *CardinalityReport) Flagged() []MetricCardinality {
+	var flagged []MetricCardinality
+	for _, source := range r.Sources {
+		for _, m := range source.Metrics {
+			if len(m.Flags) > 0 {
+				flagged = append(flagged, m)
+			}
+		}
+	}
+	return flagged
+}
+
+// WriteText writes a table of the metrics of each source followed by the flagged metrics.
+func (r *CardinalityReport) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, source := range r.Sources {
+		fmt.Fprintf(tw, "%s (%d series)\n", source.Source, source.Series)
+		fmt.Fprintf(tw, "  metric\tseries\tattributes min/p95/max\tresource\tdatapoint\taws\ttop keys\n")
+		for _, m := range source.Metrics {
+			var keys []string
+			for _, k := range m.TopKeys {
+				keys = append(keys, fmt.Sprintf("%s=%d", k.Key, k.Values))
+			}
+			fmt.Fprintf(tw, "  %s\t%d\t%d/%d/%d\t%d\t%d\t%d\t%s\n", m.Metric, m.Series,
+				m.Attributes.Min, m.Attributes.P95, m.Attributes.Max,
+				m.Scopes[LabelScopeResource].Max, m.Scopes[LabelScopeDatapoint].Max, m.Scopes[LabelScopeAWS].Max,
+				strings.Join(keys, ", "))
+		}
+	}
+	for _, m := range r.Flagged() {
+		for _, flag := range m.Flags {
+			fmt.Fprintf(tw, "flagged %s: %s\n", m.Metric, flag)
+		}
+	}
+	return tw.Flush()
+}
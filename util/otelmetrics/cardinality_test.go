@@ -0,0 +1,103 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package otelmetrics
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeCardinality(t *testing.T) {
+	sources := []SourceMapping{
+		{Source: SourceCadvisor, Metrics: []MetricDefinition{{Name: "container_cpu_usage_seconds_total"}}},
+		{Source: SourceNodeExporter, Metrics: []MetricDefinition{{Name: "node_load1"}, {Name: "node_memory_MemAvailable_bytes"}}},
+	}
+	limits := CardinalityLimits{Attributes: 10, ScopeAttributes: map[string]int{LabelScopeResource: 5}, Series: 3}
+	report, err := AnalyzeCardinality(context.Background(), NewRecordedResults("testdata/cardinality"), sources, limits)
+	if err != nil {
+		t.Fatalf("AnalyzeCardinality: %v", err)
+	}
+	if len(report.Sources) != 2 || report.Sources[0].Series != 3 || report.Sources[1].Series != 1 {
+		t.Fatalf("unexpected sources %+v", report.Sources)
+	}
+
+	cpu := report.Sources[0].Metrics[0]
+	if cpu.Series != 3 {
+		t.Fatalf("expected the duplicate series to be counted once, got %d series", cpu.Series)
+	}
+	if want := (Distribution{Min: 7, Max: 9, Mean: 8, P95: 9}); cpu.Attributes != want {
+		t.Fatalf("got attributes %+v, want %+v", cpu.Attributes, want)
+	}
+	if want := (Distribution{Min: 4, Max: 6, Mean: 5, P95: 6}); cpu.Scopes[LabelScopeResource] != want {
+		t.Fatalf("got resource attributes %+v, want %+v", cpu.Scopes[LabelScopeResource], want)
+	}
+	if want := (Distribution{Min: 1, Max: 1, Mean: 1, P95: 1}); cpu.Scopes[LabelScopeAWS] != want {
+		t.Fatalf("got aws attributes %+v, want %+v", cpu.Scopes[LabelScopeAWS], want)
+	}
+	wantKeys := []KeyCardinality{
+		{Key: "@resource.k8s.pod.name", Values: 3},
+		{Key: "@resource.k8s.node.name", Values: 2},
+		{Key: "@aws.account", Values: 1},
+		{Key: "@instrumentation.@name", Values: 1},
+		{Key: "@resource.host.type", Values: 1},
+	}
+	if !reflect.DeepEqual(cpu.TopKeys, wantKeys) {
+		t.Fatalf("got top keys %+v, want %+v", cpu.TopKeys, wantKeys)
+	}
+	wantFlags := []CardinalityFlag{
+		{Limit: "attributes", Value: 9, Max: 10},
+		{Limit: "resource attributes", Value: 6, Max: 5, Exceeded: true},
+		{Limit: "series", Value: 3, Max: 3},
+	}
+	if !reflect.DeepEqual(cpu.Flags, wantFlags) || !cpu.Exceeded() {
+		t.Fatalf("got flags %+v, want %+v", cpu.Flags, wantFlags)
+	}
+
+	load, memory := report.Sources[1].Metrics[0], report.Sources[1].Metrics[1]
+	if load.Series != 1 || len(load.Flags) != 0 || memory.Series != 0 || len(memory.Flags) != 0 {
+		t.Fatalf("unexpected node_exporter metrics %+v", report.Sources[1].Metrics)
+	}
+	if flagged := report.Flagged(); len(flagged) != 1 || flagged[0].Metric != cpu.Metric {
+		t.Fatalf("unexpected flagged metrics %+v", flagged)
+	}
+
+	var text strings.Builder
+	if err := report.WriteText(&text); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	for _, line := range []string{
+		"cadvisor (3 series)",
+		"flagged container_cpu_usage_seconds_total: attributes 9 is 90% of 10",
+		"flagged container_cpu_usage_seconds_total: resource attributes 6 exceeds 5",
+	} {
+		if !strings.Contains(text.String(), line) {
+			t.Errorf("report text does not contain %q:\n%s", line, text.String())
+		}
+	}
+}
+
+func TestRecordResults(t *testing.T) {
+	labels := NewMetricLabels()
+	labels.Resource["k8s.node.name"] = "ip-10-0-0-1"
+	labels.Datapoint["cpu"] = "total"
+	at := time.Unix(1760659200, 500*int64(time.Millisecond))
+	results := []MetricResult{
+		{MetricName: "container_cpu_usage_seconds_total", Labels: labels, Value: 12.5, Timestamp: at},
+		{MetricName: "container_cpu_usage_seconds_total", Labels: labels, Timestamp: at, IsHistogram: true},
+	}
+	dir := t.TempDir()
+	if err := RecordResults(dir, "container_cpu_usage_seconds_total", results); err != nil {
+		t.Fatalf("RecordResults: %v", err)
+	}
+	got, err := NewRecordedResults(dir).Get(context.Background(), "container_cpu_usage_seconds_total")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !reflect.DeepEqual(got, results) {
+		t.Fatalf("got %+v, want %+v", got, results)
+	}
+}
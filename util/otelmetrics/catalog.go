@@ -0,0 +1,264 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package otelmetrics
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+//go:generate go run ../../generator/otelcatalog -catalog catalog.yaml -out ../../test/otel
+
+// CatalogVersion is the catalog version this package reads.
+const CatalogVersion = 1
+
+var metricTypes = []string{"counter", "gauge", "histogram", "summary"}
+
+//go:embed catalog.yaml
+var defaultCatalogYAML []byte
+
+var (
+	defaultCatalog     *Catalog
+	defaultCatalogOnce sync.Once
+)
+
+// Catalog is the metric catalog shared by the OTel suites: the metrics of each source and the sources
+// each suite validates.
+type Catalog struct {
+	Version int             `yaml:"version"`
+	Sources []CatalogSource `yaml:"sources"`
+	Suites  []CatalogSuite  `yaml:"suites"`
+}
+
+// CatalogSource is a collector or exporter and the metrics it produces. The metrics of a cluster scoped
+// source are queried without a host type.
+type CatalogSource struct {
+	Name          string          `yaml:"name"`
+	ClusterScoped bool            `yaml:"cluster_scoped"`
+	Metrics       []CatalogMetric `yaml:"metrics"`
+}
+
+// CatalogMetric is the catalog entry of a MetricDefinition. An empty scope is node.
+type CatalogMetric struct {
+	Name           string   `yaml:"name"`
+	Type           string   `yaml:"type"`
+	Scope          string   `yaml:"scope"`
+	ExpectedLabels []string `yaml:"expected_labels"`
+	Unit           string   `yaml:"unit"`
+	Tags           []string `yaml:"tags"`
+	ExcludeSuites  []string `yaml:"exclude_suites"`
+}
+
+// CatalogSuite is a test suite: the host types of its cluster and the sources it validates.
+type CatalogSuite struct {
+	Name      string   `yaml:"name"`
+	HostTypes []string `yaml:"host_types"`
+	Sources   []string `yaml:"sources"`
+}
+
+// DefaultCatalog returns the catalog embedded from catalog.yaml. It panics if the catalog is invalid, which
+// the package tests rule out.
+func DefaultCatalog() *Catalog {
+	defaultCatalogOnce.Do(func() {
+		catalog, err := LoadCatalog(defaultCatalogYAML)
+		if err != nil {
+			panic(fmt.Sprintf("invalid catalog.yaml: %v", err))
+		}
+		defaultCatalog = catalog
+	})
+	return defaultCatalog
+}
+
+// LoadCatalog decodes and validates a catalog. Unknown fields are errors.
+func LoadCatalog(data []byte) (*Catalog, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var catalog Catalog
+	if err := decoder.Decode(&catalog); err != nil {
+		return nil, fmt.Errorf("decoding catalog: %w", err)
+	}
+	if err := catalog.validate(); err != nil {
+		return nil, err
+	}
+	return &catalog, nil
+}
+
+func (c *Catalog) validate() error {
+	var errs []error
+	addError := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+	if c.Version != CatalogVersion {
+		addError("catalog version %d is not supported, expected %d", c.Version, CatalogVersion)
+	}
+
+	suiteNames := map[string]struct{}{}
+	for _, suite := range c.Suites {
+		suiteNames[suite.Name] = struct{}{}
+	}
+	sourceNames := map[string]struct{}{}
+	metricNames := map[string]string{}
+	for i, source := range c.Sources {
+		if _, err := ParseMetricSource(source.Name); err != nil {
+			addError("sources[%d]: %v", i, err)
+		}
+		if _, ok := sourceNames[source.Name]; ok {
+			addError("sources[%d]: source %s is listed twice", i, source.Name)
+		}
+		sourceNames[source.Name] = struct{}{}
+		for j, metric := range source.Metrics {
+			name := fmt.Sprintf("sources[%d].metrics[%d]", i, j)
+			if metric.Name == "" {
+				addError("%s needs a name", name)
+			} else if other, ok := metricNames[metric.Name]; ok {
+				addError("%s: metric %s is already in source %s", name, metric.Name, other)
+			} else {
+				metricNames[metric.Name] = source.Name
+			}
+			if !slices.Contains(metricTypes, metric.Type) {
+				addError("%s: type must be one of %v, got %q", name, metricTypes, metric.Type)
+			}
+			if metric.Scope != "" {
+				if _, err := ParseMetricScope(metric.Scope); err != nil {
+					addError("%s: %v", name, err)
+				}
+			}
+			for _, suite := range metric.ExcludeSuites {
+				if _, ok := suiteNames[suite]; !ok {
+					addError("%s: excluded suite %s is not in the catalog", name, suite)
+				}
+			}
+		}
+	}
+
+	seenSuites := map[string]struct{}{}
+	for i, suite := range c.Suites {
+		if suite.Name == "" {
+			addError("suites[%d] needs a name", i)
+		}
+		if _, ok := seenSuites[suite.Name]; ok {
+			addError("suites[%d]: suite %s is listed twice", i, suite.Name)
+		}
+		seenSuites[suite.Name] = struct{}{}
+		if len(suite.HostTypes) == 0 {
+			addError("suites[%d]: suite %s needs host_types", i, suite.Name)
+		}
+		for _, source := range suite.Sources {
+			if _, ok := sourceNames[source]; !ok {
+				addError("suites[%d]: source %s of suite %s is not in the catalog", i, source, suite.Name)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Suite returns the suite with the name.
+func (c *Catalog) Suite(name string) (CatalogSuite, error) {
+	for _, suite := range c.Suites {
+		if suite.Name == name {
+			return suite, nil
+		}
+	}
+	return CatalogSuite{}, fmt.Errorf("suite %s is not in the catalog", name)
+}
+
+// HostTypes returns the host types of the suite, or nil if it is not in the catalog.
+func (c *Catalog) HostTypes(suite string) []string {
+	s, err := c.Suite(suite)
+	if err != nil {
+		return nil
+	}
+	return s.HostTypes
+}
+
+func (c *Catalog) source(source MetricSource) (CatalogSource, bool) {
+	for _, s := range c.Sources {
+		if s.Name == source.String() {
+			return s, true
+		}
+	}
+	return CatalogSource{}, false
+}
+
+// Metrics returns the definitions of the source for the suite, in catalog order, leaving out the metrics
+// excluded from the suite. With tags, only the metrics with all of them are returned.
+func (c *Catalog) Metrics(suite string, source MetricSource, tags ...string) []MetricDefinition {
+	s, ok := c.source(source)
+	if !ok {
+		return nil
+	}
+	var defs []MetricDefinition
+	for _, metric := range s.Metrics {
+		if slices.Contains(metric.ExcludeSuites, suite) || !hasTags(metric.Tags, tags) {
+			continue
+		}
+		defs = append(defs, metric.definition())
+	}
+	return defs
+}
+
+func hasTags(metricTags []string, tags []string) bool {
+	for _, tag := range tags {
+		if !slices.Contains(metricTags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m CatalogMetric) definition() MetricDefinition {
+	scope := ScopeNode
+	if m.Scope != "" {
+		scope, _ = ParseMetricScope(m.Scope)
+	}
+	return MetricDefinition{
+		Name:           m.Name,
+		MetricType:     m.Type,
+		Scope:          scope,
+		ExpectedLabels: m.ExpectedLabels,
+		Unit:           m.Unit,
+	}
+}
+
+// SourceMappings returns the sources of the suite and their metrics.
+func (c *Catalog) SourceMappings(suite string) ([]SourceMapping, error) {
+	s, err := c.Suite(suite)
+	if err != nil {
+		return nil, err
+	}
+	mappings := make([]SourceMapping, 0, len(s.Sources))
+	for _, name := range s.Sources {
+		source, err := ParseMetricSource(name)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, SourceMapping{Source: source, Metrics: c.Metrics(suite, source)})
+	}
+	return mappings, nil
+}
+
+// SourceRegistry builds the registry of the suite: the metrics of its sources, queried on the host types of
+// the suite unless the source is cluster scoped.
+func (c *Catalog) SourceRegistry(suite string) (*SourceRegistry, error) {
+	mappings, err := c.SourceMappings(suite)
+	if err != nil {
+		return nil, err
+	}
+	hostTypes := c.HostTypes(suite)
+	hostMappings := make([]SourceHostMapping, 0, len(mappings))
+	for _, mapping := range mappings {
+		hostMapping := SourceHostMapping{Source: mapping.Source, HostTypes: hostTypes}
+		if s, _ := c.source(mapping.Source); s.ClusterScoped {
+			hostMapping.HostTypes = nil
+		}
+		hostMappings = append(hostMappings, hostMapping)
+	}
+	return NewSourceRegistry(hostTypes, hostMappings, mappings...), nil
+}
@@ -0,0 +1,115 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package otelmetrics
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDefaultCatalog(t *testing.T) {
+	catalog := DefaultCatalog()
+
+	kubeletstats := catalog.Metrics("gpu", SourceKubeletstats)
+	if len(kubeletstats) != 10 {
+		t.Fatalf("expected 10 kubeletstats metrics on gpu, got %d", len(kubeletstats))
+	}
+	for _, d := range catalog.Metrics("standard", SourceKubeletstats) {
+		if d.Name == "k8s.node.network.io" {
+			t.Fatalf("k8s.node.network.io is excluded from standard")
+		}
+	}
+	core := catalog.Metrics("neuron", SourceNeuron, "core")
+	if len(core) != 2 || core[0].Name != "neuroncore_utilization_ratio" || core[0].Scope != ScopePod || core[0].Unit != "1" {
+		t.Fatalf("unexpected neuron core metrics %+v", core)
+	}
+	nodeExporter := catalog.Metrics("standard", SourceNodeExporter)
+	want := MetricDefinition{Name: "node_cpu_seconds_total", MetricType: "counter", Scope: ScopeNode, ExpectedLabels: []string{"cpu", "mode"}, Unit: "s"}
+	if !reflect.DeepEqual(nodeExporter[0], want) {
+		t.Fatalf("got %+v, want %+v", nodeExporter[0], want)
+	}
+
+	registry, err := catalog.SourceRegistry("standard")
+	if err != nil {
+		t.Fatalf("SourceRegistry: %v", err)
+	}
+	if got := registry.HostTypesFor("node_load1"); !reflect.DeepEqual(got, []string{"t3.medium"}) {
+		t.Fatalf("node_load1 host types %v", got)
+	}
+	if got := registry.HostTypesFor("kube_deployment_status_replicas"); got != nil || !registry.IsClusterScoped("kube_deployment_status_replicas") {
+		t.Fatalf("kube_deployment_status_replicas should be cluster scoped, got host types %v", got)
+	}
+	if _, err := catalog.SourceRegistry("unknown"); err == nil {
+		t.Fatalf("expected an error for an unknown suite")
+	}
+}
+
+// TestSuiteTestsUpToDate fails when catalog.yaml changed without running go generate.
+func TestSuiteTestsUpToDate(t *testing.T) {
+	catalog := DefaultCatalog()
+	for _, suite := range catalog.Suites {
+		want, err := catalog.GenerateSuiteTest(suite.Name)
+		if err != nil {
+			t.Fatalf("GenerateSuiteTest(%s): %v", suite.Name, err)
+		}
+		path := filepath.Join("..", "..", "test", "otel", suite.Name, SuiteTestFile)
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("reading %s: %v", path, err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("%s is out of date, run go generate ./util/otelmetrics", path)
+		}
+	}
+}
+
+func TestLoadCatalogErrors(t *testing.T) {
+	testCases := map[string]struct {
+		catalog string
+		want    []string
+	}{
+		"UnknownField": {
+			catalog: "version: 1\nsources: [{name: efa, metrics: [{name: efa_rx_bytes, type: counter, units: By}]}]\n",
+			want:    []string{"field units not found"},
+		},
+		"Invalid": {
+			catalog: `version: 2
+sources:
+  - name: efa
+    metrics:
+      - {name: efa_rx_bytes, type: meter, scope: rack, exclude_suites: [gpu]}
+      - {name: efa_rx_bytes, type: counter}
+  - name: infiniband
+suites:
+  - {name: efa, sources: [efa, dcgm]}
+`,
+			want: []string{
+				"catalog version 2 is not supported, expected 1",
+				`sources[0].metrics[0]: type must be one of [counter gauge histogram summary], got "meter"`,
+				`sources[0].metrics[0]: unknown metric scope "rack"`,
+				"sources[0].metrics[0]: excluded suite gpu is not in the catalog",
+				"sources[0].metrics[1]: metric efa_rx_bytes is already in source efa",
+				`sources[1]: unknown metric source "infiniband"`,
+				"suites[0]: suite efa needs host_types",
+				"suites[0]: source dcgm of suite efa is not in the catalog",
+			},
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadCatalog([]byte(testCase.catalog))
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			for _, want := range testCase.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
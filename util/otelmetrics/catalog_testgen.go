@@ -0,0 +1,93 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package otelmetrics
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"text/template"
+)
+
+// SuiteTestFile is the name of the test file GenerateSuiteTest writes in the directory of a suite.
+const SuiteTestFile = "catalog_test.go"
+
+var suiteTestTemplate = template.Must(template.New(SuiteTestFile).Parse(`//go:build integration
+
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+// Code generated by generator/otelcatalog from util/otelmetrics/catalog.yaml. DO NOT EDIT.
+
+package {{.Suite}}
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var catalogTestCases = []struct {
+	name           string
+	source         string
+	expectedLabels []string
+}{
+{{- range .Cases}}
+	{name: {{printf "%q" .Name}}, source: {{printf "%q" .Source}}{{if .ExpectedLabels}}, expectedLabels: []string{ {{- range $i, $label := .ExpectedLabels}}{{if $i}}, {{end}}{{printf "%q" $label}}{{end -}} }{{end}}},
+{{- end}}
+}
+
+// TestCatalogMetrics checks every metric the catalog lists for the suite is available with its expected labels.
+func TestCatalogMetrics(t *testing.T) {
+	for _, tc := range catalogTestCases {
+		tc := tc
+		t.Run(tc.source+"/"+tc.name, func(t *testing.T) {
+			t.Parallel()
+			results, err := queryCache.Get(context.Background(), tc.name)
+			require.NoError(t, err, "querying %s", tc.name)
+			require.NotEmpty(t, results, "%s not available", tc.name)
+			for _, r := range results {
+				for _, label := range tc.expectedLabels {
+					_, ok := r.Labels.Datapoint[label]
+					require.True(t, ok, "%s missing expected label '%s'", tc.name, label)
+				}
+			}
+		})
+	}
+}
+`))
+
+type suiteTestCase struct {
+	Name           string
+	Source         string
+	ExpectedLabels []string
+}
+
+// GenerateSuiteTest renders the table-driven test of the suite, with a case per metric of its sources. The
+// package of the test is the suite name.
+func (c *Catalog) GenerateSuiteTest(suite string) ([]byte, error) {
+	mappings, err := c.SourceMappings(suite)
+	if err != nil {
+		return nil, err
+	}
+	var cases []suiteTestCase
+	for _, mapping := range mappings {
+		for _, metric := range mapping.Metrics {
+			cases = append(cases, suiteTestCase{Name: metric.Name, Source: mapping.Source.String(), ExpectedLabels: metric.ExpectedLabels})
+		}
+	}
+	var b bytes.Buffer
+	if err = suiteTestTemplate.Execute(&b, struct {
+		Suite string
+		Cases []suiteTestCase
+	}{suite, cases}); err != nil {
+		return nil, fmt.Errorf("rendering the test of suite %s: %w", suite, err)
+	}
+	formatted, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting the test of suite %s: %w", suite, err)
+	}
+	return formatted, nil
+}
@@ -9,16 +9,26 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/localbackend"
 )
 
+// LocalScheme is the Endpoint scheme that runs the queries against an in-process PromQL server instead of
+// AWS, e.g local://127.0.0.1:4318. The server serves the OTLP metrics exported to its /v1/metrics path, so
+// the agent's OTLP exporter must point at LocalServer().Endpoint(). Requests are not signed, and the
+// @aws.* labels CloudWatch adds on ingestion are absent.
+const LocalScheme = "local://"
+
 // TestConfig holds configuration for the OTEL integration test suite.
 type TestConfig struct {
 	Region         string
@@ -30,7 +40,8 @@ type TestConfig struct {
 	SigningService string
 }
 
-// OtelMetricsClient queries the OTLP PromQL API with SigV4 authentication.
+// OtelMetricsClient queries the OTLP PromQL API with SigV4 authentication, or the in-process server of a
+// LocalScheme endpoint.
 type OtelMetricsClient struct {
 	httpClient     *http.Client
 	signer         *v4.Signer
@@ -39,6 +50,8 @@ type OtelMetricsClient struct {
 	region         string
 	signingService string
 	maxRetries     int
+	// local is the in-process server of a LocalScheme endpoint
+	local *localbackend.Server
 }
 
 type promqlResponse struct {
@@ -57,8 +70,23 @@ type promqlSeries struct {
 	Histogram []json.RawMessage `json:"histogram"`
 }
 
-// NewClient creates an OtelMetricsClient from the given config.
+// NewClient creates an OtelMetricsClient from the given config. An Endpoint with the LocalScheme starts an
+// in-process server on its address, which Close stops.
 func NewClient(ctx context.Context, config TestConfig) (*OtelMetricsClient, error) {
+	if address, ok := strings.CutPrefix(config.Endpoint, LocalScheme); ok {
+		server, err := localbackend.NewServer(address)
+		if err != nil {
+			return nil, fmt.Errorf("starting local PromQL server: %w", err)
+		}
+		server.Start()
+		return &OtelMetricsClient{
+			httpClient: &http.Client{Timeout: config.Timeout},
+			queryURL:   server.Endpoint() + "/api/v1/query",
+			region:     config.Region,
+			maxRetries: config.MaxRetries,
+			local:      server,
+		}, nil
+	}
 	cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(config.Region))
 	if err != nil {
 		return nil, fmt.Errorf("loading AWS config: %w", err)
@@ -74,6 +102,19 @@ func NewClient(ctx context.Context, config TestConfig) (*OtelMetricsClient, erro
 	}, nil
 }
 
+// LocalServer returns the in-process server of a LocalScheme endpoint, or nil.
+func (c *OtelMetricsClient) LocalServer() *localbackend.Server {
+	return c.local
+}
+
+// Close stops the in-process server of a LocalScheme endpoint.
+func (c *OtelMetricsClient) Close() error {
+	if c.local == nil {
+		return nil
+	}
+	return c.local.Close()
+}
+
 // Query executes a PromQL instant query and returns parsed results.
 func (c *OtelMetricsClient) Query(ctx context.Context, promql string) ([]MetricResult, error) {
 	params := url.Values{"query": {promql}}
@@ -83,7 +124,7 @@ func (c *OtelMetricsClient) Query(ctx context.Context, promql string) ([]MetricR
 	if err != nil {
 		return nil, err
 	}
-	results := c.parseResponse(raw)
+	results := parseQueryResponse(raw)
 	log.Printf("query returned count=%d", len(results))
 	return results, nil
 }
@@ -111,13 +152,15 @@ func (c *OtelMetricsClient) requestRawWithRetry(ctx context.Context, baseURL str
 			return nil, fmt.Errorf("creating request: %w", err)
 		}
 
-		creds, err := c.creds.Retrieve(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("retrieving credentials: %w", err)
-		}
+		if c.local == nil {
+			creds, err := c.creds.Retrieve(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("retrieving credentials: %w", err)
+			}
 
-		if err := c.signer.SignHTTP(ctx, creds, req, emptyPayloadHash, c.signingService, c.region, time.Now()); err != nil {
-			return nil, fmt.Errorf("signing request: %w", err)
+			if err := c.signer.SignHTTP(ctx, creds, req, emptyPayloadHash, c.signingService, c.region, time.Now()); err != nil {
+				return nil, fmt.Errorf("signing request: %w", err)
+			}
 		}
 
 		resp, err := c.httpClient.Do(req)
@@ -154,7 +197,8 @@ func (c *OtelMetricsClient) requestRawWithRetry(ctx context.Context, baseURL str
 	return nil, fmt.Errorf("all %d attempts failed: %w", c.maxRetries, lastErr)
 }
 
-func (c *OtelMetricsClient) parseResponse(response *promqlResponse) []MetricResult {
+// parseQueryResponse parses the vector of an instant query. Histograms have no value.
+func parseQueryResponse(response *promqlResponse) []MetricResult {
 	var results []MetricResult
 	for _, series := range response.Data.Result {
 		metricName := series.Metric["__name__"]
@@ -246,7 +290,29 @@ func (c *OtelMetricsClient) QueryRange(ctx context.Context, promql string, start
 	if err != nil {
 		return nil, err
 	}
+	return parseRangeResponse(rawBytes)
+}
+
+// QuerySamples executes the range vector selector selector[window] as an instant query at the given time and
+// returns the raw samples of each series within the window, with the timestamps they were collected at.
+// Unlike QueryRange, which repeats the latest sample at every step, it shows the gaps between collections.
+func (c *OtelMetricsClient) QuerySamples(ctx context.Context, selector string, window time.Duration, at time.Time) ([]RangeResult, error) {
+	promql := fmt.Sprintf("%s[%ds]", selector, int(window.Seconds()))
+	params := url.Values{
+		"query": {promql},
+		"time":  {fmt.Sprintf("%d", at.Unix())},
+	}
+	log.Printf("querying samples promql=%s", promql)
+
+	rawBytes, err := c.requestRawWithRetry(ctx, c.queryURL, params)
+	if err != nil {
+		return nil, err
+	}
+	return parseRangeResponse(rawBytes)
+}
 
+// parseRangeResponse parses a matrix result. Timestamps keep their millisecond precision.
+func parseRangeResponse(rawBytes []byte) ([]RangeResult, error) {
 	var rangeResp promqlRangeResponse
 	if err := json.Unmarshal(rawBytes, &rangeResp); err != nil {
 		return nil, fmt.Errorf("parsing range response: %w", err)
@@ -272,10 +338,63 @@ func (c *OtelMetricsClient) QueryRange(ctx context.Context, promql string, start
 			if err != nil {
 				continue
 			}
-			rr.Timestamps = append(rr.Timestamps, time.Unix(int64(tsFloat), 0))
+			rr.Timestamps = append(rr.Timestamps, time.UnixMilli(int64(math.Round(tsFloat*1000))))
 			rr.Values = append(rr.Values, val)
 		}
 		results = append(results, rr)
 	}
 	return results, nil
 }
+
+// apiURL returns the URL of the PromQL API path relative to /api/v1 (e.g /series).
+func (c *OtelMetricsClient) apiURL(path string) string {
+	return strings.TrimSuffix(c.queryURL, "/query") + path
+}
+
+// metadataParams returns the parameters of the metadata endpoints: the match[] selectors and the time range.
+func metadataParams(start, end time.Time, matchers []string) url.Values {
+	params := url.Values{
+		"start": {fmt.Sprintf("%d", start.Unix())},
+		"end":   {fmt.Sprintf("%d", end.Unix())},
+	}
+	for _, matcher := range matchers {
+		params.Add("match[]", matcher)
+	}
+	return params
+}
+
+// LabelValues returns the values of the label on the series with samples between start and end, restricted
+// to the series matching any of the selectors. With the __name__ label, these are the metric names.
+func (c *OtelMetricsClient) LabelValues(ctx context.Context, label string, start, end time.Time, matchers ...string) ([]string, error) {
+	log.Printf("querying label values label=%s match=%v", label, matchers)
+	body, err := c.requestRawWithRetry(ctx, c.apiURL("/label/"+url.PathEscape(label)+"/values"), metadataParams(start, end, matchers))
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Status string   `json:"status"`
+		Data   []string `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("parsing label values response: %w", err)
+	}
+	return response.Data, nil
+}
+
+// Series returns the raw label sets of the series matching any of the selectors with samples between start
+// and end.
+func (c *OtelMetricsClient) Series(ctx context.Context, start, end time.Time, matchers ...string) ([]map[string]string, error) {
+	log.Printf("querying series match=%v", matchers)
+	body, err := c.requestRawWithRetry(ctx, c.apiURL("/series"), metadataParams(start, end, matchers))
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Status string              `json:"status"`
+		Data   []map[string]string `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("parsing series response: %w", err)
+	}
+	return response.Data, nil
+}
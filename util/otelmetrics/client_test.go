@@ -0,0 +1,94 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package otelmetrics
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func attribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
+
+func TestLocalClient(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewClient(ctx, TestConfig{Endpoint: LocalScheme + "127.0.0.1:0", Timeout: 5 * time.Second, MaxRetries: 1})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer client.Close()
+
+	now := time.Now().Truncate(time.Second)
+	var points []*metricspb.NumberDataPoint
+	for i := 0; i < 5; i++ {
+		points = append(points, &metricspb.NumberDataPoint{
+			TimeUnixNano: uint64(now.Add(time.Duration(i-4) * 30 * time.Second).UnixNano()),
+			Attributes:   []*commonpb.KeyValue{attribute("UUID", "GPU-1")},
+			Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: float64(10 * i)},
+		})
+	}
+	request := &collectormetrics.ExportMetricsServiceRequest{ResourceMetrics: []*metricspb.ResourceMetrics{{
+		Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{
+			attribute("k8s.cluster.name", "test-cluster"),
+			attribute("k8s.node.name", "ip-10-0-0-1"),
+		}},
+		ScopeMetrics: []*metricspb.ScopeMetrics{{
+			Scope:   &commonpb.InstrumentationScope{Name: "github.com/NVIDIA/dcgm-exporter", Version: "3.3.5"},
+			Metrics: []*metricspb.Metric{{Name: "DCGM_FI_DEV_GPU_UTIL", Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: points}}}},
+		}},
+	}}}
+	payload, err := proto.Marshal(request)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	resp, err := http.Post(client.LocalServer().Endpoint()+"/v1/metrics", "application/x-protobuf", bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("export status %d", resp.StatusCode)
+	}
+
+	promql := `DCGM_FI_DEV_GPU_UTIL{"@resource.k8s.cluster.name"="test-cluster","@resource.k8s.node.name"=~"ip-.*",UUID="GPU-1"}`
+	results, err := client.Query(ctx, promql)
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	r := results[0]
+	if r.MetricName != "DCGM_FI_DEV_GPU_UTIL" || r.Value != 40 {
+		t.Fatalf("got %s=%g", r.MetricName, r.Value)
+	}
+	if r.Labels.Resource["k8s.node.name"] != "ip-10-0-0-1" || r.Labels.Datapoint["UUID"] != "GPU-1" {
+		t.Fatalf("unexpected labels %+v", r.Labels)
+	}
+	if r.Labels.Instrumentation["@name"] != "github.com/NVIDIA/dcgm-exporter" || r.Labels.Instrumentation["@version"] != "3.3.5" {
+		t.Fatalf("unexpected scope %+v", r.Labels.Instrumentation)
+	}
+
+	ranges, err := client.QueryRange(ctx, promql, now.Add(-2*time.Minute), now, 30*time.Second)
+	if err != nil {
+		t.Fatalf("QueryRange: %v", err)
+	}
+	if len(ranges) != 1 || len(ranges[0].Values) != 5 {
+		t.Fatalf("expected 1 series of 5 samples, got %+v", ranges)
+	}
+
+	if _, err := client.Query(ctx, "rate(DCGM_FI_DEV_GPU_UTIL[5m])"); err == nil {
+		t.Fatalf("expected an error for an unsupported query")
+	}
+}
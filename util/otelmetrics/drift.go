@@ -0,0 +1,235 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package otelmetrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+const (
+	metricNameLabel  = "__name__"
+	metricUnitLabel  = "__unit__"
+	clusterNameLabel = "k8s.cluster.name"
+)
+
+// DriftOptions configures DetectDrift.
+type DriftOptions struct {
+	// Start and End bound the samples the metric names and label sets are read from.
+	Start time.Time
+	End   time.Time
+	// IgnoreMetrics drops the emitted metrics matching any of the expressions from UnexpectedMetrics, e.g
+	// the node_exporter metrics the catalog does not list.
+	IgnoreMetrics []*regexp.Regexp
+	// IgnoreLabels are data point labels never reported as new, e.g labels added by a scrape.
+	IgnoreLabels []string
+}
+
+// DriftReport is the difference between what a cluster emits and the metric catalog.
+type DriftReport struct {
+	Cluster string    `json:"cluster"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	// UnexpectedMetrics are emitted by the cluster but not in the catalog.
+	UnexpectedMetrics []string `json:"unexpected_metrics"`
+	// MissingMetrics are in the catalog but not emitted by the cluster.
+	MissingMetrics []MissingMetric `json:"missing_metrics"`
+	// NewLabels are data point labels of emitted series that are not expected labels of the metric.
+	NewLabels []LabelDrift `json:"new_labels"`
+	// MissingLabels are expected labels of the metric that some of its series do not have.
+	MissingLabels []LabelDrift `json:"missing_labels"`
+	// UnitChanges are metrics whose __unit__ differs from the catalog unit.
+	UnitChanges []UnitDrift `json:"unit_changes"`
+}
+
+// MissingMetric is a catalog metric the cluster does not emit. OtherClusters lists the clusters that emit
+// it, which points at a missing k8s.cluster.name rather than a missing metric.
+type MissingMetric struct {
+	Name          string   `json:"name"`
+	Source        string   `json:"source"`
+	OtherClusters []string `json:"other_clusters,omitempty"`
+}
+
+// LabelDrift lists the drifted labels of a metric.
+type LabelDrift struct {
+	Metric string   `json:"metric"`
+	Source string   `json:"source"`
+	Labels []string `json:"labels"`
+}
+
+// UnitDrift is a metric whose series carry other units than the catalog. A series without __unit__ is
+// observed as "".
+type UnitDrift struct {
+	Metric   string   `json:"metric"`
+	Source   string   `json:"source"`
+	Expected string   `json:"expected"`
+	Observed []string `json:"observed"`
+}
+
+// HasDrift returns whether the report lists any difference.
+func (r *DriftReport) HasDrift() bool {
+	return len(r.UnexpectedMetrics) > 0 || len(r.MissingMetrics) > 0 || len(r.NewLabels) > 0 ||
+		len(r.MissingLabels) > 0 || len(r.UnitChanges) > 0
+}
+
+// WriteText writes the report for a human, a line per difference.
+func (r *DriftReport) WriteText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "catalog drift of cluster %s between %s and %s\n", r.Cluster, r.Start.Format(time.RFC3339), r.End.Format(time.RFC3339))
+	if !r.HasDrift() {
+		b.WriteString("no drift\n")
+	}
+	for _, name := range r.UnexpectedMetrics {
+		fmt.Fprintf(&b, "unexpected metric %s\n", name)
+	}
+	for _, m := range r.MissingMetrics {
+		fmt.Fprintf(&b, "missing metric %s (%s)", m.Name, m.Source)
+		if len(m.OtherClusters) > 0 {
+			fmt.Fprintf(&b, ", emitted by clusters %s", strings.Join(m.OtherClusters, ", "))
+		}
+		b.WriteString("\n")
+	}
+	for _, d := range r.NewLabels {
+		fmt.Fprintf(&b, "new labels of %s (%s): %s\n", d.Metric, d.Source, strings.Join(d.Labels, ", "))
+	}
+	for _, d := range r.MissingLabels {
+		fmt.Fprintf(&b, "missing labels of %s (%s): %s\n", d.Metric, d.Source, strings.Join(d.Labels, ", "))
+	}
+	for _, d := range r.UnitChanges {
+		fmt.Fprintf(&b, "unit of %s (%s) is %q, expected %q\n", d.Metric, d.Source, strings.Join(d.Observed, ", "), d.Expected)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// DetectDrift diffs the metrics the cluster of the cache emits against the catalog metrics of the sources.
+// The emitted metric names come from the __name__ label values and the label keys from the series endpoint.
+// A catalog metric neither lists is looked up with Get, in case the metadata endpoints lag the query API,
+// and then with GetUnfiltered to find the clusters that do emit it. Units are only compared for catalog
+// metrics with a unit, as the suites do.
+func (qc *QueryCache) DetectDrift(ctx context.Context, sources []SourceMapping, opts DriftOptions) (*DriftReport, error) {
+	clusterMatcher := fmt.Sprintf(`"@resource.%s"="%s"`, clusterNameLabel, promqlEscaper.Replace(qc.cluster))
+	emitted, err := qc.client.LabelValues(ctx, metricNameLabel, opts.Start, opts.End, "{"+clusterMatcher+"}")
+	if err != nil {
+		return nil, fmt.Errorf("listing the metrics of cluster %s: %w", qc.cluster, err)
+	}
+
+	report := &DriftReport{Cluster: qc.cluster, Start: opts.Start, End: opts.End}
+	expected := map[string]struct{}{}
+	for _, mapping := range sources {
+		for _, md := range mapping.Metrics {
+			expected[md.Name] = struct{}{}
+			var series []map[string]string
+			if slices.Contains(emitted, md.Name) {
+				labelSets, err := qc.client.Series(ctx, opts.Start, opts.End, promqlMetricSelector(md.Name)+clusterMatcher+"}")
+				if err != nil {
+					return nil, fmt.Errorf("listing the series of %s: %w", md.Name, err)
+				}
+				for _, labels := range labelSets {
+					series = append(series, ParseLabels(labels).Datapoint)
+				}
+			}
+			if len(series) == 0 {
+				results, err := qc.Get(ctx, md.Name)
+				if err != nil {
+					return nil, fmt.Errorf("querying %s: %w", md.Name, err)
+				}
+				for _, r := range results {
+					series = append(series, r.Labels.Datapoint)
+				}
+			}
+			if len(series) == 0 {
+				missing, err := qc.missingMetric(ctx, md.Name, mapping.Source)
+				if err != nil {
+					return nil, err
+				}
+				report.MissingMetrics = append(report.MissingMetrics, missing)
+				continue
+			}
+			report.addSeriesDrift(md, mapping.Source, series, opts.IgnoreLabels)
+		}
+	}
+
+	for _, name := range emitted {
+		if _, ok := expected[name]; ok || matchesAny(opts.IgnoreMetrics, name) {
+			continue
+		}
+		report.UnexpectedMetrics = append(report.UnexpectedMetrics, name)
+	}
+	sort.Strings(report.UnexpectedMetrics)
+	return report, nil
+}
+
+func (qc *QueryCache) missingMetric(ctx context.Context, name string, source MetricSource) (MissingMetric, error) {
+	missing := MissingMetric{Name: name, Source: source.String()}
+	results, err := qc.GetUnfiltered(ctx, name)
+	if err != nil {
+		return missing, fmt.Errorf("querying %s in all clusters: %w", name, err)
+	}
+	for _, r := range results {
+		cluster := r.Labels.Resource[clusterNameLabel]
+		if cluster != qc.cluster && !slices.Contains(missing.OtherClusters, cluster) {
+			missing.OtherClusters = append(missing.OtherClusters, cluster)
+		}
+	}
+	sort.Strings(missing.OtherClusters)
+	return missing, nil
+}
+
+// addSeriesDrift compares the data point labels of the series of a catalog metric with its definition.
+// Metadata labels such as __unit__ are never reported as new labels.
+func (r *DriftReport) addSeriesDrift(md MetricDefinition, source MetricSource, series []map[string]string, ignoreLabels []string) {
+	newLabels := map[string]struct{}{}
+	missingLabels := map[string]struct{}{}
+	units := map[string]struct{}{}
+	for _, labels := range series {
+		for label := range labels {
+			if strings.HasPrefix(label, "__") || slices.Contains(md.ExpectedLabels, label) || slices.Contains(ignoreLabels, label) {
+				continue
+			}
+			newLabels[label] = struct{}{}
+		}
+		for _, label := range md.ExpectedLabels {
+			if _, ok := labels[label]; !ok {
+				missingLabels[label] = struct{}{}
+			}
+		}
+		units[labels[metricUnitLabel]] = struct{}{}
+	}
+
+	if len(newLabels) > 0 {
+		r.NewLabels = append(r.NewLabels, LabelDrift{Metric: md.Name, Source: source.String(), Labels: sortedKeys(newLabels)})
+	}
+	if len(missingLabels) > 0 {
+		r.MissingLabels = append(r.MissingLabels, LabelDrift{Metric: md.Name, Source: source.String(), Labels: sortedKeys(missingLabels)})
+	}
+	if _, ok := units[md.Unit]; md.Unit != "" && (!ok || len(units) > 1) {
+		r.UnitChanges = append(r.UnitChanges, UnitDrift{Metric: md.Name, Source: source.String(), Expected: md.Unit, Observed: sortedKeys(units)})
+	}
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func matchesAny(expressions []*regexp.Regexp, name string) bool {
+	for _, re := range expressions {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
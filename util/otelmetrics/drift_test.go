@@ -0,0 +1,114 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package otelmetrics
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func gauge(name, unit string, at time.Time, attributes ...*commonpb.KeyValue) *metricspb.Metric {
+	return &metricspb.Metric{Name: name, Unit: unit, Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: []*metricspb.NumberDataPoint{{
+		TimeUnixNano: uint64(at.UnixNano()),
+		Attributes:   attributes,
+		Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: 1},
+	}}}}}
+}
+
+func exportMetrics(t *testing.T, client *OtelMetricsClient, cluster string, metrics ...*metricspb.Metric) {
+	t.Helper()
+	request := &collectormetrics.ExportMetricsServiceRequest{ResourceMetrics: []*metricspb.ResourceMetrics{{
+		Resource:     &resourcepb.Resource{Attributes: []*commonpb.KeyValue{attribute("k8s.cluster.name", cluster)}},
+		ScopeMetrics: []*metricspb.ScopeMetrics{{Metrics: metrics}},
+	}}}
+	payload, err := proto.Marshal(request)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	resp, err := http.Post(client.LocalServer().Endpoint()+"/v1/metrics", "application/x-protobuf", bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("export status %d", resp.StatusCode)
+	}
+}
+
+func TestDetectDrift(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewClient(ctx, TestConfig{Endpoint: LocalScheme + "127.0.0.1:0", Timeout: 5 * time.Second, MaxRetries: 1})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer client.Close()
+
+	now := time.Now().Truncate(time.Second)
+	exportMetrics(t, client, "test-cluster",
+		gauge("DCGM_FI_DEV_GPU_UTIL", "%", now, attribute("UUID", "GPU-1"), attribute("modelName", "Tesla T4")),
+		gauge("DCGM_FI_DEV_FB_USED", "By", now, attribute("UUID", "GPU-1")),
+		gauge("DCGM_FI_DEV_SM_CLOCK", "MHz", now, attribute("UUID", "GPU-1")),
+		gauge("go_goroutines", "", now),
+	)
+	exportMetrics(t, client, "other-cluster", gauge("DCGM_FI_DEV_GPU_TEMP", "Cel", now, attribute("UUID", "GPU-2")))
+
+	sources := []SourceMapping{{Source: SourceDCGM, Metrics: []MetricDefinition{
+		{Name: "DCGM_FI_DEV_GPU_UTIL", ExpectedLabels: []string{"UUID"}, Unit: "%"},
+		{Name: "DCGM_FI_DEV_FB_USED", ExpectedLabels: []string{"UUID", "device"}, Unit: "MiBy"},
+		{Name: "DCGM_FI_DEV_GPU_TEMP", Unit: "Cel"},
+		{Name: "DCGM_FI_DEV_POWER_USAGE", Unit: "W"},
+	}}}
+	queryCache := NewQueryCache(client, "test-cluster")
+	report, err := queryCache.DetectDrift(ctx, sources, DriftOptions{
+		Start:         now.Add(-time.Minute),
+		End:           now,
+		IgnoreMetrics: []*regexp.Regexp{regexp.MustCompile("^go_.*$")},
+	})
+	if err != nil {
+		t.Fatalf("DetectDrift: %v", err)
+	}
+
+	want := &DriftReport{
+		Cluster:           "test-cluster",
+		Start:             now.Add(-time.Minute),
+		End:               now,
+		UnexpectedMetrics: []string{"DCGM_FI_DEV_SM_CLOCK"},
+		MissingMetrics: []MissingMetric{
+			{Name: "DCGM_FI_DEV_GPU_TEMP", Source: "dcgm", OtherClusters: []string{"other-cluster"}},
+			{Name: "DCGM_FI_DEV_POWER_USAGE", Source: "dcgm"},
+		},
+		NewLabels:     []LabelDrift{{Metric: "DCGM_FI_DEV_GPU_UTIL", Source: "dcgm", Labels: []string{"modelName"}}},
+		MissingLabels: []LabelDrift{{Metric: "DCGM_FI_DEV_FB_USED", Source: "dcgm", Labels: []string{"device"}}},
+		UnitChanges:   []UnitDrift{{Metric: "DCGM_FI_DEV_FB_USED", Source: "dcgm", Expected: "MiBy", Observed: []string{"By"}}},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Fatalf("got %+v, want %+v", report, want)
+	}
+
+	var text strings.Builder
+	if err := report.WriteText(&text); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	for _, line := range []string{
+		"unexpected metric DCGM_FI_DEV_SM_CLOCK",
+		"missing metric DCGM_FI_DEV_GPU_TEMP (dcgm), emitted by clusters other-cluster",
+		`unit of DCGM_FI_DEV_FB_USED (dcgm) is "By", expected "MiBy"`,
+	} {
+		if !strings.Contains(text.String(), line+"\n") {
+			t.Errorf("report text does not contain %q:\n%s", line, text.String())
+		}
+	}
+}
@@ -34,6 +34,16 @@ func (s MetricScope) String() string {
 	}
 }
 
+// ParseMetricScope returns the scope with the name String returns (e.g pod).
+func ParseMetricScope(name string) (MetricScope, error) {
+	for _, scope := range []MetricScope{ScopeNode, ScopePod, ScopeContainer, ScopeCluster} {
+		if scope.String() == name {
+			return scope, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown metric scope %q", name)
+}
+
 // MetricLabels holds parsed OTLP labels grouped by ZIP-0006 scope.
 // Always use NewMetricLabels() to avoid nil map panics.
 type MetricLabels struct {
@@ -155,3 +165,17 @@ type MetricDefinition struct {
 	ExpectedLabels []string
 	Unit           string // "" if unset
 }
+
+// MetricsWithScope returns the definitions with one of the scopes.
+func MetricsWithScope(defs []MetricDefinition, scopes ...MetricScope) []MetricDefinition {
+	var matching []MetricDefinition
+	for _, d := range defs {
+		for _, scope := range scopes {
+			if d.Scope == scope {
+				matching = append(matching, d)
+				break
+			}
+		}
+	}
+	return matching
+}
@@ -230,7 +230,8 @@ func (qc *QueryCache) GetWithFilter(ctx context.Context, metricName string, extr
 	return qc.client.Query(ctx, sel+filters+"}")
 }
 
-// GetUnfiltered returns results without cluster filtering. Cached separately.
+// GetUnfiltered returns results without cluster filtering. Cached separately. Names that are not valid PromQL
+// identifiers, such as dotted OTel names, are selected by __name__.
 func (qc *QueryCache) GetUnfiltered(ctx context.Context, metricName string) ([]MetricResult, error) {
 	qc.mu.RLock()
 	if entry, ok := qc.unfiltered[metricName]; ok {
@@ -246,7 +247,11 @@ func (qc *QueryCache) GetUnfiltered(ctx context.Context, metricName string) ([]M
 	}
 	qc.mu.Unlock()
 
-	results, queryErr := qc.client.Query(ctx, metricName)
+	promql := metricName
+	if strings.Contains(metricName, ".") {
+		promql = promqlMetricSelector(metricName) + "}"
+	}
+	results, queryErr := qc.client.Query(ctx, promql)
 	entry := cacheEntry{results: results, err: queryErr}
 
 	qc.mu.Lock()
@@ -0,0 +1,77 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package otelmetrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// recordedFileSuffix is the extension of the response files of RecordedResults.
+const recordedFileSuffix = ".json"
+
+// RecordedResults serves instant query responses of the PromQL API saved in a directory, a <metric>.json
+// file per metric, e.g to analyze the series of a cluster offline. A metric without a file has no series.
+type RecordedResults struct {
+	dir string
+}
+
+// NewRecordedResults returns the RecordedResults of the directory.
+func NewRecordedResults(dir string) *RecordedResults {
+	return &RecordedResults{dir: dir}
+}
+
+// Get returns the series of the recorded response of the metric.
+func (r *RecordedResults) Get(_ context.Context, metricName string) ([]MetricResult, error) {
+	path := filepath.Join(r.dir, metricName+recordedFileSuffix)
+	body, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var response promqlResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return parseQueryResponse(&response), nil
+}
+
+// RecordResults saves the series of a metric as the instant query response RecordedResults reads.
+func RecordResults(dir, metricName string, results []MetricResult) error {
+	type recordedSeries struct {
+		Metric    map[string]string `json:"metric"`
+		Value     []interface{}     `json:"value,omitempty"`
+		Histogram []interface{}     `json:"histogram,omitempty"`
+	}
+	series := make([]recordedSeries, 0, len(results))
+	for _, r := range results {
+		labels := r.Labels.AllLabels()
+		labels[metricNameLabel] = r.MetricName
+		timestamp := float64(r.Timestamp.UnixMilli()) / 1000
+		if r.IsHistogram {
+			series = append(series, recordedSeries{Metric: labels, Histogram: []interface{}{timestamp, map[string]string{}}})
+		} else {
+			series = append(series, recordedSeries{Metric: labels, Value: []interface{}{timestamp, strconv.FormatFloat(r.Value, 'f', -1, 64)}})
+		}
+	}
+	body, err := json.MarshalIndent(map[string]interface{}{
+		"status": "success",
+		"data":   map[string]interface{}{"resultType": "vector", "result": series},
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, metricName+recordedFileSuffix), body, 0644)
+}
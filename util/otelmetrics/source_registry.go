@@ -3,6 +3,8 @@
 
 package otelmetrics
 
+import "fmt"
+
 // MetricSource identifies a metric's origin collector/exporter.
 type MetricSource int
 
@@ -20,6 +22,38 @@ const (
 	SourceKSMNodeScoped
 )
 
+// sourceNames are the names of the sources in the metric catalog.
+var sourceNames = map[MetricSource]string{
+	SourceNodeExporter:     "node_exporter",
+	SourceCadvisor:         "cadvisor",
+	SourceKubeletstats:     "kubeletstats",
+	SourceDCGM:             "dcgm",
+	SourceNeuron:           "neuron",
+	SourceEFA:              "efa",
+	SourceEBSCSI:           "ebs_csi",
+	SourceLISCSI:           "lis_csi",
+	SourceControlPlane:     "control_plane",
+	SourceKubeStateMetrics: "kube_state_metrics",
+	SourceKSMNodeScoped:    "ksm_node_scoped",
+}
+
+func (s MetricSource) String() string {
+	if name, ok := sourceNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("MetricSource(%d)", int(s))
+}
+
+// ParseMetricSource returns the source with the catalog name (e.g node_exporter).
+func ParseMetricSource(name string) (MetricSource, error) {
+	for source, sourceName := range sourceNames {
+		if sourceName == name {
+			return source, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown metric source %q", name)
+}
+
 // SourceMapping pairs a MetricSource with its metric definitions.
 type SourceMapping struct {
 	Source  MetricSource
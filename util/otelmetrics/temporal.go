@@ -0,0 +1,173 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package otelmetrics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/metricassert"
+)
+
+// Series converts the result to a metricassert series, labelled with its sorted labels.
+func (r RangeResult) Series() metricassert.Series {
+	all := r.Labels.AllLabels()
+	pairs := make([]string, 0, len(all))
+	for key, value := range all {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return metricassert.Series{
+		Label:      "{" + strings.Join(pairs, ", ") + "}",
+		Timestamps: r.Timestamps,
+		Values:     r.Values,
+	}
+}
+
+// ExpectRange starts metricassert expectations on the samples of a result, e.g
+//
+//	err := otelmetrics.ExpectRange(r).ResetsAtMost(0).SpacingWithin(15*time.Second, time.Minute).FreshAt(end, time.Minute).Err()
+func ExpectRange(r RangeResult) *metricassert.Expectation {
+	return metricassert.ExpectSeries(r.Series())
+}
+
+// MostSamples returns the result with the most samples, the best covered series of a query, or an empty
+// result when there is none.
+func MostSamples(results []RangeResult) RangeResult {
+	var best RangeResult
+	for _, r := range results {
+		if len(r.Timestamps) > len(best.Timestamps) {
+			best = r
+		}
+	}
+	return best
+}
+
+// CounterResets returns the timestamps at which the counter decreased, e.g because its process restarted.
+func (r RangeResult) CounterResets() []time.Time {
+	var resets []time.Time
+	for i := 1; i < len(r.Values); i++ {
+		if r.Values[i] < r.Values[i-1] {
+			resets = append(resets, r.Timestamps[i])
+		}
+	}
+	return resets
+}
+
+// Increase returns the increase of the counter between its first and last samples. Like the PromQL increase,
+// a sample lower than the one before it is a reset, and counts from zero.
+func (r RangeResult) Increase() float64 {
+	var increase float64
+	for i := 1; i < len(r.Values); i++ {
+		if r.Values[i] < r.Values[i-1] {
+			increase += r.Values[i]
+		} else {
+			increase += r.Values[i] - r.Values[i-1]
+		}
+	}
+	return increase
+}
+
+// Rate returns the per second increase of the counter between its first and last samples, like the PromQL
+// rate without extrapolation. It is false with fewer than two samples.
+func (r RangeResult) Rate() (float64, bool) {
+	if len(r.Timestamps) < 2 {
+		return 0, false
+	}
+	elapsed := r.Timestamps[len(r.Timestamps)-1].Sub(r.Timestamps[0]).Seconds()
+	if elapsed <= 0 {
+		return 0, false
+	}
+	return r.Increase() / elapsed, true
+}
+
+// LatestSample returns the time of the newest sample of the results, or the zero time when there is none.
+// Unlike the query time, it already accounts for the export and ingestion lag of the pipeline, so it is the
+// reference to measure staleness against.
+func LatestSample(results []RangeResult) time.Time {
+	var latest time.Time
+	for _, r := range results {
+		if n := len(r.Timestamps); n > 0 && r.Timestamps[n-1].After(latest) {
+			latest = r.Timestamps[n-1]
+		}
+	}
+	return latest
+}
+
+// StaleSeries returns the results whose last sample is more than maxAge before at, the series that stopped
+// being collected within the queried range. at is usually the LatestSample of the results.
+func StaleSeries(results []RangeResult, at time.Time, maxAge time.Duration) []RangeResult {
+	var stale []RangeResult
+	for _, r := range results {
+		if len(r.Timestamps) == 0 || at.Sub(r.Timestamps[len(r.Timestamps)-1]) > maxAge {
+			stale = append(stale, r)
+		}
+	}
+	return stale
+}
+
+// ByResource groups results by a resource attribute, e.g host.type for the node groups of a cluster.
+func ByResource(attribute string) func(MetricLabels) string {
+	return func(labels MetricLabels) string {
+		return labels.Resource[attribute]
+	}
+}
+
+// GroupRates returns the mean Rate of the series of each group groupBy returns. Series with fewer than two
+// samples are left out.
+func GroupRates(results []RangeResult, groupBy func(MetricLabels) string) map[string]float64 {
+	sums := map[string]float64{}
+	counts := map[string]int{}
+	for _, r := range results {
+		rate, ok := r.Rate()
+		if !ok {
+			continue
+		}
+		group := groupBy(r.Labels)
+		sums[group] += rate
+		counts[group]++
+	}
+	rates := make(map[string]float64, len(sums))
+	for group, sum := range sums {
+		rates[group] = sum / float64(counts[group])
+	}
+	return rates
+}
+
+// ExpectComparableRates expects the groups to have positive rates within maxRatio of each other (e.g 1.5),
+// and returns an error listing the rates otherwise. Every group of want must have a rate.
+func ExpectComparableRates(rates map[string]float64, maxRatio float64, want ...string) error {
+	var errs []error
+	for _, group := range want {
+		if _, ok := rates[group]; !ok {
+			errs = append(errs, fmt.Errorf("no rate for group %q", group))
+		}
+	}
+	groups := make([]string, 0, len(rates))
+	for group := range rates {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	lo, hi := math.Inf(1), math.Inf(-1)
+	var rendered []string
+	for _, group := range groups {
+		rate := rates[group]
+		if rate <= 0 || math.IsNaN(rate) {
+			errs = append(errs, fmt.Errorf("rate %g/s of group %q is not positive", rate, group))
+		}
+		lo, hi = math.Min(lo, rate), math.Max(hi, rate)
+		rendered = append(rendered, fmt.Sprintf("%s=%g/s", group, rate))
+	}
+	if len(groups) > 1 && lo > 0 && hi/lo > maxRatio {
+		errs = append(errs, fmt.Errorf("rates differ by a factor of %.2f, want at most %g", hi/lo, maxRatio))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("%w\nrates: %s", err, strings.Join(rendered, ", "))
+	}
+	return nil
+}
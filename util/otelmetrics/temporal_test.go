@@ -0,0 +1,155 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package otelmetrics
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+)
+
+func counter(name string, start time.Time, spacing time.Duration, values []float64, attributes ...*commonpb.KeyValue) *metricspb.Metric {
+	var points []*metricspb.NumberDataPoint
+	for i, value := range values {
+		points = append(points, &metricspb.NumberDataPoint{
+			TimeUnixNano: uint64(start.Add(time.Duration(i) * spacing).UnixNano()),
+			Attributes:   attributes,
+			Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: value},
+		})
+	}
+	return &metricspb.Metric{Name: name, Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{DataPoints: points, IsMonotonic: true}}}
+}
+
+func TestQuerySamples(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewClient(ctx, TestConfig{Endpoint: LocalScheme + "127.0.0.1:0", Timeout: 5 * time.Second, MaxRetries: 1})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer client.Close()
+
+	now := time.Now().Truncate(time.Second)
+	start := now.Add(-4 * time.Minute)
+	// the counter resets at the fourth sample, and the last sample comes two minutes after the one before it
+	exportMetrics(t, client, "test-cluster", counter("node_cpu_seconds_total", start, 30*time.Second, []float64{10, 40, 70, 5, 35}, attribute("cpu", "0")))
+	exportMetrics(t, client, "test-cluster", counter("node_cpu_seconds_total", now, 0, []float64{95}, attribute("cpu", "0")))
+
+	results, err := client.QuerySamples(ctx, `node_cpu_seconds_total{"@resource.k8s.cluster.name"="test-cluster"}`, 5*time.Minute, now)
+	if err != nil {
+		t.Fatalf("QuerySamples: %v", err)
+	}
+	if len(results) != 1 || len(results[0].Values) != 6 {
+		t.Fatalf("expected 1 series of 6 samples, got %+v", results)
+	}
+	r := results[0]
+	if !r.Timestamps[0].Equal(start) || !r.Timestamps[5].Equal(now) {
+		t.Fatalf("unexpected timestamps %v", r.Timestamps)
+	}
+	if resets := r.CounterResets(); !reflect.DeepEqual(resets, []time.Time{start.Add(90 * time.Second)}) {
+		t.Fatalf("unexpected resets %v", resets)
+	}
+	if increase := r.Increase(); increase != 30+30+5+30+60 {
+		t.Fatalf("unexpected increase %g", increase)
+	}
+	if rate, ok := r.Rate(); !ok || rate != 155.0/240 {
+		t.Fatalf("unexpected rate %g", rate)
+	}
+
+	err = ExpectRange(r).ResetsAtMost(0).SpacingWithin(15*time.Second, time.Minute).FreshAt(now, time.Minute).Err()
+	if err == nil {
+		t.Fatalf("expected the reset and the late sample to fail")
+	}
+	for _, want := range []string{
+		`failed on "{@resource.k8s.cluster.name=test-cluster, cpu=0}"`,
+		"1 counter resets at",
+		"spacing of 2m0s before",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+}
+
+func TestStaleSeries(t *testing.T) {
+	now := time.Now()
+	fresh := RangeResult{Timestamps: []time.Time{now.Add(-time.Minute), now.Add(-10 * time.Second)}, Values: []float64{1, 2}}
+	stale := RangeResult{Timestamps: []time.Time{now.Add(-5 * time.Minute), now.Add(-3 * time.Minute)}, Values: []float64{1, 2}}
+	got := StaleSeries([]RangeResult{fresh, stale, {}}, now, time.Minute)
+	if len(got) != 2 || !reflect.DeepEqual(got[0], stale) || len(got[1].Timestamps) != 0 {
+		t.Fatalf("unexpected stale series %+v", got)
+	}
+	if latest := LatestSample([]RangeResult{stale, fresh, {}}); !latest.Equal(now.Add(-10 * time.Second)) {
+		t.Fatalf("got latest sample %s, want %s", latest, now.Add(-10*time.Second))
+	}
+	if !LatestSample(nil).IsZero() {
+		t.Fatal("expected no latest sample without results")
+	}
+}
+
+func TestGroupRates(t *testing.T) {
+	now := time.Now()
+	series := func(hostType string, values ...float64) RangeResult {
+		labels := NewMetricLabels()
+		labels.Resource["host.type"] = hostType
+		r := RangeResult{Labels: labels, Values: values}
+		for i := range values {
+			r.Timestamps = append(r.Timestamps, now.Add(time.Duration(i)*10*time.Second))
+		}
+		return r
+	}
+	rates := GroupRates([]RangeResult{
+		series("g4dn.xlarge", 0, 10, 20),
+		series("g4dn.xlarge", 0, 30, 60),
+		series("g4dn.12xlarge", 0, 25),
+		series("g4dn.12xlarge", 7),
+	}, ByResource("host.type"))
+	if !reflect.DeepEqual(rates, map[string]float64{"g4dn.xlarge": 2, "g4dn.12xlarge": 2.5}) {
+		t.Fatalf("unexpected rates %v", rates)
+	}
+
+	testCases := map[string]struct {
+		rates    map[string]float64
+		maxRatio float64
+		groups   []string
+		want     []string
+	}{
+		"Comparable": {rates: rates, maxRatio: 1.5, groups: []string{"g4dn.xlarge", "g4dn.12xlarge"}},
+		"TooFarApart": {
+			rates:    rates,
+			maxRatio: 1.2,
+			want:     []string{"rates differ by a factor of 1.25, want at most 1.2", "rates: g4dn.12xlarge=2.5/s, g4dn.xlarge=2/s"},
+		},
+		"MissingAndIdle": {
+			rates:    map[string]float64{"g4dn.xlarge": 0, "g4dn.12xlarge": math.NaN()},
+			maxRatio: 1.5,
+			groups:   []string{"g4dn.xlarge", "p4d.24xlarge"},
+			want:     []string{`no rate for group "p4d.24xlarge"`, `rate 0/s of group "g4dn.xlarge" is not positive`, `rate NaN/s of group "g4dn.12xlarge"`},
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := ExpectComparableRates(testCase.rates, testCase.maxRatio, testCase.groups...)
+			if len(testCase.want) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			for _, want := range testCase.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
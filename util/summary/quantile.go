@@ -0,0 +1,114 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package summary
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Method is how a quantile falling between two values of a sample is computed. The names follow numpy.
+type Method string
+
+const (
+	// Linear interpolates between the two closest values (Hyndman and Fan type 7, the default of numpy
+	// and of most spreadsheets).
+	Linear Method = "linear"
+	// Lower takes the closest value below.
+	Lower Method = "lower"
+	// Higher takes the closest value above.
+	Higher Method = "higher"
+	// Nearest takes the closest value, the lower one on a tie.
+	Nearest Method = "nearest"
+	// Midpoint averages the two closest values.
+	Midpoint Method = "midpoint"
+	// NearestRank takes the value at rank ceil(q*n) (Hyndman and Fan type 1), which is always a value of
+	// the sample.
+	NearestRank Method = "nearest-rank"
+
+	DefaultMethod = Linear
+)
+
+var ErrEmpty = errors.New("no values")
+
+// Quantile returns the q quantile (0 <= q <= 1) of the values. The values are not modified.
+func Quantile(values []float64, q float64, method Method) (float64, error) {
+	quantiles, err := Quantiles(values, []float64{q}, method)
+	if err != nil {
+		return 0, err
+	}
+	return quantiles[0], nil
+}
+
+// Quantiles returns the quantiles of the values, sorting a copy of them once. The values are not modified.
+func Quantiles(values []float64, qs []float64, method Method) ([]float64, error) {
+	if len(values) == 0 {
+		return nil, ErrEmpty
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	result := make([]float64, len(qs))
+	for i, q := range qs {
+		value, err := sortedQuantile(sorted, q, method)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = value
+	}
+	return result, nil
+}
+
+func sortedQuantile(sorted []float64, q float64, method Method) (float64, error) {
+	if q < 0 || q > 1 || math.IsNaN(q) {
+		return 0, fmt.Errorf("quantile %v is not within [0, 1]", q)
+	}
+	n := len(sorted)
+	if method == NearestRank {
+		rank := int(math.Ceil(q * float64(n)))
+		if rank < 1 {
+			rank = 1
+		}
+		return sorted[rank-1], nil
+	}
+
+	position := q * float64(n-1)
+	lower, higher := int(math.Floor(position)), int(math.Ceil(position))
+	fraction := position - float64(lower)
+	switch method {
+	case Linear, "":
+		return sorted[lower] + fraction*(sorted[higher]-sorted[lower]), nil
+	case Lower:
+		return sorted[lower], nil
+	case Higher:
+		return sorted[higher], nil
+	case Nearest:
+		if fraction > 0.5 {
+			return sorted[higher], nil
+		}
+		return sorted[lower], nil
+	case Midpoint:
+		return (sorted[lower] + sorted[higher]) / 2, nil
+	default:
+		return 0, fmt.Errorf("unsupported quantile method %q", method)
+	}
+}
+
+// ParseQuantile parses a quantile written as a percentile (e.g p99.9 is 0.999).
+func ParseQuantile(name string) (float64, error) {
+	percentile, err := strconv.ParseFloat(strings.TrimPrefix(strings.ToLower(name), "p"), 64)
+	if err != nil || !strings.HasPrefix(strings.ToLower(name), "p") || percentile < 0 || percentile > 100 {
+		return 0, fmt.Errorf("invalid percentile %q, must be p0 to p100 (e.g p99.9)", name)
+	}
+	return percentile / 100, nil
+}
+
+// QuantileName writes the quantile as a percentile (e.g 0.999 is p99.9).
+func QuantileName(q float64) string {
+	return "p" + strconv.FormatFloat(math.Round(q*100*1e6)/1e6, 'f', -1, 64)
+}
@@ -0,0 +1,223 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package summary
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	DefaultRelativeAccuracy = 0.01
+	DefaultMaxBins          = 2048
+)
+
+// Sketch is a DDSketch: a streaming quantile sketch whose quantiles are within a relative accuracy of
+// the exact ones, using memory bounded by the number of bins rather than the number of values. Use it
+// instead of Summarize for long soak runs where keeping every value is not practical. Count, Min, Max
+// and Mean are exact.
+// https://arxiv.org/abs/1908.10693
+type Sketch struct {
+	gamma            float64
+	logGamma         float64
+	maxBins          int
+	positive         *bins
+	negative         *bins
+	zeros            uint64
+	count            uint64
+	sum              float64
+	min              float64
+	max              float64
+	relativeAccuracy float64
+}
+
+// NewSketch creates a sketch with the relative accuracy (e.g 0.01 for quantiles within 1%) and keeping at
+// most maxBins bins per sign. When more bins are needed, the lowest ones are collapsed, losing accuracy
+// on the lowest quantiles only.
+func NewSketch(relativeAccuracy float64, maxBins int) (*Sketch, error) {
+	if relativeAccuracy <= 0 || relativeAccuracy >= 1 {
+		return nil, fmt.Errorf("relative accuracy %v is not within (0, 1)", relativeAccuracy)
+	}
+	if maxBins < 1 {
+		return nil, fmt.Errorf("max bins must be at least 1, got %d", maxBins)
+	}
+	gamma := (1 + relativeAccuracy) / (1 - relativeAccuracy)
+	return &Sketch{
+		gamma:            gamma,
+		logGamma:         math.Log(gamma),
+		maxBins:          maxBins,
+		positive:         newBins(),
+		negative:         newBins(),
+		min:              math.Inf(1),
+		max:              math.Inf(-1),
+		relativeAccuracy: relativeAccuracy,
+	}, nil
+}
+
+// Add records a value. NaN and infinite values are ignored.
+func (s *Sketch) Add(value float64) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return
+	}
+	switch {
+	case value > 0:
+		s.positive.add(s.index(value), 1, s.maxBins)
+	case value < 0:
+		s.negative.add(s.index(-value), 1, s.maxBins)
+	default:
+		s.zeros++
+	}
+	s.count++
+	s.sum += value
+	s.min = math.Min(s.min, value)
+	s.max = math.Max(s.max, value)
+}
+
+// Merge adds the values recorded by other, which must have the same relative accuracy.
+func (s *Sketch) Merge(other *Sketch) error {
+	if other.gamma != s.gamma {
+		return fmt.Errorf("cannot merge a sketch with relative accuracy %v into one with %v", other.relativeAccuracy, s.relativeAccuracy)
+	}
+	for index, count := range other.positive.counts {
+		s.positive.add(index, count, s.maxBins)
+	}
+	for index, count := range other.negative.counts {
+		s.negative.add(index, count, s.maxBins)
+	}
+	s.zeros += other.zeros
+	s.count += other.count
+	s.sum += other.sum
+	s.min = math.Min(s.min, other.min)
+	s.max = math.Max(s.max, other.max)
+	return nil
+}
+
+func (s *Sketch) Count() int { return int(s.count) }
+
+func (s *Sketch) Min() float64 { return s.min }
+
+func (s *Sketch) Max() float64 { return s.max }
+
+func (s *Sketch) Mean() float64 {
+	if s.count == 0 {
+		return 0
+	}
+	return s.sum / float64(s.count)
+}
+
+// Quantile returns the q quantile (0 <= q <= 1) of the values added, within the relative accuracy.
+func (s *Sketch) Quantile(q float64) (float64, error) {
+	if s.count == 0 {
+		return 0, ErrEmpty
+	}
+	if q < 0 || q > 1 || math.IsNaN(q) {
+		return 0, fmt.Errorf("quantile %v is not within [0, 1]", q)
+	}
+	rank := q * float64(s.count-1)
+
+	var value float64
+	var cumulative float64
+	found := false
+	// Negative values from the most negative, then zeros, then positive values
+	negativeIndexes := s.negative.sortedIndexes()
+	for i := len(negativeIndexes) - 1; i >= 0 && !found; i-- {
+		cumulative += float64(s.negative.counts[negativeIndexes[i]])
+		if cumulative > rank {
+			value, found = -s.value(negativeIndexes[i]), true
+		}
+	}
+	if !found {
+		cumulative += float64(s.zeros)
+		if cumulative > rank {
+			value, found = 0, true
+		}
+	}
+	if !found {
+		for _, index := range s.positive.sortedIndexes() {
+			cumulative += float64(s.positive.counts[index])
+			if cumulative > rank {
+				value, found = s.value(index), true
+				break
+			}
+		}
+	}
+	if !found {
+		value = s.max
+	}
+	// The exact extremes are known and bound every quantile
+	return math.Max(s.min, math.Min(s.max, value)), nil
+}
+
+// Summary summarizes the sketch with the quantiles (DefaultQuantiles when empty). Std is not available
+// from a sketch and is left at 0.
+func (s *Sketch) Summary(qs ...float64) (Summary, error) {
+	if s.count == 0 {
+		return Summary{}, ErrEmpty
+	}
+	if len(qs) == 0 {
+		qs = DefaultQuantiles
+	}
+	summary := Summary{
+		Count:     s.Count(),
+		Min:       s.min,
+		Max:       s.max,
+		Mean:      s.Mean(),
+		Quantiles: make(map[string]float64, len(qs)),
+	}
+	for _, q := range qs {
+		value, err := s.Quantile(q)
+		if err != nil {
+			return Summary{}, err
+		}
+		summary.Quantiles[QuantileName(q)] = value
+	}
+	return summary, nil
+}
+
+func (s *Sketch) index(value float64) int {
+	return int(math.Ceil(math.Log(value) / s.logGamma))
+}
+
+// value is the representative of the bin, within the relative accuracy of every value of the bin.
+func (s *Sketch) value(index int) float64 {
+	return 2 * math.Pow(s.gamma, float64(index)) / (s.gamma + 1)
+}
+
+type bins struct {
+	counts map[int]uint64
+	// floor is the lowest index left after collapsing, lower indexes are counted in it
+	floor     int
+	collapsed bool
+}
+
+func newBins() *bins {
+	return &bins{counts: map[int]uint64{}}
+}
+
+func (b *bins) add(index int, count uint64, maxBins int) {
+	if b.collapsed && index < b.floor {
+		index = b.floor
+	}
+	b.counts[index] += count
+	if len(b.counts) <= maxBins {
+		return
+	}
+	indexes := b.sortedIndexes()
+	floor := indexes[len(indexes)-maxBins]
+	for _, i := range indexes[:len(indexes)-maxBins] {
+		b.counts[floor] += b.counts[i]
+		delete(b.counts, i)
+	}
+	b.floor, b.collapsed = floor, true
+}
+
+func (b *bins) sortedIndexes() []int {
+	indexes := make([]int, 0, len(b.counts))
+	for index := range b.counts {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
@@ -0,0 +1,69 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package summary
+
+import (
+	"math"
+)
+
+// DefaultQuantiles are the quantiles summarized when none are asked for.
+var DefaultQuantiles = []float64{0.5, 0.9, 0.95, 0.99, 0.999}
+
+// Summary describes the distribution of a sample.
+type Summary struct {
+	Count int
+	Min   float64
+	Max   float64
+	Mean  float64
+	// Std is the population standard deviation
+	Std float64
+	// Quantiles are keyed by their percentile name (e.g p99.9)
+	Quantiles map[string]float64
+}
+
+// Quantile returns a quantile of the summary, which must have been summarized.
+func (s Summary) Quantile(q float64) (float64, bool) {
+	value, ok := s.Quantiles[QuantileName(q)]
+	return value, ok
+}
+
+// Summarize computes the summary of the values with the quantiles (DefaultQuantiles when empty). The
+// values are not modified.
+func Summarize(values []float64, method Method, qs ...float64) (Summary, error) {
+	if len(values) == 0 {
+		return Summary{}, ErrEmpty
+	}
+	if len(qs) == 0 {
+		qs = DefaultQuantiles
+	}
+	quantiles, err := Quantiles(values, qs, method)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	summary := Summary{
+		Count:     len(values),
+		Min:       math.Inf(1),
+		Max:       math.Inf(-1),
+		Quantiles: make(map[string]float64, len(qs)),
+	}
+	var sum float64
+	for _, value := range values {
+		sum += value
+		summary.Min = math.Min(summary.Min, value)
+		summary.Max = math.Max(summary.Max, value)
+	}
+	summary.Mean = sum / float64(len(values))
+
+	var squares float64
+	for _, value := range values {
+		squares += (value - summary.Mean) * (value - summary.Mean)
+	}
+	summary.Std = math.Sqrt(squares / float64(len(values)))
+
+	for i, q := range qs {
+		summary.Quantiles[QuantileName(q)] = quantiles[i]
+	}
+	return summary, nil
+}
@@ -0,0 +1,173 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package summary
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuantile(t *testing.T) {
+	values := []float64{7, 1, 3, 5, 9, 2, 8, 4, 6, 10}
+	// numpy.quantile(values, q, method=...)
+	testCases := map[string]struct {
+		q      float64
+		method Method
+		want   float64
+	}{
+		"LinearMedian":        {q: 0.5, method: Linear, want: 5.5},
+		"LinearP90":           {q: 0.9, method: Linear, want: 9.1},
+		"LinearP99":           {q: 0.99, method: Linear, want: 9.91},
+		"DefaultIsLinear":     {q: 0.99, want: 9.91},
+		"LowerP90":            {q: 0.9, method: Lower, want: 9},
+		"HigherP90":           {q: 0.9, method: Higher, want: 10},
+		"NearestP90":          {q: 0.9, method: Nearest, want: 9},
+		"NearestP95":          {q: 0.95, method: Nearest, want: 10},
+		"MidpointP90":         {q: 0.9, method: Midpoint, want: 9.5},
+		"NearestRankP90":      {q: 0.9, method: NearestRank, want: 9},
+		"NearestRankP91":      {q: 0.91, method: NearestRank, want: 10},
+		"NearestRankMinimum":  {q: 0, method: NearestRank, want: 1},
+		"LinearMinimum":       {q: 0, method: Linear, want: 1},
+		"LinearMaximum":       {q: 1, method: Linear, want: 10},
+		"NearestRankMaximum":  {q: 1, method: NearestRank, want: 10},
+		"MidpointMedian":      {q: 0.5, method: Midpoint, want: 5.5},
+		"LowerMedianIsSample": {q: 0.5, method: Lower, want: 5},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Quantile(values, testCase.q, testCase.method)
+			require.NoError(t, err)
+			assert.InDelta(t, testCase.want, got, 1e-9)
+		})
+	}
+	assert.Equal(t, []float64{7, 1, 3, 5, 9, 2, 8, 4, 6, 10}, values, "the values must not be sorted in place")
+}
+
+func TestQuantileSmallSamples(t *testing.T) {
+	for _, method := range []Method{Linear, Lower, Higher, Nearest, Midpoint, NearestRank} {
+		got, err := Quantile([]float64{42}, 0.99, method)
+		require.NoError(t, err, method)
+		assert.Equal(t, 42.0, got, method)
+
+		got, err = Quantile([]float64{1, 2}, 0.999, method)
+		require.NoError(t, err, method)
+		assert.GreaterOrEqual(t, got, 1.0, method)
+		assert.LessOrEqual(t, got, 2.0, method)
+	}
+}
+
+func TestQuantileErrors(t *testing.T) {
+	_, err := Quantile(nil, 0.5, Linear)
+	assert.ErrorIs(t, err, ErrEmpty)
+	_, err = Quantile([]float64{1}, 1.5, Linear)
+	assert.EqualError(t, err, "quantile 1.5 is not within [0, 1]")
+	_, err = Quantile([]float64{1}, 0.5, "cubic")
+	assert.EqualError(t, err, `unsupported quantile method "cubic"`)
+}
+
+func TestQuantileNames(t *testing.T) {
+	for name, q := range map[string]float64{"p50": 0.5, "p90": 0.9, "p99": 0.99, "p99.9": 0.999, "p100": 1, "p0": 0} {
+		parsed, err := ParseQuantile(name)
+		require.NoError(t, err)
+		assert.InDelta(t, q, parsed, 1e-12)
+		assert.Equal(t, name, QuantileName(q))
+	}
+	for _, name := range []string{"99", "p", "p101", "median"} {
+		_, err := ParseQuantile(name)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	values := []float64{4, 2, 6, 8}
+	summary, err := Summarize(values, Linear)
+	require.NoError(t, err)
+	assert.Equal(t, 4, summary.Count)
+	assert.Equal(t, 2.0, summary.Min)
+	assert.Equal(t, 8.0, summary.Max)
+	assert.Equal(t, 5.0, summary.Mean)
+	assert.InDelta(t, math.Sqrt(5), summary.Std, 1e-12)
+	assert.Len(t, summary.Quantiles, len(DefaultQuantiles))
+	p50, ok := summary.Quantile(0.5)
+	assert.True(t, ok)
+	assert.Equal(t, 5.0, p50)
+	p999, ok := summary.Quantile(0.999)
+	assert.True(t, ok)
+	assert.InDelta(t, 7.994, p999, 1e-9)
+	assert.Equal(t, []float64{4, 2, 6, 8}, values)
+
+	summary, err = Summarize(values, NearestRank, 0.75)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"p75": 6}, summary.Quantiles)
+
+	_, err = Summarize(nil, Linear)
+	assert.ErrorIs(t, err, ErrEmpty)
+}
+
+func TestSketch(t *testing.T) {
+	sketch, err := NewSketch(DefaultRelativeAccuracy, DefaultMaxBins)
+	require.NoError(t, err)
+	_, err = sketch.Quantile(0.5)
+	assert.ErrorIs(t, err, ErrEmpty)
+
+	rng := rand.New(rand.NewSource(1))
+	values := make([]float64, 100000)
+	for i := range values {
+		// A long tailed distribution with some zeros and negative values
+		values[i] = math.Exp(rng.NormFloat64()*2) - 0.05
+		if i%1000 == 0 {
+			values[i] = 0
+		}
+		sketch.Add(values[i])
+	}
+	sketch.Add(math.NaN())
+
+	assert.Equal(t, len(values), sketch.Count())
+	for _, q := range []float64{0, 0.1, 0.5, 0.9, 0.95, 0.99, 0.999, 1} {
+		exact, err := Quantile(values, q, Lower)
+		require.NoError(t, err)
+		approximate, err := sketch.Quantile(q)
+		require.NoError(t, err)
+		assert.InDelta(t, exact, approximate, math.Abs(exact)*DefaultRelativeAccuracy+1e-12, "quantile %v", q)
+	}
+
+	summary, err := sketch.Summary(0.99)
+	require.NoError(t, err)
+	assert.Equal(t, len(values), summary.Count)
+	assert.Contains(t, summary.Quantiles, "p99")
+}
+
+func TestSketchMergeAndCollapse(t *testing.T) {
+	first, err := NewSketch(0.02, 64)
+	require.NoError(t, err)
+	second, err := NewSketch(0.02, 64)
+	require.NoError(t, err)
+	for i := 1; i <= 1000; i++ {
+		first.Add(float64(i))
+		second.Add(float64(i) * 1000)
+	}
+	require.NoError(t, first.Merge(second))
+	assert.Equal(t, 2000, first.Count())
+	assert.LessOrEqual(t, len(first.positive.counts), 64)
+	assert.Equal(t, 1.0, first.Min())
+	assert.Equal(t, 1000000.0, first.Max())
+
+	// The highest quantiles keep their accuracy when the lowest bins are collapsed
+	p99, err := first.Quantile(0.99)
+	require.NoError(t, err)
+	assert.InDelta(t, 980000, p99, 980000*0.02)
+
+	other, err := NewSketch(0.05, 64)
+	require.NoError(t, err)
+	assert.Error(t, first.Merge(other))
+
+	_, err = NewSketch(0, 64)
+	assert.Error(t, err)
+	_, err = NewSketch(0.01, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,146 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/validators"
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/validators/stress"
+)
+
+const (
+	scopeOS           = "os"
+	scopeArch         = "arch"
+	scopeInstanceType = "instance-type"
+)
+
+// runBaseline implements `validator baseline`. It runs the stress load of a parameters.yml several times
+// against the running agent, recommends bounds from the highest values observed and writes them into
+// the baseline of the host, printing a report of the changes to review followed by the unified diff of the
+// baseline file.
+func runBaseline(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("baseline", flag.ContinueOnError)
+	var (
+		configPath  = flags.String("validator-config", "", "The stress parameters.yml to run, the agent must already run with its config")
+		iterations  = flags.Int("iterations", 3, "Number of times the load is run")
+		headroom    = flags.Float64("headroom", 0.1, "Fraction added to the highest value observed, on top of the error bound of the baseline")
+		baselineDir = flags.String("baselines-dir", stress.DefaultBaselineDir, "Directory of the baseline files to update")
+		scope       = flags.String("scope", scopeOS, "Baseline file the bounds are written to: os, arch or instance-type of the host")
+		dryRun      = flags.Bool("dry-run", false, "Only print the report without writing the baseline")
+	)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *configPath == "" {
+		return errors.New("baseline needs --validator-config")
+	}
+	if *iterations < 1 {
+		return fmt.Errorf("iterations must be at least 1, got %d", *iterations)
+	}
+
+	vConfig, err := loadValidateConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	if len(vConfig.GetPluginsConfig()) == 0 {
+		return fmt.Errorf("test case %s has no receivers to run the load of", vConfig.GetTestCase())
+	}
+	var (
+		host     = stress.NewTarget(vConfig.GetOSFamily(), runtime.GOARCH, awsservice.GetInstanceType())
+		target   = stress.Target{OS: host.OS}
+		dataRate = fmt.Sprint(vConfig.GetDataRate())
+		receiver = vConfig.GetPluginsConfig()[0]
+	)
+	switch *scope {
+	case scopeOS:
+	case scopeArch:
+		target.Arch = host.Arch
+	case scopeInstanceType:
+		target.Arch, target.InstanceType = host.Arch, host.InstanceType
+	default:
+		return fmt.Errorf("unsupported scope %q, must be %s, %s or %s", *scope, scopeOS, scopeArch, scopeInstanceType)
+	}
+
+	baselines, err := stress.LoadBaselines(os.DirFS(*baselineDir))
+	if err != nil {
+		return err
+	}
+	// The host may not have bounds yet (e.g a new instance type), the report then shows every bound as new
+	current, _ := stress.ResolveBounds(baselines, host)
+
+	observations, err := validators.Observe(vConfig, clock.Real(), *iterations)
+	if err != nil {
+		return err
+	}
+	recommended := stress.Recommend(observations, *headroom)
+
+	update, err := stress.UpdateBaseline(*baselineDir, target, dataRate, receiver, recommended, *dryRun)
+	if err != nil {
+		return err
+	}
+	diff, err := update.Diff()
+	if err != nil {
+		return err
+	}
+
+	action := "Written to"
+	if *dryRun {
+		action = "Dry run, not written to"
+	}
+	fmt.Fprintf(out, "Stress baseline of %s at data rate %s on %s over %d iterations with %.0f%% headroom. %s %s\n\n",
+		receiver, dataRate, host, *iterations, *headroom*100, action, update.Path)
+	writeBaselineReport(out, observations, current, dataRate, receiver, recommended)
+	if diff == "" {
+		fmt.Fprintf(out, "\n%s is unchanged\n", update.Path)
+	} else {
+		fmt.Fprintf(out, "\n```diff\n%s```\n", diff)
+	}
+	return nil
+}
+
+// writeBaselineReport writes the changes as a markdown table to paste in the pull request.
+func writeBaselineReport(out io.Writer, observations []map[string]float64, current stress.Bounds, dataRate, receiver string, recommended map[string]float64) {
+	metrics := maps.Keys(recommended)
+	sort.Strings(metrics)
+
+	fmt.Fprintln(out, "| Metric | Observed max | Current bound | Recommended | Change |")
+	fmt.Fprintln(out, "|--------|--------------|---------------|-------------|--------|")
+	for _, metric := range metrics {
+		var observed float64
+		for _, observation := range observations {
+			if value, ok := observation[metric]; ok && value > observed {
+				observed = value
+			}
+		}
+		currentBound, difference := "-", "new"
+		if bound, ok := current.Bound(dataRate, receiver, metric); ok {
+			currentBound = formatValue(bound)
+			switch {
+			case bound == recommended[metric]:
+				difference = "unchanged"
+			case bound == 0:
+				difference = "from 0"
+			default:
+				difference = fmt.Sprintf("%+.1f%%", (recommended[metric]-bound)/bound*100)
+			}
+		}
+		fmt.Fprintf(out, "| %s | %s | %s | %s | %s |\n", metric, formatValue(observed), currentBound, formatValue(recommended[metric]), difference)
+	}
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
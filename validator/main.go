@@ -9,73 +9,143 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"golang.org/x/exp/slices"
+
 	"github.com/aws/amazon-cloudwatch-agent-test/environment"
-	"github.com/aws/amazon-cloudwatch-agent-test/test/acceptance"
-	"github.com/aws/amazon-cloudwatch-agent-test/test/log_state/journald"
-	"github.com/aws/amazon-cloudwatch-agent-test/test/log_state/logfile"
-	"github.com/aws/amazon-cloudwatch-agent-test/test/log_state/windows_event_log"
-	"github.com/aws/amazon-cloudwatch-agent-test/test/nvidia_gpu"
-	"github.com/aws/amazon-cloudwatch-agent-test/test/restart"
-	"github.com/aws/amazon-cloudwatch-agent-test/test/ssm_document"
-	"github.com/aws/amazon-cloudwatch-agent-test/test/workload_discovery"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/localbackend"
 	"github.com/aws/amazon-cloudwatch-agent-test/validator/models"
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/registry"
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/regression"
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/resultstore"
 	"github.com/aws/amazon-cloudwatch-agent-test/validator/validators"
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/validators/stress"
+
+	// Tests run with --test-name register themselves with the registry.
+	_ "github.com/aws/amazon-cloudwatch-agent-test/test/acceptance"
+	_ "github.com/aws/amazon-cloudwatch-agent-test/test/log_loss"
+	_ "github.com/aws/amazon-cloudwatch-agent-test/test/log_state/chaos"
+	_ "github.com/aws/amazon-cloudwatch-agent-test/test/log_state/journald"
+	_ "github.com/aws/amazon-cloudwatch-agent-test/test/log_state/logfile"
+	_ "github.com/aws/amazon-cloudwatch-agent-test/test/log_state/windows_event_log"
+	_ "github.com/aws/amazon-cloudwatch-agent-test/test/nvidia_gpu"
+	_ "github.com/aws/amazon-cloudwatch-agent-test/test/restart"
+	_ "github.com/aws/amazon-cloudwatch-agent-test/test/ssm_document"
+	_ "github.com/aws/amazon-cloudwatch-agent-test/test/workload_discovery"
 )
 
 var (
-	configPath      = flag.String("validator-config", "", "A yaml depicts test information")
-	preparationMode = flag.Bool("preparation-mode", false, "Prepare all the resources for the validation (e.g set up config) ")
-	testName        = flag.String("test-name", "", "Test name to execute")
-	assumeRoleArn   = flag.String("role-arn", "", "Arn for assume IAM role if any")
+	metaDataStrings *environment.MetaDataStrings
+
+	configPath          = flag.String("validator-config", "", "A yaml depicts test information")
+	preparationMode     = flag.Bool("preparation-mode", false, "Prepare all the resources for the validation (e.g set up config) ")
+	testName            = flag.String("test-name", "", "Test name to execute")
+	assumeRoleArn       = flag.String("role-arn", "", "Arn for assume IAM role if any")
+	backend             = flag.String("backend", backendAWS, "Backend the validator reads from: aws or local (an in-process fake of CloudWatch, CloudWatch Logs and X-Ray)")
+	localAddress        = flag.String("local-backend-address", localbackend.DefaultAddress, "Address the local backend listens on, the agent's endpoint_override must point here")
+	listPlugins         = flag.Bool("list", false, "List the available validate types with their parameters and the available test names")
+	timeScale           = flag.Float64("time-scale", 1, "Run every validator wait this many times faster than the wall clock (e.g 60 turns a minute into a second), 1 is real time")
+	checkConfig         = flag.Bool("check-config", false, "Only check the --validator-config and any parameters.yml given as arguments, exiting with 1 if one is invalid")
+	stressBaselines     = flag.String("stress-baselines", "", "Directory of the stress baseline files, the baselines built into the validator are used when empty")
+	regressionMode      = flag.String("regression", regression.ModeWarn, "What a performance regression against the previous commits in the results store does: off, warn or fail")
+	regressionMethod    = flag.String("regression-method", regression.MethodBootstrap, "Significance test of the regression check: bootstrap or mann-whitney")
+	regressionHistory   = flag.Int("regression-history", regression.DefaultConfig().History, "Number of previous commits the regression check compares against")
+	regressionRecent    = flag.Int("regression-recent", 0, "Number of latest commits, the current one included, the regression check treats as the candidate, 0 for the default of the method: 1 for bootstrap, 3 for mann-whitney")
+	regressionMaxEffect = flag.Float64("regression-max-effect", regression.DefaultConfig().MaxEffect, "Relative increase of a statistic tolerated by the regression check (e.g 0.1 for 10%)")
+	regressionAlpha     = flag.Float64("regression-alpha", regression.DefaultConfig().Alpha, "Significance level of the regression check")
+	resultsStore        = flag.String("results-store", resultstore.KindDynamoDB, "Where the performance validator saves its results: dynamodb[:<table>], file:<path> (a local JSON file) or pushgateway:<url>")
+)
+
+const (
+	backendAWS             = "aws"
+	backendLocal           = "local"
+	localBackendRegion     = "us-west-2"
+	localBackendInstanceId = "i-localbackend"
+	localResultsFile       = "cwagent-performance-local.json"
 )
 
 func init() {
-	environment.RegisterEnvironmentMetaDataFlags() // Add this line
+	metaDataStrings = environment.RegisterEnvironmentMetaDataFlags()
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "baseline" {
+		if err := runBaseline(os.Args[2:], os.Stdout); err != nil && !errors.Is(err, flag.ErrHelp) {
+			log.Fatalf("Baseline failed: %v", err)
+		}
+		return
+	}
+
 	flag.Parse()
 
+	if *listPlugins {
+		registry.List(os.Stdout)
+		return
+	}
+
+	if *checkConfig {
+		if !checkConfigs(append([]string{*configPath}, flag.Args()...)) {
+			os.Exit(1)
+		}
+		return
+	}
+
 	startTime := time.Now()
 
+	switch *backend {
+	case backendAWS:
+	case backendLocal:
+		server, err := startLocalBackend()
+		if err != nil {
+			log.Fatalf("Failed to start local backend: %v", err)
+		}
+		defer server.Close()
+	default:
+		log.Fatalf("Unsupported backend %q, must be %s or %s", *backend, backendAWS, backendLocal)
+	}
+
+	store, err := openResultsStore()
+	if err != nil {
+		log.Fatalf("Failed to open results store: %v", err)
+	}
+	resultstore.SetDefault(store)
+
+	regressionConfig := regression.DefaultConfigFor(*regressionMethod)
+	regressionConfig.Mode, regressionConfig.History = *regressionMode, *regressionHistory
+	if *regressionRecent > 0 {
+		regressionConfig.Recent = *regressionRecent
+	}
+	regressionConfig.MaxEffect, regressionConfig.Alpha = *regressionMaxEffect, *regressionAlpha
+	if regressionConfig.MinHistory > regressionConfig.History {
+		regressionConfig.MinHistory = regressionConfig.History
+	}
+	if err = regressionConfig.Validate(); err != nil {
+		log.Fatalf("Invalid regression check: %v", err)
+	}
+	regression.Configure(regressionConfig)
+	stress.SetBaselineDir(*stressBaselines)
+
 	// validator calls test code to get around OOM issue on windows hosts while running go test
 	if len(*configPath) == 0 && len(*testName) > 0 {
 		// execute test without parsing or processing configuration yaml
 
-		var err error
-
 		splitNames := strings.Split(*testName, "/")
-		switch splitNames[len(splitNames)-1] {
-		case "restart":
-			err = restart.Validate()
-		case "workload_discovery":
-			err = workload_discovery.Validate()
-		case "nvidia_gpu":
-			err = nvidia_gpu.Validate()
-		case "acceptance":
-			err = acceptance.Validate()
-		case "logfile":
-			err = logfile.Validate()
-		case "journald":
-			err = journald.Validate()
-		case "windows_event_log":
-			err = windows_event_log.Validate()
-		case "ssm_document":
-			err = ssm_document.Validate()
-		default:
-			err = errors.New("unsupported test name")
+		test, err := registry.LookupTest(splitNames[len(splitNames)-1])
+		if err == nil {
+			err = test.Validate()
 		}
 
 		if err != nil {
 			log.Fatalf("Validator failed with %s: %v", *testName, err)
 		}
 	} else {
-		vConfig, err := models.NewValidateConfig(*configPath)
+		vConfig, err := loadValidateConfig(*configPath)
 		if err != nil {
 			log.Fatalf("Failed to create validation config : %v \n", err)
 		}
@@ -87,7 +157,7 @@ func main() {
 
 			os.Exit(0)
 		}
-		err = validate(vConfig)
+		err = validate(vConfig, clock.Accelerated(*timeScale))
 		if err != nil {
 			log.Fatalf("Failed to validate: %v", err)
 		}
@@ -99,16 +169,66 @@ func main() {
 
 }
 
-func validate(vConfig models.ValidateConfig) error {
+// loadValidateConfig reads the parameters.yml and checks it declares everything its validate type needs.
+func loadValidateConfig(path string) (models.ValidateConfig, error) {
+	vConfig, err := models.NewValidateConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	validator, err := registry.LookupValidator(vConfig.GetValidateType())
+	if err != nil {
+		return nil, fmt.Errorf("test case %s: %w", vConfig.GetTestCase(), err)
+	}
+	if err = registry.CheckParameters(validator, path); err != nil {
+		return nil, err
+	}
+	if *backend == backendLocal {
+		var problems []string
+		for i, metric := range vConfig.GetMetricValidation() {
+			for _, problem := range metric.LocalBackendProblems() {
+				problems = append(problems, fmt.Sprintf("metric_validation[%d] %s", i, problem))
+			}
+		}
+		if len(problems) > 0 {
+			return nil, fmt.Errorf("test case %s cannot run on the local backend:\n%s", vConfig.GetTestCase(), strings.Join(problems, "\n"))
+		}
+	}
+	return vConfig, nil
+}
+
+// checkConfigs reports whether every non empty path is a valid parameters.yml, printing the problems of
+// the ones that are not.
+func checkConfigs(paths []string) bool {
+	valid, checked := true, 0
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		checked++
+		if _, err := loadValidateConfig(path); err != nil {
+			valid = false
+			fmt.Printf("%s: %v\n", path, err)
+			continue
+		}
+		fmt.Printf("%s: ok\n", path)
+	}
+	if checked == 0 {
+		fmt.Println("No parameters.yml to check, pass --validator-config or paths as arguments")
+		return false
+	}
+	return valid
+}
+
+func validate(vConfig models.ValidateConfig, clk clock.Clock) error {
 	var err error
 	for i := 0; i < awsservice.StandardRetries; i++ {
-		err = validators.LaunchValidator(vConfig)
+		err = validators.LaunchValidator(vConfig, clk)
 
 		if err == nil {
 			log.Printf("Test case: %s, validate type: %s has been successfully validated", vConfig.GetTestCase(), vConfig.GetValidateType())
 			return nil
 		}
-		time.Sleep(60 * time.Second)
+		clk.Sleep(60 * time.Second)
 		log.Printf("test case: %s, validate type: %s, error: %v", vConfig.GetTestCase(), vConfig.GetValidateType(), err)
 		continue
 	}
@@ -124,11 +244,54 @@ func prepare(vConfig models.ValidateConfig) error {
 		numberLogsMonitored = vConfig.GetNumberMonitoredLogs()
 		agentConfigFilePath = vConfig.GetCloudWatchAgentConfigPath()
 	)
-	switch dataType {
-	case "logs":
+	// Mixed workloads (e.g a performance run with statsd and logs) monitor logs whatever their data type
+	if dataType == "logs" || slices.Contains(vConfig.GetPluginsConfig(), "logs") {
 		err = common.GenerateLogConfig(numberLogsMonitored, agentConfigFilePath)
-	default:
 	}
 
 	return err
 }
+
+// openResultsStore opens the --results-store. The local backend does not fake DynamoDB, so it saves the
+// results to a file unless another store is given, and refuses to write them to the shared table.
+func openResultsStore() (resultstore.Store, error) {
+	if *backend != backendLocal {
+		return resultstore.Open(*resultsStore)
+	}
+	spec := *resultsStore
+	explicit := false
+	flag.Visit(func(f *flag.Flag) { explicit = explicit || f.Name == "results-store" })
+	if kind, _, _ := strings.Cut(spec, ":"); kind == resultstore.KindDynamoDB {
+		if explicit {
+			return nil, fmt.Errorf("results store %q cannot be used with the %s backend, use %s:<path> or %s:<url>",
+				spec, backendLocal, resultstore.KindFile, resultstore.KindPushgateway)
+		}
+		spec = resultstore.KindFile + ":" + filepath.Join(os.TempDir(), localResultsFile)
+		log.Printf("Validator is saving the performance results of the local backend to %s", spec)
+	}
+	return resultstore.Open(spec)
+}
+
+// startLocalBackend starts the in-process fake and points the awsservice clients at it, so the
+// validators can run without an AWS account.
+func startLocalBackend() (*localbackend.Server, error) {
+	server, err := localbackend.NewServer(*localAddress)
+	if err != nil {
+		return nil, err
+	}
+	server.Start()
+
+	// Read the raw flags since the full environment metadata requires flags (e.g computeType) that do
+	// not matter when running locally.
+	region := metaDataStrings.Region
+	if region == "" {
+		region = localBackendRegion
+	}
+	instanceId := metaDataStrings.InstanceId
+	if instanceId == "" {
+		instanceId = localBackendInstanceId
+	}
+	awsservice.ConfigureLocalBackend(server.Endpoint(), region, instanceId)
+	log.Printf("Validator is using the local backend at %s with instance id %s", server.Endpoint(), instanceId)
+	return server, nil
+}
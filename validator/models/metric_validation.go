@@ -0,0 +1,162 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package models
+
+import (
+	"fmt"
+	"regexp"
+
+	"golang.org/x/exp/slices"
+)
+
+// Comparator is how the values of a metric_validation compare with its metric_value.
+type Comparator string
+
+const (
+	// ComparatorEq expects every value to equal metric_value.
+	ComparatorEq Comparator = "eq"
+	// ComparatorApprox expects every value to be within tolerance of metric_value.
+	ComparatorApprox Comparator = "approx"
+	// ComparatorGte expects every value to be at least metric_value.
+	ComparatorGte Comparator = "gte"
+	// ComparatorLte expects every value to be at most metric_value.
+	ComparatorLte Comparator = "lte"
+	// ComparatorRange expects every value to be in [min_value, max_value].
+	ComparatorRange Comparator = "range"
+	// ComparatorRateOfChange expects the change per second between consecutive values to be within tolerance
+	// of metric_value, e.g for a counter.
+	ComparatorRateOfChange Comparator = "rate-of-change"
+
+	DefaultTolerance = 0.1
+	// ResultQueryID is the id of the query of the validated metric or expression, which expression_metrics cannot use
+	ResultQueryID = "result"
+)
+
+var Comparators = []Comparator{ComparatorEq, ComparatorApprox, ComparatorGte, ComparatorLte, ComparatorRange, ComparatorRateOfChange}
+
+var (
+	basicStatistics = []string{"SampleCount", "Average", "Sum", "Minimum", "Maximum"}
+	// percentileRegex matches the percentile statistics (e.g p99, p99.9)
+	percentileRegex = regexp.MustCompile(`^p\d{1,2}(\.\d+)?$`)
+	// expressionIDRegex is what GetMetricData accepts as the id of a query
+	expressionIDRegex = regexp.MustCompile(`^[a-z][a-zA-Z0-9_]*$`)
+	// highResolutionPeriods are the periods below a minute GetMetricData accepts
+	highResolutionPeriods = []int{1, 5, 10, 30}
+)
+
+// HasExpectation is true when the validation declares its expectation rather than relying on the check of the
+// first value against metric_value.
+func (m MetricValidation) HasExpectation() bool {
+	return m.Statistic != "" || m.Period != 0 || m.Comparator != "" || m.Tolerance != 0 || m.MinValue != nil ||
+		m.MaxValue != nil || len(m.RequiredDimensions) > 0 || m.Absent || m.Expression != ""
+}
+
+// GetTolerance returns the relative bound of the approx and rate-of-change comparators.
+func (m MetricValidation) GetTolerance() float64 {
+	if m.Tolerance == 0 {
+		return DefaultTolerance
+	}
+	return m.Tolerance
+}
+
+// GetStatistic returns the statistic queried.
+func (m MetricValidation) GetStatistic() string {
+	if m.Statistic == "" {
+		return "Average"
+	}
+	return m.Statistic
+}
+
+// LocalBackendProblems returns why the local backend (see util/localbackend) cannot serve the validation, which
+// only answers GetMetricData with the basic statistics and without metric math.
+func (m MetricValidation) LocalBackendProblems() []string {
+	var problems []string
+	if m.Expression != "" {
+		problems = append(problems, fmt.Sprintf("expression %q is not supported by the local backend", m.Expression))
+	}
+	if percentileRegex.MatchString(m.Statistic) {
+		problems = append(problems, fmt.Sprintf("statistic %s is not supported by the local backend, use one of %v", m.Statistic, basicStatistics))
+	}
+	for i, metric := range m.ExpressionMetrics {
+		if percentileRegex.MatchString(metric.Statistic) {
+			problems = append(problems, fmt.Sprintf("expression_metrics[%d] statistic %s is not supported by the local backend", i, metric.Statistic))
+		}
+	}
+	return problems
+}
+
+func validStatistic(statistic string) bool {
+	return statistic == "" || slices.Contains(basicStatistics, statistic) || percentileRegex.MatchString(statistic)
+}
+
+func validateMetricValidation(name string, m MetricValidation, addError func(format string, args ...interface{})) {
+	if m.MetricName == "" && m.Expression == "" {
+		addError("%s needs a metric_name or an expression", name)
+	}
+	if m.MetricName != "" {
+		name += " (" + m.MetricName + ")"
+	}
+	for _, dimension := range m.MetricDimension {
+		if dimension.Name == "" {
+			addError("%s has a dimension without a name", name)
+		}
+	}
+	if !validStatistic(m.Statistic) {
+		addError("%s statistic must be one of %v or a percentile (e.g p99), got %q", name, basicStatistics, m.Statistic)
+	}
+	if m.Period < 0 || (m.Period > 0 && m.Period%60 != 0 && !slices.Contains(highResolutionPeriods, m.Period)) {
+		addError("%s period must be one of %v or a multiple of 60, got %d", name, highResolutionPeriods, m.Period)
+	}
+
+	if m.Comparator != "" && !slices.Contains(Comparators, m.Comparator) {
+		addError("%s comparator must be one of %v, got %q", name, Comparators, m.Comparator)
+	}
+	if m.Tolerance < 0 {
+		addError("%s tolerance must not be negative", name)
+	}
+	if m.Tolerance != 0 && m.Comparator != ComparatorApprox && m.Comparator != ComparatorRateOfChange {
+		addError("%s tolerance only applies to the %s and %s comparators", name, ComparatorApprox, ComparatorRateOfChange)
+	}
+	if m.Comparator == ComparatorRange {
+		if m.MinValue == nil || m.MaxValue == nil {
+			addError("%s comparator range needs min_value and max_value", name)
+		} else if *m.MinValue > *m.MaxValue {
+			addError("%s min_value %g is above max_value %g", name, *m.MinValue, *m.MaxValue)
+		}
+	} else if m.MinValue != nil || m.MaxValue != nil {
+		addError("%s min_value and max_value only apply to the range comparator", name)
+	}
+
+	if m.Absent && (m.Comparator != "" || m.MetricSampleCount != 0 || len(m.RequiredDimensions) > 0 || m.Expression != "") {
+		addError("%s absent cannot be combined with comparator, metric_sample_count, required_dimensions or expression", name)
+	}
+
+	if m.Expression == "" {
+		if len(m.ExpressionMetrics) > 0 {
+			addError("%s expression_metrics requires an expression", name)
+		}
+		return
+	}
+	if len(m.RequiredDimensions) > 0 || m.MetricSampleCount != 0 {
+		addError("%s required_dimensions and metric_sample_count do not apply to an expression", name)
+	}
+	ids := map[string]struct{}{}
+	for i, metric := range m.ExpressionMetrics {
+		if !expressionIDRegex.MatchString(metric.ID) {
+			addError("%s expression_metrics[%d] id must start with a lowercase letter and only have letters, digits and _, got %q", name, i, metric.ID)
+		}
+		if _, ok := ids[metric.ID]; ok {
+			addError("%s expression_metrics[%d] id %q is not unique", name, i, metric.ID)
+		} else if metric.ID == ResultQueryID {
+			addError("%s expression_metrics[%d] id %q is reserved", name, i, metric.ID)
+		}
+		ids[metric.ID] = struct{}{}
+		if metric.MetricName == "" {
+			addError("%s expression_metrics[%d] needs a metric_name", name, i)
+		}
+		if !validStatistic(metric.Statistic) {
+			addError("%s expression_metrics[%d] statistic must be one of %v or a percentile (e.g p99), got %q", name, i, basicStatistics, metric.Statistic)
+		}
+	}
+}
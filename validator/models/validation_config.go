@@ -4,20 +4,33 @@
 package models // import "github.com/aws/amazon-cloudwatch-agent-test/validator/models"
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"runtime"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/exp/slices"
 	"gopkg.in/yaml.v3"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common/emf"
 )
 
 var supportedReceivers = []string{"logs", "statsd", "collectd", "system", "emf", "xray", "app_signals", "prometheus", "traces"}
+var supportedDataTypes = []string{"metrics", "logs", "traces"}
 var retryCount = 0
 
+const (
+	defaultNumberMonitoredLogs = 1
+	defaultScrapeInterval      = 60
+)
+
 type ValidateConfig interface {
 	GetPluginsConfig() []string
 	GetValidateType() string
@@ -34,6 +47,7 @@ type ValidateConfig interface {
 	GetCommitInformation() (string, int64)
 	GetUniqueID() string
 	GetOSFamily() string
+	GetEMFMode() string
 }
 
 type validatorConfig struct {
@@ -42,13 +56,14 @@ type validatorConfig struct {
 	TestCase string `yaml:"test_case"` // Test case name
 
 	// Validate type for the test https://github.com/aws/amazon-cloudwatch-agent-test/blob/39a9e16c70f07a17c43c0630647158cd496bd168/validator/validators/validator.go#L15-L24
-	ValidateType          string `yaml:"validate_type"`
-	DataType              string `yaml:"data_type"`               // Only supports metrics/logs/traces
-	NumberMonitoredLogs   int    `yaml:"number_monitored_logs"`   // Number of logs to be monitored
-	ValuesPerMinute       string `yaml:"values_per_minute"`       // Number of metrics to be sent or number of log lines to write
-	ScrapeInterval        string `yaml:"scrape_interval"`         // Prometheus Scraping interval
-	AgentCollectionPeriod int    `yaml:"agent_collection_period"` // Number of seconds the agent should run and collect the metrics
-	OSFamily              string `yaml:"os_family"`               // OS Family for the validator test
+	ValidateType          string  `yaml:"validate_type"`
+	DataType              string  `yaml:"data_type"`               // Only supports metrics/logs/traces
+	NumberMonitoredLogs   int     `yaml:"number_monitored_logs"`   // Number of logs to be monitored
+	ValuesPerMinute       Integer `yaml:"values_per_minute"`       // Number of metrics to be sent or number of log lines to write
+	ScrapeInterval        Integer `yaml:"scrape_interval"`         // Prometheus Scraping interval
+	AgentCollectionPeriod int     `yaml:"agent_collection_period"` // Number of seconds the agent should run and collect the metrics
+	OSFamily              string  `yaml:"os_family"`               // OS Family for the validator test
+	EMFMode               string  `yaml:"emf_mode"`                // Shape of the emf events (e.g high-cardinality), basic when empty
 
 	ConfigPath string `yaml:"cloudwatch_agent_config"`
 
@@ -56,16 +71,53 @@ type validatorConfig struct {
 	MetricValidation []MetricValidation `yaml:"metric_validation"`
 	LogValidation    []LogValidation    `yaml:"log_validation"`
 
-	CommitHash string `yaml:"commit_hash"`
-	CommitDate string `yaml:"commit_date"`
+	CommitHash string  `yaml:"commit_hash"`
+	CommitDate Integer `yaml:"commit_date"`
 	retryCount int
 }
 
+// Integer is an integer parameter that may be quoted, since the terraform templates substitute
+// placeholders such as "<values_per_minute>" inside quotes. Anything that is not an integer, including a
+// placeholder that was never substituted, fails the decoding instead of silently becoming 0.
+type Integer int64
+
+func (i *Integer) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: expected an integer", node.Line)
+	}
+	value, err := strconv.ParseInt(strings.TrimSpace(node.Value), 10, 64)
+	if err != nil {
+		return fmt.Errorf("line %d: %q is not an integer", node.Line, node.Value)
+	}
+	*i = Integer(value)
+	return nil
+}
+
 type MetricValidation struct {
 	MetricName        string            `yaml:"metric_name"`
 	MetricDimension   []MetricDimension `yaml:"metric_dimension"`
 	MetricValue       float64           `yaml:"metric_value"`
 	MetricSampleCount int               `yaml:"metric_sample_count"`
+
+	// Without any of the fields below, only the first value must be within 10% of metric_value. See HasExpectation.
+	Statistic          string             `yaml:"statistic"`           // Statistic queried (e.g Sum, p99), Average when empty
+	Period             int                `yaml:"period"`              // Period of the query in seconds, agent_collection_period when zero
+	Comparator         Comparator         `yaml:"comparator"`          // How every value compares with metric_value
+	Tolerance          float64            `yaml:"tolerance"`           // Relative bound of approx and rate-of-change, 0.1 when zero
+	MinValue           *float64           `yaml:"min_value"`           // Lower bound of the range comparator
+	MaxValue           *float64           `yaml:"max_value"`           // Upper bound of the range comparator
+	RequiredDimensions []string           `yaml:"required_dimensions"` // Dimensions every published series of the metric must have
+	Absent             bool               `yaml:"absent"`              // The metric must not be published at all
+	Expression         string             `yaml:"expression"`          // Metric math evaluated instead of the metric (e.g "m1 / m2")
+	ExpressionMetrics  []ExpressionMetric `yaml:"expression_metrics"`  // Metrics the expression refers to by id
+}
+
+// ExpressionMetric is a metric a metric math expression refers to by its id.
+type ExpressionMetric struct {
+	ID              string            `yaml:"id"`
+	MetricName      string            `yaml:"metric_name"`
+	MetricDimension []MetricDimension `yaml:"metric_dimension"`
+	Statistic       string            `yaml:"statistic"`
 }
 
 type LogValidation struct {
@@ -85,14 +137,15 @@ type MetricDimension struct {
 
 var _ ValidateConfig = (*validatorConfig)(nil)
 
+// NewValidateConfig reads the parameters.yml at configPath. Unknown keys and mistyped values are errors,
+// defaults are filled in and the parameters are validated together with ValidateValidatorConfig.
 func NewValidateConfig(configPath string) (*validatorConfig, error) {
 	configPathBytes, err := os.ReadFile(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("%v with file %s", err, configPath)
 	}
 
-	vConfig := validatorConfig{}
-	err = yaml.Unmarshal(configPathBytes, &vConfig)
+	vConfig, err := decodeValidateConfig(configPathBytes)
 	if err != nil {
 		return nil, err
 	}
@@ -104,13 +157,115 @@ func NewValidateConfig(configPath string) (*validatorConfig, error) {
 	return &vConfig, nil
 }
 
+func decodeValidateConfig(content []byte) (validatorConfig, error) {
+	vConfig := validatorConfig{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&vConfig); err != nil {
+		if err == io.EOF {
+			return vConfig, errors.New("the file is empty")
+		}
+		return vConfig, err
+	}
+	vConfig.applyDefaults()
+	return vConfig, nil
+}
+
+func (v *validatorConfig) applyDefaults() {
+	if (v.DataType == "logs" || slices.Contains(v.Receivers, "logs")) && v.NumberMonitoredLogs == 0 {
+		v.NumberMonitoredLogs = defaultNumberMonitoredLogs
+	}
+	if slices.Contains(v.Receivers, "prometheus") && v.ScrapeInterval == 0 {
+		v.ScrapeInterval = defaultScrapeInterval
+	}
+	if v.OSFamily == "" {
+		v.OSFamily = runtime.GOOS
+	}
+}
+
+// ValidateValidatorConfig returns every problem of the parameters, including the ones that depend on
+// more than one field, so a typo fails before the validator starts sending load.
 func ValidateValidatorConfig(vConfig validatorConfig) error {
+	var errs []error
+	addError := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
 	for _, receiver := range vConfig.Receivers {
 		if !slices.Contains(supportedReceivers, receiver) {
-			return fmt.Errorf("only support %v, the validator does not support %s", supportedReceivers, receiver)
+			addError("only support %v, the validator does not support %s", supportedReceivers, receiver)
 		}
 	}
-	return nil
+	if vConfig.TestCase == "" {
+		addError("test_case is required")
+	}
+	if vConfig.ValidateType == "" {
+		addError("validate_type is required")
+	}
+	if !slices.Contains(supportedDataTypes, vConfig.DataType) {
+		addError("data_type must be one of %v, got %q", supportedDataTypes, vConfig.DataType)
+	}
+	if vConfig.AgentCollectionPeriod <= 0 {
+		addError("agent_collection_period must be a positive number of seconds, got %d", vConfig.AgentCollectionPeriod)
+	}
+	if vConfig.ValuesPerMinute < 0 || vConfig.ScrapeInterval < 0 || vConfig.NumberMonitoredLogs < 0 {
+		addError("values_per_minute, scrape_interval and number_monitored_logs must not be negative")
+	}
+
+	switch vConfig.ValidateType {
+	case "performance":
+		// The receivers of a mixed workload form the key its results are stored under.
+		seen := map[string]struct{}{}
+		for _, receiver := range vConfig.Receivers {
+			if _, ok := seen[receiver]; ok {
+				addError("validate_type performance lists receiver %s more than once", receiver)
+			}
+			seen[receiver] = struct{}{}
+		}
+		if len(vConfig.Receivers) == 0 {
+			addError("validate_type performance needs at least one receiver")
+		}
+		fallthrough
+	case "stress":
+		if vConfig.ValuesPerMinute <= 0 {
+			addError("validate_type %s needs a positive values_per_minute", vConfig.ValidateType)
+		}
+	}
+
+	if vConfig.EMFMode != "" {
+		if !slices.Contains(emf.Modes, emf.Mode(vConfig.EMFMode)) {
+			addError("emf_mode must be one of %v, got %q", emf.Modes, vConfig.EMFMode)
+		}
+		if !slices.Contains(vConfig.Receivers, "emf") {
+			addError("emf_mode requires the emf receiver")
+		}
+	}
+
+	if len(vConfig.LogValidation) > 0 && vConfig.DataType != "logs" {
+		addError("log_validation requires data_type logs, got %q", vConfig.DataType)
+	}
+	if len(vConfig.MetricValidation) > 0 && vConfig.MetricNamespace == "" {
+		addError("metric_validation requires metric_namespace")
+	}
+	for i, metric := range vConfig.MetricValidation {
+		validateMetricValidation(fmt.Sprintf("metric_validation[%d]", i), metric, addError)
+	}
+	for i, logValidation := range vConfig.LogValidation {
+		if logValidation.LogValue == "" {
+			addError("log_validation[%d] needs a log_value", i)
+		}
+	}
+
+	for name, value := range map[string]string{
+		"cloudwatch_agent_config": vConfig.ConfigPath,
+		"commit_hash":             vConfig.CommitHash,
+		"os_family":               vConfig.OSFamily,
+	} {
+		if strings.HasPrefix(value, "<") && strings.HasSuffix(value, ">") {
+			addError("%s is still the template placeholder %s", name, value)
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // GetTestCase return the test case name
@@ -135,17 +290,12 @@ func (v *validatorConfig) GetDataType() string {
 
 // GetDataRate returns number of metrics to be sent or number of log lines to write
 func (v *validatorConfig) GetDataRate() int {
-	if dataRate, err := strconv.Atoi(v.ValuesPerMinute); err == nil {
-		return dataRate
-	}
-	return 0
+	return int(v.ValuesPerMinute)
 }
 
+// GetScrapeInterval returns the prometheus scrape interval in seconds
 func (v *validatorConfig) GetScrapeInterval() int {
-	if scrapeInterval, err := strconv.Atoi(v.ScrapeInterval); err == nil {
-		return scrapeInterval
-	}
-	return 0
+	return int(v.ScrapeInterval)
 }
 
 // GetNumberMonitoredLogs returns number of log to be monitored by cloudwatchagent so the validator configuration will setup the agent config dynamically
@@ -179,8 +329,7 @@ func (v *validatorConfig) GetLogValidation() []LogValidation {
 }
 
 func (v *validatorConfig) GetCommitInformation() (string, int64) {
-	commitDate, _ := strconv.ParseInt(v.CommitDate, 10, 64)
-	return v.CommitHash, commitDate
+	return v.CommitHash, int64(v.CommitDate)
 }
 
 func (v *validatorConfig) GetUniqueID() string {
@@ -190,3 +339,8 @@ func (v *validatorConfig) GetUniqueID() string {
 func (v *validatorConfig) GetOSFamily() string {
 	return v.OSFamily
 }
+
+// GetEMFMode returns the shape of the emf events sent (e.g dimension-explosion), empty for the basic events
+func (v *validatorConfig) GetEMFMode() string {
+	return v.EMFMode
+}
@@ -0,0 +1,225 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validConfig = `
+receivers: ["statsd"]
+test_case: "statsd_performance"
+validate_type: "performance"
+data_type: "metrics"
+values_per_minute: "1000"
+agent_collection_period: 300
+cloudwatch_agent_config: "/tmp/agent_config.json"
+commit_hash: "abc123"
+commit_date: 1690000000
+metric_namespace: "CWAgent/Performance"
+metric_validation:
+  - metric_name: "procstat_cpu_usage"
+    metric_dimension:
+      - name: "exe"
+        value: "cloudwatch-agent"
+`
+
+func TestDecodeValidateConfig(t *testing.T) {
+	vConfig, err := decodeValidateConfig([]byte(validConfig))
+	require.NoError(t, err)
+	require.NoError(t, ValidateValidatorConfig(vConfig))
+	assert.Equal(t, 1000, vConfig.GetDataRate())
+	hash, date := vConfig.GetCommitInformation()
+	assert.Equal(t, "abc123", hash)
+	assert.Equal(t, int64(1690000000), date)
+	assert.NotEmpty(t, vConfig.GetOSFamily())
+}
+
+func TestDecodeValidateConfigIsStrict(t *testing.T) {
+	_, err := decodeValidateConfig([]byte(validConfig + "metrics_config: \"x\"\n"))
+	assert.ErrorContains(t, err, "field metrics_config not found")
+
+	_, err = decodeValidateConfig([]byte("values_per_minute: \"<values_per_minute>\"\n"))
+	assert.ErrorContains(t, err, `"<values_per_minute>" is not an integer`)
+
+	_, err = decodeValidateConfig([]byte("scrape_interval: [60]\n"))
+	assert.ErrorContains(t, err, "expected an integer")
+
+	_, err = decodeValidateConfig(nil)
+	assert.ErrorContains(t, err, "empty")
+}
+
+func TestDecodeValidateConfigDefaults(t *testing.T) {
+	vConfig, err := decodeValidateConfig([]byte(`
+receivers: ["prometheus"]
+data_type: "logs"
+`))
+	require.NoError(t, err)
+	assert.Equal(t, defaultScrapeInterval, vConfig.GetScrapeInterval())
+	assert.Equal(t, defaultNumberMonitoredLogs, vConfig.GetNumberMonitoredLogs())
+}
+
+func TestDecodeMetricExpectations(t *testing.T) {
+	vConfig, err := decodeValidateConfig([]byte(validConfig + `
+  - metric_name: "mem_used_percent"
+    statistic: "Maximum"
+    period: 60
+    comparator: "range"
+    min_value: 0
+    max_value: 100
+    required_dimensions: ["InstanceId", "host"]
+  - metric_name: "statsd_timing_1"
+    absent: true
+  - expression: "100 * m1 / m2"
+    comparator: "approx"
+    metric_value: 50
+    tolerance: 0.2
+    expression_metrics:
+      - id: "m1"
+        metric_name: "mem_used"
+      - id: "m2"
+        metric_name: "mem_total"
+        statistic: "Sum"
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateValidatorConfig(vConfig))
+	metrics := vConfig.GetMetricValidation()
+	require.Len(t, metrics, 4)
+	assert.False(t, metrics[0].HasExpectation())
+	assert.Equal(t, "Average", metrics[0].GetStatistic())
+	assert.Equal(t, DefaultTolerance, metrics[0].GetTolerance())
+
+	assert.True(t, metrics[1].HasExpectation())
+	assert.Equal(t, ComparatorRange, metrics[1].Comparator)
+	require.NotNil(t, metrics[1].MinValue)
+	assert.Equal(t, 0.0, *metrics[1].MinValue)
+	assert.Equal(t, []string{"InstanceId", "host"}, metrics[1].RequiredDimensions)
+
+	assert.True(t, metrics[2].HasExpectation())
+	assert.True(t, metrics[2].Absent)
+
+	assert.True(t, metrics[3].HasExpectation())
+	assert.Equal(t, 0.2, metrics[3].GetTolerance())
+	assert.Equal(t, []ExpressionMetric{{ID: "m1", MetricName: "mem_used"}, {ID: "m2", MetricName: "mem_total", Statistic: "Sum"}}, metrics[3].ExpressionMetrics)
+}
+
+func TestLocalBackendProblems(t *testing.T) {
+	assert.Empty(t, MetricValidation{MetricName: "mem_used_percent", Statistic: "Maximum"}.LocalBackendProblems())
+	assert.Equal(t, []string{"statistic p99 is not supported by the local backend, use one of [SampleCount Average Sum Minimum Maximum]"},
+		MetricValidation{MetricName: "mem_used_percent", Statistic: "p99"}.LocalBackendProblems())
+	assert.Equal(t, []string{`expression "m1 / m2" is not supported by the local backend`, "expression_metrics[1] statistic p90 is not supported by the local backend"},
+		MetricValidation{Expression: "m1 / m2", ExpressionMetrics: []ExpressionMetric{{ID: "m1", MetricName: "mem_used"}, {ID: "m2", MetricName: "mem_total", Statistic: "p90"}}}.LocalBackendProblems())
+}
+
+func TestValidateValidatorConfigCrossFields(t *testing.T) {
+	testCases := map[string]struct {
+		change   func(v *validatorConfig)
+		expected string
+	}{
+		"UnsupportedReceiver": {
+			change:   func(v *validatorConfig) { v.Receivers = []string{"statsd", "nginx"} },
+			expected: "does not support nginx",
+		},
+		"PerformanceWithDuplicateReceivers": {
+			change:   func(v *validatorConfig) { v.Receivers = []string{"statsd", "emf", "statsd"} },
+			expected: "lists receiver statsd more than once",
+		},
+		"LogValidationWithoutLogs": {
+			change:   func(v *validatorConfig) { v.LogValidation = []LogValidation{{LogValue: "line"}} },
+			expected: "log_validation requires data_type logs",
+		},
+		"MissingNamespace": {
+			change:   func(v *validatorConfig) { v.MetricNamespace = "" },
+			expected: "metric_validation requires metric_namespace",
+		},
+		"StressWithoutLoad": {
+			change: func(v *validatorConfig) {
+				v.ValidateType = "stress"
+				v.ValuesPerMinute = 0
+			},
+			expected: "validate_type stress needs a positive values_per_minute",
+		},
+		"Placeholder": {
+			change:   func(v *validatorConfig) { v.ConfigPath = "<cloudwatch_agent_config>" },
+			expected: "cloudwatch_agent_config is still the template placeholder",
+		},
+		"UnknownDataType": {
+			change:   func(v *validatorConfig) { v.DataType = "metric" },
+			expected: `data_type must be one of [metrics logs traces], got "metric"`,
+		},
+		"UnknownEMFMode": {
+			change:   func(v *validatorConfig) { v.EMFMode = "huge" },
+			expected: `emf_mode must be one of [basic high-cardinality dimension-explosion mixed-units high-resolution value-arrays oversized], got "huge"`,
+		},
+		"EMFModeWithoutEMF": {
+			change:   func(v *validatorConfig) { v.EMFMode = "oversized" },
+			expected: "emf_mode requires the emf receiver",
+		},
+		"UnknownComparator": {
+			change:   func(v *validatorConfig) { v.MetricValidation[0].Comparator = "gt" },
+			expected: `metric_validation[0] (procstat_cpu_usage) comparator must be one of [eq approx gte lte range rate-of-change], got "gt"`,
+		},
+		"RangeWithoutBounds": {
+			change:   func(v *validatorConfig) { v.MetricValidation[0].Comparator = ComparatorRange },
+			expected: "comparator range needs min_value and max_value",
+		},
+		"ToleranceWithoutApprox": {
+			change: func(v *validatorConfig) {
+				v.MetricValidation[0].Comparator = ComparatorGte
+				v.MetricValidation[0].Tolerance = 0.2
+			},
+			expected: "tolerance only applies to the approx and rate-of-change comparators",
+		},
+		"UnknownStatistic": {
+			change:   func(v *validatorConfig) { v.MetricValidation[0].Statistic = "Median" },
+			expected: `statistic must be one of [SampleCount Average Sum Minimum Maximum] or a percentile (e.g p99), got "Median"`,
+		},
+		"InvalidPeriod": {
+			change:   func(v *validatorConfig) { v.MetricValidation[0].Period = 90 },
+			expected: "period must be one of [1 5 10 30] or a multiple of 60, got 90",
+		},
+		"AbsentWithComparator": {
+			change: func(v *validatorConfig) {
+				v.MetricValidation[0].Absent = true
+				v.MetricValidation[0].Comparator = ComparatorEq
+			},
+			expected: "absent cannot be combined with comparator",
+		},
+		"ExpressionMetricsWithoutExpression": {
+			change: func(v *validatorConfig) {
+				v.MetricValidation[0].ExpressionMetrics = []ExpressionMetric{{ID: "m1", MetricName: "mem_used"}}
+			},
+			expected: "expression_metrics requires an expression",
+		},
+		"InvalidExpressionMetricID": {
+			change: func(v *validatorConfig) {
+				v.MetricValidation[0].Expression = "M1 / m2"
+				v.MetricValidation[0].ExpressionMetrics = []ExpressionMetric{{ID: "M1", MetricName: "mem_used"}, {ID: "result", MetricName: "mem_total"}}
+			},
+			expected: `expression_metrics[0] id must start with a lowercase letter and only have letters, digits and _, got "M1"`,
+		},
+		"ReservedExpressionMetricID": {
+			change: func(v *validatorConfig) {
+				v.MetricValidation[0].Expression = "m1 / result"
+				v.MetricValidation[0].ExpressionMetrics = []ExpressionMetric{{ID: "m1", MetricName: "mem_used"}, {ID: "result", MetricName: "mem_total"}}
+			},
+			expected: `expression_metrics[1] id "result" is reserved`,
+		},
+		"MissingMetricName": {
+			change:   func(v *validatorConfig) { v.MetricValidation[0].MetricName = "" },
+			expected: "metric_validation[0] needs a metric_name or an expression",
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			vConfig, err := decodeValidateConfig([]byte(validConfig))
+			require.NoError(t, err)
+			testCase.change(&vConfig)
+			assert.ErrorContains(t, ValidateValidatorConfig(vConfig), testCase.expected)
+		})
+	}
+}
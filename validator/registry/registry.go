@@ -0,0 +1,245 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package registry
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/models"
+)
+
+// maxSuggestions caps how many similar names are offered when a lookup fails.
+const maxSuggestions = 3
+
+// Parameter describes a key of parameters.yml that a validator reads.
+type Parameter struct {
+	Name        string
+	Description string
+	Required    bool
+}
+
+// Validator is a validate_type (e.g stress) that parameters.yml driven tests run through
+// validators.LaunchValidator.
+type Validator struct {
+	Name        string
+	Description string
+	Parameters  []Parameter
+	New         func(vConfig models.ValidateConfig, clk clock.Clock) models.ValidatorFactory
+}
+
+// Test is a named test the validator runs directly with --test-name, without a parameters.yml.
+type Test struct {
+	Name        string
+	Description string
+	Validate    func() error
+}
+
+var (
+	mu         sync.RWMutex
+	validators = map[string]Validator{}
+	tests      = map[string]Test{}
+)
+
+// RegisterValidator makes a validate_type available. It is meant to be called from the init function of
+// the package implementing the validator and panics on duplicate names.
+func RegisterValidator(v Validator) {
+	mu.Lock()
+	defer mu.Unlock()
+	if v.Name == "" || v.New == nil {
+		panic("registry: validator needs a name and a constructor")
+	}
+	if _, ok := validators[v.Name]; ok {
+		panic(fmt.Sprintf("registry: validator %s is registered twice", v.Name))
+	}
+	validators[v.Name] = v
+}
+
+// RegisterTest makes a named test available. It is meant to be called from the init function of the test
+// package and panics on duplicate names.
+func RegisterTest(t Test) {
+	mu.Lock()
+	defer mu.Unlock()
+	if t.Name == "" || t.Validate == nil {
+		panic("registry: test needs a name and a validate function")
+	}
+	if _, ok := tests[t.Name]; ok {
+		panic(fmt.Sprintf("registry: test %s is registered twice", t.Name))
+	}
+	tests[t.Name] = t
+}
+
+// LookupValidator returns the validator registered under name, or an error suggesting similar names.
+func LookupValidator(name string) (Validator, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if v, ok := validators[name]; ok {
+		return v, nil
+	}
+	return Validator{}, unknownError("validate type", name, keys(validators))
+}
+
+// LookupTest returns the test registered under name, or an error suggesting similar names.
+func LookupTest(name string) (Test, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if t, ok := tests[name]; ok {
+		return t, nil
+	}
+	return Test{}, unknownError("test", name, keys(tests))
+}
+
+// Validators returns all registered validators sorted by name.
+func Validators() []Validator {
+	mu.RLock()
+	defer mu.RUnlock()
+	result := make([]Validator, 0, len(validators))
+	for _, name := range keys(validators) {
+		result = append(result, validators[name])
+	}
+	return result
+}
+
+// Tests returns all registered tests sorted by name.
+func Tests() []Test {
+	mu.RLock()
+	defer mu.RUnlock()
+	result := make([]Test, 0, len(tests))
+	for _, name := range keys(tests) {
+		result = append(result, tests[name])
+	}
+	return result
+}
+
+// CheckParameters returns an error listing the required parameters of the validator that are missing
+// from the parameters.yml at configPath.
+func CheckParameters(v Validator, configPath string) error {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return err
+	}
+	var missing []string
+	for _, p := range v.Parameters {
+		if _, ok := document[p.Name]; p.Required && !ok {
+			missing = append(missing, p.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("validate type %s requires %s in %s", v.Name, strings.Join(missing, ", "), configPath)
+	}
+	return nil
+}
+
+// List writes a human readable summary of everything registered, used by --list.
+func List(w io.Writer) {
+	fmt.Fprintln(w, "Validate types (--validator-config with validate_type):")
+	for _, v := range Validators() {
+		fmt.Fprintf(w, "  %-20s %s\n", v.Name, v.Description)
+		for _, p := range v.Parameters {
+			required := ""
+			if p.Required {
+				required = " (required)"
+			}
+			fmt.Fprintf(w, "      %-26s %s%s\n", p.Name, p.Description, required)
+		}
+	}
+	fmt.Fprintln(w, "Tests (--test-name):")
+	for _, t := range Tests() {
+		fmt.Fprintf(w, "  %-20s %s\n", t.Name, t.Description)
+	}
+}
+
+func unknownError(kind, name string, candidates []string) error {
+	suggestions := Suggest(name, candidates)
+	if len(suggestions) == 0 {
+		return fmt.Errorf("unknown %s %q, available: %s", kind, name, strings.Join(candidates, ", "))
+	}
+	return fmt.Errorf("unknown %s %q, did you mean %s?", kind, name, strings.Join(suggestions, " or "))
+}
+
+// Suggest returns up to maxSuggestions candidates that are close to name, closest first. A candidate is
+// close if it contains name (or the other way around) or is within a small edit distance.
+func Suggest(name string, candidates []string) []string {
+	type scored struct {
+		candidate string
+		distance  int
+	}
+	var matches []scored
+	lowerName := strings.ToLower(name)
+	for _, candidate := range candidates {
+		lowerCandidate := strings.ToLower(candidate)
+		distance := levenshtein(lowerName, lowerCandidate)
+		if lowerName != "" && (strings.Contains(lowerCandidate, lowerName) || strings.Contains(lowerName, lowerCandidate)) {
+			distance = 0
+		}
+		if distance <= maxDistance(name) {
+			matches = append(matches, scored{candidate: candidate, distance: distance})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].distance < matches[j].distance
+	})
+	var suggestions []string
+	for i := 0; i < len(matches) && i < maxSuggestions; i++ {
+		suggestions = append(suggestions, matches[i].candidate)
+	}
+	return suggestions
+}
+
+func maxDistance(name string) int {
+	if d := len(name) / 3; d > 2 {
+		return d
+	}
+	return 2
+}
+
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func min(values ...int) int {
+	result := values[0]
+	for _, v := range values[1:] {
+		if v < result {
+			result = v
+		}
+	}
+	return result
+}
+
+func keys[T any](m map[string]T) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,59 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/models"
+)
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"feature", "performance", "stress", "windows_event_log", "journald"}
+
+	assert.Equal(t, []string{"stress"}, Suggest("stres", candidates))
+	assert.Equal(t, []string{"performance"}, Suggest("perf", candidates))
+	assert.Equal(t, []string{"windows_event_log"}, Suggest("windows_eventlog", candidates))
+	assert.Empty(t, Suggest("canary", candidates))
+}
+
+func TestLookup(t *testing.T) {
+	RegisterTest(Test{Name: "registry_lookup", Validate: func() error { return nil }})
+
+	test, err := LookupTest("registry_lookup")
+	require.NoError(t, err)
+	assert.NoError(t, test.Validate())
+
+	_, err = LookupTest("registry_lokup")
+	assert.EqualError(t, err, `unknown test "registry_lokup", did you mean registry_lookup?`)
+
+	assert.Panics(t, func() {
+		RegisterTest(Test{Name: "registry_lookup", Validate: func() error { return nil }})
+	})
+}
+
+func TestCheckParameters(t *testing.T) {
+	v := Validator{
+		Name: "check",
+		Parameters: []Parameter{
+			{Name: "receivers", Required: true},
+			{Name: "commit_hash", Required: true},
+			{Name: "metric_namespace"},
+		},
+		New: func(models.ValidateConfig, clock.Clock) models.ValidatorFactory { return nil },
+	}
+	path := filepath.Join(t.TempDir(), "parameters.yml")
+	require.NoError(t, os.WriteFile(path, []byte("receivers: [\"statsd\"]\n"), 0644))
+
+	assert.ErrorContains(t, CheckParameters(v, path), "validate type check requires commit_hash")
+
+	require.NoError(t, os.WriteFile(path, []byte("receivers: [\"statsd\"]\ncommit_hash: abc\n"), 0644))
+	assert.NoError(t, CheckParameters(v, path))
+}
@@ -0,0 +1,370 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package regression
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/resultstore"
+)
+
+const (
+	ModeOff  = "off"
+	ModeWarn = "warn"
+	ModeFail = "fail"
+
+	MethodBootstrap   = "bootstrap"
+	MethodMannWhitney = "mann-whitney"
+
+	// DefaultMannWhitneyRecent is the candidate size of the Mann-Whitney test, which cannot be significant
+	// at the default alpha with a single candidate value.
+	DefaultMannWhitneyRecent = 3
+)
+
+var (
+	// DefaultMetrics are the CPU, memory and file descriptor metrics of the agent on Linux and Windows. The
+	// Windows results are stored under the last word of the metric name (e.g cpu_usage for "procstat cpu_usage").
+	DefaultMetrics = []string{
+		"procstat_cpu_usage", "procstat_memory_rss", "procstat_memory_vms", "procstat_num_fds",
+		"cpu_usage", "memory_rss", "memory_vms",
+	}
+	DefaultStatistics = []string{"Average", "P99"}
+)
+
+// Config is how a performance result is compared with the results of the previous commits.
+type Config struct {
+	// Mode is off, warn to only log the regressions or fail to fail the validation on a regression
+	Mode string
+	// Method is the significance test, bootstrap or mann-whitney
+	Method string
+	// History is the number of previous commits the candidate is compared against
+	History int
+	// MinHistory is the number of previous commits needed before comparing at all
+	MinHistory int
+	// Recent is the number of latest commits, the current one included, forming the candidate. The
+	// Mann-Whitney test cannot be significant with a single candidate value at the default alpha.
+	Recent int
+	// MaxEffect is the relative increase of the mean (e.g 0.1 for 10%) tolerated even when significant
+	MaxEffect float64
+	// Alpha is the significance level of the test
+	Alpha               float64
+	Metrics             []string
+	Statistics          []string
+	BootstrapIterations int
+	Seed                int64
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Mode:                ModeWarn,
+		Method:              MethodBootstrap,
+		History:             10,
+		MinHistory:          3,
+		Recent:              1,
+		MaxEffect:           0.1,
+		Alpha:               0.05,
+		Metrics:             DefaultMetrics,
+		Statistics:          DefaultStatistics,
+		BootstrapIterations: 10000,
+		Seed:                1,
+	}
+}
+
+// DefaultConfigFor returns the default config of the method, which compares more recent commits with
+// the Mann-Whitney test.
+func DefaultConfigFor(method string) Config {
+	cfg := DefaultConfig()
+	cfg.Method = method
+	if method == MethodMannWhitney {
+		cfg.Recent = DefaultMannWhitneyRecent
+	}
+	return cfg
+}
+
+func (c Config) Validate() error {
+	var errs []error
+	switch c.Mode {
+	case ModeOff, ModeWarn, ModeFail:
+	default:
+		errs = append(errs, fmt.Errorf("mode must be %s, %s or %s, got %q", ModeOff, ModeWarn, ModeFail, c.Mode))
+	}
+	switch c.Method {
+	case MethodBootstrap, MethodMannWhitney:
+	default:
+		errs = append(errs, fmt.Errorf("method must be %s or %s, got %q", MethodBootstrap, MethodMannWhitney, c.Method))
+	}
+	if c.History < 1 || c.MinHistory < 1 || c.Recent < 1 {
+		errs = append(errs, fmt.Errorf("history, min history and recent must be at least 1"))
+	}
+	if c.MinHistory > c.History {
+		errs = append(errs, fmt.Errorf("min history %d is larger than the history %d", c.MinHistory, c.History))
+	}
+	if c.MaxEffect < 0 {
+		errs = append(errs, fmt.Errorf("max effect must not be negative, got %v", c.MaxEffect))
+	}
+	if c.Alpha <= 0 || c.Alpha >= 1 {
+		errs = append(errs, fmt.Errorf("alpha must be between 0 and 1, got %v", c.Alpha))
+	}
+	if c.Method == MethodMannWhitney && c.History >= 1 && c.Recent >= 1 {
+		if p := MannWhitneyMinPValue(c.History, c.Recent); p >= c.Alpha {
+			errs = append(errs, fmt.Errorf("%s with %d recent and %d previous commits cannot find a regression, its smallest p-value %.3f is not below alpha %v",
+				MethodMannWhitney, c.Recent, c.History, p, c.Alpha))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+var (
+	mu            sync.RWMutex
+	currentConfig = DefaultConfig()
+)
+
+// Configure sets the config the performance validator checks its results with.
+func Configure(cfg Config) {
+	mu.Lock()
+	defer mu.Unlock()
+	currentConfig = cfg
+}
+
+func CurrentConfig() Config {
+	mu.RLock()
+	defer mu.RUnlock()
+	return currentConfig
+}
+
+// Finding is the comparison of a statistic of a metric at a data rate.
+type Finding struct {
+	DataRate      string
+	Method        string
+	Metric        string
+	Statistic     string
+	BaselineMean  float64
+	CandidateMean float64
+	// Effect is the relative increase of the candidate mean over the baseline mean
+	Effect float64
+	// Significance is the p-value of the Mann-Whitney test or the lower bound of the bootstrap
+	// confidence interval of the difference of the means
+	Significance float64
+	Significant  bool
+	Regressed    bool
+}
+
+func (f Finding) String() string {
+	significance := fmt.Sprintf("p=%.3f", f.Significance)
+	if f.Method == MethodBootstrap {
+		significance = fmt.Sprintf("difference lower bound %.4g", f.Significance)
+	}
+	return fmt.Sprintf("%s %s at %s: %.4g -> %.4g (%+.1f%%, %s)", f.Metric, f.Statistic, f.DataRate,
+		f.BaselineMean, f.CandidateMean, f.Effect*100, significance)
+}
+
+// Report is the result of comparing a commit with its history.
+type Report struct {
+	UseCase    string
+	CommitHash string
+	Findings   []Finding
+	// Skipped explains the data rates that were not compared
+	Skipped []string
+}
+
+func (r Report) Regressions() []Finding {
+	var regressions []Finding
+	for _, finding := range r.Findings {
+		if finding.Regressed {
+			regressions = append(regressions, finding)
+		}
+	}
+	return regressions
+}
+
+// Analyze compares the results of the record with the results of the previous commits of the history
+// with the same use case, data rate and instance type.
+func Analyze(cfg Config, history []resultstore.Record, record resultstore.Record) (Report, error) {
+	record, err := resultstore.Normalize(record)
+	if err != nil {
+		return Report{}, err
+	}
+	report := Report{UseCase: fmt.Sprint(record["UseCase"]), CommitHash: fmt.Sprint(record["CommitHash"])}
+
+	var previous []resultstore.Record
+	for _, h := range history {
+		if h["UseCase"] == record["UseCase"] && h["InstanceType"] == record["InstanceType"] &&
+			h["CommitHash"] != record["CommitHash"] && number(h["CommitDate"]) <= number(record["CommitDate"]) {
+			previous = append(previous, h)
+		}
+	}
+	sort.SliceStable(previous, func(i, j int) bool { return number(previous[i]["CommitDate"]) < number(previous[j]["CommitDate"]) })
+
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	results, _ := record["Results"].(map[string]interface{})
+	dataRates := make([]string, 0, len(results))
+	for dataRate := range results {
+		dataRates = append(dataRates, dataRate)
+	}
+	sort.Strings(dataRates)
+
+	for _, dataRate := range dataRates {
+		var withDataRate []resultstore.Record
+		for _, p := range previous {
+			if _, ok := p.Result(dataRate); ok {
+				withDataRate = append(withDataRate, p)
+			}
+		}
+		// The latest previous commits join the current one as the candidate, the ones before are the baseline
+		recent := cfg.Recent - 1
+		if recent > len(withDataRate) {
+			recent = len(withDataRate)
+		}
+		candidates := append(append([]resultstore.Record{}, withDataRate[len(withDataRate)-recent:]...), record)
+		baselines := withDataRate[:len(withDataRate)-recent]
+		if len(baselines) > cfg.History {
+			baselines = baselines[len(baselines)-cfg.History:]
+		}
+		if len(baselines) < cfg.MinHistory {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("data rate %s has %d previous results, %d needed", dataRate, len(baselines), cfg.MinHistory))
+			continue
+		}
+
+		// The percentiles of the records computed with another method (e.g before util/summary) are not comparable
+		method := record.PercentileMethod()
+		percentileBaselines := sameMethod(baselines, method)
+		percentileCandidates := sameMethod(candidates, method)
+		if mixed := len(baselines) + len(candidates) - len(percentileBaselines) - len(percentileCandidates); mixed > 0 {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("data rate %s has %d previous results with percentiles not computed by the %s method, only their other statistics are compared",
+				dataRate, mixed, method))
+		}
+
+		for _, metric := range cfg.Metrics {
+			for _, statistic := range cfg.Statistics {
+				if _, ok := record.Statistic(dataRate, metric, statistic); !ok {
+					continue
+				}
+				baselineRecords, candidateRecords := baselines, candidates
+				if isPercentile(statistic) {
+					baselineRecords, candidateRecords = percentileBaselines, percentileCandidates
+				}
+				baseline := statisticValues(baselineRecords, dataRate, metric, statistic)
+				candidate := statisticValues(candidateRecords, dataRate, metric, statistic)
+				if len(baseline) < cfg.MinHistory {
+					continue
+				}
+				report.Findings = append(report.Findings, compare(cfg, rng, dataRate, metric, statistic, baseline, candidate))
+			}
+		}
+	}
+	return report, nil
+}
+
+func compare(cfg Config, rng *rand.Rand, dataRate, metric, statistic string, baseline, candidate []float64) Finding {
+	finding := Finding{
+		DataRate:      dataRate,
+		Method:        cfg.Method,
+		Metric:        metric,
+		Statistic:     statistic,
+		BaselineMean:  mean(baseline),
+		CandidateMean: mean(candidate),
+	}
+	switch {
+	case finding.BaselineMean != 0:
+		finding.Effect = (finding.CandidateMean - finding.BaselineMean) / finding.BaselineMean
+	case finding.CandidateMean > 0:
+		finding.Effect = math.Inf(1)
+	}
+
+	if cfg.Method == MethodMannWhitney {
+		finding.Significance = MannWhitneyGreater(baseline, candidate)
+		finding.Significant = finding.Significance < cfg.Alpha
+	} else {
+		finding.Significance = BootstrapDifferenceLowerBound(baseline, candidate, 1-cfg.Alpha, cfg.BootstrapIterations, rng)
+		finding.Significant = finding.Significance > 0
+	}
+	finding.Regressed = finding.Significant && finding.Effect > cfg.MaxEffect
+	return finding
+}
+
+func sameMethod(records []resultstore.Record, method string) []resultstore.Record {
+	var same []resultstore.Record
+	for _, r := range records {
+		if r.PercentileMethod() == method {
+			same = append(same, r)
+		}
+	}
+	return same
+}
+
+// isPercentile tells if the statistic is a percentile (e.g P99 or P999) rather than e.g Average or Max.
+func isPercentile(statistic string) bool {
+	return len(statistic) > 1 && statistic[0] == 'P' && statistic[1] >= '0' && statistic[1] <= '9'
+}
+
+func statisticValues(records []resultstore.Record, dataRate, metric, statistic string) []float64 {
+	var values []float64
+	for _, r := range records {
+		if value, ok := r.Statistic(dataRate, metric, statistic); ok {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// Check compares the record with the history of the store and logs the findings. In fail mode, it
+// returns an error when a metric regressed or the history cannot be read.
+func Check(cfg Config, store resultstore.Store, record resultstore.Record) error {
+	if cfg.Mode == ModeOff {
+		return nil
+	}
+	useCase := fmt.Sprint(record["UseCase"])
+	history, err := store.History(useCase)
+	if errors.Is(err, resultstore.ErrWriteOnly) {
+		log.Printf("Skip the regression check of %s since %s cannot read the history", useCase, store.Name())
+		return nil
+	}
+	if err != nil {
+		return failOrWarn(cfg, fmt.Errorf("reading the history of %s from %s: %w", useCase, store.Name(), err))
+	}
+
+	report, err := Analyze(cfg, history, record)
+	if err != nil {
+		return failOrWarn(cfg, err)
+	}
+	for _, skipped := range report.Skipped {
+		log.Printf("Skip the regression check of %s: %s", useCase, skipped)
+	}
+	for _, finding := range report.Findings {
+		log.Printf("Regression check of %s at commit %s: %s", useCase, report.CommitHash, finding)
+	}
+
+	regressions := report.Regressions()
+	if len(regressions) == 0 {
+		return nil
+	}
+	descriptions := make([]string, 0, len(regressions))
+	for _, regression := range regressions {
+		descriptions = append(descriptions, regression.String())
+	}
+	return failOrWarn(cfg, fmt.Errorf("performance of %s regressed at commit %s by more than %.0f%%:\n%s",
+		useCase, report.CommitHash, cfg.MaxEffect*100, strings.Join(descriptions, "\n")))
+}
+
+func failOrWarn(cfg Config, err error) error {
+	if cfg.Mode == ModeFail {
+		return err
+	}
+	log.Printf("Warning: %v", err)
+	return nil
+}
+
+func number(value interface{}) float64 {
+	if v, ok := value.(float64); ok {
+		return v
+	}
+	return 0
+}
@@ -0,0 +1,239 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package regression
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/resultstore"
+)
+
+type stats struct {
+	Average float64
+	P99     float64
+}
+
+func record(commit int, instanceType string, cpu float64) resultstore.Record {
+	return resultstore.Record{
+		"UseCase":      "statsd",
+		"CommitHash":   fmt.Sprintf("commit%d", commit),
+		"CommitDate":   int64(1700000000 + commit),
+		"InstanceType": instanceType,
+		"Results": map[string]interface{}{
+			"1000": map[string]stats{
+				"procstat_cpu_usage":  {Average: cpu, P99: cpu * 1.5},
+				"procstat_memory_rss": {Average: 150, P99: 160},
+			},
+		},
+	}
+}
+
+func history(cpus ...float64) []resultstore.Record {
+	var records []resultstore.Record
+	for i, cpu := range cpus {
+		r, _ := resultstore.Normalize(record(i, "c5.xlarge", cpu))
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestAnalyze(t *testing.T) {
+	previous := history(20, 21, 19, 20.5, 19.5, 20, 21, 20)
+	// Other instance types and later commits are not part of the history
+	other, _ := resultstore.Normalize(record(3, "m5.large", 90))
+	later, _ := resultstore.Normalize(record(100, "c5.xlarge", 90))
+	previous = append(previous, other, later)
+
+	testCases := map[string]struct {
+		method      string
+		cpu         float64
+		regressions []string
+	}{
+		"BootstrapStable":    {method: MethodBootstrap, cpu: 20.5},
+		"BootstrapRegressed": {method: MethodBootstrap, cpu: 26, regressions: []string{"procstat_cpu_usage Average", "procstat_cpu_usage P99"}},
+		// Significant but below the effect size
+		"BootstrapSmallEffect":   {method: MethodBootstrap, cpu: 21.5},
+		"MannWhitneyStable":      {method: MethodMannWhitney, cpu: 20},
+		"MannWhitneyRegressed":   {method: MethodMannWhitney, cpu: 26, regressions: []string{"procstat_cpu_usage Average", "procstat_cpu_usage P99"}},
+		"MannWhitneyImprovement": {method: MethodMannWhitney, cpu: 10},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Method = testCase.method
+			cfg.Alpha = 0.1
+			report, err := Analyze(cfg, previous, record(50, "c5.xlarge", testCase.cpu))
+			require.NoError(t, err)
+
+			assert.Equal(t, "commit50", report.CommitHash)
+			assert.Empty(t, report.Skipped)
+			// cpu and memory with Average and P99
+			require.Len(t, report.Findings, 4)
+			for _, finding := range report.Findings {
+				if finding.Metric == "procstat_memory_rss" {
+					assert.Zero(t, finding.Effect)
+				}
+			}
+			var regressions []string
+			for _, finding := range report.Regressions() {
+				regressions = append(regressions, finding.Metric+" "+finding.Statistic)
+			}
+			assert.Equal(t, testCase.regressions, regressions)
+		})
+	}
+}
+
+func TestAnalyzeRecentAndHistory(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Metrics, cfg.Statistics = []string{"procstat_cpu_usage"}, []string{"Average"}
+	cfg.History, cfg.Recent = 4, 3
+
+	report, err := Analyze(cfg, history(5, 5, 20, 21, 19, 20, 30, 31), record(50, "c5.xlarge", 32))
+	require.NoError(t, err)
+	require.Len(t, report.Findings, 1)
+	// The two latest previous commits join the candidate, the 4 commits before them are the baseline
+	assert.Equal(t, 20.0, report.Findings[0].BaselineMean)
+	assert.Equal(t, 31.0, report.Findings[0].CandidateMean)
+	assert.True(t, report.Findings[0].Regressed)
+
+	report, err = Analyze(cfg, history(20, 21), record(50, "c5.xlarge", 32))
+	require.NoError(t, err)
+	assert.Empty(t, report.Findings)
+	assert.Equal(t, []string{"data rate 1000 has 0 previous results, 3 needed"}, report.Skipped)
+}
+
+type writeOnlyStore struct{ resultstore.Store }
+
+func (writeOnlyStore) History(string) ([]resultstore.Record, error) {
+	return nil, resultstore.ErrWriteOnly
+}
+
+func (writeOnlyStore) Name() string { return "write only" }
+
+func TestCheck(t *testing.T) {
+	store := resultstore.NewFileStore(filepath.Join(t.TempDir(), "perf.json"))
+	for _, r := range history(20, 21, 19, 20.5, 19.5) {
+		require.NoError(t, store.Save(r))
+	}
+	regressed := record(50, "c5.xlarge", 30)
+	require.NoError(t, store.Save(regressed))
+
+	cfg := DefaultConfig()
+	cfg.Mode = ModeWarn
+	assert.NoError(t, Check(cfg, store, regressed))
+
+	cfg.Mode = ModeFail
+	err := Check(cfg, store, regressed)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "performance of statsd regressed at commit commit50 by more than 10%")
+	assert.Contains(t, err.Error(), "procstat_cpu_usage Average at 1000: 20 -> 30 (+50.0%")
+
+	assert.NoError(t, Check(cfg, store, record(51, "c5.xlarge", 20)))
+	assert.NoError(t, Check(cfg, writeOnlyStore{}, regressed))
+
+	cfg.Mode = ModeOff
+	assert.NoError(t, Check(cfg, store, regressed))
+}
+
+func TestAnalyzePercentileMethod(t *testing.T) {
+	// The history predates the PercentileMethod, so only the Average of the current record is compared
+	current := record(50, "c5.xlarge", 26)
+	current["PercentileMethod"] = "linear"
+	report, err := Analyze(DefaultConfig(), history(20, 21, 19, 20.5, 19.5, 20, 21, 20), current)
+	require.NoError(t, err)
+	var regressions []string
+	for _, finding := range report.Regressions() {
+		regressions = append(regressions, finding.Metric+" "+finding.Statistic)
+	}
+	assert.Equal(t, []string{"procstat_cpu_usage Average"}, regressions)
+	for _, finding := range report.Findings {
+		assert.Equal(t, "Average", finding.Statistic)
+	}
+	require.Len(t, report.Skipped, 1)
+	assert.Contains(t, report.Skipped[0], "8 previous results with percentiles not computed by the linear method")
+
+	// Once enough previous results share the method, their percentiles are compared again
+	previous := history(20, 21, 19, 20.5, 19.5)
+	for _, r := range previous[2:] {
+		r["PercentileMethod"] = "linear"
+	}
+	report, err = Analyze(DefaultConfig(), previous, current)
+	require.NoError(t, err)
+	regressions = nil
+	for _, finding := range report.Regressions() {
+		regressions = append(regressions, finding.Metric+" "+finding.Statistic)
+	}
+	assert.Equal(t, []string{"procstat_cpu_usage Average", "procstat_cpu_usage P99"}, regressions)
+}
+
+func TestCheckWindows(t *testing.T) {
+	// The Windows performance results are keyed by the last word of "procstat cpu_usage" and "procstat memory_rss"
+	windowsRecord := func(commit int, cpu float64) resultstore.Record {
+		return resultstore.Record{
+			"UseCase":      "logs",
+			"CommitHash":   fmt.Sprintf("commit%d", commit),
+			"CommitDate":   int64(1700000000 + commit),
+			"InstanceType": "c5.xlarge",
+			"Results": map[string]interface{}{
+				"1000": map[string]stats{
+					"cpu_usage":  {Average: cpu, P99: cpu * 1.5},
+					"memory_rss": {Average: 150, P99: 160},
+				},
+			},
+		}
+	}
+	store := resultstore.NewFileStore(filepath.Join(t.TempDir(), "perf.json"))
+	for i, cpu := range []float64{20, 21, 19, 20.5, 19.5} {
+		require.NoError(t, store.Save(windowsRecord(i, cpu)))
+	}
+	regressed := windowsRecord(50, 30)
+	require.NoError(t, store.Save(regressed))
+
+	cfg := DefaultConfig()
+	cfg.Mode = ModeFail
+	err := Check(cfg, store, regressed)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cpu_usage Average at 1000: 20 -> 30 (+50.0%")
+	assert.NotContains(t, err.Error(), "memory_rss")
+}
+
+func TestAnalyzeMannWhitneyDefaults(t *testing.T) {
+	cfg := DefaultConfigFor(MethodMannWhitney)
+	require.NoError(t, cfg.Validate())
+	previous := history(20, 21, 19, 20.5, 19.5, 20, 21, 20, 20.5, 19.5, 26, 27)
+
+	report, err := Analyze(cfg, previous, record(50, "c5.xlarge", 26.5))
+	require.NoError(t, err)
+
+	var regressions []string
+	for _, regression := range report.Regressions() {
+		regressions = append(regressions, regression.Metric+" "+regression.Statistic)
+	}
+	assert.Equal(t, []string{"procstat_cpu_usage Average", "procstat_cpu_usage P99"}, regressions)
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, DefaultConfig().Validate())
+	assert.NoError(t, DefaultConfigFor(MethodBootstrap).Validate())
+
+	cfg := DefaultConfig()
+	cfg.Method = MethodMannWhitney
+	assert.EqualError(t, cfg.Validate(), "mann-whitney with 1 recent and 10 previous commits cannot find a regression, its smallest p-value 0.077 is not below alpha 0.05")
+	cfg.History = 50
+	assert.NoError(t, cfg.Validate())
+
+	cfg = DefaultConfig()
+	cfg.Mode, cfg.Method, cfg.MinHistory, cfg.Alpha = "block", "t-test", 20, 1
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `mode must be off, warn or fail, got "block"`)
+	assert.Contains(t, err.Error(), `method must be bootstrap or mann-whitney, got "t-test"`)
+	assert.Contains(t, err.Error(), "min history 20 is larger than the history 10")
+	assert.Contains(t, err.Error(), "alpha must be between 0 and 1, got 1")
+}
@@ -0,0 +1,113 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package regression
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// MannWhitneyGreater returns the one sided p-value of the Mann-Whitney U test for the candidate values
+// being stochastically greater than the baseline ones. It uses the normal approximation with tie and
+// continuity corrections, which is conservative for the few values of a performance history.
+func MannWhitneyGreater(baseline, candidate []float64) float64 {
+	var (
+		nx, ny = float64(len(baseline)), float64(len(candidate))
+		n      = nx + ny
+	)
+	if nx == 0 || ny == 0 {
+		return 1
+	}
+
+	type value struct {
+		v           float64
+		isCandidate bool
+	}
+	values := make([]value, 0, len(baseline)+len(candidate))
+	for _, v := range baseline {
+		values = append(values, value{v: v})
+	}
+	for _, v := range candidate {
+		values = append(values, value{v: v, isCandidate: true})
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].v < values[j].v })
+
+	// Tied values share the average of their ranks
+	var rankSum, tieCorrection float64
+	for i := 0; i < len(values); {
+		j := i
+		for j < len(values) && values[j].v == values[i].v {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if values[k].isCandidate {
+				rankSum += rank
+			}
+		}
+		t := float64(j - i)
+		tieCorrection += t*t*t - t
+		i = j
+	}
+
+	u := rankSum - ny*(ny+1)/2
+	mean := nx * ny / 2
+	variance := nx * ny / 12 * ((n + 1) - tieCorrection/(n*(n-1)))
+	if variance <= 0 {
+		return 1
+	}
+	z := (u - mean - 0.5) / math.Sqrt(variance)
+	return 0.5 * math.Erfc(z/math.Sqrt2)
+}
+
+// MannWhitneyMinPValue returns the smallest p-value MannWhitneyGreater can return for the numbers of
+// baseline and candidate values, when every candidate value is greater than every baseline one. A test
+// whose smallest p-value is not below the significance level can never find a regression.
+func MannWhitneyMinPValue(baselines, candidates int) float64 {
+	baseline := make([]float64, baselines)
+	for i := range baseline {
+		baseline[i] = float64(i)
+	}
+	candidate := make([]float64, candidates)
+	for i := range candidate {
+		candidate[i] = float64(baselines + i)
+	}
+	return MannWhitneyGreater(baseline, candidate)
+}
+
+// BootstrapDifferenceLowerBound returns the lower bound at the confidence (e.g 0.95) of the difference
+// between the mean of the candidate values and the mean of the baseline ones, estimated with the
+// percentile bootstrap. A positive lower bound means the candidate is significantly greater.
+func BootstrapDifferenceLowerBound(baseline, candidate []float64, confidence float64, iterations int, rng *rand.Rand) float64 {
+	if len(baseline) == 0 || len(candidate) == 0 || iterations <= 0 {
+		return math.Inf(-1)
+	}
+	differences := make([]float64, iterations)
+	for i := range differences {
+		differences[i] = resampledMean(candidate, rng) - resampledMean(baseline, rng)
+	}
+	sort.Float64s(differences)
+	index := int(math.Floor((1 - confidence) * float64(iterations)))
+	if index >= iterations {
+		index = iterations - 1
+	}
+	return differences[index]
+}
+
+func resampledMean(values []float64, rng *rand.Rand) float64 {
+	var sum float64
+	for range values {
+		sum += values[rng.Intn(len(values))]
+	}
+	return sum / float64(len(values))
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
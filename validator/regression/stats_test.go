@@ -0,0 +1,47 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package regression
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMannWhitneyGreater(t *testing.T) {
+	testCases := map[string]struct {
+		baseline  []float64
+		candidate []float64
+		want      float64
+	}{
+		// scipy.stats.mannwhitneyu(candidate, baseline, alternative="greater", method="asymptotic")
+		"Separated":  {baseline: []float64{1, 2, 3, 4, 5}, candidate: []float64{6, 7, 8, 9, 10}, want: 0.006093},
+		"Reversed":   {baseline: []float64{6, 7, 8, 9, 10}, candidate: []float64{1, 2, 3, 4, 5}, want: 0.996692},
+		"WithTies":   {baseline: []float64{1, 2, 2, 3, 4}, candidate: []float64{2, 4, 5, 6}, want: 0.066639},
+		"AllTied":    {baseline: []float64{3, 3, 3}, candidate: []float64{3, 3}, want: 1},
+		"NoBaseline": {candidate: []float64{1}, want: 1},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.InDelta(t, testCase.want, MannWhitneyGreater(testCase.baseline, testCase.candidate), 1e-4)
+		})
+	}
+}
+
+func TestMannWhitneyMinPValue(t *testing.T) {
+	assert.InDelta(t, 0.0766, MannWhitneyMinPValue(10, 1), 1e-3)
+	assert.InDelta(t, 0.0484, MannWhitneyMinPValue(50, 1), 1e-3)
+	assert.Less(t, MannWhitneyMinPValue(10, DefaultMannWhitneyRecent), 0.01)
+}
+
+func TestBootstrapDifferenceLowerBound(t *testing.T) {
+	baseline := []float64{20, 21, 19, 20.5, 19.5, 20, 21, 20}
+
+	rng := rand.New(rand.NewSource(1))
+	assert.Greater(t, BootstrapDifferenceLowerBound(baseline, []float64{25}, 0.95, 2000, rng), 3.0)
+	assert.Less(t, BootstrapDifferenceLowerBound(baseline, []float64{20.2}, 0.95, 2000, rng), 0.0)
+	assert.True(t, math.IsInf(BootstrapDifferenceLowerBound(nil, []float64{25}, 0.95, 2000, rng), -1))
+}
@@ -0,0 +1,64 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package resultstore
+
+import (
+	"fmt"
+
+	"github.com/cenkalti/backoff/v4"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+)
+
+const (
+	DefaultDynamoDBTable = "CWAPerformanceMetrics"
+	// The secondary global indexes of the table
+	// https://github.com/aws/amazon-cloudwatch-agent-test/blob/e07fe7adb1b1d75244d8984507d3f83a7237c3d3/terraform/setup/main.tf#L37-L53
+	useCaseHashIndex = "UseCaseHash"
+	useCaseDateIndex = "UseCaseDate"
+)
+
+type dynamoDBStore struct {
+	table string
+}
+
+var _ Store = (*dynamoDBStore)(nil)
+
+// NewDynamoDBStore stores the records in the DynamoDB table shared by the performance tests.
+func NewDynamoDBStore(table string) Store {
+	return &dynamoDBStore{table: table}
+}
+
+func (d *dynamoDBStore) Name() string {
+	return KindDynamoDB + ":" + d.table
+}
+
+func (d *dynamoDBStore) Save(record Record) error {
+	var (
+		// Query based on the UseCaseHash index to confirm if the current commit with the use case
+		// has been exist or not? If yes, merge it. If not, sending it to the database
+		kCheckingAttribute = []string{"CommitHash", "UseCase"}
+		vCheckingAttribute = []string{fmt.Sprint(record["CommitHash"]), fmt.Sprint(record["UseCase"])}
+	)
+
+	return backoff.Retry(func() error {
+		existing, err := awsservice.GetItemInDatabase(d.table, useCaseHashIndex, kCheckingAttribute, vCheckingAttribute, record)
+		if err != nil {
+			return err
+		}
+		return awsservice.ReplaceItemInDatabase(d.table, merge(existing, record))
+	}, awsservice.StandardExponentialBackoff)
+}
+
+func (d *dynamoDBStore) History(useCase string) ([]Record, error) {
+	items, err := awsservice.QueryItemsInDatabase(d.table, useCaseDateIndex, "UseCase", useCase)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]Record, 0, len(items))
+	for _, item := range items {
+		records = append(records, item)
+	}
+	return records, nil
+}
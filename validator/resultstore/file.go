@@ -0,0 +1,117 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package resultstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+type fileStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+var _ Store = (*fileStore)(nil)
+
+// NewFileStore keeps the records as a JSON array in a file on the local disk, so the performance history
+// can be kept and compared (e.g one file per branch) without access to the shared table. The file is
+// created on the first Save.
+func NewFileStore(path string) Store {
+	return &fileStore{path: path}
+}
+
+func (f *fileStore) Name() string {
+	return KindFile + ":" + f.path
+}
+
+func (f *fileStore) Save(record Record) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	record, err := Normalize(record)
+	if err != nil {
+		return err
+	}
+	records, err := f.read()
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i, existing := range records {
+		if sameRun(existing, record) {
+			records[i] = merge(existing, record)
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		records = append(records, record)
+	}
+	sortByCommitDate(records)
+	return f.write(records)
+}
+
+func (f *fileStore) History(useCase string) ([]Record, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	records, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+	var history []Record
+	for _, record := range records {
+		if record["UseCase"] == useCase {
+			history = append(history, record)
+		}
+	}
+	sortByCommitDate(history)
+	return history, nil
+}
+
+func (f *fileStore) read() ([]Record, error) {
+	content, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var records []Record
+	if err = json.Unmarshal(content, &records); err != nil {
+		return nil, fmt.Errorf("results file %s: %w", f.path, err)
+	}
+	return records, nil
+}
+
+// write replaces the file through a rename so an interrupted run does not lose the history.
+func (f *fileStore) write(records []Record) error {
+	content, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(f.path)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(f.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(append(content, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
@@ -0,0 +1,145 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package resultstore
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultPushgatewayJob = "cwagent_performance"
+	// pushgatewayMetric is the gauge every statistic is pushed as, the metric and statistic are labels
+	// (e.g cwagent_performance{metric="procstat_cpu_usage",statistic="P99"}).
+	pushgatewayMetric = "cwagent_performance"
+	pushTimeout       = 30 * time.Second
+)
+
+// recordLabels are the record fields pushed as labels of every sample.
+var recordLabels = []struct{ field, label string }{
+	{"UseCase", "use_case"},
+	{"CommitHash", "commit_hash"},
+	{"DataType", "data_type"},
+	{"InstanceType", "instance_type"},
+	{"InstanceAMI", "instance_ami"},
+}
+
+type pushgatewayStore struct {
+	url    string
+	job    string
+	client *http.Client
+}
+
+var _ Store = (*pushgatewayStore)(nil)
+
+// NewPushgatewayStore pushes the records to a Prometheus pushgateway in the text exposition format. The
+// pushgateway only keeps the latest push of a group, the history is kept by the Prometheus scraping it,
+// so History is not supported.
+func NewPushgatewayStore(url, job string) Store {
+	return &pushgatewayStore{
+		url:    strings.TrimSuffix(url, "/"),
+		job:    job,
+		client: &http.Client{Timeout: pushTimeout},
+	}
+}
+
+func (p *pushgatewayStore) Name() string {
+	return KindPushgateway + ":" + p.url
+}
+
+// Save pushes a group per data rate of the record, grouped by use case and data rate so runs of the
+// same use case at different rates do not replace each other.
+func (p *pushgatewayStore) Save(record Record) error {
+	record, err := Normalize(record)
+	if err != nil {
+		return err
+	}
+	results, _ := record["Results"].(map[string]interface{})
+	if len(results) == 0 {
+		return fmt.Errorf("record of %v has no results to push", record["UseCase"])
+	}
+	for _, dataRate := range sortedKeys(results) {
+		stats, ok := results[dataRate].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("results of data rate %s are not metric statistics", dataRate)
+		}
+		if err = p.push(record, dataRate, stats); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *pushgatewayStore) History(string) ([]Record, error) {
+	return nil, ErrWriteOnly
+}
+
+func (p *pushgatewayStore) push(record Record, dataRate string, stats map[string]interface{}) error {
+	url := fmt.Sprintf("%s/metrics/job/%s/use_case@base64/%s/data_rate/%s", p.url, p.job,
+		base64.URLEncoding.EncodeToString([]byte(fmt.Sprint(record["UseCase"]))), dataRate)
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(exposition(record, dataRate, stats)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("pushgateway %s returned %s: %s", url, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// exposition renders the statistics of a data rate in the Prometheus text format.
+func exposition(record Record, dataRate string, stats map[string]interface{}) []byte {
+	var common strings.Builder
+	for _, l := range recordLabels {
+		if value, ok := record[l.field]; ok && value != nil {
+			fmt.Fprintf(&common, "%s=%q,", l.label, fmt.Sprint(value))
+		}
+	}
+	fmt.Fprintf(&common, "data_rate=%q", dataRate)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# TYPE %s gauge\n", pushgatewayMetric)
+	for _, metric := range sortedKeys(stats) {
+		statistics, ok := stats[metric].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, statistic := range sortedKeys(statistics) {
+			value, ok := statistics[statistic].(float64)
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(&buf, "%s{%s,metric=%q,statistic=%q} %s\n", pushgatewayMetric, common.String(), metric, statistic,
+				strconv.FormatFloat(value, 'g', -1, 64))
+		}
+	}
+	if commitDate, ok := record["CommitDate"].(float64); ok {
+		fmt.Fprintf(&buf, "# TYPE %s_commit_date_seconds gauge\n", pushgatewayMetric)
+		fmt.Fprintf(&buf, "%s_commit_date_seconds{%s} %s\n", pushgatewayMetric, common.String(), strconv.FormatFloat(commitDate, 'f', -1, 64))
+	}
+	return buf.Bytes()
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
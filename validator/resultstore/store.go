@@ -0,0 +1,175 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package resultstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	KindDynamoDB    = "dynamodb"
+	KindFile        = "file"
+	KindPushgateway = "pushgateway"
+)
+
+// ErrWriteOnly is returned by History for stores that cannot read their records back.
+var ErrWriteOnly = errors.New("results store is write only")
+
+// LegacyPercentileMethod is the method of the percentiles of the records saved without a PercentileMethod,
+// which took the value at int(0.99*n)-1 of the sorted values as the P99.
+const LegacyPercentileMethod = "index"
+
+// Record is the performance information of a use case at a commit, in the format of the
+// CWAPerformanceMetrics table (e.g UseCase, CommitHash, CommitDate and Results keyed by data rate).
+// https://github.com/aws/amazon-cloudwatch-agent-test/blob/e07fe7adb1b1d75244d8984507d3f83a7237c3d3/terraform/setup/main.tf#L8-L63
+type Record map[string]interface{}
+
+// Store keeps the performance history of the agent.
+type Store interface {
+	// Save stores the record. If a record already exists for the same CommitHash and UseCase, the
+	// Results of both are merged, so runs of the same commit at different data rates end up together.
+	Save(record Record) error
+	// History returns the records of the use case ordered by CommitDate, oldest first.
+	History(useCase string) ([]Record, error)
+	// Name describes the store in logs (e.g file:/tmp/results.json).
+	Name() string
+}
+
+var (
+	mu           sync.RWMutex
+	defaultStore Store = NewDynamoDBStore(DefaultDynamoDBTable)
+)
+
+// Default returns the store the performance validator saves its results to, which is the
+// CWAPerformanceMetrics table unless SetDefault was called.
+func Default() Store {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultStore
+}
+
+// SetDefault replaces the store returned by Default.
+func SetDefault(store Store) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultStore = store
+}
+
+// Open creates the store described by spec, which is one of
+//   - dynamodb or dynamodb:<table>
+//   - file:<path> for a JSON file kept on the local disk
+//   - pushgateway:<url> for a Prometheus pushgateway
+func Open(spec string) (Store, error) {
+	kind, location, _ := strings.Cut(spec, ":")
+	switch kind {
+	case KindDynamoDB:
+		if location == "" {
+			location = DefaultDynamoDBTable
+		}
+		return NewDynamoDBStore(location), nil
+	case KindFile:
+		if location == "" {
+			return nil, fmt.Errorf("results store %q needs a path (e.g file:/tmp/performance.json)", spec)
+		}
+		return NewFileStore(location), nil
+	case KindPushgateway:
+		if location == "" {
+			return nil, fmt.Errorf("results store %q needs an url (e.g pushgateway:http://localhost:9091)", spec)
+		}
+		return NewPushgatewayStore(location, DefaultPushgatewayJob), nil
+	default:
+		return nil, fmt.Errorf("unsupported results store %q, must be %s, %s:<path> or %s:<url>", spec, KindDynamoDB, KindFile, KindPushgateway)
+	}
+}
+
+// merge returns the record with the Results of the existing record it replaces merged in. The results of
+// the record win for a data rate present in both, and the UniqueID of the existing record is kept.
+func merge(existing, record Record) Record {
+	merged := Record{}
+	for key, value := range record {
+		merged[key] = value
+	}
+	if uniqueID, ok := existing["UniqueID"]; ok {
+		merged["UniqueID"] = uniqueID
+	}
+	results := map[string]interface{}{}
+	if existingResults, ok := existing["Results"].(map[string]interface{}); ok {
+		for dataRate, stats := range existingResults {
+			results[dataRate] = stats
+		}
+	}
+	if recordResults, ok := record["Results"].(map[string]interface{}); ok {
+		for dataRate, stats := range recordResults {
+			results[dataRate] = stats
+		}
+	}
+	merged["Results"] = results
+	return merged
+}
+
+// Normalize converts the record to plain JSON values (e.g the Stats of the performance validator become
+// maps and numbers become float64) so every store handles the same shapes.
+func Normalize(record Record) (Record, error) {
+	content, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	var normalized Record
+	if err = json.Unmarshal(content, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// Result returns the statistics of the metrics at the data rate of a normalized record.
+func (r Record) Result(dataRate string) (map[string]interface{}, bool) {
+	results, _ := r["Results"].(map[string]interface{})
+	stats, ok := results[dataRate].(map[string]interface{})
+	return stats, ok
+}
+
+// Statistic returns a statistic (e.g P99) of a metric at the data rate of a normalized record.
+func (r Record) Statistic(dataRate, metric, statistic string) (float64, bool) {
+	stats, _ := r.Result(dataRate)
+	statistics, _ := stats[metric].(map[string]interface{})
+	value, ok := statistics[statistic].(float64)
+	return value, ok
+}
+
+// PercentileMethod returns how the percentiles (e.g P99) of the record were computed, which is the
+// interpolation method of util/summary or LegacyPercentileMethod.
+func (r Record) PercentileMethod() string {
+	if method, ok := r["PercentileMethod"].(string); ok && method != "" {
+		return method
+	}
+	return LegacyPercentileMethod
+}
+
+func sortByCommitDate(records []Record) {
+	sort.SliceStable(records, func(i, j int) bool {
+		return number(records[i]["CommitDate"]) < number(records[j]["CommitDate"])
+	})
+}
+
+func number(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case int64:
+		return float64(v)
+	case int:
+		return float64(v)
+	default:
+		return 0
+	}
+}
+
+func sameRun(a, b Record) bool {
+	return fmt.Sprint(a["CommitHash"]) == fmt.Sprint(b["CommitHash"]) && fmt.Sprint(a["UseCase"]) == fmt.Sprint(b["UseCase"])
+}
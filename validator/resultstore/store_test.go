@@ -0,0 +1,139 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package resultstore
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stats struct {
+	Average float64
+	P99     float64
+}
+
+func record(uniqueID, commitHash string, commitDate int64, dataRate string, cpu float64) Record {
+	return Record{
+		"UniqueID":   uniqueID,
+		"Service":    "AmazonCloudWatchAgent",
+		"UseCase":    "statsd",
+		"CommitHash": commitHash,
+		"CommitDate": commitDate,
+		"DataType":   "metrics",
+		"Results": map[string]interface{}{
+			dataRate: map[string]stats{"procstat_cpu_usage": {Average: cpu, P99: cpu * 2}},
+		},
+	}
+}
+
+func TestOpen(t *testing.T) {
+	testCases := map[string]struct {
+		spec    string
+		name    string
+		wantErr bool
+	}{
+		"DynamoDB":         {spec: "dynamodb", name: "dynamodb:" + DefaultDynamoDBTable},
+		"DynamoDBTable":    {spec: "dynamodb:MyTable", name: "dynamodb:MyTable"},
+		"File":             {spec: "file:/tmp/perf.json", name: "file:/tmp/perf.json"},
+		"Pushgateway":      {spec: "pushgateway:http://localhost:9091/", name: "pushgateway:http://localhost:9091"},
+		"FileWithoutPath":  {spec: "file", wantErr: true},
+		"PushgatewayNoURL": {spec: "pushgateway:", wantErr: true},
+		"UnsupportedStore": {spec: "sqlite:/tmp/perf.db", wantErr: true},
+		"EmptySpec":        {spec: "", wantErr: true},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			store, err := Open(testCase.spec)
+			if testCase.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.name, store.Name())
+		})
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history", "perf.json")
+	store := NewFileStore(path)
+
+	history, err := store.History("statsd")
+	require.NoError(t, err)
+	assert.Empty(t, history)
+
+	require.NoError(t, store.Save(record("second", "def456", 200, "1000", 3)))
+	require.NoError(t, store.Save(record("first", "abc123", 100, "1000", 1)))
+	// Another data rate of the same commit is merged into the first record
+	require.NoError(t, store.Save(record("first-5000", "abc123", 100, "5000", 2)))
+
+	history, err = NewFileStore(path).History("statsd")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, "first", history[0]["UniqueID"])
+	assert.Equal(t, "abc123", history[0]["CommitHash"])
+	assert.Equal(t, map[string]interface{}{
+		"1000": map[string]interface{}{"procstat_cpu_usage": map[string]interface{}{"Average": 1.0, "P99": 2.0}},
+		"5000": map[string]interface{}{"procstat_cpu_usage": map[string]interface{}{"Average": 2.0, "P99": 4.0}},
+	}, history[0]["Results"])
+	assert.Equal(t, "def456", history[1]["CommitHash"])
+
+	history, err = store.History("logs")
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func TestPushgatewayStore(t *testing.T) {
+	var paths, bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		body, _ := io.ReadAll(r.Body)
+		paths = append(paths, r.URL.Path)
+		bodies = append(bodies, string(body))
+	}))
+	defer server.Close()
+
+	store := NewPushgatewayStore(server.URL, DefaultPushgatewayJob)
+	r := record("first", "abc123", 100, "1000", 1.5)
+	r["Results"].(map[string]interface{})["5000"] = map[string]stats{"procstat_cpu_usage": {Average: 2}}
+	require.NoError(t, store.Save(r))
+
+	useCase := base64.URLEncoding.EncodeToString([]byte("statsd"))
+	assert.Equal(t, []string{
+		"/metrics/job/cwagent_performance/use_case@base64/" + useCase + "/data_rate/1000",
+		"/metrics/job/cwagent_performance/use_case@base64/" + useCase + "/data_rate/5000",
+	}, paths)
+	require.Len(t, bodies, 2)
+	labels := `use_case="statsd",commit_hash="abc123",data_type="metrics",data_rate="1000"`
+	assert.Equal(t, strings.Join([]string{
+		"# TYPE cwagent_performance gauge",
+		`cwagent_performance{` + labels + `,metric="procstat_cpu_usage",statistic="Average"} 1.5`,
+		`cwagent_performance{` + labels + `,metric="procstat_cpu_usage",statistic="P99"} 3`,
+		"# TYPE cwagent_performance_commit_date_seconds gauge",
+		`cwagent_performance_commit_date_seconds{` + labels + `} 100`,
+		"",
+	}, "\n"), bodies[0])
+
+	_, err := store.History("statsd")
+	assert.ErrorIs(t, err, ErrWriteOnly)
+}
+
+func TestPushgatewayStoreError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad exposition", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	err := NewPushgatewayStore(server.URL, DefaultPushgatewayJob).Save(record("first", "abc123", 100, "1000", 1))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad exposition")
+}
@@ -16,6 +16,7 @@ import (
 
 	AppSignalMetrics "github.com/aws/amazon-cloudwatch-agent-test/test/metric"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/common/traces"
 	"github.com/aws/amazon-cloudwatch-agent-test/validator/models"
@@ -27,13 +28,15 @@ const AppSignalNamespace = "ApplicationSignals"
 
 type BasicValidator struct {
 	vConfig models.ValidateConfig
+	clock   clock.Clock
 }
 
 var _ models.ValidatorFactory = (*BasicValidator)(nil)
 
-func NewBasicValidator(vConfig models.ValidateConfig) models.ValidatorFactory {
+func NewBasicValidator(vConfig models.ValidateConfig, clk clock.Clock) models.ValidatorFactory {
 	return &BasicValidator{
 		vConfig: vConfig,
+		clock:   clk,
 	}
 }
 
@@ -51,7 +54,7 @@ func (s *BasicValidator) GenerateLoad() error {
 
 	switch dataType {
 	case "logs":
-		return common.StartLogWrite(agentConfigFilePath, agentCollectionPeriod, metricSendingInterval, dataRate)
+		return common.StartLogWrite(s.clock, agentConfigFilePath, agentCollectionPeriod, metricSendingInterval, dataRate)
 	case "traces":
 		return traces.StartTraceGeneration(receiver, agentConfigFilePath, agentCollectionPeriod, metricSendingInterval)
 	default:
@@ -63,7 +66,7 @@ func (s *BasicValidator) GenerateLoad() error {
 				log.Printf("Using scrape_interval defined in parameters.yml for metric sending interval: %v seconds", metricSendingInterval.Seconds())
 			}
 		}
-		return common.StartSendingMetrics(receiver, agentCollectionPeriod, metricSendingInterval, dataRate, logGroup, metricNamespace)
+		return common.StartSendingMetrics(s.clock, receiver, agentCollectionPeriod, metricSendingInterval, dataRate, logGroup, metricNamespace, s.vConfig.GetEMFMode())
 	}
 }
 
@@ -77,26 +80,24 @@ func (s *BasicValidator) CheckData(startTime, endTime time.Time) error {
 	)
 
 	for _, metric := range validationMetric {
-		metricDimensions := []cwtypes.Dimension{}
+		baseDimensions := []cwtypes.Dimension{}
 		//App Signal Metrics don't have instanceid dimension
 		if !isAppSignalMetric(metric) {
-			metricDimensions = []cwtypes.Dimension{
+			baseDimensions = []cwtypes.Dimension{
 				{
 					Name:  aws.String("InstanceId"),
 					Value: aws.String(ec2InstanceId),
 				},
 			}
 		}
+		metricDimensions := withDimensions(baseDimensions, metric.MetricDimension)
 
-		for _, dimension := range metric.MetricDimension {
-			metricDimensions = append(metricDimensions, cwtypes.Dimension{
-				Name:  aws.String(dimension.Name),
-				Value: aws.String(dimension.Value),
-			})
-		}
-
-		//App Signals metric testing (This is because we want to use a different checking method (same that was done for linux test))
-		if metric.MetricName == "Latency" || metric.MetricName == "Fault" || metric.MetricName == "Error" {
+		if metric.HasExpectation() {
+			if err := s.ValidateMetricExpectation(metric, metricNamespace, baseDimensions, startTime, endTime); err != nil {
+				multiErr = multierr.Append(multiErr, err)
+			}
+		} else if metric.MetricName == "Latency" || metric.MetricName == "Fault" || metric.MetricName == "Error" {
+			//App Signals metric testing (This is because we want to use a different checking method (same that was done for linux test))
 			err := s.ValidateAppSignalMetrics(metric, metricDimensions)
 			if err != nil {
 				multiErr = multierr.Append(multiErr, err)
@@ -168,7 +169,7 @@ func (s *BasicValidator) ValidateTracesMetrics() error {
 	serviceType := "AWS::EC2::Instance"
 	//filtering traces
 	filterExpression := fmt.Sprintf("(service(id(name: \"%s\", type: \"%s\")))", serviceName, serviceType)
-	timeNow := time.Now()
+	timeNow := s.clock.Now()
 
 	traceIds, err := awsservice.GetTraceIDs(timeNow.Add(lookbackDuration), timeNow, filterExpression)
 	if err != nil {
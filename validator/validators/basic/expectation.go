@@ -0,0 +1,238 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package basic
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/metricassert"
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/models"
+)
+
+const (
+	// listMetricsLag is how long CloudWatch can take to list a new metric in ListMetrics
+	listMetricsLag           = 15 * time.Minute
+	listMetricsRetryInterval = time.Minute
+)
+
+// ValidateMetricExpectation checks a metric_validation that declares its expectation (see
+// models.MetricValidation.HasExpectation). The base dimensions are added to the metric and to the metrics of its
+// expression.
+func (s *BasicValidator) ValidateMetricExpectation(metric models.MetricValidation, metricNamespace string, baseDimensions []cwtypes.Dimension, startTime, endTime time.Time) error {
+	label := metricLabel(metric)
+	metricDimensions := withDimensions(baseDimensions, metric.MetricDimension)
+	period := int32(metric.Period)
+	if period == 0 {
+		period = int32(s.vConfig.GetAgentCollectionPeriod().Seconds())
+	}
+	if metric.Absent {
+		return s.validateAbsent(metric, label, metricNamespace, metricDimensions, period, startTime, endTime)
+	}
+
+	var errs []error
+	if len(metric.RequiredDimensions) > 0 {
+		metrics, err := listMetricsWithLag(s.clock, func() ([]cwtypes.Metric, error) {
+			return awsservice.ListMetrics(metric.MetricName, metricNamespace, dimensionFilters(metricDimensions))
+		})
+		if err != nil {
+			return err
+		}
+		if err = checkRequiredDimensions(label, metric.RequiredDimensions, metrics); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	log.Printf("Start to validate the expectation on %s with the namespace %s, start time %v and end time %v", label, metricNamespace, startTime, endTime)
+	output, err := awsservice.GetMetricData(expectationQueries(metric, metricNamespace, baseDimensions, period), startTime, endTime)
+	if err != nil {
+		return err
+	}
+	var result cwtypes.MetricDataResult
+	for _, r := range output.MetricDataResults {
+		if aws.StringValue(r.Id) == models.ResultQueryID {
+			result = r
+		}
+	}
+	result.Label = aws.String(label)
+	if err = expect(metric, result).Err(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if metric.MetricSampleCount > 0 {
+		if ok := awsservice.ValidateSampleCount(metric.MetricName, metricNamespace, metricDimensions, startTime, endTime, metric.MetricSampleCount, metric.MetricSampleCount, period); !ok {
+			errs = append(errs, fmt.Errorf("metric %s is not within sample count bound [ %d, %d]", label, metric.MetricSampleCount, metric.MetricSampleCount))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateAbsent checks the metric has no datapoints with the dimensions over the time range. ListMetrics can take
+// up to listMetricsLag to list a new metric, so it only finds the series published with other dimensions before.
+func (s *BasicValidator) validateAbsent(metric models.MetricValidation, label, metricNamespace string, metricDimensions []cwtypes.Dimension, period int32, startTime, endTime time.Time) error {
+	log.Printf("Start to validate %s with the namespace %s is absent, start time %v and end time %v", label, metricNamespace, startTime, endTime)
+	query := metricQuery(models.ResultQueryID, metric.MetricName, "SampleCount", metricNamespace, metricDimensions, period, true)
+	output, err := awsservice.GetMetricData([]cwtypes.MetricDataQuery{query}, startTime, endTime)
+	if err != nil {
+		return err
+	}
+	for _, result := range output.MetricDataResults {
+		if len(result.Values) > 0 {
+			return fmt.Errorf("metric %s must not be published but has %d datapoints between %v and %v", label, len(result.Values), startTime, endTime)
+		}
+	}
+	metrics, err := awsservice.ListMetrics(metric.MetricName, metricNamespace, dimensionFilters(metricDimensions))
+	if err != nil {
+		return err
+	}
+	if len(metrics) > 0 {
+		return fmt.Errorf("metric %s must not be published but has %d series, e.g with the dimensions %s", label, len(metrics), dimensionNames(metrics[0].Dimensions))
+	}
+	return nil
+}
+
+// listMetricsWithLag lists the series of a metric, retrying for up to listMetricsLag while none is listed since
+// ListMetrics lists a new metric late. Series listed after the first ones are not waited for.
+func listMetricsWithLag(clk clock.Clock, list func() ([]cwtypes.Metric, error)) ([]cwtypes.Metric, error) {
+	deadline := clk.Now().Add(listMetricsLag)
+	for {
+		metrics, err := list()
+		if err != nil || len(metrics) > 0 || !clk.Now().Before(deadline) {
+			return metrics, err
+		}
+		log.Printf("No series listed yet, retrying in %v", listMetricsRetryInterval)
+		clk.Sleep(listMetricsRetryInterval)
+	}
+}
+
+// expectationQueries returns the query of the metric, or of the expression and the metrics it refers to. Only the
+// result of the query with the id models.ResultQueryID is returned.
+func expectationQueries(metric models.MetricValidation, metricNamespace string, baseDimensions []cwtypes.Dimension, period int32) []cwtypes.MetricDataQuery {
+	if metric.Expression == "" {
+		dimensions := withDimensions(baseDimensions, metric.MetricDimension)
+		return []cwtypes.MetricDataQuery{metricQuery(models.ResultQueryID, metric.MetricName, metric.GetStatistic(), metricNamespace, dimensions, period, true)}
+	}
+	queries := []cwtypes.MetricDataQuery{{
+		Id:         aws.String(models.ResultQueryID),
+		Expression: aws.String(metric.Expression),
+		Period:     aws.Int32(period),
+		ReturnData: aws.Bool(true),
+	}}
+	for _, m := range metric.ExpressionMetrics {
+		dimensions := withDimensions(baseDimensions, m.MetricDimension)
+		statistic := m.Statistic
+		if statistic == "" {
+			statistic = "Average"
+		}
+		queries = append(queries, metricQuery(m.ID, m.MetricName, statistic, metricNamespace, dimensions, period, false))
+	}
+	return queries
+}
+
+func metricQuery(id, metricName, statistic, metricNamespace string, dimensions []cwtypes.Dimension, period int32, returnData bool) cwtypes.MetricDataQuery {
+	return cwtypes.MetricDataQuery{
+		Id: aws.String(id),
+		MetricStat: &cwtypes.MetricStat{
+			Metric: &cwtypes.Metric{
+				Namespace:  aws.String(metricNamespace),
+				MetricName: aws.String(metricName),
+				Dimensions: dimensions,
+			},
+			Period: aws.Int32(period),
+			Stat:   aws.String(statistic),
+		},
+		ReturnData: aws.Bool(returnData),
+	}
+}
+
+// expect declares the expectation of the comparator on the result. Without a comparator, the values must be within
+// the tolerance of a non zero metric_value.
+func expect(metric models.MetricValidation, result cwtypes.MetricDataResult) *metricassert.Expectation {
+	expectation := metricassert.Expect(result).NotEmpty()
+	switch metric.Comparator {
+	case models.ComparatorEq:
+		expectation.AllWithin(metric.MetricValue, metric.MetricValue)
+	case models.ComparatorGte:
+		expectation.AllAtLeast(metric.MetricValue)
+	case models.ComparatorLte:
+		expectation.AllAtMost(metric.MetricValue)
+	case models.ComparatorRange:
+		expectation.AllWithin(*metric.MinValue, *metric.MaxValue)
+	case models.ComparatorRateOfChange:
+		expectation.RateNear(metric.MetricValue, metric.GetTolerance())
+	case models.ComparatorApprox:
+		expectation.AllNear(metric.MetricValue, metric.GetTolerance())
+	default:
+		if metric.MetricValue != 0 {
+			expectation.AllNear(metric.MetricValue, metric.GetTolerance())
+		}
+	}
+	return expectation
+}
+
+// checkRequiredDimensions checks every series of the metric has the required dimensions.
+func checkRequiredDimensions(label string, required []string, metrics []cwtypes.Metric) error {
+	if len(metrics) == 0 {
+		return fmt.Errorf("metric %s has no series to check the required dimensions %v", label, required)
+	}
+	var failures []string
+	for _, metric := range metrics {
+		present := map[string]struct{}{}
+		for _, dimension := range metric.Dimensions {
+			present[aws.StringValue(dimension.Name)] = struct{}{}
+		}
+		var missing []string
+		for _, name := range required {
+			if _, ok := present[name]; !ok {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			failures = append(failures, fmt.Sprintf("%v missing from %s", missing, dimensionNames(metric.Dimensions)))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%d of the %d series of metric %s lack required dimensions: %s", len(failures), len(metrics), label, strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+func metricLabel(metric models.MetricValidation) string {
+	if metric.MetricName != "" {
+		return metric.MetricName
+	}
+	return metric.Expression
+}
+
+func withDimensions(base []cwtypes.Dimension, declared []models.MetricDimension) []cwtypes.Dimension {
+	dimensions := append([]cwtypes.Dimension{}, base...)
+	for _, dimension := range declared {
+		dimensions = append(dimensions, cwtypes.Dimension{Name: aws.String(dimension.Name), Value: aws.String(dimension.Value)})
+	}
+	return dimensions
+}
+
+func dimensionFilters(dimensions []cwtypes.Dimension) []cwtypes.DimensionFilter {
+	filters := make([]cwtypes.DimensionFilter, len(dimensions))
+	for i, dimension := range dimensions {
+		filters[i] = cwtypes.DimensionFilter{Name: dimension.Name, Value: dimension.Value}
+	}
+	return filters
+}
+
+func dimensionNames(dimensions []cwtypes.Dimension) string {
+	names := make([]string, len(dimensions))
+	for i, dimension := range dimensions {
+		names[i] = aws.StringValue(dimension.Name)
+	}
+	return "[" + strings.Join(names, " ") + "]"
+}
@@ -0,0 +1,122 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package basic
+
+import (
+	"testing"
+	"time"
+
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/models"
+)
+
+func TestExpectationQueries(t *testing.T) {
+	base := []cwtypes.Dimension{{Name: aws.String("InstanceId"), Value: aws.String("i-123")}}
+
+	queries := expectationQueries(models.MetricValidation{
+		MetricName:      "cpu_usage_idle",
+		MetricDimension: []models.MetricDimension{{Name: "cpu", Value: "cpu-total"}},
+		Statistic:       "p99",
+	}, "CWAgent", base, 60)
+	require.Len(t, queries, 1)
+	assert.Equal(t, models.ResultQueryID, *queries[0].Id)
+	assert.Equal(t, "p99", *queries[0].MetricStat.Stat)
+	assert.Equal(t, int32(60), *queries[0].MetricStat.Period)
+	assert.Equal(t, "[InstanceId cpu]", dimensionNames(queries[0].MetricStat.Metric.Dimensions))
+
+	queries = expectationQueries(models.MetricValidation{
+		Expression: "m1 / m2",
+		ExpressionMetrics: []models.ExpressionMetric{
+			{ID: "m1", MetricName: "mem_used", MetricDimension: []models.MetricDimension{{Name: "host", Value: "a"}}},
+			{ID: "m2", MetricName: "mem_total", Statistic: "Sum"},
+		},
+	}, "CWAgent", base, 300)
+	require.Len(t, queries, 3)
+	assert.Equal(t, "m1 / m2", *queries[0].Expression)
+	assert.True(t, *queries[0].ReturnData)
+	assert.Equal(t, "m1", *queries[1].Id)
+	assert.False(t, *queries[1].ReturnData)
+	assert.Equal(t, "Average", *queries[1].MetricStat.Stat)
+	assert.Equal(t, "[InstanceId host]", dimensionNames(queries[1].MetricStat.Metric.Dimensions))
+	assert.Equal(t, "Sum", *queries[2].MetricStat.Stat)
+	assert.Equal(t, "[InstanceId]", dimensionNames(queries[2].MetricStat.Metric.Dimensions))
+	assert.Len(t, base, 1, "the base dimensions are not modified")
+}
+
+func TestExpect(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// GetMetricData returns the newest value first
+	result := cwtypes.MetricDataResult{
+		Id:         aws.String(models.ResultQueryID),
+		Timestamps: []time.Time{start.Add(2 * time.Minute), start.Add(time.Minute), start},
+		Values:     []float64{240, 120, 0},
+	}
+	minValue, maxValue := 0.0, 200.0
+	testCases := map[string]struct {
+		metric models.MetricValidation
+		fails  bool
+	}{
+		"Eq":                {metric: models.MetricValidation{Comparator: models.ComparatorEq, MetricValue: 120}, fails: true},
+		"Gte":               {metric: models.MetricValidation{Comparator: models.ComparatorGte, MetricValue: 0}},
+		"Lte":               {metric: models.MetricValidation{Comparator: models.ComparatorLte, MetricValue: 200}, fails: true},
+		"Range":             {metric: models.MetricValidation{Comparator: models.ComparatorRange, MinValue: &minValue, MaxValue: &maxValue}, fails: true},
+		"RateOfChange":      {metric: models.MetricValidation{Comparator: models.ComparatorRateOfChange, MetricValue: 2}},
+		"Approx":            {metric: models.MetricValidation{Comparator: models.ComparatorApprox, MetricValue: 120, Tolerance: 1}},
+		"NoComparator":      {metric: models.MetricValidation{Statistic: "Sum"}},
+		"NoComparatorValue": {metric: models.MetricValidation{Statistic: "Sum", MetricValue: 120}, fails: true},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := expect(testCase.metric, result).Err()
+			if testCase.fails {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+	assert.ErrorContains(t, expect(models.MetricValidation{Comparator: models.ComparatorGte}, cwtypes.MetricDataResult{}).Err(), "no data points")
+}
+
+func TestCheckRequiredDimensions(t *testing.T) {
+	metric := func(names ...string) cwtypes.Metric {
+		var m cwtypes.Metric
+		for _, name := range names {
+			m.Dimensions = append(m.Dimensions, cwtypes.Dimension{Name: aws.String(name), Value: aws.String("v")})
+		}
+		return m
+	}
+	assert.NoError(t, checkRequiredDimensions("mem_used", []string{"host"}, []cwtypes.Metric{metric("InstanceId", "host")}))
+	assert.EqualError(t, checkRequiredDimensions("mem_used", []string{"host"}, nil),
+		"metric mem_used has no series to check the required dimensions [host]")
+	assert.EqualError(t, checkRequiredDimensions("mem_used", []string{"host", "cpu"}, []cwtypes.Metric{metric("InstanceId", "host", "cpu"), metric("InstanceId")}),
+		"1 of the 2 series of metric mem_used lack required dimensions: [host cpu] missing from [InstanceId]")
+}
+
+func TestListMetricsWithLag(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	listed := []cwtypes.Metric{{MetricName: aws.String("mem_used")}}
+	calls := 0
+	metrics, err := listMetricsWithLag(clk, func() ([]cwtypes.Metric, error) {
+		calls++
+		if calls < 3 {
+			return nil, nil
+		}
+		return listed, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, listed, metrics)
+	assert.Equal(t, 2*listMetricsRetryInterval, clk.Slept())
+
+	clk = clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	metrics, err = listMetricsWithLag(clk, func() ([]cwtypes.Metric, error) { return nil, nil })
+	require.NoError(t, err)
+	assert.Empty(t, metrics)
+	assert.Equal(t, listMetricsLag, clk.Slept())
+}
@@ -0,0 +1,26 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package basic
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/registry"
+)
+
+// Parameters are the parameters.yml keys read by the BasicValidator, which every validator embeds.
+var Parameters = []registry.Parameter{
+	{Name: "receivers", Description: "agent plugins to send load to and validate (e.g statsd, collectd, system)", Required: true},
+	{Name: "test_case", Description: "test case name", Required: true},
+	{Name: "validate_type", Description: "validator to run", Required: true},
+	{Name: "data_type", Description: "metrics, logs or traces", Required: true},
+	{Name: "values_per_minute", Description: "number of metrics to send or log lines to write per minute", Required: true},
+	{Name: "agent_collection_period", Description: "seconds the agent runs and collects the load", Required: true},
+	{Name: "cloudwatch_agent_config", Description: "path to the agent configuration", Required: true},
+	{Name: "metric_namespace", Description: "namespace of the validated metrics"},
+	{Name: "metric_validation", Description: "metrics and dimensions to validate, with optional expectations (see Metric expectations in the README)"},
+	{Name: "log_validation", Description: "log lines to validate"},
+	{Name: "number_monitored_logs", Description: "number of log files the agent monitors"},
+	{Name: "scrape_interval", Description: "prometheus scrape interval in seconds"},
+	{Name: "os_family", Description: "OS family of the host"},
+	{Name: "emf_mode", Description: "shape of the emf events (e.g high-cardinality, dimension-explosion, oversized), basic when empty"},
+}
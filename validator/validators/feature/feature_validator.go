@@ -9,22 +9,35 @@ import (
 	"go.uber.org/multierr"
 
 	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
 	"github.com/aws/amazon-cloudwatch-agent-test/validator/models"
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/registry"
 	"github.com/aws/amazon-cloudwatch-agent-test/validator/validators/basic"
 )
 
 type FeatureValidator struct {
 	vConfig models.ValidateConfig
+	clock   clock.Clock
 	models.ValidatorFactory
 }
 
 var _ models.ValidatorFactory = (*FeatureValidator)(nil)
 
-func NewFeatureValidator(vConfig models.ValidateConfig) models.ValidatorFactory {
+func init() {
+	registry.RegisterValidator(registry.Validator{
+		Name:        "feature",
+		Description: "Send load to every receiver and validate the metrics and logs are published",
+		Parameters:  basic.Parameters,
+		New:         NewFeatureValidator,
+	})
+}
+
+func NewFeatureValidator(vConfig models.ValidateConfig, clk clock.Clock) models.ValidatorFactory {
 	return &FeatureValidator{
 		vConfig:          vConfig,
-		ValidatorFactory: basic.NewBasicValidator(vConfig),
+		clock:            clk,
+		ValidatorFactory: basic.NewBasicValidator(vConfig, clk),
 	}
 }
 
@@ -41,13 +54,13 @@ func (s *FeatureValidator) GenerateLoad() error {
 		validationLog         = s.vConfig.GetLogValidation()
 	)
 
-	if err := common.GenerateLogs(agentConfigFilePath, agentCollectionPeriod, metricSendingInterval, dataRate, validationLog); err != nil {
+	if err := common.GenerateLogs(s.clock, agentConfigFilePath, agentCollectionPeriod, metricSendingInterval, dataRate, validationLog); err != nil {
 		multiErr = multierr.Append(multiErr, err)
 	}
 
 	// Sending metrics based on the receivers; however, for scraping plugin  (e.g prometheus), we would need to scrape it instead of sending
 	for _, receiver := range receivers {
-		if err := common.StartSendingMetrics(receiver, agentCollectionPeriod, metricSendingInterval, dataRate, logGroup, metricNamespace); err != nil {
+		if err := common.StartSendingMetrics(s.clock, receiver, agentCollectionPeriod, metricSendingInterval, dataRate, logGroup, metricNamespace, s.vConfig.GetEMFMode()); err != nil {
 			multiErr = multierr.Append(multiErr, err)
 		}
 	}
@@ -0,0 +1,95 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package performance
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/multierr"
+	"golang.org/x/exp/slices"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common/traces"
+)
+
+// useCaseSeparator joins the receivers of a mixed workload into its UseCase (e.g emf+logs+statsd).
+const useCaseSeparator = "+"
+
+// receiverDataType returns the type of data a receiver is driven with.
+func receiverDataType(receiver string) string {
+	switch receiver {
+	case "logs":
+		return "logs"
+	case "xray":
+		return "traces"
+	default:
+		return "metrics"
+	}
+}
+
+// useCaseInformation returns the UseCase and DataType the results are stored under, with the receivers they
+// are attributed to. A single receiver keeps its own name so its history is unchanged, while a mixed workload
+// is keyed by its sorted receivers so every combination has its own history.
+func useCaseInformation(receivers []string, dataType string) (string, string, []string) {
+	sorted := append([]string{}, receivers...)
+	sort.Strings(sorted)
+	if len(sorted) <= 1 {
+		return strings.Join(sorted, useCaseSeparator), dataType, sorted
+	}
+	var dataTypes []string
+	for _, receiver := range sorted {
+		if t := receiverDataType(receiver); !slices.Contains(dataTypes, t) {
+			dataTypes = append(dataTypes, t)
+		}
+	}
+	sort.Strings(dataTypes)
+	return strings.Join(sorted, useCaseSeparator), strings.Join(dataTypes, useCaseSeparator), sorted
+}
+
+// GenerateLoad drives every receiver at values_per_minute at the same time so the agent's resource usage
+// reflects a mixed workload. Runs with a single receiver generate the load the same way as the other
+// validators.
+func (s *PerformanceValidator) GenerateLoad() error {
+	receivers := s.vConfig.GetPluginsConfig()
+	if len(receivers) <= 1 {
+		return s.ValidatorFactory.GenerateLoad()
+	}
+
+	var (
+		multiErr              error
+		sendingInterval       = time.Minute
+		logGroup              = awsservice.GetInstanceId()
+		metricNamespace       = s.vConfig.GetMetricNamespace()
+		dataRate              = s.vConfig.GetDataRate()
+		agentCollectionPeriod = s.vConfig.GetAgentCollectionPeriod()
+		agentConfigFilePath   = s.vConfig.GetCloudWatchAgentConfigPath()
+		traceReceivers        []string
+	)
+	for _, receiver := range receivers {
+		log.Printf("Start generating %s load for receiver %s", receiverDataType(receiver), receiver)
+		var err error
+		switch receiverDataType(receiver) {
+		case "logs":
+			err = common.StartLogWrite(s.clock, agentConfigFilePath, agentCollectionPeriod, sendingInterval, dataRate)
+		case "traces":
+			// The trace generator blocks for the whole collection period, so it runs once the other loads are started.
+			traceReceivers = append(traceReceivers, receiver)
+		default:
+			err = common.StartSendingMetrics(s.clock, receiver, agentCollectionPeriod, sendingInterval, dataRate, logGroup, metricNamespace, s.vConfig.GetEMFMode())
+		}
+		if err != nil {
+			multiErr = multierr.Append(multiErr, err)
+		}
+	}
+	for _, receiver := range traceReceivers {
+		if err := traces.StartTraceGeneration(receiver, agentConfigFilePath, agentCollectionPeriod, sendingInterval); err != nil {
+			multiErr = multierr.Append(multiErr, err)
+		}
+	}
+	return multiErr
+}
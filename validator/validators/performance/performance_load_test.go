@@ -0,0 +1,50 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package performance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUseCaseInformation(t *testing.T) {
+	testCases := map[string]struct {
+		receivers         []string
+		dataType          string
+		expectedUseCase   string
+		expectedDataType  string
+		expectedReceivers []string
+	}{
+		"SingleReceiver": {
+			receivers:         []string{"statsd"},
+			dataType:          "metrics",
+			expectedUseCase:   "statsd",
+			expectedDataType:  "metrics",
+			expectedReceivers: []string{"statsd"},
+		},
+		"MixedWorkload": {
+			receivers:         []string{"statsd", "logs", "emf"},
+			dataType:          "metrics",
+			expectedUseCase:   "emf+logs+statsd",
+			expectedDataType:  "logs+metrics",
+			expectedReceivers: []string{"emf", "logs", "statsd"},
+		},
+		"MixedWorkloadIsOrderIndependent": {
+			receivers:         []string{"xray", "emf"},
+			dataType:          "traces",
+			expectedUseCase:   "emf+xray",
+			expectedDataType:  "metrics+traces",
+			expectedReceivers: []string{"emf", "xray"},
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			useCase, dataType, receivers := useCaseInformation(testCase.receivers, testCase.dataType)
+			assert.Equal(t, testCase.expectedUseCase, useCase)
+			assert.Equal(t, testCase.expectedDataType, dataType)
+			assert.Equal(t, testCase.expectedReceivers, receivers)
+		})
+	}
+}
@@ -9,12 +9,15 @@ type PerformanceInformation map[string]interface{}
 	Contains the following:
 		// A service name we want to monitor (e.g CloudWatchAgent)
 		"Service":          ServiceName,
-		// A use case for generate metrics loads (e.g statsd, collectd)
-		"UseCase":          receiver,
+		// A use case for generate metrics loads (e.g statsd, collectd), or the receivers of a mixed workload
+		// joined with "+" (e.g emf+logs+statsd)
+		"UseCase":          useCase,
+		// The receivers the results are attributed to
+		"Receivers":        receivers,
 		// Commit Information
 		"CommitDate":       commitDate,
 		"CommitHash":       commitHash,
-		// Data type (e.g metrics/traces/logs)
+		// Data type (e.g metrics/traces/logs), joined with "+" for mixed workloads (e.g logs+metrics)
 		"DataType":         dataType,
 		// Performance metrics of the monitored services.
 		"Results":          result,
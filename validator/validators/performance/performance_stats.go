@@ -5,13 +5,17 @@ package performance
 
 import (
 	"log"
-	"math"
-	"sort"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/summary"
 )
 
 type Stats struct {
 	Average float64
+	P50     float64
+	P90     float64
+	P95     float64
 	P99     float64 //99% percent process
+	P999    float64
 	Max     float64
 	Min     float64
 	Period  int //in seconds
@@ -19,45 +23,32 @@ type Stats struct {
 }
 
 /*
-CalculateMetricStatisticsBasedOnDataAndPeriod takes in an array of data and returns the average, min, max, p99, and stdev of the data.
+CalculateMetricStatisticsBasedOnDataAndPeriod takes in an array of data and returns the average, min, max, percentiles, and stdev of the data.
 statistics are calculated this way instead of using GetMetricStatistics API because GetMetricStatistics would require multiple
 API calls as only one metric can be requested/processed at a time whereas all metrics can be requested in one GetMetricData request.
+The percentiles are interpolated linearly between the two closest values and the data is not modified.
 */
 func CalculateMetricStatisticsBasedOnDataAndPeriod(data []float64, dataPeriod float64) Stats {
-	length := len(data)
-	if length == 0 {
+	if len(data) == 0 {
 		return Stats{}
 	}
 
-	sort.Float64s(data)
-
-	min := data[0]
-	max := data[length-1]
-
-	sum := 0.0
-	for _, value := range data {
-		sum += value
-	}
-
-	avg := sum / float64(length)
-
-	if length < 99 {
-		log.Println("Note: less than 99 values given, p99 value will be equal the max value")
-	}
-	p99Index := int(float64(length)*.99) - 1
-	p99Val := data[p99Index]
-
-	stdDevSum := 0.0
-	for _, value := range data {
-		stdDevSum += math.Pow(avg-value, 2)
+	s, err := summary.Summarize(data, summary.DefaultMethod, 0.5, 0.9, 0.95, 0.99, 0.999)
+	if err != nil {
+		log.Printf("Error: failed to summarize %d values: %v", len(data), err)
+		return Stats{}
 	}
 
 	return Stats{
-		Average: avg,
-		Max:     max,
-		Min:     min,
-		P99:     p99Val,
-		Std:     math.Sqrt(stdDevSum / float64(length)),
-		Period:  int(dataPeriod / float64(length)),
+		Average: s.Mean,
+		P50:     s.Quantiles["p50"],
+		P90:     s.Quantiles["p90"],
+		P95:     s.Quantiles["p95"],
+		P99:     s.Quantiles["p99"],
+		P999:    s.Quantiles["p99.9"],
+		Max:     s.Max,
+		Min:     s.Min,
+		Std:     s.Std,
+		Period:  int(dataPeriod / float64(len(data))),
 	}
 }
@@ -0,0 +1,51 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package performance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateMetricStatisticsBasedOnDataAndPeriod(t *testing.T) {
+	testCases := map[string]struct {
+		data []float64
+		want Stats
+	}{
+		"Empty": {
+			data: nil,
+			want: Stats{},
+		},
+		"SingleValue": {
+			data: []float64{7},
+			want: Stats{Average: 7, P50: 7, P90: 7, P95: 7, P99: 7, P999: 7, Max: 7, Min: 7, Period: 60},
+		},
+		"Interpolated": {
+			data: []float64{30, 10, 20, 40},
+			want: Stats{Average: 25, P50: 25, P90: 37, P95: 38.5, P99: 39.7, P999: 39.97, Max: 40, Min: 10, Period: 15, Std: 11.180339887},
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			original := append([]float64(nil), testCase.data...)
+			got := CalculateMetricStatisticsBasedOnDataAndPeriod(testCase.data, 60)
+			assert.Equal(t, testCase.want.Period, got.Period)
+			for statistic, values := range map[string][2]float64{
+				"Average": {testCase.want.Average, got.Average},
+				"P50":     {testCase.want.P50, got.P50},
+				"P90":     {testCase.want.P90, got.P90},
+				"P95":     {testCase.want.P95, got.P95},
+				"P99":     {testCase.want.P99, got.P99},
+				"P999":    {testCase.want.P999, got.P999},
+				"Max":     {testCase.want.Max, got.Max},
+				"Min":     {testCase.want.Min, got.Min},
+				"Std":     {testCase.want.Std, got.Std},
+			} {
+				assert.InDelta(t, values[0], values[1], 1e-6, statistic)
+			}
+			assert.Equal(t, original, testCase.data, "the data must not be sorted in place")
+		})
+	}
+}
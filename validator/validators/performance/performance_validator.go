@@ -12,18 +12,21 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/cenkalti/backoff/v4"
-	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 
 	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/summary"
 	"github.com/aws/amazon-cloudwatch-agent-test/validator/models"
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/registry"
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/regression"
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/resultstore"
 	"github.com/aws/amazon-cloudwatch-agent-test/validator/validators/basic"
 )
 
 const (
 	ServiceName      = "AmazonCloudWatchAgent"
-	DynamoDBDataBase = "CWAPerformanceMetrics"
+	DynamoDBDataBase = resultstore.DefaultDynamoDBTable
 )
 
 var (
@@ -33,15 +36,29 @@ var (
 
 type PerformanceValidator struct {
 	vConfig models.ValidateConfig
+	clock   clock.Clock
 	models.ValidatorFactory
 }
 
 var _ models.ValidatorFactory = (*PerformanceValidator)(nil)
 
-func NewPerformanceValidator(vConfig models.ValidateConfig) models.ValidatorFactory {
+func init() {
+	registry.RegisterValidator(registry.Validator{
+		Name:        "performance",
+		Description: "Record the agent's resource usage under the load of one or more receivers and save it to the results store",
+		Parameters: append(append([]registry.Parameter{}, basic.Parameters...),
+			registry.Parameter{Name: "commit_hash", Description: "agent commit the performance is recorded for", Required: true},
+			registry.Parameter{Name: "commit_date", Description: "commit date of the agent in epoch seconds", Required: true},
+		),
+		New: NewPerformanceValidator,
+	})
+}
+
+func NewPerformanceValidator(vConfig models.ValidateConfig, clk clock.Clock) models.ValidatorFactory {
 	return &PerformanceValidator{
 		vConfig:          vConfig,
-		ValidatorFactory: basic.NewBasicValidator(vConfig),
+		clock:            clk,
+		ValidatorFactory: basic.NewBasicValidator(vConfig, clk),
 	}
 }
 
@@ -72,53 +89,25 @@ func (s *PerformanceValidator) CheckData(startTime, endTime time.Time) error {
 		return err
 	}
 
-	return nil
+	// Compare with the previous commits of the use case so a regression fails (or warns about) the run
+	return regression.Check(regression.CurrentConfig(), resultstore.Default(), resultstore.Record(perfInfo))
 }
 
+// SendPacketToDatabase saves the performance information to the results store selected with
+// --results-store, merging it with the results already stored for the commit and use case.
 func (s *PerformanceValidator) SendPacketToDatabase(perfInfo PerformanceInformation) error {
-	var (
-		dataType               = s.vConfig.GetDataType()
-		receiver               = s.vConfig.GetPluginsConfig()[0] //Assuming one plugin at a time
-		commitHash, commitDate = s.vConfig.GetCommitInformation()
-		agentCollectionPeriod  = fmt.Sprint(s.vConfig.GetAgentCollectionPeriod().Seconds())
-		// The secondary global index that is used for checking if there are item has already been exist in the table
-		// The performance validator will query based on the UseCaseHash to confirm if the current commit with the use case
-		// has been exist or not? If yes, merge it. If not, sending it to the database
-		// https://github.com/aws/amazon-cloudwatch-agent-test/blob/e07fe7adb1b1d75244d8984507d3f83a7237c3d3/terraform/setup/main.tf#L46-L53
-		kCheckingAttribute = []string{"CommitHash", "UseCase"}
-		vCheckingAttribute = []string{fmt.Sprint(commitHash), receiver}
-	)
-
-	err := backoff.Retry(func() error {
-		existingPerfInfo, err := awsservice.GetItemInDatabase(DynamoDBDataBase, "UseCaseHash", kCheckingAttribute, vCheckingAttribute, perfInfo)
-		if err != nil {
-			return err
-		}
-
-		// Get the latest performance information from the database and update by merging the existing one
-		// and finally replace the packet in the database
-		maps.Copy(existingPerfInfo["Results"].(map[string]interface{}), perfInfo["Results"].(map[string]interface{}))
-
-		finalPerfInfo := packIntoPerformanceInformation(existingPerfInfo["UniqueID"].(string), receiver, dataType, agentCollectionPeriod, commitHash, commitDate, existingPerfInfo["Results"])
-
-		err = awsservice.ReplaceItemInDatabase(DynamoDBDataBase, finalPerfInfo)
-
-		if err != nil {
-			return err
-		}
-		return nil
-	}, awsservice.StandardExponentialBackoff)
-
-	return err
+	store := resultstore.Default()
+	log.Printf("Saving the performance information of use case %v to %s", perfInfo["UseCase"], store.Name())
+	return store.Save(resultstore.Record(perfInfo))
 }
+
 func (s *PerformanceValidator) CalculateMetricStatsAndPackMetrics(metrics []types.MetricDataResult) (PerformanceInformation, error) {
 	var (
-		receiver               = s.vConfig.GetPluginsConfig()[0] //Assuming one plugin at a time
-		commitHash, commitDate = s.vConfig.GetCommitInformation()
-		dataType               = s.vConfig.GetDataType()
-		dataRate               = fmt.Sprint(s.vConfig.GetDataRate())
-		uniqueID               = s.vConfig.GetUniqueID()
-		agentCollectionPeriod  = s.vConfig.GetAgentCollectionPeriod().Seconds()
+		useCase, dataType, receivers = useCaseInformation(s.vConfig.GetPluginsConfig(), s.vConfig.GetDataType())
+		commitHash, commitDate       = s.vConfig.GetCommitInformation()
+		dataRate                     = fmt.Sprint(s.vConfig.GetDataRate())
+		uniqueID                     = s.vConfig.GetUniqueID()
+		agentCollectionPeriod        = s.vConfig.GetAgentCollectionPeriod().Seconds()
 	)
 	performanceMetricResults := make(map[string]Stats)
 
@@ -141,17 +130,16 @@ func (s *PerformanceValidator) CalculateMetricStatsAndPackMetrics(metrics []type
 		performanceMetricResults[metricName] = metricStats
 	}
 
-	return packIntoPerformanceInformation(uniqueID, receiver, dataType, fmt.Sprint(agentCollectionPeriod), commitHash, commitDate, map[string]interface{}{dataRate: performanceMetricResults}), nil
+	return packIntoPerformanceInformation(uniqueID, useCase, receivers, dataType, fmt.Sprint(agentCollectionPeriod), commitHash, commitDate, map[string]interface{}{dataRate: performanceMetricResults}), nil
 }
 
 func (s *PerformanceValidator) CalculateWindowsMetricStatsAndPackMetrics(statistic []*cloudwatch.GetMetricStatisticsOutput) (PerformanceInformation, error) {
 	var (
-		receiver               = s.vConfig.GetPluginsConfig()[0] //Assuming one plugin at a time
-		commitHash, commitDate = s.vConfig.GetCommitInformation()
-		dataType               = s.vConfig.GetDataType()
-		dataRate               = fmt.Sprint(s.vConfig.GetDataRate())
-		uniqueID               = s.vConfig.GetUniqueID()
-		agentCollectionPeriod  = s.vConfig.GetAgentCollectionPeriod().Seconds()
+		useCase, dataType, receivers = useCaseInformation(s.vConfig.GetPluginsConfig(), s.vConfig.GetDataType())
+		commitHash, commitDate       = s.vConfig.GetCommitInformation()
+		dataRate                     = fmt.Sprint(s.vConfig.GetDataRate())
+		uniqueID                     = s.vConfig.GetUniqueID()
+		agentCollectionPeriod        = s.vConfig.GetAgentCollectionPeriod().Seconds()
 	)
 	performanceMetricResults := make(map[string]Stats)
 
@@ -181,7 +169,7 @@ func (s *PerformanceValidator) CalculateWindowsMetricStatsAndPackMetrics(statist
 		performanceMetricResults[metricName] = metricStats
 	}
 
-	return packIntoPerformanceInformation(uniqueID, receiver, dataType, fmt.Sprint(agentCollectionPeriod), commitHash, commitDate, map[string]interface{}{dataRate: performanceMetricResults}), nil
+	return packIntoPerformanceInformation(uniqueID, useCase, receivers, dataType, fmt.Sprint(agentCollectionPeriod), commitHash, commitDate, map[string]interface{}{dataRate: performanceMetricResults}), nil
 }
 
 func (s *PerformanceValidator) GetPerformanceMetrics(startTime, endTime time.Time) ([]types.MetricDataResult, error) {
@@ -293,18 +281,20 @@ func (s *PerformanceValidator) buildPerformanceMetricQueries(metricName, metricN
 
 // packIntoPerformanceInformation will package all the information into the required format of MongoDb Database
 // https://github.com/aws/amazon-cloudwatch-agent-test/blob/e07fe7adb1b1d75244d8984507d3f83a7237c3d3/terraform/setup/main.tf#L8-L63
-func packIntoPerformanceInformation(uniqueID, receiver, dataType, collectionPeriod, commitHash string, commitDate int64, result interface{}) PerformanceInformation {
+func packIntoPerformanceInformation(uniqueID, useCase string, receivers []string, dataType, collectionPeriod, commitHash string, commitDate int64, result interface{}) PerformanceInformation {
 	instanceAMI := awsservice.GetImageId()
 	instanceType := awsservice.GetInstanceType()
 
 	return PerformanceInformation{
 		"UniqueID":         uniqueID,
 		"Service":          ServiceName,
-		"UseCase":          receiver,
+		"UseCase":          useCase,
+		"Receivers":        receivers,
 		"CommitDate":       commitDate,
 		"CommitHash":       commitHash,
 		"DataType":         dataType,
 		"Results":          result,
+		"PercentileMethod": string(summary.DefaultMethod),
 		"CollectionPeriod": collectionPeriod,
 		"InstanceAMI":      instanceAMI,
 		"InstanceType":     instanceType,
@@ -0,0 +1,391 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package stress
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	baselineVersion = 1
+	// defaultErrorBound is the tolerance above the bound of a baseline that does not set error_bound
+	defaultErrorBound = 0.3
+	// recommendedDigits is the number of significant digits recommended bounds are rounded up to
+	recommendedDigits = 3
+)
+
+// DefaultBaselineDir is where the baselines live in the repository, relative to its root.
+const DefaultBaselineDir = "validator/validators/stress/baselines"
+
+//go:embed baselines/*.yaml
+var embeddedBaselines embed.FS
+
+var (
+	baselineMu sync.RWMutex
+	baselineFS fs.FS
+)
+
+func init() {
+	baselineFS, _ = fs.Sub(embeddedBaselines, "baselines")
+}
+
+// SetBaselineDir makes the stress validator read its baselines from dir instead of the ones built into
+// the validator. An empty dir restores the built in baselines.
+func SetBaselineDir(dir string) {
+	baselineMu.Lock()
+	defer baselineMu.Unlock()
+	if dir == "" {
+		baselineFS, _ = fs.Sub(embeddedBaselines, "baselines")
+		return
+	}
+	baselineFS = os.DirFS(dir)
+}
+
+func baselines() ([]Baseline, error) {
+	baselineMu.RLock()
+	defer baselineMu.RUnlock()
+	return LoadBaselines(baselineFS)
+}
+
+// Baseline is a YAML file of stress bounds for an OS, optionally narrowed down to an arch and an
+// instance type. Several baselines can match a host, the more specific ones override the bounds of the
+// others metric by metric.
+type Baseline struct {
+	Version      int                    `yaml:"version"`
+	OS           string                 `yaml:"os"`
+	Arch         string                 `yaml:"arch,omitempty"`
+	InstanceType string                 `yaml:"instance_type,omitempty"`
+	ErrorBound   *float64               `yaml:"error_bound,omitempty"`
+	Bounds       MetricPluginBoundValue `yaml:"bounds"`
+
+	// Path is the file the baseline was read from
+	Path string `yaml:"-"`
+}
+
+// Target is the host the bounds are resolved for.
+type Target struct {
+	OS           string
+	Arch         string
+	InstanceType string
+}
+
+// NewTarget returns the target of a host, the OS being windows or linux for every other os_family.
+func NewTarget(osFamily, arch, instanceType string) Target {
+	target := Target{OS: "linux", Arch: arch, InstanceType: instanceType}
+	if osFamily == "windows" {
+		target.OS = "windows"
+	}
+	return target
+}
+
+func (t Target) String() string {
+	parts := []string{t.OS}
+	if t.Arch != "" {
+		parts = append(parts, t.Arch)
+	}
+	if t.InstanceType != "" {
+		parts = append(parts, t.InstanceType)
+	}
+	return strings.Join(parts, "-")
+}
+
+func (b Baseline) target() Target {
+	return Target{OS: b.OS, Arch: b.Arch, InstanceType: b.InstanceType}
+}
+
+func (b Baseline) matches(t Target) bool {
+	return b.OS == t.OS && (b.Arch == "" || b.Arch == t.Arch) && (b.InstanceType == "" || b.InstanceType == t.InstanceType)
+}
+
+// specificity orders the matching baselines, an instance type being more specific than an arch.
+func (b Baseline) specificity() int {
+	specificity := 0
+	if b.Arch != "" {
+		specificity++
+	}
+	if b.InstanceType != "" {
+		specificity += 2
+	}
+	return specificity
+}
+
+// LoadBaselines reads every .yaml file at the root of fsys.
+func LoadBaselines(fsys fs.FS) ([]Baseline, error) {
+	paths, err := fs.Glob(fsys, "*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	var result []Baseline
+	for _, p := range paths {
+		content, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, err
+		}
+		baseline, err := parseBaseline(content)
+		if err != nil {
+			return nil, fmt.Errorf("baseline %s: %w", p, err)
+		}
+		baseline.Path = p
+		result = append(result, baseline)
+	}
+	return result, nil
+}
+
+func parseBaseline(content []byte) (Baseline, error) {
+	var baseline Baseline
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&baseline); err != nil {
+		return Baseline{}, err
+	}
+	if baseline.Version != baselineVersion {
+		return Baseline{}, fmt.Errorf("unsupported version %d, must be %d", baseline.Version, baselineVersion)
+	}
+	if baseline.OS != "linux" && baseline.OS != "windows" {
+		return Baseline{}, fmt.Errorf("os must be linux or windows, got %q", baseline.OS)
+	}
+	if baseline.ErrorBound != nil && *baseline.ErrorBound < 0 {
+		return Baseline{}, fmt.Errorf("error_bound must not be negative, got %v", *baseline.ErrorBound)
+	}
+	return baseline, nil
+}
+
+// Bounds are the stress bounds resolved for a target.
+type Bounds struct {
+	Values     MetricPluginBoundValue
+	ErrorBound float64
+	// Sources are the baseline files the bounds come from, least specific first
+	Sources []string
+}
+
+// ResolveBounds merges the baselines matching the target, the more specific ones winning.
+func ResolveBounds(baselines []Baseline, target Target) (Bounds, error) {
+	var matching []Baseline
+	for _, baseline := range baselines {
+		if baseline.matches(target) {
+			matching = append(matching, baseline)
+		}
+	}
+	if len(matching) == 0 {
+		return Bounds{}, fmt.Errorf("no stress baseline for %s", target)
+	}
+	sort.SliceStable(matching, func(i, j int) bool { return matching[i].specificity() < matching[j].specificity() })
+
+	bounds := Bounds{Values: MetricPluginBoundValue{}, ErrorBound: defaultErrorBound}
+	for _, baseline := range matching {
+		if baseline.ErrorBound != nil {
+			bounds.ErrorBound = *baseline.ErrorBound
+		}
+		for dataRate, receivers := range baseline.Bounds {
+			for receiver, metrics := range receivers {
+				for metric, value := range metrics {
+					bounds.set(dataRate, receiver, metric, value)
+				}
+			}
+		}
+		bounds.Sources = append(bounds.Sources, baseline.Path)
+	}
+	return bounds, nil
+}
+
+func (b Bounds) set(dataRate, receiver, metric string, value float64) {
+	if b.Values[dataRate] == nil {
+		b.Values[dataRate] = map[string]map[string]float64{}
+	}
+	if b.Values[dataRate][receiver] == nil {
+		b.Values[dataRate][receiver] = map[string]float64{}
+	}
+	b.Values[dataRate][receiver][metric] = value
+}
+
+// Bound returns the baseline of the metric, without the error bound.
+func (b Bounds) Bound(dataRate, receiver, metric string) (float64, bool) {
+	value, ok := b.Values[dataRate][receiver][metric]
+	return value, ok
+}
+
+// UpperBound returns the highest value the metric is allowed to reach.
+func (b Bounds) UpperBound(dataRate, receiver, metric string) (float64, error) {
+	if _, ok := b.Values[dataRate][receiver]; !ok {
+		return 0, fmt.Errorf("\n plugin %s does not have data rate %s", receiver, dataRate)
+	}
+	value, ok := b.Values[dataRate][receiver][metric]
+	if !ok {
+		return 0, fmt.Errorf("\n metric %s does not have bound", metric)
+	}
+	return value * (1 + b.ErrorBound), nil
+}
+
+// Recommend returns a bound for every metric observed: the highest value over the runs with the headroom
+// added, rounded up to a few significant digits so the baselines stay readable.
+func Recommend(observations []map[string]float64, headroom float64) map[string]float64 {
+	recommended := map[string]float64{}
+	for _, observation := range observations {
+		for metric, value := range observation {
+			if current, ok := recommended[metric]; !ok || value > current {
+				recommended[metric] = value
+			}
+		}
+	}
+	for metric, value := range recommended {
+		recommended[metric] = roundUp(value*(1+headroom), recommendedDigits)
+	}
+	return recommended
+}
+
+func roundUp(value float64, digits int) float64 {
+	if value <= 0 {
+		return 0
+	}
+	scale := math.Pow(10, float64(digits)-math.Ceil(math.Log10(value)))
+	return math.Ceil(value*scale) / scale
+}
+
+// BaselineUpdate is a change UpdateBaseline made, or would make on a dry run, to a baseline file.
+type BaselineUpdate struct {
+	Path string
+	// Old is the content of the file before the update, nil when the file is created
+	Old []byte
+	New []byte
+}
+
+// Diff returns the unified diff of the update, empty when the file does not change.
+func (u BaselineUpdate) Diff() (string, error) {
+	from := u.Path
+	if u.Old == nil {
+		from = "/dev/null"
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(u.Old)),
+		B:        difflib.SplitLines(string(u.New)),
+		FromFile: from,
+		ToFile:   u.Path,
+		Context:  3,
+	})
+}
+
+// UpdateBaseline writes the bounds of the receiver at the data rate into the baseline of dir whose
+// selector is exactly the target, creating <os>[-<arch>[-<instance type>]].yaml if there is none. The
+// other bounds and the comments of the file are kept so the change reviews as a small diff. The file is
+// not written with dryRun.
+func UpdateBaseline(dir string, target Target, dataRate, receiver string, bounds map[string]float64, dryRun bool) (BaselineUpdate, error) {
+	existing, err := LoadBaselines(os.DirFS(dir))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return BaselineUpdate{}, err
+	}
+	var (
+		file = target.String() + ".yaml"
+		doc  yaml.Node
+	)
+	for _, baseline := range existing {
+		if baseline.target() == target {
+			file = baseline.Path
+			break
+		}
+	}
+	update := BaselineUpdate{Path: filepath.Join(dir, filepath.FromSlash(file))}
+
+	content, err := os.ReadFile(update.Path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		content = newBaseline(target)
+	case err != nil:
+		return BaselineUpdate{}, err
+	default:
+		update.Old = content
+	}
+	if err = yaml.Unmarshal(content, &doc); err != nil {
+		return BaselineUpdate{}, fmt.Errorf("baseline %s: %w", update.Path, err)
+	}
+
+	metrics := make([]string, 0, len(bounds))
+	for metric := range bounds {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+	for _, metric := range metrics {
+		setBoundNode(&doc, dataRate, receiver, metric, bounds[metric])
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err = encoder.Encode(&doc); err != nil {
+		return BaselineUpdate{}, err
+	}
+	update.New = buf.Bytes()
+	if dryRun {
+		return update, nil
+	}
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return BaselineUpdate{}, err
+	}
+	return update, os.WriteFile(update.Path, update.New, 0644)
+}
+
+func newBaseline(target Target) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Stress test bounds of the agent on %s, overriding the bounds of the less specific baselines.\n", target)
+	fmt.Fprintf(&buf, "version: %d\nos: %s\n", baselineVersion, target.OS)
+	if target.Arch != "" {
+		fmt.Fprintf(&buf, "arch: %s\n", target.Arch)
+	}
+	if target.InstanceType != "" {
+		fmt.Fprintf(&buf, "instance_type: %s\n", target.InstanceType)
+	}
+	buf.WriteString("bounds: {}\n")
+	return buf.Bytes()
+}
+
+// setBoundNode sets bounds.<data rate>.<receiver>.<metric> in the document, adding the missing keys.
+func setBoundNode(doc *yaml.Node, dataRate, receiver, metric string, value float64) {
+	node := doc.Content[0]
+	for _, key := range []string{"bounds", dataRate, receiver} {
+		node = mappingValue(node, key, true)
+	}
+	scalar := mappingValue(node, metric, false)
+	scalar.Kind, scalar.Tag, scalar.Style = yaml.ScalarNode, "!!int", 0
+	scalar.Value = strconv.FormatFloat(value, 'f', -1, 64)
+	if value != math.Trunc(value) {
+		scalar.Tag = "!!float"
+	}
+}
+
+// mappingValue returns the value of the key in the mapping node, adding an empty one if it is missing.
+func mappingValue(mapping *yaml.Node, key string, isMapping bool) *yaml.Node {
+	if mapping.Kind != yaml.MappingNode {
+		*mapping = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	// Flow style (e.g bounds: {}) would put every bound added on a single line
+	mapping.Style &^= yaml.FlowStyle
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+	if _, err := strconv.Atoi(key); err == nil {
+		keyNode.Style = yaml.DoubleQuotedStyle
+	}
+	valueNode := &yaml.Node{Kind: yaml.ScalarNode}
+	if isMapping {
+		valueNode = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	mapping.Content = append(mapping.Content, keyNode, valueNode)
+	return valueNode
+}
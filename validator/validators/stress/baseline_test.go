@@ -0,0 +1,225 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package stress
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedBaselines(t *testing.T) {
+	all, err := baselines()
+	require.NoError(t, err)
+
+	linux, err := ResolveBounds(all, NewTarget("linux", "amd64", "c5.xlarge"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"linux.yaml"}, linux.Sources)
+	assert.Equal(t, 0.3, linux.ErrorBound)
+	bound, ok := linux.Bound("1000", "statsd", "procstat_cpu_usage")
+	assert.True(t, ok)
+	assert.Equal(t, float64(25), bound)
+	upperBound, err := linux.UpperBound("50000", "logs", "procstat_memory_rss")
+	require.NoError(t, err)
+	assert.InDelta(t, 840000000*1.3, upperBound, 1)
+
+	// Every os_family other than windows uses the linux bounds
+	darwin, err := ResolveBounds(all, NewTarget("darwin", "arm64", ""))
+	require.NoError(t, err)
+	assert.Equal(t, linux.Values, darwin.Values)
+
+	windows, err := ResolveBounds(all, NewTarget("windows", "amd64", ""))
+	require.NoError(t, err)
+	bound, ok = windows.Bound("1000", "logs", "procstat cpu_usage")
+	assert.True(t, ok)
+	assert.Equal(t, float64(250), bound)
+	_, err = windows.UpperBound("1000", "statsd", "procstat cpu_usage")
+	assert.ErrorContains(t, err, "plugin statsd does not have data rate 1000")
+	_, err = windows.UpperBound("1000", "logs", "procstat_num_fds")
+	assert.ErrorContains(t, err, "metric procstat_num_fds does not have bound")
+}
+
+func TestResolveBoundsOverrides(t *testing.T) {
+	fsys := fstest.MapFS{
+		"linux.yaml": {Data: []byte(`
+version: 1
+os: linux
+bounds:
+  "1000":
+    statsd:
+      procstat_cpu_usage: 25
+      procstat_memory_rss: 150000000
+`)},
+		"linux-arm64.yaml": {Data: []byte(`
+version: 1
+os: linux
+arch: arm64
+error_bound: 0.2
+bounds:
+  "1000":
+    statsd:
+      procstat_cpu_usage: 30
+`)},
+		"linux-arm64-c6g.large.yaml": {Data: []byte(`
+version: 1
+os: linux
+arch: arm64
+instance_type: c6g.large
+bounds:
+  "1000":
+    statsd:
+      procstat_memory_rss: 160000000
+`)},
+		"windows.yaml": {Data: []byte(`
+version: 1
+os: windows
+bounds: {}
+`)},
+	}
+	all, err := LoadBaselines(fsys)
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		target     Target
+		sources    []string
+		errorBound float64
+		cpu        float64
+		rss        float64
+	}{
+		"Amd64": {
+			target:     NewTarget("linux", "amd64", "c5.large"),
+			sources:    []string{"linux.yaml"},
+			errorBound: defaultErrorBound,
+			cpu:        25,
+			rss:        150000000,
+		},
+		"Arm64": {
+			target:     NewTarget("linux", "arm64", "m6g.large"),
+			sources:    []string{"linux.yaml", "linux-arm64.yaml"},
+			errorBound: 0.2,
+			cpu:        30,
+			rss:        150000000,
+		},
+		"Arm64InstanceType": {
+			target:     NewTarget("linux", "arm64", "c6g.large"),
+			sources:    []string{"linux.yaml", "linux-arm64.yaml", "linux-arm64-c6g.large.yaml"},
+			errorBound: 0.2,
+			cpu:        30,
+			rss:        160000000,
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			bounds, err := ResolveBounds(all, testCase.target)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.sources, bounds.Sources)
+			assert.Equal(t, testCase.errorBound, bounds.ErrorBound)
+			assert.Equal(t, map[string]float64{"procstat_cpu_usage": testCase.cpu, "procstat_memory_rss": testCase.rss}, bounds.Values["1000"]["statsd"])
+		})
+	}
+}
+
+func TestLoadBaselinesErrors(t *testing.T) {
+	testCases := map[string]struct {
+		content string
+		wantErr string
+	}{
+		"UnknownField":       {content: "version: 1\nos: linux\nbound: {}\n", wantErr: "field bound not found"},
+		"UnsupportedVersion": {content: "version: 2\nos: linux\n", wantErr: "unsupported version 2"},
+		"UnknownOS":          {content: "version: 1\nos: darwin\n", wantErr: `os must be linux or windows, got "darwin"`},
+		"NegativeErrorBound": {content: "version: 1\nos: linux\nerror_bound: -0.1\n", wantErr: "error_bound must not be negative"},
+		"NonNumericBound":    {content: "version: 1\nos: linux\nbounds: {\"1000\": {statsd: {procstat_cpu_usage: high}}}\n", wantErr: "cannot unmarshal"},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadBaselines(fstest.MapFS{"linux.yaml": {Data: []byte(testCase.content)}})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "baseline linux.yaml: ")
+			assert.Contains(t, err.Error(), testCase.wantErr)
+		})
+	}
+
+	_, err := ResolveBounds(nil, NewTarget("linux", "amd64", ""))
+	assert.EqualError(t, err, "no stress baseline for linux-amd64")
+}
+
+func TestRecommend(t *testing.T) {
+	recommended := Recommend([]map[string]float64{
+		{"procstat_cpu_usage": 21.3, "procstat_memory_rss": 143123456, "procstat_memory_swap": 0},
+		{"procstat_cpu_usage": 22.9, "procstat_memory_rss": 139000000, "procstat_memory_swap": 0},
+	}, 0.1)
+	assert.Equal(t, map[string]float64{
+		"procstat_cpu_usage":   25.2,
+		"procstat_memory_rss":  158000000,
+		"procstat_memory_swap": 0,
+	}, recommended)
+}
+
+func TestUpdateBaseline(t *testing.T) {
+	dir := t.TempDir()
+	original, err := embeddedBaselines.ReadFile("baselines/linux.yaml")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "linux.yaml"), original, 0644))
+
+	bounds := map[string]float64{"procstat_cpu_usage": 27.5, "procstat_memory_rss": 150000000, "procstat_threads": 40}
+	update, err := UpdateBaseline(dir, Target{OS: "linux"}, "1000", "statsd", bounds, true)
+	require.NoError(t, err)
+	path := update.Path
+	assert.Equal(t, filepath.Join(dir, "linux.yaml"), path)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(original), string(content), "dry run must not write the baseline")
+	assert.Equal(t, string(original), string(update.Old))
+	diff, err := update.Diff()
+	require.NoError(t, err)
+	assert.Contains(t, diff, "--- "+path+"\n+++ "+path+"\n")
+	assert.Contains(t, diff, "\n-      procstat_cpu_usage: 25\n+      procstat_cpu_usage: 27.5\n")
+
+	written, err := UpdateBaseline(dir, Target{OS: "linux"}, "1000", "statsd", bounds, false)
+	require.NoError(t, err)
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(update.New), string(content), "the dry run diff must match what is written")
+	assert.Equal(t, string(content), string(written.New))
+
+	// Only the changed bounds show up in the diff, the comments are kept
+	var changed []string
+	originalLines := strings.Split(string(original), "\n")
+	for i, line := range strings.Split(string(content), "\n") {
+		if i >= len(originalLines) || line != originalLines[i] {
+			changed = append(changed, strings.TrimSpace(line))
+		}
+	}
+	assert.Contains(t, changed, "procstat_cpu_usage: 27.5")
+	assert.Contains(t, string(content), "# Bounds bumped 2026-05-08")
+	assert.Contains(t, string(content), "# Single use case where most of the metrics will be dropped.")
+
+	all, err := LoadBaselines(os.DirFS(dir))
+	require.NoError(t, err)
+	resolved, err := ResolveBounds(all, NewTarget("linux", "amd64", ""))
+	require.NoError(t, err)
+	assert.Equal(t, 27.5, resolved.Values["1000"]["statsd"]["procstat_cpu_usage"])
+	assert.Equal(t, float64(40), resolved.Values["1000"]["statsd"]["procstat_threads"])
+	assert.Equal(t, float64(20), resolved.Values["1000"]["collectd"]["procstat_cpu_usage"])
+
+	// A scoped baseline is created with only the bounds written to it
+	update, err = UpdateBaseline(dir, Target{OS: "linux", Arch: "arm64"}, "5000", "emf", map[string]float64{"procstat_cpu_usage": 30}, false)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "linux-arm64.yaml"), update.Path)
+	assert.Nil(t, update.Old)
+	diff, err = update.Diff()
+	require.NoError(t, err)
+	assert.Contains(t, diff, "--- /dev/null\n")
+	all, err = LoadBaselines(os.DirFS(dir))
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "linux-arm64.yaml", all[0].Path)
+	assert.Equal(t, "arm64", all[0].Arch)
+	assert.Equal(t, MetricPluginBoundValue{"5000": {"emf": {"procstat_cpu_usage": 30}}}, all[0].Bounds)
+}
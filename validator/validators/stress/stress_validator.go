@@ -6,6 +6,7 @@ package stress
 import (
 	"fmt"
 	"log"
+	"runtime"
 	"strings"
 	"time"
 
@@ -14,378 +15,51 @@ import (
 	"go.uber.org/multierr"
 
 	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
 	"github.com/aws/amazon-cloudwatch-agent-test/validator/models"
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/registry"
 	"github.com/aws/amazon-cloudwatch-agent-test/validator/validators/basic"
 	"github.com/aws/amazon-cloudwatch-agent-test/validator/validators/util"
 )
 
+// MetricPluginBoundValue are the bounds of the metrics keyed by data rate, receiver and metric name.
 type MetricPluginBoundValue map[string]map[string]map[string]float64
 
-// Todo:
-// * Create a database  to store these metrics instead of using cache?
-// * Create a workflow to update the bound metrics?
-// * Add more metrics (healthcheckextension to detect dropping metrics and prometheus to detect agent crash in EKS env)
-//
-// Bounds bumped 2026-05-08 after run 25433434462 baselined ~25-30% higher
-// procstat_memory_data and proportional net_bytes_sent. Follow-up needed:
-// investigate the agent-side cause of the memory growth.
-var (
-	metricErrorBound       = 0.3
-	metricPluginBoundValue = MetricPluginBoundValue{
-		"1000": {
-			"statsd": {
-				"procstat_cpu_usage":   float64(25),
-				"procstat_memory_rss":  float64(150000000),
-				"procstat_memory_swap": float64(0),
-				"procstat_memory_vms":  float64(1550000000),
-				"procstat_memory_data": float64(138000000),
-				"procstat_num_fds":     float64(11),
-				"net_bytes_sent":       float64(116000),
-				"net_packets_sent":     float64(105),
-			},
-			"collectd": {
-				"procstat_cpu_usage":   float64(20),
-				"procstat_memory_rss":  float64(150000000),
-				"procstat_memory_swap": float64(0),
-				"procstat_memory_vms":  float64(1550000000),
-				"procstat_memory_data": float64(135000000),
-				"procstat_num_fds":     float64(11),
-				"net_bytes_sent":       float64(116000),
-				"net_packets_sent":     float64(105),
-			},
-			"logs": {
-				"procstat_cpu_usage":   float64(250),
-				"procstat_memory_rss":  float64(340000000),
-				"procstat_memory_swap": float64(0),
-				"procstat_memory_vms":  float64(1550000000),
-				"procstat_memory_data": float64(290000000),
-				"procstat_num_fds":     float64(110),
-				"net_bytes_sent":       float64(1800000),
-				"net_packets_sent":     float64(5000),
-			},
-			"system": {
-				"procstat_cpu_usage":   float64(16),
-				"procstat_memory_rss":  float64(150000000),
-				"procstat_memory_swap": float64(0),
-				"procstat_memory_vms":  float64(1550000000),
-				"procstat_memory_data": float64(135000000),
-				"procstat_num_fds":     float64(12),
-				"net_bytes_sent":       float64(90000),
-				"net_packets_sent":     float64(100),
-			},
-			"emf": {
-				"procstat_cpu_usage":   float64(35),
-				"procstat_memory_rss":  float64(150000000),
-				"procstat_memory_swap": float64(0),
-				"procstat_memory_vms":  float64(1550000000),
-				"procstat_memory_data": float64(128000000),
-				"procstat_num_fds":     float64(12),
-				"net_bytes_sent":       float64(90000),
-				"net_packets_sent":     float64(100),
-			},
-			"prometheus": {
-				"procstat_cpu_usage":   float64(12),
-				"procstat_memory_rss":  float64(165000000),
-				"procstat_memory_swap": float64(0),
-				"procstat_memory_vms":  float64(1610000000),
-				"procstat_memory_data": float64(128000000),
-				"procstat_num_fds":     float64(10),
-			},
-		},
-		"5000": {
-			"statsd": {
-				"procstat_cpu_usage":   float64(110),
-				"procstat_memory_rss":  float64(225000000),
-				"procstat_memory_swap": float64(0),
-				"procstat_memory_vms":  float64(1550000000),
-				"procstat_memory_data": float64(200000000),
-				"procstat_num_fds":     float64(15),
-				"net_bytes_sent":       float64(524000),
-				"net_packets_sent":     float64(520),
-			},
-			"collectd": {
-				"procstat_cpu_usage":   float64(90),
-				"procstat_memory_rss":  float64(190000000),
-				"procstat_memory_swap": float64(0),
-				"procstat_memory_vms":  float64(1550000000),
-				"procstat_memory_data": float64(165000000),
-				"procstat_num_fds":     float64(17),
-				"net_bytes_sent":       float64(490000),
-				"net_packets_sent":     float64(450),
-			},
-			"logs": {
-				"procstat_cpu_usage":   float64(400),
-				"procstat_memory_rss":  float64(580000000),
-				"procstat_memory_swap": float64(0),
-				"procstat_memory_vms":  float64(1550000000),
-				"procstat_memory_data": float64(570000000),
-				"procstat_num_fds":     float64(180),
-				"net_bytes_sent":       float64(6500000),
-				"net_packets_sent":     float64(8500),
-			},
-			"system": {
-				"procstat_cpu_usage":   float64(20),
-				"procstat_memory_rss":  float64(150000000),
-				"procstat_memory_swap": float64(0),
-				"procstat_memory_vms":  float64(1550000000),
-				"procstat_memory_data": float64(138000000),
-				"procstat_num_fds":     float64(12),
-				"net_bytes_sent":       float64(90000),
-				"net_packets_sent":     float64(120),
-			},
-			"emf": {
-				"procstat_cpu_usage":   float64(25),
-				"procstat_memory_rss":  float64(150000000),
-				"procstat_memory_swap": float64(0),
-				"procstat_memory_vms":  float64(1550000000),
-				"procstat_memory_data": float64(135000000),
-				"procstat_num_fds":     float64(12),
-				"net_bytes_sent":       float64(90000),
-				"net_packets_sent":     float64(120),
-			},
-			"prometheus": {
-				"procstat_cpu_usage":   float64(13),
-				"procstat_memory_rss":  float64(170000000),
-				"procstat_memory_swap": float64(0),
-				"procstat_memory_vms":  float64(1600000000),
-				"procstat_memory_data": float64(130000000),
-				"procstat_num_fds":     float64(10),
-			},
-		},
-		"10000": {
-			"statsd": {
-				"procstat_cpu_usage":   float64(200),
-				"procstat_memory_rss":  float64(230000000),
-				"procstat_memory_swap": float64(0),
-				"procstat_memory_vms":  float64(1550000000),
-				"procstat_memory_data": float64(240000000),
-				"procstat_num_fds":     float64(17),
-				"net_bytes_sent":       float64(980000),
-				"net_packets_sent":     float64(860),
-			},
-			"collectd": {
-				"procstat_cpu_usage":   float64(120),
-				"procstat_memory_rss":  float64(170000000),
-				"procstat_memory_swap": float64(0),
-				"procstat_memory_vms":  float64(1550000000),
-				"procstat_memory_data": float64(195000000),
-				"procstat_num_fds":     float64(17),
-				"net_bytes_sent":       float64(760000),
-				"net_packets_sent":     float64(700),
-			},
-			"logs": {
-				"procstat_cpu_usage":   float64(400),
-				"procstat_memory_rss":  float64(840000000),
-				"procstat_memory_swap": float64(0),
-				"procstat_memory_vms":  float64(1700000000),
-				"procstat_memory_data": float64(870000000),
-				"procstat_num_fds":     float64(180),
-				"net_bytes_sent":       float64(6820000),
-				"net_packets_sent":     float64(8300),
-			},
-			"system": {
-				"procstat_cpu_usage":   float64(35),
-				"procstat_memory_rss":  float64(150000000),
-				"procstat_memory_swap": float64(0),
-				"procstat_memory_vms":  float64(1550000000),
-				"procstat_memory_data": float64(138000000),
-				"procstat_num_fds":     float64(12),
-				"net_bytes_sent":       float64(90000),
-				"net_packets_sent":     float64(120),
-			},
-			"emf": {
-				"procstat_cpu_usage":   float64(45),
-				"procstat_memory_rss":  float64(150000000),
-				"procstat_memory_swap": float64(0),
-				"procstat_memory_vms":  float64(1550000000),
-				"procstat_memory_data": float64(145000000),
-				"procstat_num_fds":     float64(12),
-				"net_bytes_sent":       float64(110000),
-				"net_packets_sent":     float64(120),
-			},
-			"prometheus": {
-				"procstat_cpu_usage":   float64(100),
-				"procstat_memory_rss":  float64(1450000000),
-				"procstat_memory_swap": float64(0),
-				"procstat_memory_vms":  float64(1750000000),
-				"procstat_memory_data": float64(270000000),
-				"procstat_num_fds":     float64(10),
-			},
-		},
-		// Single use case where most of the metrics will be dropped. Since the default buffer for telegraf is 10000
-		// https://github.com/aws/amazon-cloudwatch-agent/blob/c85501042b088014ec40b636a8b6b2ccc9739738/translator/translate/agent/ruleMetricBufferLimit.go#L14
-		// For more information on Metric Buffer and how they will exchange for the resources, please follow
-		// https://github.com/influxdata/telegraf/wiki/MetricBuffer
-
-		"50000": {
-			"statsd": {
-				"procstat_cpu_usage":   float64(300),
-				"procstat_memory_rss":  float64(585000000),
-				"procstat_memory_swap": float64(0),
-				"procstat_memory_vms":  float64(2200000000),
-				"procstat_memory_data": float64(630000000),
-				"procstat_num_fds":     float64(18),
-				"net_bytes_sent":       float64(2100000),
-				"net_packets_sent":     float64(1620),
-			},
-			"collectd": {
-				"procstat_cpu_usage":   float64(220),
-				"procstat_memory_rss":  float64(258000000),
-				"procstat_memory_swap": float64(0),
-				"procstat_memory_vms":  float64(1500000000),
-				"procstat_memory_data": float64(270000000),
-				"procstat_num_fds":     float64(18),
-				"net_bytes_sent":       float64(1250000),
-				"net_packets_sent":     float64(1100),
-			},
-			"logs": {
-				"procstat_cpu_usage":   float64(400),
-				"procstat_memory_rss":  float64(840000000),
-				"procstat_memory_swap": float64(0),
-				"procstat_memory_vms":  float64(2150000000),
-				"procstat_memory_data": float64(680000000),
-				"procstat_num_fds":     float64(200),
-				"net_bytes_sent":       float64(6900000),
-				"net_packets_sent":     float64(6500),
-			},
-			"system": {
-				"procstat_cpu_usage":   float64(35),
-				"procstat_memory_rss":  float64(150000000),
-				"procstat_memory_swap": float64(0),
-				"procstat_memory_vms":  float64(1400000000),
-				"procstat_memory_data": float64(138000000),
-				"procstat_num_fds":     float64(12),
-				"net_bytes_sent":       float64(90000),
-				"net_packets_sent":     float64(100),
-			},
-			"emf": {
-				"procstat_cpu_usage":   float64(165),
-				"procstat_memory_rss":  float64(200000000),
-				"procstat_memory_swap": float64(0),
-				"procstat_memory_vms":  float64(1400000000),
-				"procstat_memory_data": float64(165000000),
-				"procstat_num_fds":     float64(12),
-				"net_bytes_sent":       float64(280000),
-				"net_packets_sent":     float64(250),
-			},
-			"prometheus": {
-				"procstat_cpu_usage":   float64(130),
-				"procstat_memory_rss":  float64(400000000),
-				"procstat_memory_swap": float64(0),
-				"procstat_memory_vms":  float64(1900000000),
-				"procstat_memory_data": float64(360000000),
-				"procstat_num_fds":     float64(10),
-			},
-		},
-	}
-
-	windowsMetricPluginBoundValue = MetricPluginBoundValue{
-		"1000": {
-			"logs": {
-				"procstat cpu_usage":   float64(250),
-				"procstat memory_rss":  float64(260000000),
-				"procstat memory_vms":  float64(1088000000),
-				"Bytes_Sent_Per_Sec":   float64(1800000),
-				"Packets_Sent_Per_Sec": float64(5000),
-			},
-			"system": {
-				"procstat cpu_usage":   float64(35),
-				"procstat memory_rss":  float64(130000000),
-				"procstat memory_vms":  float64(1018000000),
-				"Bytes_Sent_Per_Sec":   float64(140000),
-				"Packets_Sent_Per_Sec": float64(130),
-			},
-		},
-		"5000": {
-			"logs": {
-				"procstat cpu_usage":   float64(400),
-				"procstat memory_rss":  float64(580000000),
-				"procstat memory_vms":  float64(1300000000),
-				"Bytes_Sent_Per_Sec":   float64(6500000),
-				"Packets_Sent_Per_Sec": float64(8500),
-			},
-			"system": {
-				"procstat cpu_usage":   float64(35),
-				"procstat memory_rss":  float64(130000000),
-				"procstat memory_vms":  float64(1018000000),
-				"Bytes_Sent_Per_Sec":   float64(140000),
-				"Packets_Sent_Per_Sec": float64(130),
-			},
-		},
-		"10000": {
-			"logs": {
-				"procstat cpu_usage":   float64(400),
-				"procstat memory_rss":  float64(840000000),
-				"procstat memory_vms":  float64(1700000000),
-				"Bytes_Sent_Per_Sec":   float64(6820000),
-				"Packets_Sent_Per_Sec": float64(8300),
-			},
-			"system": {
-				"procstat cpu_usage":   float64(35),
-				"procstat memory_rss":  float64(130000000),
-				"procstat memory_vms":  float64(1018000000),
-				"Bytes_Sent_Per_Sec":   float64(140000),
-				"Packets_Sent_Per_Sec": float64(130),
-			},
-		},
-		// Single use case where most of the metrics will be dropped. Since the default buffer for telegraf is 10000
-		// https://github.com/aws/amazon-cloudwatch-agent/blob/c85501042b088014ec40b636a8b6b2ccc9739738/translator/translate/agent/ruleMetricBufferLimit.go#L14
-		// For more information on Metric Buffer and how they will exchange for the resources, please follow
-		// https://github.com/influxdata/telegraf/wiki/MetricBuffer
-
-		"50000": {
-			"logs": {
-				"procstat cpu_usage":   float64(400),
-				"procstat memory_rss":  float64(840000000),
-				"procstat memory_vms":  float64(1700000000),
-				"Bytes_Sent_Per_Sec":   float64(6900000),
-				"Packets_Sent_Per_Sec": float64(6500),
-			},
-			"system": {
-				"procstat cpu_usage":   float64(35),
-				"procstat memory_rss":  float64(130000000),
-				"procstat memory_vms":  float64(1018000000),
-				"Bytes_Sent_Per_Sec":   float64(140000),
-				"Packets_Sent_Per_Sec": float64(130),
-			},
-		},
-	}
-)
-
 type StressValidator struct {
 	vConfig models.ValidateConfig
+	bounds  *Bounds
 	models.ValidatorFactory
 }
 
 var _ models.ValidatorFactory = (*StressValidator)(nil)
 
-func NewStressValidator(vConfig models.ValidateConfig) models.ValidatorFactory {
+func init() {
+	registry.RegisterValidator(registry.Validator{
+		Name:        "stress",
+		Description: "Send high load and validate the agent's resource usage stays within bounds",
+		Parameters:  basic.Parameters,
+		New:         NewStressValidator,
+	})
+}
+
+func NewStressValidator(vConfig models.ValidateConfig, clk clock.Clock) models.ValidatorFactory {
 	return &StressValidator{
 		vConfig:          vConfig,
-		ValidatorFactory: basic.NewBasicValidator(vConfig),
+		ValidatorFactory: basic.NewBasicValidator(vConfig, clk),
 	}
 }
 
 func (s *StressValidator) CheckData(startTime, endTime time.Time) error {
 	var (
 		multiErr         error
-		ec2InstanceId    = awsservice.GetInstanceId()
 		metricNamespace  = s.vConfig.GetMetricNamespace()
 		validationMetric = s.vConfig.GetMetricValidation()
 	)
+	if _, err := s.Bounds(); err != nil {
+		return err
+	}
 	for _, metric := range validationMetric {
-		metricDimensions := []types.Dimension{
-			{
-				Name:  aws.String("InstanceId"),
-				Value: aws.String(ec2InstanceId),
-			},
-		}
-		for _, dimension := range metric.MetricDimension {
-			metricDimensions = append(metricDimensions, types.Dimension{
-				Name:  aws.String(dimension.Name),
-				Value: aws.String(dimension.Value),
-			})
-		}
+		metricDimensions := s.metricDimensions(metric)
 
 		var err error
 		if s.vConfig.GetOSFamily() == "windows" {
@@ -401,6 +75,67 @@ func (s *StressValidator) CheckData(startTime, endTime time.Time) error {
 	return multiErr
 }
 
+// Observe returns the maximum of every validated metric between the start and end time, which the
+// baseline command recommends bounds from.
+func (s *StressValidator) Observe(startTime, endTime time.Time) (map[string]float64, error) {
+	var (
+		observation     = map[string]float64{}
+		metricNamespace = s.vConfig.GetMetricNamespace()
+	)
+	for _, metric := range s.vConfig.GetMetricValidation() {
+		var (
+			value float64
+			err   error
+		)
+		if s.vConfig.GetOSFamily() == "windows" {
+			value, err = s.windowsMetricMaximum(metric.MetricName, metricNamespace, s.metricDimensions(metric), startTime, endTime)
+		} else {
+			value, err = s.metricMaximum(metric.MetricName, metricNamespace, s.metricDimensions(metric), startTime, endTime)
+		}
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Metric %s within the namespace %s has maximum %f \n", metric.MetricName, metricNamespace, value)
+		observation[metric.MetricName] = value
+	}
+	return observation, nil
+}
+
+// Bounds returns the bounds of the baselines matching the OS, arch and instance type the validator runs on.
+func (s *StressValidator) Bounds() (Bounds, error) {
+	if s.bounds != nil {
+		return *s.bounds, nil
+	}
+	all, err := baselines()
+	if err != nil {
+		return Bounds{}, err
+	}
+	target := NewTarget(s.vConfig.GetOSFamily(), runtime.GOARCH, awsservice.GetInstanceType())
+	bounds, err := ResolveBounds(all, target)
+	if err != nil {
+		return Bounds{}, err
+	}
+	log.Printf("Using the stress bounds of %s from %s", target, strings.Join(bounds.Sources, ", "))
+	s.bounds = &bounds
+	return bounds, nil
+}
+
+func (s *StressValidator) metricDimensions(metric models.MetricValidation) []types.Dimension {
+	metricDimensions := []types.Dimension{
+		{
+			Name:  aws.String("InstanceId"),
+			Value: aws.String(awsservice.GetInstanceId()),
+		},
+	}
+	for _, dimension := range metric.MetricDimension {
+		metricDimensions = append(metricDimensions, types.Dimension{
+			Name:  aws.String(dimension.Name),
+			Value: aws.String(dimension.Value),
+		})
+	}
+	return metricDimensions
+}
+
 func (s *StressValidator) ValidateStressMetric(metricName, metricNamespace string, metricDimensions []types.Dimension, metricSampleCount int, startTime, endTime time.Time) error {
 	var (
 		dataRate       = fmt.Sprint(s.vConfig.GetDataRate())
@@ -408,32 +143,24 @@ func (s *StressValidator) ValidateStressMetric(metricName, metricNamespace strin
 		receiver       = s.vConfig.GetPluginsConfig()[0] //Assuming one plugin at a time
 	)
 
-	stressMetricQueries := s.buildStressMetricQueries(metricName, metricNamespace, metricDimensions)
-
 	log.Printf("Start to collect and validate metric %s with the namespace %s, start time %v and end time %v \n", metricName, metricNamespace, startTime, endTime)
 
-	// We are only interested in the maximum metric values within the time range
-	metrics, err := awsservice.GetMetricData(stressMetricQueries, startTime, endTime)
+	metricValue, err := s.metricMaximum(metricName, metricNamespace, metricDimensions, startTime, endTime)
 	if err != nil {
 		return err
 	}
 
-	if len(metrics.MetricDataResults) == 0 || len(metrics.MetricDataResults[0].Values) == 0 {
-		return fmt.Errorf("\n getting metric %s failed with the namespace %s and dimension %v", metricName, metricNamespace, util.LogCloudWatchDimension(metricDimensions))
-	}
-
-	if _, ok := metricPluginBoundValue[dataRate][receiver]; !ok {
-		return fmt.Errorf("\n plugin %s does not have data rate", receiver)
-	}
-
-	if _, ok := metricPluginBoundValue[dataRate][receiver][metricName]; !ok {
-		return fmt.Errorf("\n metric %s does not have bound", receiver)
+	bounds, err := s.Bounds()
+	if err != nil {
+		return err
 	}
 
 	// Assuming each plugin are testing one at a time
-	// Validate if the corresponding metrics are within the acceptable range [acceptable value +- 30%]
-	metricValue := metrics.MetricDataResults[0].Values[0]
-	upperBoundValue := metricPluginBoundValue[dataRate][receiver][metricName] * (1 + metricErrorBound)
+	// Validate if the corresponding metrics are within the acceptable range [acceptable value +- error bound]
+	upperBoundValue, err := bounds.UpperBound(dataRate, receiver, metricName)
+	if err != nil {
+		return err
+	}
 	log.Printf("Metric %s within the namespace %s has value of %f and the upper bound is %f \n", metricName, metricNamespace, metricValue, upperBoundValue)
 
 	if metricValue < 0 || metricValue > upperBoundValue {
@@ -457,36 +184,22 @@ func (s *StressValidator) ValidateStressMetricWindows(metricName, metricNamespac
 	)
 	log.Printf("Start to collect and validate metric %s with the namespace %s, start time %v and end time %v \n", metricName, metricNamespace, startTime, endTime)
 
-	metrics, err := awsservice.GetMetricStatistics(
-		metricName,
-		metricNamespace,
-		metricDimensions,
-		startTime,
-		endTime,
-		int32(boundAndPeriod),
-		[]types.Statistic{types.StatisticMaximum},
-		nil,
-	)
+	metricValue, err := s.windowsMetricMaximum(metricName, metricNamespace, metricDimensions, startTime, endTime)
 	if err != nil {
 		return err
 	}
 
-	if len(metrics.Datapoints) == 0 || metrics.Datapoints[0].Maximum == nil {
-		return fmt.Errorf("\n getting metric %s failed with the namespace %s and dimension %v", metricName, metricNamespace, util.LogCloudWatchDimension(metricDimensions))
-	}
-
-	if _, ok := windowsMetricPluginBoundValue[dataRate][receiver]; !ok {
-		return fmt.Errorf("\n plugin %s does not have data rate", receiver)
-	}
-
-	if _, ok := windowsMetricPluginBoundValue[dataRate][receiver][metricName]; !ok {
-		return fmt.Errorf("\n metric %s does not have bound", metricName)
+	bounds, err := s.Bounds()
+	if err != nil {
+		return err
 	}
 
 	// Assuming each plugin are testing one at a time
-	// Validate if the corresponding metrics are within the acceptable range [acceptable value +- 30%]
-	metricValue := *metrics.Datapoints[0].Maximum
-	upperBoundValue := windowsMetricPluginBoundValue[dataRate][receiver][metricName] * (1 + metricErrorBound)
+	// Validate if the corresponding metrics are within the acceptable range [acceptable value +- error bound]
+	upperBoundValue, err := bounds.UpperBound(dataRate, receiver, metricName)
+	if err != nil {
+		return err
+	}
 	log.Printf("Metric %s within the namespace %s has value of %f and the upper bound is %f \n", metricName, metricNamespace, metricValue, upperBoundValue)
 
 	if metricValue < 0 || metricValue > upperBoundValue {
@@ -502,6 +215,47 @@ func (s *StressValidator) ValidateStressMetricWindows(metricName, metricNamespac
 	return nil
 }
 
+// metricMaximum returns the maximum of the metric within the time range.
+func (s *StressValidator) metricMaximum(metricName, metricNamespace string, metricDimensions []types.Dimension, startTime, endTime time.Time) (float64, error) {
+	stressMetricQueries := s.buildStressMetricQueries(metricName, metricNamespace, metricDimensions)
+
+	// We are only interested in the maximum metric values within the time range
+	metrics, err := awsservice.GetMetricData(stressMetricQueries, startTime, endTime)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(metrics.MetricDataResults) == 0 || len(metrics.MetricDataResults[0].Values) == 0 {
+		return 0, fmt.Errorf("\n getting metric %s failed with the namespace %s and dimension %v", metricName, metricNamespace, util.LogCloudWatchDimension(metricDimensions))
+	}
+
+	return metrics.MetricDataResults[0].Values[0], nil
+}
+
+// windowsMetricMaximum returns the maximum of the metric within the time range. Windows procstat metrics
+// contain a space which GetMetricData does not support, so it uses GetMetricStatistics instead.
+func (s *StressValidator) windowsMetricMaximum(metricName, metricNamespace string, metricDimensions []types.Dimension, startTime, endTime time.Time) (float64, error) {
+	metrics, err := awsservice.GetMetricStatistics(
+		metricName,
+		metricNamespace,
+		metricDimensions,
+		startTime,
+		endTime,
+		int32(s.vConfig.GetAgentCollectionPeriod().Seconds()),
+		[]types.Statistic{types.StatisticMaximum},
+		nil,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(metrics.Datapoints) == 0 || metrics.Datapoints[0].Maximum == nil {
+		return 0, fmt.Errorf("\n getting metric %s failed with the namespace %s and dimension %v", metricName, metricNamespace, util.LogCloudWatchDimension(metricDimensions))
+	}
+
+	return *metrics.Datapoints[0].Maximum, nil
+}
+
 func (s *StressValidator) buildStressMetricQueries(metricName, metricNamespace string, metricDimensions []types.Dimension) []types.MetricDataQuery {
 	var (
 		metricQueryPeriod = int32(s.vConfig.GetAgentCollectionPeriod().Seconds())
@@ -8,53 +8,45 @@ import (
 	"log"
 	"time"
 
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
 	"github.com/aws/amazon-cloudwatch-agent-test/validator/models"
-	"github.com/aws/amazon-cloudwatch-agent-test/validator/validators/feature"
-	"github.com/aws/amazon-cloudwatch-agent-test/validator/validators/performance"
-	"github.com/aws/amazon-cloudwatch-agent-test/validator/validators/stress"
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/registry"
+
+	// Validate types register themselves with the registry.
+	_ "github.com/aws/amazon-cloudwatch-agent-test/validator/validators/feature"
+	_ "github.com/aws/amazon-cloudwatch-agent-test/validator/validators/performance"
+	_ "github.com/aws/amazon-cloudwatch-agent-test/validator/validators/stress"
 )
 
-func NewValidator(vConfig models.ValidateConfig) (validator models.ValidatorFactory, err error) {
-	switch vConfig.GetValidateType() {
-	case "performance":
-		validator = performance.NewPerformanceValidator(vConfig)
-	case "feature":
-		validator = feature.NewFeatureValidator(vConfig)
-	case "stress":
-		validator = stress.NewStressValidator(vConfig)
-	default:
-		return nil, fmt.Errorf("unknown validation type %s provided by test case %s", vConfig.GetValidateType(), vConfig.GetTestCase())
-	}
+// cloudWatchProcessingDelay is how long to wait after the load is sent for CloudWatch to make all the
+// data available for querying.
+const cloudWatchProcessingDelay = 2 * time.Minute
 
-	return validator, nil
+// NewValidator creates the validator registered for the validate_type of the config.
+func NewValidator(vConfig models.ValidateConfig, clk clock.Clock) (models.ValidatorFactory, error) {
+	v, err := registry.LookupValidator(vConfig.GetValidateType())
+	if err != nil {
+		return nil, fmt.Errorf("test case %s: %w", vConfig.GetTestCase(), err)
+	}
+	return v.New(vConfig, clk), nil
 }
 
-func LaunchValidator(vConfig models.ValidateConfig) error {
-	var (
-		agentCollectionPeriod    = vConfig.GetAgentCollectionPeriod()
-		startTimeValidation      = time.Now().Truncate(time.Minute).Add(time.Minute)
-		endTimeValidation        = startTimeValidation.Add(agentCollectionPeriod)
-		durationBeforeNextMinute = time.Until(startTimeValidation)
-	)
-
-	validator, err := NewValidator(vConfig)
+func LaunchValidator(vConfig models.ValidateConfig, clk clock.Clock) error {
+	validator, err := NewValidator(vConfig, clk)
 	if err != nil {
 		return err
 	}
-	log.Printf("Start to sleep %f s for the metric to be available in the beginning of next minute ", durationBeforeNextMinute.Seconds())
-	time.Sleep(durationBeforeNextMinute)
+	return runValidator(validator, vConfig.GetAgentCollectionPeriod(), clk)
+}
 
-	log.Printf("Start to generate load in %f s for the agent to collect and send all the metrics to CloudWatch within the datapoint period ", agentCollectionPeriod.Seconds())
-	err = validator.GenerateLoad()
+// runValidator drives a validator through GenerateLoad -> CheckData -> Cleanup, waiting on clk so the
+// flow can be tested with a fake clock.
+func runValidator(validator models.ValidatorFactory, agentCollectionPeriod time.Duration, clk clock.Clock) error {
+	startTimeValidation, endTimeValidation, err := runLoad(validator, agentCollectionPeriod, clk)
 	if err != nil {
 		return err
-
 	}
 
-	time.Sleep(agentCollectionPeriod)
-	log.Printf("Start to sleep 120s for CloudWatch to process all the metrics")
-	time.Sleep(2 * time.Minute)
-
 	err = validator.CheckData(startTimeValidation, endTimeValidation)
 	if err != nil {
 		return err
@@ -68,3 +60,75 @@ func LaunchValidator(vConfig models.ValidateConfig) error {
 	return nil
 
 }
+
+// runLoad generates the load from the beginning of the next minute and waits for CloudWatch to make it
+// available, returning the time range the data can be queried in. The data is stamped with the wall
+// clock, so when an accelerated clock cuts the waits short the range is widened to the wall clock times
+// the load ran in.
+func runLoad(validator models.ValidatorFactory, agentCollectionPeriod time.Duration, clk clock.Clock) (time.Time, time.Time, error) {
+	var (
+		startTimeValidation      = clk.Now().Truncate(time.Minute).Add(time.Minute)
+		durationBeforeNextMinute = startTimeValidation.Sub(clk.Now())
+	)
+
+	log.Printf("Start to sleep %f s for the metric to be available in the beginning of next minute ", durationBeforeNextMinute.Seconds())
+	clk.Sleep(durationBeforeNextMinute)
+	if loadStart := clk.Now(); loadStart.Before(startTimeValidation) {
+		startTimeValidation = loadStart.Truncate(time.Minute)
+	}
+	endTimeValidation := startTimeValidation.Add(agentCollectionPeriod)
+
+	log.Printf("Start to generate load in %f s for the agent to collect and send all the metrics to CloudWatch within the datapoint period ", agentCollectionPeriod.Seconds())
+	err := validator.GenerateLoad()
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	clk.Sleep(agentCollectionPeriod)
+	// A real clock only overshoots the end by the scheduling delay of the sleep
+	if loadEnd := clk.Now(); loadEnd.Sub(endTimeValidation) >= time.Second {
+		endTimeValidation = loadEnd
+	}
+	log.Printf("Start to sleep %f s for CloudWatch to process all the metrics", cloudWatchProcessingDelay.Seconds())
+	clk.Sleep(cloudWatchProcessingDelay)
+
+	return startTimeValidation, endTimeValidation, nil
+}
+
+// Observer is a validator that can report the values it validates, so bounds can be recommended from
+// them (e.g the stress baselines).
+type Observer interface {
+	models.ValidatorFactory
+	Observe(startTime, endTime time.Time) (map[string]float64, error)
+}
+
+// Observe runs the load of the config the given number of times and returns what the validator observed
+// in each run.
+func Observe(vConfig models.ValidateConfig, clk clock.Clock, iterations int) ([]map[string]float64, error) {
+	validator, err := NewValidator(vConfig, clk)
+	if err != nil {
+		return nil, err
+	}
+	observer, ok := validator.(Observer)
+	if !ok {
+		return nil, fmt.Errorf("validate type %s cannot observe its metrics", vConfig.GetValidateType())
+	}
+	return observe(observer, vConfig.GetAgentCollectionPeriod(), clk, iterations)
+}
+
+func observe(observer Observer, agentCollectionPeriod time.Duration, clk clock.Clock, iterations int) ([]map[string]float64, error) {
+	var observations []map[string]float64
+	for i := 1; i <= iterations; i++ {
+		log.Printf("Start iteration %d of %d", i, iterations)
+		startTime, endTime, err := runLoad(observer, agentCollectionPeriod, clk)
+		if err != nil {
+			return nil, err
+		}
+		observation, err := observer.Observe(startTime, endTime)
+		if err != nil {
+			return nil, fmt.Errorf("iteration %d: %w", i, err)
+		}
+		observations = append(observations, observation)
+	}
+	return observations, observer.Cleanup()
+}
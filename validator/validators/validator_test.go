@@ -0,0 +1,119 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package validators
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
+)
+
+type recordingValidator struct {
+	clock      *clock.Fake
+	calls      []string
+	loadTime   time.Time
+	startTime  time.Time
+	endTime    time.Time
+	checkError error
+}
+
+func (v *recordingValidator) GenerateLoad() error {
+	v.calls = append(v.calls, "GenerateLoad")
+	v.loadTime = v.clock.Now()
+	return nil
+}
+
+func (v *recordingValidator) CheckData(startTime, endTime time.Time) error {
+	v.calls = append(v.calls, "CheckData")
+	v.startTime, v.endTime = startTime, endTime
+	return v.checkError
+}
+
+func (v *recordingValidator) Cleanup() error {
+	v.calls = append(v.calls, "Cleanup")
+	return nil
+}
+
+func TestRunValidator(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 10, 0, 15, 0, time.UTC))
+	validator := &recordingValidator{clock: clk}
+
+	require.NoError(t, runValidator(validator, 5*time.Minute, clk))
+
+	assert.Equal(t, []string{"GenerateLoad", "CheckData", "Cleanup"}, validator.calls)
+	assert.Equal(t, time.Date(2024, 1, 1, 10, 1, 0, 0, time.UTC), validator.loadTime)
+	assert.Equal(t, time.Date(2024, 1, 1, 10, 1, 0, 0, time.UTC), validator.startTime)
+	assert.Equal(t, time.Date(2024, 1, 1, 10, 6, 0, 0, time.UTC), validator.endTime)
+	assert.Equal(t, 45*time.Second+5*time.Minute+cloudWatchProcessingDelay, clk.Slept())
+}
+
+func TestRunValidatorSkipsCleanupOnFailedCheck(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC))
+	validator := &recordingValidator{clock: clk, checkError: errors.New("missing datapoints")}
+
+	assert.EqualError(t, runValidator(validator, time.Minute, clk), "missing datapoints")
+	assert.Equal(t, []string{"GenerateLoad", "CheckData"}, validator.calls)
+}
+
+// wallClockValidator writes its data at wall clock times, like the agent and the load generators.
+type wallClockValidator struct {
+	written   time.Time
+	startTime time.Time
+	endTime   time.Time
+}
+
+func (v *wallClockValidator) GenerateLoad() error {
+	v.written = time.Now()
+	return nil
+}
+
+func (v *wallClockValidator) CheckData(startTime, endTime time.Time) error {
+	v.startTime, v.endTime = startTime, endTime
+	return nil
+}
+
+func (v *wallClockValidator) Cleanup() error { return nil }
+
+func TestRunValidatorAcceleratedFindsWallClockData(t *testing.T) {
+	validator := &wallClockValidator{}
+	started := time.Now()
+
+	require.NoError(t, runValidator(validator, time.Minute, clock.Accelerated(600)))
+
+	assert.Less(t, time.Since(started), 10*time.Second)
+	assert.False(t, validator.written.Before(validator.startTime), "data written at %s before the window %s", validator.written, validator.startTime)
+	assert.True(t, validator.written.Before(validator.endTime), "data written at %s after the window %s", validator.written, validator.endTime)
+}
+
+type observingValidator struct {
+	recordingValidator
+	observations []time.Time
+}
+
+func (v *observingValidator) Observe(startTime, _ time.Time) (map[string]float64, error) {
+	v.calls = append(v.calls, "Observe")
+	v.observations = append(v.observations, startTime)
+	return map[string]float64{"procstat_cpu_usage": float64(len(v.observations))}, nil
+}
+
+func TestObserve(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 10, 0, 30, 0, time.UTC))
+	validator := &observingValidator{recordingValidator: recordingValidator{clock: clk}}
+
+	observations, err := observe(validator, time.Minute, clk, 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, []map[string]float64{{"procstat_cpu_usage": 1}, {"procstat_cpu_usage": 2}}, observations)
+	assert.Equal(t, []string{"GenerateLoad", "Observe", "GenerateLoad", "Observe", "Cleanup"}, validator.calls)
+	// Every iteration starts at the beginning of the minute after the previous one was processed
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 1, 1, 10, 1, 0, 0, time.UTC),
+		time.Date(2024, 1, 1, 10, 5, 0, 0, time.UTC),
+	}, validator.observations)
+}